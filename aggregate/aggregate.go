@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"time"
 
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/core"
@@ -22,33 +23,105 @@ type aggregate interface {
 
 // Load returns the aggregate based on its events
 func Load(ctx context.Context, es core.EventStore, id string, a aggregate) error {
+	return load(ctx, es, id, a, nil)
+}
+
+// LoadVersion returns the aggregate as it was at the version by only applying the events up to and including the
+// version. If the aggregate has fewer events it's loaded to its latest version, check the aggregate Version to know
+// the version it's loaded to.
+func LoadVersion(ctx context.Context, es core.EventStore, id string, version eventsourcing.Version, a aggregate) error {
+	return load(ctx, es, id, a, func(event eventsourcing.Event) bool {
+		return event.Version() > version
+	})
+}
+
+// LoadAt returns the aggregate as it was at the point in time by only applying the events with a timestamp up to and
+// including t. eventsourcing.ErrAggregateNotFound is returned if the aggregate had no events at the time.
+func LoadAt(ctx context.Context, es core.EventStore, id string, t time.Time, a aggregate) error {
+	return load(ctx, es, id, a, func(event eventsourcing.Event) bool {
+		return event.Timestamp().After(t)
+	})
+}
+
+// load builds the aggregate from its events until stop returns true, a nil stop applies all events
+func load(ctx context.Context, es core.EventStore, id string, a aggregate, stop func(eventsourcing.Event) bool) error {
 	if reflect.ValueOf(a).Kind() != reflect.Ptr {
 		return eventsourcing.ErrAggregateNeedsToBeAPointer
 	}
 
 	root := a.root()
+	oldTypes := internal.GlobalRegister.OldTypes(aggregateType(a))
+	if root.aggregateStream != "" || len(oldTypes) == 0 {
+		_, err := loadStream(ctx, es, id, streamType(a), root.Version(), a, stop)
+		if err != nil {
+			return err
+		}
+	} else {
+		// the events could be stored with an old aggregate type, refetch the last known event to find the stream
+		// holding it
+		from := root.Version()
+		if from > 0 {
+			from--
+		}
+		for _, typ := range append([]string{aggregateType(a)}, oldTypes...) {
+			found, err := loadStream(ctx, es, id, typ, from, a, stop)
+			if err != nil {
+				return err
+			}
+			if found {
+				if typ != aggregateType(a) {
+					root.aggregateStream = typ
+				}
+				break
+			}
+		}
+	}
+	if root.Version() == 0 {
+		return eventsourcing.ErrAggregateNotFound
+	}
+	return nil
+}
 
-	iterator, err := getEvents(ctx, es, id, aggregateType(a), root.Version())
+// loadStream builds the aggregate from the events in the stream of the aggregate type after the version. Events
+// already applied to the aggregate are skipped and the loading ends at the first event stop returns true for. Returns
+// true if the stream holds events after the version.
+func loadStream(ctx context.Context, es core.EventStore, id, aggregateType string, from eventsourcing.Version, a aggregate, stop func(eventsourcing.Event) bool) (bool, error) {
+	root := a.root()
+	iterator, err := getEvents(ctx, es, id, aggregateType, from)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer iterator.Close()
+	found := false
 	for iterator.Next() {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return found, ctx.Err()
 		default:
 			event, err := iterator.Value()
 			if err != nil {
-				return err
+				return found, err
+			}
+			found = true
+			if event.Version() <= root.Version() {
+				continue
+			}
+			if stop != nil && stop(event) {
+				return found, nil
+			}
+			if isTombstone(event) {
+				root.aggregateID = event.AggregateID()
+				root.aggregateVersion = event.Version()
+				root.aggregateGlobalVersion = event.GlobalVersion()
+				return found, eventsourcing.ErrAggregateDeleted
+			}
+			err = buildFromHistory(a, []eventsourcing.Event{event})
+			if err != nil {
+				return found, err
 			}
-			buildFromHistory(a, []eventsourcing.Event{event})
 		}
 	}
-	if root.Version() == 0 {
-		return eventsourcing.ErrAggregateNotFound
-	}
-	return nil
+	return found, nil
 }
 
 // LoadFromSnapshot fetch the aggregate by first get its snapshot and later append events after the snapshot was stored
@@ -61,8 +134,37 @@ func LoadFromSnapshot(ctx context.Context, es core.EventStore, ss core.SnapshotS
 	return Load(ctx, es, id, as)
 }
 
+// LoadWithSnapshot fetch the aggregate from its snapshot if there is one and appends the events saved after the
+// snapshot was taken. If there is no snapshot the aggregate is built from all its events. The version of the loaded
+// aggregate is returned.
+func LoadWithSnapshot(ctx context.Context, es core.EventStore, ss core.SnapshotStore, id string, as aggregateSnapshot) (eventsourcing.Version, error) {
+	if reflect.ValueOf(as).Kind() != reflect.Ptr {
+		return 0, eventsourcing.ErrAggregateNeedsToBeAPointer
+	}
+	err := getSnapshot(ctx, ss, id, as)
+	if err != nil && !errors.Is(err, core.ErrSnapshotNotFound) {
+		return 0, err
+	}
+	err = Load(ctx, es, id, as)
+	if err != nil {
+		return 0, err
+	}
+	return as.root().Version(), nil
+}
+
 // Save stores the aggregate events in the supplied event store
 func Save(es core.EventStore, a aggregate) error {
+	return SaveContext(context.Background(), es, a)
+}
+
+// SaveContext stores the aggregate events in the supplied event store. The context bounds the save in event stores
+// implementing core.ContextSaver.
+func SaveContext(ctx context.Context, es core.EventStore, a aggregate) error {
+	return save(ctx, es, a, nil)
+}
+
+// save stores the aggregate events with the metadata from the enrichers
+func save(ctx context.Context, es core.EventStore, a aggregate, enrichers []Enricher) error {
 	root := a.root()
 
 	// return as quick as possible when no events to process
@@ -73,40 +175,113 @@ func Save(es core.EventStore, a aggregate) error {
 	if !internal.GlobalRegister.AggregateRegistered(a) {
 		return fmt.Errorf("%s %w", aggregateType(a), eventsourcing.ErrAggregateNotRegistered)
 	}
+	// a tracked event was not handled by Transition, the aggregate state doesn't match its events
+	if root.unhandled != nil {
+		return root.unhandled
+	}
+	err := validateID(a, root.aggregateID)
+	if err != nil {
+		return err
+	}
 
-	globalVersion, err := saveEvents(es, root.Events())
+	events := root.Events()
+	err = beforeSave(a, events)
 	if err != nil {
 		return err
 	}
-	// update the global version on the aggregate
-	root.aggregateGlobalVersion = globalVersion
 
-	// set internal properties and reset the events slice
-	lastEvent := root.aggregateEvents[len(root.aggregateEvents)-1]
-	root.aggregateVersion = lastEvent.Version()
-	root.aggregateEvents = []eventsourcing.Event{}
+	from := root.aggregateVersion
+	globalVersion, err := saveEvents(ctx, es, events, enrichers)
+	if err != nil {
+		return err
+	}
+	root.saved(globalVersion)
+	afterSave(a, events)
+	applySnapshotPolicy(a, from)
+	return nil
+}
 
+// RegisterAlias registers old names of the aggregate type. Events stored before the aggregate was renamed are loaded
+// and new events are appended to the old event stream. Projections get the events with the current aggregate type.
+// The aggregate has to be registered via Register.
+//
+//	aggregate.Register(&Customer{})
+//	aggregate.RegisterAlias(&Customer{}, "Person")
+func RegisterAlias(a aggregate, oldTypes ...string) {
+	for _, old := range oldTypes {
+		internal.GlobalRegister.RegisterTypeAlias(aggregateType(a), old)
+	}
+}
+
+// RegisterEventAlias registers an old reason of the event on the aggregate, making events stored before the event type
+// was renamed deserialize into the event type. The event has to be registered on the aggregate.
+//
+//	aggregate.RegisterEventAlias(&Customer{}, "Born", &Registered{})
+func RegisterEventAlias(a aggregate, oldReason string, event interface{}) error {
+	if !internal.GlobalRegister.RegisterReasonAlias(aggregateType(a), oldReason, event) {
+		return fmt.Errorf("%T %w", event, eventsourcing.ErrEventNotRegistered)
+	}
 	return nil
 }
 
+// EventOf returns a registration of the event type T to use in the aggregate Register method. It creates new events
+// with the concrete type when they are deserialized instead of using reflection.
+//
+//	func (p *Person) Register(f aggregate.RegisterFunc) {
+//		f(aggregate.EventOf[Born](), aggregate.EventOf[AgedOneYear]())
+//	}
+func EventOf[T any]() internal.TypedEvent {
+	return internal.NewTypedEvent[T]()
+}
+
 // Register registers the aggregate and its events
 func Register(a aggregate) {
 	internal.GlobalRegister.Register(a)
 }
 
+// Registered returns the registered aggregate types with the reasons of their events
+func Registered() map[string][]string {
+	return internal.GlobalRegister.Registered()
+}
+
 // Save events to the event store
-func saveEvents(eventStore core.EventStore, events []eventsourcing.Event) (eventsourcing.Version, error) {
-	var esEvents = make([]core.Event, 0, len(events))
+func saveEvents(ctx context.Context, eventStore core.EventStore, events []eventsourcing.Event, enrichers []Enricher) (eventsourcing.Version, error) {
+	esEvents, err := toCoreEvents(ctx, events, enrichers)
+	if err != nil {
+		return 0, err
+	}
+
+	err = core.SaveContext(ctx, eventStore, esEvents)
+	if err != nil {
+		if errors.Is(err, core.ErrConcurrency) {
+			return 0, eventsourcing.ErrConcurrency
+		}
+		return 0, fmt.Errorf("error from event store: %w", err)
+	}
+
+	return eventsourcing.Version(esEvents[len(esEvents)-1].GlobalVersion), nil
+}
 
+// toCoreEvents serializes the events to the event store format. The metadata from the enrichers is added to the
+// event metadata, keys already in the event metadata are kept.
+func toCoreEvents(ctx context.Context, events []eventsourcing.Event, enrichers []Enricher) ([]core.Event, error) {
+	var esEvents = make([]core.Event, 0, len(events))
 	for _, event := range events {
-		data, err := internal.EventEncoder.Serialize(event.Data())
+		// the event is stored in the stream of the old aggregate type but encoded as the type it's renamed to, the
+		// same as it's decoded by the iterator
+		resolved := internal.GlobalRegister.ResolveType(event.AggregateType())
+		encoder := internal.EventEncoderFor(resolved)
+		data, err := encoder.Serialize(event.Data())
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
-		metadata, err := internal.EventEncoder.Serialize(event.Metadata())
+		enriched := enrich(ctx, event.Metadata(), enrichers)
+		metadata, err := internal.EncodeMetadata(resolved, enriched)
 		if err != nil {
-			return 0, err
+			return nil, err
 		}
+		// the correlation id is kept next to the serialized metadata for event stores to index it
+		correlationID, _ := enriched[CorrelationIDKey].(string)
 
 		esEvent := core.Event{
 			AggregateID:   event.AggregateID(),
@@ -116,33 +291,36 @@ func saveEvents(eventStore core.EventStore, events []eventsourcing.Event) (event
 			Data:          data,
 			Metadata:      metadata,
 			Reason:        event.Reason(),
+			SchemaVersion: event.SchemaVersion(),
+			CorrelationID: correlationID,
 		}
 		_, ok := internal.GlobalRegister.EventRegistered(esEvent)
 		if !ok {
-			return 0, fmt.Errorf("%s %w", esEvent.Reason, eventsourcing.ErrEventNotRegistered)
+			return nil, fmt.Errorf("%s %w", esEvent.Reason, eventsourcing.ErrEventNotRegistered)
 		}
 		esEvents = append(esEvents, esEvent)
 	}
-
-	err := eventStore.Save(esEvents)
-	if err != nil {
-		if errors.Is(err, core.ErrConcurrency) {
-			return 0, eventsourcing.ErrConcurrency
-		}
-		return 0, fmt.Errorf("error from event store: %w", err)
-	}
-
-	return eventsourcing.Version(esEvents[len(esEvents)-1].GlobalVersion), nil
+	return esEvents, nil
 }
 
 // getEvents return event iterator based on aggregate inputs from the event store
 func getEvents(ctx context.Context, eventStore core.EventStore, id, aggregateType string, fromVersion eventsourcing.Version) (*eventsourcing.Iterator, error) {
 	// fetch events after the current version of the aggregate that could be fetched from the snapshot store
-	eventIterator, err := eventStore.Get(ctx, id, aggregateType, core.Version(fromVersion))
-	if err != nil {
-		return nil, err
+	return eventsourcing.AggregateEvents(ctx, eventStore, id, aggregateType, fromVersion)
+}
+
+// lookup returns the event store as T, or the first event store it wraps implementing T
+func lookup[T any](es core.EventStore) (T, bool) {
+	for es != nil {
+		if t, ok := es.(T); ok {
+			return t, true
+		}
+		u, ok := es.(core.Unwrapper)
+		if !ok {
+			break
+		}
+		es = u.Unwrap()
 	}
-	return &eventsourcing.Iterator{
-		CoreIterator: eventIterator,
-	}, nil
+	var zero T
+	return zero, false
 }
@@ -1,11 +1,15 @@
 package aggregate_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/eventstore/memory"
 	ss "github.com/hallgren/eventsourcing/snapshotstore/memory"
 )
@@ -45,6 +49,33 @@ func TestSaveAndLoadAggregate(t *testing.T) {
 	}
 }
 
+func TestSaveContextCanceled(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = aggregate.SaveContext(ctx, es, person)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled was %v", err)
+	}
+	if !person.UnsavedEvents() {
+		t.Fatal("expected the events to still be unsaved")
+	}
+
+	err = aggregate.SaveContext(context.Background(), es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.UnsavedEvents() {
+		t.Fatal("expected the events to be saved")
+	}
+}
+
 func TestLoadAggregateFromSnapshot(t *testing.T) {
 	es := memory.Create()
 	ss := ss.Create()
@@ -80,6 +111,129 @@ func TestLoadAggregateFromSnapshot(t *testing.T) {
 	}
 }
 
+func TestLoadWithSnapshot(t *testing.T) {
+	es := memory.Create()
+	ss := ss.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no snapshot, falls back to replay all events
+	twin := &Person{}
+	version, err := aggregate.LoadWithSnapshot(context.Background(), es, ss, person.ID(), twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 2 || twin.Age != person.Age {
+		t.Fatalf("expected version 2 and age %d was %d and %d", person.Age, version, twin.Age)
+	}
+
+	err = aggregate.SaveSnapshot(ss, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// from the snapshot and the event saved after it
+	twin = &Person{}
+	version, err = aggregate.LoadWithSnapshot(context.Background(), es, ss, person.ID(), twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 3 || twin.Age != person.Age {
+		t.Fatalf("expected version 3 and age %d was %d and %d", person.Age, version, twin.Age)
+	}
+
+	// the loaded aggregate can be saved again
+	twin.GrowOlder()
+	err = aggregate.Save(es, twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = aggregate.LoadWithSnapshot(context.Background(), es, ss, "none_existing", &Person{})
+	if err != eventsourcing.ErrAggregateNotFound {
+		t.Fatalf("expected aggregate not found was %v", err)
+	}
+}
+
+func TestLoadVersion(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Delete(context.Background(), es, person.ID(), &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	twin := Person{}
+	err = aggregate.LoadVersion(context.Background(), es, person.ID(), 2, &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Version() != 2 || twin.Age != 1 {
+		t.Fatalf("expected person at version 2 was %+v", twin)
+	}
+
+	// the tombstone is applied when loading the latest version
+	err = aggregate.LoadVersion(context.Background(), es, person.ID(), 10, &Person{})
+	if !errors.Is(err, eventsourcing.ErrAggregateDeleted) {
+		t.Fatalf("expected aggregate deleted was %v", err)
+	}
+}
+
+func TestLoadAt(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	start := time.Now()
+	events := []core.Event{
+		{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: start, Data: []byte(`{"Name":"kalle"}`)},
+		{AggregateID: "123", AggregateType: "Person", Version: 2, Reason: "AgedOneYear", Timestamp: start.Add(time.Hour), Data: []byte(`{}`)},
+		{AggregateID: "123", AggregateType: "Person", Version: 3, Reason: "AgedOneYear", Timestamp: start.Add(2 * time.Hour), Data: []byte(`{}`)},
+	}
+	err := es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	person := Person{}
+	err = aggregate.LoadAt(context.Background(), es, "123", start.Add(time.Hour), &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.Version() != 2 || person.Age != 1 {
+		t.Fatalf("expected person at version 2 was %+v", person)
+	}
+
+	err = aggregate.LoadAt(context.Background(), es, "123", start.Add(-time.Hour), &Person{})
+	if !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		t.Fatalf("expected aggregate not found was %v", err)
+	}
+}
+
 func TestLoadNoneExistingAggregate(t *testing.T) {
 	es := memory.Create()
 	aggregate.Register(&Person{})
@@ -90,3 +244,157 @@ func TestLoadNoneExistingAggregate(t *testing.T) {
 		t.Fatal("could not get aggregate")
 	}
 }
+
+// prefixEncoder prefix the JSON payload to make it possible to see which encoder that was used
+type prefixEncoder struct {
+	eventsourcing.EncoderJSON
+}
+
+func (e prefixEncoder) Serialize(v interface{}) ([]byte, error) {
+	b, err := e.EncoderJSON.Serialize(v)
+	return append([]byte("prefix"), b...), err
+}
+
+func (e prefixEncoder) Deserialize(data []byte, v interface{}) error {
+	return e.EncoderJSON.Deserialize(bytes.TrimPrefix(data, []byte("prefix")), v)
+}
+
+func TestAggregateEventEncoder(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+	aggregate.Register(&versioned{})
+	eventsourcing.SetAggregateEventEncoder("Person", prefixEncoder{})
+	defer eventsourcing.SetAggregateEventEncoder("Person", eventsourcing.EncoderJSON{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := versioned{}
+	aggregate.TrackChange(&v, &Renamed{Name: "anka"})
+	err = aggregate.Save(es, &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := es.All(0, 10)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		prefixed := bytes.HasPrefix(event.Data, []byte("prefix"))
+		if prefixed != (event.AggregateType == "Person") {
+			t.Fatalf("unexpected encoding of %s event %q", event.AggregateType, event.Data)
+		}
+	}
+
+	twin := Person{}
+	err = aggregate.Load(context.Background(), es, person.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Name != "kalle" {
+		t.Fatalf("expected name kalle was %q", twin.Name)
+	}
+}
+
+// typedPerson registers its events with the generic EventOf
+type typedPerson struct {
+	aggregate.Root
+	Name string
+	Age  int
+}
+
+func (p *typedPerson) Register(f aggregate.RegisterFunc) {
+	f(aggregate.EventOf[Born](), aggregate.EventOf[AgedOneYear]())
+}
+
+func (p *typedPerson) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Born:
+		p.Name = e.Name
+	case *AgedOneYear:
+		p.Age++
+	}
+}
+
+func TestEventOf(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&typedPerson{})
+
+	p := typedPerson{}
+	aggregate.TrackChange(&p, &Born{Name: "kalle"})
+	aggregate.TrackChange(&p, &AgedOneYear{})
+	err := aggregate.Save(es, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	twin := typedPerson{}
+	err = aggregate.Load(context.Background(), es, p.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Name != "kalle" || twin.Age != 1 {
+		t.Fatalf("unexpected loaded aggregate %+v", twin)
+	}
+}
+
+func BenchmarkLoadReflect(b *testing.B) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+	p := Person{}
+	aggregate.TrackChange(&p, &Born{Name: "kalle"})
+	for i := 0; i < 100; i++ {
+		aggregate.TrackChange(&p, &AgedOneYear{})
+	}
+	err := aggregate.Save(es, &p)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err = aggregate.Load(context.Background(), es, p.ID(), &Person{})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadEventOf(b *testing.B) {
+	es := memory.Create()
+	aggregate.Register(&typedPerson{})
+	p := typedPerson{}
+	aggregate.TrackChange(&p, &Born{Name: "kalle"})
+	for i := 0; i < 100; i++ {
+		aggregate.TrackChange(&p, &AgedOneYear{})
+	}
+	err := aggregate.Save(es, &p)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err = aggregate.Load(context.Background(), es, p.ID(), &typedPerson{})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	aggregate.Register(&Person{})
+	reasons := aggregate.Registered()["Person"]
+	if len(reasons) != 3 || reasons[0] != "Born" || reasons[1] != "AgedOneYear" || reasons[2] != "Tombstone" {
+		t.Fatalf("unexpected Person events %v", reasons)
+	}
+}
@@ -0,0 +1,168 @@
+package aggregate_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+// customer is the renamed Human aggregate
+type customer struct {
+	aggregate.Root
+	Name string
+	Age  int
+}
+
+// Registered is the renamed Birth event
+type Registered struct {
+	Name string
+}
+
+func (c *customer) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Registered:
+		c.Name = e.Name
+	case *AgedOneYear:
+		c.Age++
+	}
+}
+
+func (c *customer) Register(f aggregate.RegisterFunc) {
+	f(&Registered{}, &AgedOneYear{})
+}
+
+func TestRegisterAlias(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&customer{})
+	aggregate.RegisterAlias(&customer{}, "Human")
+	err := aggregate.RegisterEventAlias(&customer{}, "Birth", &Registered{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// events stored before Human was renamed to customer and Birth to Registered
+	err = es.Save([]core.Event{
+		{AggregateID: "123", AggregateType: "Human", Version: 1, Reason: "Birth", Timestamp: time.Now(), Data: []byte(`{"Name":"kalle"}`)},
+		{AggregateID: "123", AggregateType: "Human", Version: 2, Reason: "AgedOneYear", Timestamp: time.Now(), Data: []byte(`{}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := customer{}
+	err = aggregate.Load(context.Background(), es, "123", &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "kalle" || c.Age != 1 || c.Version() != 2 {
+		t.Fatalf("unexpected customer %+v", c)
+	}
+
+	// new events are appended to the old stream
+	aggregate.TrackChange(&c, &AgedOneYear{})
+	err = aggregate.Save(es, &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	twin := customer{}
+	err = aggregate.Load(context.Background(), es, "123", &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Age != 2 || twin.Version() != 3 {
+		t.Fatalf("unexpected customer %+v", twin)
+	}
+
+	// the events are resolved to the new names
+	iterator := &eventsourcing.Iterator{CoreIterator: mustIterator(es.All(0, 10)())}
+	defer iterator.Close()
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.AggregateType() != "customer" {
+			t.Fatalf("expected aggregate type customer was %s", event.AggregateType())
+		}
+		if event.Version() == 1 && event.Reason() != "Registered" {
+			t.Fatalf("expected reason Registered was %s", event.Reason())
+		}
+	}
+}
+
+func TestRegisterAliasEventEncoder(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&customer{})
+	aggregate.RegisterAlias(&customer{}, "Human")
+	err := aggregate.RegisterEventAlias(&customer{}, "Birth", &Registered{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventsourcing.SetAggregateEventEncoder("customer", prefixEncoder{})
+	defer eventsourcing.SetAggregateEventEncoder("customer", eventsourcing.EncoderJSON{})
+
+	err = es.Save([]core.Event{
+		{AggregateID: "456", AggregateType: "Human", Version: 1, Reason: "Birth", Timestamp: time.Now(), Data: []byte(`prefix{"Name":"kalle"}`)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := customer{}
+	err = aggregate.Load(context.Background(), es, "456", &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the event appended to the old stream is encoded with the encoder of the renamed aggregate type
+	aggregate.TrackChange(&c, &AgedOneYear{})
+	err = aggregate.Save(es, &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err := es.Get(context.Background(), "456", "Human", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected the saved event in the old stream")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(event.Data, []byte("prefix")) {
+		t.Fatalf("expected the event encoded with the customer encoder was %q", event.Data)
+	}
+
+	twin := customer{}
+	err = aggregate.Load(context.Background(), es, "456", &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Name != "kalle" || twin.Age != 1 {
+		t.Fatalf("unexpected customer %+v", twin)
+	}
+}
+
+func TestRegisterEventAliasNotRegistered(t *testing.T) {
+	aggregate.Register(&Person{})
+	err := aggregate.RegisterEventAlias(&Person{}, "Renamed", &struct{}{})
+	if !errors.Is(err, eventsourcing.ErrEventNotRegistered) {
+		t.Fatalf("expected event not registered was %v", err)
+	}
+}
+
+func mustIterator(i core.Iterator, err error) core.Iterator {
+	if err != nil {
+		panic(err)
+	}
+	return i
+}
@@ -0,0 +1,120 @@
+package aggregate
+
+import (
+	"container/list"
+	"context"
+	"reflect"
+	"sync"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Cache keeps the most recently loaded aggregates in memory in front of the event store. A cached aggregate is only
+// brought up to date with the events saved after it was cached, cutting the replay cost of aggregates that are loaded
+// on every request. The least recently used aggregate is evicted when the cache is full.
+//
+// The cached aggregate is copied into the aggregate passed to Load. The copy is shallow, an aggregate with maps or
+// slices that its Transition method changes in place must not be cached.
+type Cache struct {
+	es   core.EventStore
+	size int
+
+	lock  sync.Mutex
+	order *list.List               // most recently used first
+	items map[string]*list.Element // elements by aggregate type and id
+}
+
+type cacheEntry struct {
+	key   string
+	value reflect.Value // copy of the aggregate struct
+}
+
+// NewCache creates a cache holding at most size aggregates loaded from the event store
+func NewCache(es core.EventStore, size int) *Cache {
+	return &Cache{
+		es:    es,
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Load returns the aggregate from the cache, appending the events saved after it was cached. Aggregates not in the
+// cache are built from all their events.
+func (c *Cache) Load(ctx context.Context, id string, a aggregate) error {
+	v := reflect.ValueOf(a)
+	if v.Kind() != reflect.Ptr {
+		return eventsourcing.ErrAggregateNeedsToBeAPointer
+	}
+	key := cacheKey(aggregateType(a), id)
+
+	c.lock.Lock()
+	if e, ok := c.items[key]; ok {
+		entry := e.Value.(*cacheEntry)
+		if entry.value.Type() == v.Elem().Type() {
+			v.Elem().Set(entry.value)
+			c.order.MoveToFront(e)
+		}
+	}
+	c.lock.Unlock()
+
+	err := Load(ctx, c.es, id, a)
+	if err != nil {
+		// the aggregate could be deleted since it was cached
+		c.Invalidate(aggregateType(a), id)
+		return err
+	}
+	c.add(key, v.Elem())
+	return nil
+}
+
+// Save stores the aggregate events and removes the aggregate from the cache
+func (c *Cache) Save(ctx context.Context, a aggregate) error {
+	defer c.Invalidate(aggregateType(a), a.root().ID())
+	return SaveContext(ctx, c.es, a)
+}
+
+// Invalidate removes the aggregate from the cache
+func (c *Cache) Invalidate(aggregateType, id string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.items[cacheKey(aggregateType, id)]; ok {
+		c.order.Remove(e)
+		delete(c.items, e.Value.(*cacheEntry).key)
+	}
+}
+
+// Len returns the number of cached aggregates
+func (c *Cache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.order.Len()
+}
+
+// add caches a copy of the aggregate struct and evicts the least recently used aggregate if the cache is full
+func (c *Cache) add(key string, v reflect.Value) {
+	if c.size <= 0 {
+		return
+	}
+	value := reflect.New(v.Type()).Elem()
+	value.Set(v)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.items[key]; ok {
+		e.Value.(*cacheEntry).value = value
+		c.order.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, value: value})
+	if c.order.Len() > c.size {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.items, last.Value.(*cacheEntry).key)
+	}
+}
+
+func cacheKey(aggregateType, id string) string {
+	return aggregateType + "_" + id
+}
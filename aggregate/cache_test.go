@@ -0,0 +1,116 @@
+package aggregate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+// versionStore records the version the events are fetched after
+type versionStore struct {
+	core.EventStore
+	afterVersion core.Version
+}
+
+func (s *versionStore) Get(ctx context.Context, id string, aggregateType string, afterVersion core.Version) (core.Iterator, error) {
+	s.afterVersion = afterVersion
+	return s.EventStore.Get(ctx, id, aggregateType, afterVersion)
+}
+
+func TestCacheLoad(t *testing.T) {
+	es := &versionStore{EventStore: memory.Create()}
+	aggregate.Register(&Person{})
+	cache := aggregate.NewCache(es, 10)
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	twin := Person{}
+	err = cache.Load(context.Background(), person.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if es.afterVersion != 0 || cache.Len() != 1 {
+		t.Fatalf("expected the aggregate to be built from all events and cached, after version %d len %d", es.afterVersion, cache.Len())
+	}
+
+	// saved outside the cache, the cached aggregate is brought up to date
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	twin = Person{}
+	err = cache.Load(context.Background(), person.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if es.afterVersion != 2 {
+		t.Fatalf("expected events after version 2 to be fetched was %d", es.afterVersion)
+	}
+	if twin.Age != 2 || twin.Version() != 3 || twin.Name != "kalle" {
+		t.Fatalf("unexpected loaded person %+v", twin)
+	}
+}
+
+func TestCacheSaveInvalidates(t *testing.T) {
+	aggregate.Register(&Person{})
+	cache := aggregate.NewCache(memory.Create(), 10)
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cache.Save(context.Background(), person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	twin := Person{}
+	err = cache.Load(context.Background(), person.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	twin.GrowOlder()
+	err = cache.Save(context.Background(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 0 {
+		t.Fatalf("expected the saved aggregate to be removed from the cache, len %d", cache.Len())
+	}
+}
+
+func TestCacheEvict(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+	cache := aggregate.NewCache(es, 1)
+
+	for _, name := range []string{"kalle", "anka"} {
+		person, err := CreatePerson(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = aggregate.Save(es, person)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cache.Load(context.Background(), person.ID(), &Person{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cache.Len() != 1 {
+		t.Fatalf("expected the least recently used aggregate to be evicted, len %d", cache.Len())
+	}
+}
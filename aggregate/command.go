@@ -0,0 +1,105 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Command is a request to change the aggregate with the id
+type Command interface {
+	AggregateID() string
+}
+
+// CommandFunc dispatches a command
+type CommandFunc func(ctx context.Context, cmd Command) error
+
+// CommandMiddleware wraps the dispatch of every command, e.g. to log or authorize commands
+type CommandMiddleware func(next CommandFunc) CommandFunc
+
+// CommandBus is the entry point for commands. It finds the handler of the command type, loads the aggregate, lets the
+// handler apply the command and saves the events the handler tracked on the aggregate.
+type CommandBus struct {
+	es         core.EventStore
+	middleware []CommandMiddleware
+
+	lock     sync.RWMutex
+	handlers map[reflect.Type]CommandFunc
+}
+
+// NewCommandBus creates a command bus loading and saving aggregates in the event store. The middleware is called in
+// the order it's passed, the first middleware is the outermost.
+func NewCommandBus(es core.EventStore, middleware ...CommandMiddleware) *CommandBus {
+	return &CommandBus{
+		es:         es,
+		middleware: middleware,
+		handlers:   make(map[reflect.Type]CommandFunc),
+	}
+}
+
+// HandleCommand registers the handler of the command type C on the aggregate T. The aggregate is registered as well.
+// If the aggregate has no events the handler gets a new aggregate with the command aggregate id set, it's up to the
+// handler to decide if the command creates the aggregate.
+//
+//	aggregate.HandleCommand(bus, func(ctx context.Context, cmd *Rename, p *Person) error {
+//		return p.Rename(cmd.Name)
+//	})
+func HandleCommand[C Command, T any, PT aggregatePointer[T]](b *CommandBus, handler func(ctx context.Context, cmd C, a *T) error) {
+	Register(PT(new(T)))
+	f := func(ctx context.Context, cmd Command) error {
+		a := create[T, PT]()
+		id := cmd.AggregateID()
+		if id != "" {
+			err := Load(ctx, b.es, id, PT(a))
+			if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+				err = SetID(PT(a), id)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		err := handler(ctx, cmd.(C), a)
+		if err != nil {
+			return err
+		}
+		return SaveContext(ctx, b.es, PT(a))
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.handlers[reflect.TypeOf((*C)(nil)).Elem()] = f
+}
+
+// Use appends middleware to the bus, it's called after the middleware already added
+func (b *CommandBus) Use(middleware ...CommandMiddleware) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.middleware = append(b.middleware, middleware...)
+}
+
+// Dispatch passes the command through the middleware to its handler
+func (b *CommandBus) Dispatch(ctx context.Context, cmd Command) error {
+	b.lock.RLock()
+	f := b.handle
+	for i := len(b.middleware) - 1; i >= 0; i-- {
+		f = b.middleware[i](f)
+	}
+	b.lock.RUnlock()
+	return f(ctx, cmd)
+}
+
+// handle calls the handler registered for the command type
+func (b *CommandBus) handle(ctx context.Context, cmd Command) error {
+	b.lock.RLock()
+	f, ok := b.handlers[reflect.TypeOf(cmd)]
+	b.lock.RUnlock()
+	if !ok {
+		return fmt.Errorf("%T %w", cmd, eventsourcing.ErrCommandNotHandled)
+	}
+	return f(ctx, cmd)
+}
@@ -0,0 +1,83 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+type bornCommand struct {
+	ID   string
+	Name string
+}
+
+func (c bornCommand) AggregateID() string { return c.ID }
+
+type growOlderCommand struct {
+	ID string
+}
+
+func (c growOlderCommand) AggregateID() string { return c.ID }
+
+func TestCommandBus(t *testing.T) {
+	es := memory.Create()
+	var dispatched []aggregate.Command
+	logger := func(next aggregate.CommandFunc) aggregate.CommandFunc {
+		return func(ctx context.Context, cmd aggregate.Command) error {
+			dispatched = append(dispatched, cmd)
+			return next(ctx, cmd)
+		}
+	}
+	bus := aggregate.NewCommandBus(es, logger)
+	aggregate.HandleCommand(bus, func(ctx context.Context, cmd bornCommand, p *Person) error {
+		if p.Version() != 0 {
+			return eventsourcing.ErrAggregateAlreadyExists
+		}
+		aggregate.TrackChange(p, &Born{Name: cmd.Name})
+		return nil
+	})
+	aggregate.HandleCommand(bus, func(ctx context.Context, cmd growOlderCommand, p *Person) error {
+		if p.Version() == 0 {
+			return eventsourcing.ErrAggregateNotFound
+		}
+		p.GrowOlder()
+		return nil
+	})
+
+	err := bus.Dispatch(context.Background(), bornCommand{ID: "123", Name: "kalle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bus.Dispatch(context.Background(), growOlderCommand{ID: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bus.Dispatch(context.Background(), bornCommand{ID: "123", Name: "kalle"})
+	if !errors.Is(err, eventsourcing.ErrAggregateAlreadyExists) {
+		t.Fatalf("expected aggregate already exists was %v", err)
+	}
+
+	person := Person{}
+	err = aggregate.Load(context.Background(), es, "123", &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.Name != "kalle" || person.Age != 1 || person.Version() != 2 {
+		t.Fatalf("unexpected person %+v", person)
+	}
+	if len(dispatched) != 3 {
+		t.Fatalf("expected the middleware to see 3 commands was %d", len(dispatched))
+	}
+}
+
+func TestCommandNotHandled(t *testing.T) {
+	bus := aggregate.NewCommandBus(memory.Create())
+	err := bus.Dispatch(context.Background(), growOlderCommand{ID: "123"})
+	if !errors.Is(err, eventsourcing.ErrCommandNotHandled) {
+		t.Fatalf("expected command not handled was %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package aggregate
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// commandValidator is implemented by commands that can validate themselves
+type commandValidator interface {
+	Validate() error
+}
+
+// commandIdentifier is implemented by commands carrying a unique id
+type commandIdentifier interface {
+	CommandID() string
+}
+
+// ValidateCommands returns middleware that calls the Validate method on commands implementing it. A command that
+// fails the validation is not passed on and the validation error is returned.
+func ValidateCommands() CommandMiddleware {
+	return func(next CommandFunc) CommandFunc {
+		return func(ctx context.Context, cmd Command) error {
+			if v, ok := cmd.(commandValidator); ok {
+				err := v.Validate()
+				if err != nil {
+					return err
+				}
+			}
+			return next(ctx, cmd)
+		}
+	}
+}
+
+// IdempotentCommands returns middleware that handles a command with the same CommandID only once. The ids of the last
+// size commands are remembered, a repeated command returns nil without being handled. Commands failing in the handler
+// are forgotten so they can be retried. Commands without a CommandID method are always passed on.
+func IdempotentCommands(size int) CommandMiddleware {
+	var lock sync.Mutex
+	order := list.New()
+	ids := make(map[string]*list.Element)
+
+	return func(next CommandFunc) CommandFunc {
+		return func(ctx context.Context, cmd Command) error {
+			c, ok := cmd.(commandIdentifier)
+			if !ok {
+				return next(ctx, cmd)
+			}
+			id := c.CommandID()
+
+			lock.Lock()
+			if _, ok := ids[id]; ok {
+				lock.Unlock()
+				return nil
+			}
+			// claim the id before the command is handled to stop concurrent duplicates
+			ids[id] = order.PushFront(id)
+			if order.Len() > size {
+				last := order.Back()
+				order.Remove(last)
+				delete(ids, last.Value.(string))
+			}
+			lock.Unlock()
+
+			err := next(ctx, cmd)
+			if err != nil {
+				lock.Lock()
+				if e, ok := ids[id]; ok {
+					order.Remove(e)
+					delete(ids, id)
+				}
+				lock.Unlock()
+			}
+			return err
+		}
+	}
+}
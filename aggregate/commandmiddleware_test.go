@@ -0,0 +1,77 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/aggregate"
+)
+
+type idCommand struct {
+	ID    string
+	Name  string
+	cmdID string
+}
+
+func (c idCommand) AggregateID() string { return c.ID }
+func (c idCommand) CommandID() string   { return c.cmdID }
+func (c idCommand) Validate() error {
+	if c.Name == "" {
+		return errors.New("name can't be blank")
+	}
+	return nil
+}
+
+func TestValidateCommands(t *testing.T) {
+	handled := 0
+	f := aggregate.ValidateCommands()(func(ctx context.Context, cmd aggregate.Command) error {
+		handled++
+		return nil
+	})
+
+	err := f(context.Background(), idCommand{ID: "123"})
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	err = f(context.Background(), idCommand{ID: "123", Name: "kalle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f(context.Background(), growOlderCommand{ID: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handled != 2 {
+		t.Fatalf("expected 2 handled commands was %d", handled)
+	}
+}
+
+func TestIdempotentCommands(t *testing.T) {
+	handled := 0
+	fail := true
+	f := aggregate.IdempotentCommands(1)(func(ctx context.Context, cmd aggregate.Command) error {
+		if fail {
+			fail = false
+			return errors.New("failed")
+		}
+		handled++
+		return nil
+	})
+
+	// the failed command is forgotten and can be retried
+	err := f(context.Background(), idCommand{ID: "123", cmdID: "a"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, id := range []string{"a", "a", "b", "a"} {
+		err = f(context.Background(), idCommand{ID: "123", cmdID: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the last "a" is handled again as only the id "b" is remembered
+	if handled != 3 {
+		t.Fatalf("expected 3 handled commands was %d", handled)
+	}
+}
@@ -0,0 +1,48 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Delete marks the aggregate as deleted by appending a tombstone event to its event stream. Loading a deleted aggregate
+// returns eventsourcing.ErrAggregateDeleted. The events are kept in the event store. The values reserved by the
+// aggregate in the reservation stores registered with ReleaseOnDelete are released.
+func Delete(ctx context.Context, es core.EventStore, id string, a aggregate) error {
+	err := Load(ctx, es, id, a)
+	if errors.Is(err, eventsourcing.ErrAggregateDeleted) {
+		// release the values left by a delete that failed to release them
+		releaseErr := releaseReservations(ctx, a, id)
+		if releaseErr != nil {
+			return releaseErr
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	TrackChange(a, &eventsourcing.Tombstone{})
+	err = SaveContext(ctx, es, a)
+	if err != nil {
+		return err
+	}
+	return releaseReservations(ctx, a, id)
+}
+
+// HardDelete deletes the aggregate and removes its events, except the tombstone, from the event store. The event store
+// has to implement core.EventDeleter otherwise core.ErrDeleteNotSupported is returned. An aggregate that is already
+// deleted gets its remaining events removed.
+func HardDelete(ctx context.Context, es core.EventStore, id string, a aggregate) error {
+	d, ok := lookup[core.EventDeleter](es)
+	if !ok {
+		return core.ErrDeleteNotSupported
+	}
+	err := Delete(ctx, es, id, a)
+	if err != nil && !errors.Is(err, eventsourcing.ErrAggregateDeleted) {
+		return err
+	}
+	return d.DeleteEvents(ctx, id, streamType(a), core.Version(a.root().Version()))
+}
@@ -0,0 +1,89 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestDelete(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = aggregate.Delete(context.Background(), es, person.ID(), &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Load(context.Background(), es, person.ID(), &Person{})
+	if !errors.Is(err, eventsourcing.ErrAggregateDeleted) {
+		t.Fatalf("expected aggregate deleted was %v", err)
+	}
+	err = aggregate.Delete(context.Background(), es, person.ID(), &Person{})
+	if !errors.Is(err, eventsourcing.ErrAggregateDeleted) {
+		t.Fatalf("expected aggregate deleted was %v", err)
+	}
+
+	// the tombstone is an event like any other
+	events, total, err := aggregate.History(context.Background(), es, person.ID(), &Person{}, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 {
+		t.Fatalf("expected 3 events was %d", total)
+	}
+	if _, ok := events[0].Data().(*eventsourcing.Tombstone); !ok {
+		t.Fatalf("expected the last event to be a tombstone was %T", events[0].Data())
+	}
+}
+
+func TestHardDelete(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = aggregate.HardDelete(context.Background(), es, person.ID(), &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Load(context.Background(), es, person.ID(), &Person{})
+	if !errors.Is(err, eventsourcing.ErrAggregateDeleted) {
+		t.Fatalf("expected aggregate deleted was %v", err)
+	}
+	_, total, err := aggregate.History(context.Background(), es, person.ID(), &Person{}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 {
+		t.Fatalf("expected only the tombstone to be kept was %d events", total)
+	}
+
+	// event stores without core.EventDeleter
+	err = aggregate.HardDelete(context.Background(), struct{ core.EventStore }{es}, person.ID(), &Person{})
+	if !errors.Is(err, core.ErrDeleteNotSupported) {
+		t.Fatalf("expected delete not supported was %v", err)
+	}
+}
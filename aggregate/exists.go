@@ -0,0 +1,35 @@
+package aggregate
+
+import (
+	"context"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/internal"
+)
+
+// Exists returns true if the aggregate has events without replaying them. Event stores implementing
+// core.VersionReader are asked for the latest version, for other event stores the first event is read. A deleted
+// aggregate exists as the tombstone is an event, loading it returns eventsourcing.ErrAggregateDeleted.
+func Exists(ctx context.Context, es core.EventStore, id string, a aggregate) (bool, error) {
+	// the events could be stored with an old aggregate type
+	for _, typ := range append([]string{streamType(a)}, internal.GlobalRegister.OldTypes(aggregateType(a))...) {
+		ok, err := streamExists(ctx, es, id, typ)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func streamExists(ctx context.Context, es core.EventStore, id, aggregateType string) (bool, error) {
+	if r, ok := lookup[core.VersionReader](es); ok {
+		version, err := r.LatestVersion(ctx, id, aggregateType)
+		return version > 0, err
+	}
+	iterator, err := es.Get(ctx, id, aggregateType, 0)
+	if err != nil {
+		return false, err
+	}
+	defer iterator.Close()
+	return iterator.Next(), nil
+}
@@ -0,0 +1,42 @@
+package aggregate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestExists(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the memory event store implements core.VersionReader, the wrapped store is read via Get
+	for _, store := range []core.EventStore{es, struct{ core.EventStore }{es}} {
+		exists, err := aggregate.Exists(context.Background(), store, person.ID(), &Person{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exists {
+			t.Fatal("expected the person to exist")
+		}
+		exists, err = aggregate.Exists(context.Background(), store, "none_existing", &Person{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists {
+			t.Fatal("expected the person to not exist")
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package aggregate
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/internal"
+)
+
+// RegisterFactory registers the aggregate T and the func creating new instances of it. The library uses the factory
+// whenever it creates the aggregate itself, e.g. in Get, Repository and the command bus, making it possible to inject
+// dependencies into the aggregate. The factory has to return an aggregate without id and events.
+//
+//	aggregate.RegisterFactory(func() *Person {
+//		return &Person{clock: clock}
+//	})
+func RegisterFactory[T any, PT aggregatePointer[T]](f func() *T) {
+	Register(PT(f()))
+	internal.GlobalRegister.SetFactory(aggregateType(PT(nil)), func() interface{} {
+		return f()
+	})
+}
+
+// New creates an aggregate of the registered aggregate type from its factory. Aggregates registered without a
+// factory are created as the zero value of the aggregate struct. It's meant for code only knowing the aggregate type
+// by name, type assert the returned value to the aggregate.
+func New(aggregateType string) (interface{}, error) {
+	a, ok := internal.GlobalRegister.NewAggregate(aggregateType)
+	if !ok {
+		return nil, fmt.Errorf("%s %w", aggregateType, eventsourcing.ErrAggregateNotRegistered)
+	}
+	return a, nil
+}
+
+// create returns a new aggregate of type T from the registered factory or the zero value if T has no factory
+func create[T any, PT aggregatePointer[T]]() *T {
+	return newAggregate(reflect.TypeOf((*T)(nil)).Elem()).(*T)
+}
+
+// newAggregate returns a pointer to a new aggregate of the struct type from the registered factory or the zero value if
+// the type has no factory
+func newAggregate(typ reflect.Type) interface{} {
+	if a, ok := internal.GlobalRegister.NewAggregate(typ.Name()); ok && reflect.TypeOf(a).Elem() == typ {
+		return a
+	}
+	return reflect.New(typ).Interface()
+}
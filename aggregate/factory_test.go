@@ -0,0 +1,81 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+// greeter aggregate has a dependency set by its factory
+type greeter struct {
+	aggregate.Root
+	greeting string
+	Greeted  []string
+}
+
+type Greeted struct {
+	Name string
+}
+
+func (g *greeter) Register(f aggregate.RegisterFunc) {
+	f(&Greeted{})
+}
+
+func (g *greeter) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Greeted:
+		g.Greeted = append(g.Greeted, g.greeting+" "+e.Name)
+	}
+}
+
+func TestRegisterFactory(t *testing.T) {
+	aggregate.RegisterFactory(func() *greeter {
+		return &greeter{greeting: "hello"}
+	})
+
+	a, err := aggregate.New("greeter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, ok := a.(*greeter)
+	if !ok {
+		t.Fatalf("expected *greeter was %T", a)
+	}
+	if g.greeting != "hello" {
+		t.Fatalf("expected the factory to set the greeting was %q", g.greeting)
+	}
+
+	es := memory.Create()
+	aggregate.TrackChange(g, &Greeted{Name: "kalle"})
+	err = aggregate.Save(es, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := aggregate.Get[greeter](context.Background(), es, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Greeted) != 1 || loaded.Greeted[0] != "hello kalle" {
+		t.Fatalf("unexpected greeted %v", loaded.Greeted)
+	}
+}
+
+func TestNewWithoutFactory(t *testing.T) {
+	aggregate.Register(&Person{})
+	a, err := aggregate.New("Person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.(*Person); !ok {
+		t.Fatalf("expected *Person was %T", a)
+	}
+
+	_, err = aggregate.New("Unknown")
+	if !errors.Is(err, eventsourcing.ErrAggregateNotRegistered) {
+		t.Fatalf("expected aggregate not registered was %v", err)
+	}
+}
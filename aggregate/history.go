@@ -0,0 +1,46 @@
+package aggregate
+
+import (
+	"context"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/internal"
+)
+
+// History returns a page of the aggregate events newest-first. The offset is the number of events to skip from the
+// latest event and limit is the max number of events in the page. The total number of events in the aggregate event
+// stream is returned to make it possible to present the full size of the history.
+func History(ctx context.Context, es core.Pager, id string, a aggregate, offset, limit uint64) ([]eventsourcing.Event, uint64, error) {
+	coreIterator, total, err := es.GetPage(ctx, id, streamType(a), offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	if a.root().aggregateStream == "" {
+		// the events could be stored with an old aggregate type
+		for _, typ := range internal.GlobalRegister.OldTypes(aggregateType(a)) {
+			if total > 0 {
+				break
+			}
+			coreIterator.Close()
+			coreIterator, total, err = es.GetPage(ctx, id, typ, offset, limit)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	iterator := &eventsourcing.Iterator{
+		CoreIterator: coreIterator,
+	}
+	defer iterator.Close()
+
+	events := make([]eventsourcing.Event, 0, limit)
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			return nil, 0, err
+		}
+		events = append(events, event)
+	}
+	return events, total, nil
+}
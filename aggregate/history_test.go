@@ -0,0 +1,50 @@
+package aggregate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestHistory(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 9; i++ {
+		person.GrowOlder()
+	}
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, total, err := aggregate.History(context.Background(), es, person.ID(), &Person{}, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 10 {
+		t.Fatalf("expected total 10 was %d", total)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events in page was %d", len(events))
+	}
+	// newest first skipping the two latest events
+	if events[0].Version() != 8 || events[2].Version() != 6 {
+		t.Fatalf("expected page to hold version 8 to 6 was %d to %d", events[0].Version(), events[2].Version())
+	}
+
+	// page after the first event
+	events, _, err = aggregate.History(context.Background(), es, person.ID(), &Person{}, 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected empty page was %d events", len(events))
+	}
+}
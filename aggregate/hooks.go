@@ -0,0 +1,30 @@
+package aggregate
+
+import "github.com/hallgren/eventsourcing"
+
+// BeforeSaver is implemented by aggregates enforcing invariants over the unsaved events. BeforeSave is called before
+// the events are saved, returning an error stops the save.
+type BeforeSaver interface {
+	BeforeSave(events []eventsourcing.Event) error
+}
+
+// AfterSaver is implemented by aggregates reacting on their saved events, e.g. to publish them in the process.
+// AfterSave is called after the events are saved.
+type AfterSaver interface {
+	AfterSave(events []eventsourcing.Event)
+}
+
+// beforeSave calls the BeforeSave hook on aggregates implementing it
+func beforeSave(a aggregate, events []eventsourcing.Event) error {
+	if h, ok := a.(BeforeSaver); ok {
+		return h.BeforeSave(events)
+	}
+	return nil
+}
+
+// afterSave calls the AfterSave hook on aggregates implementing it
+func afterSave(a aggregate, events []eventsourcing.Event) {
+	if h, ok := a.(AfterSaver); ok {
+		h.AfterSave(events)
+	}
+}
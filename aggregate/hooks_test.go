@@ -0,0 +1,70 @@
+package aggregate_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+var errTooManyTasks = errors.New("too many tasks")
+
+// todo aggregate has save hooks
+type todo struct {
+	aggregate.Root
+	Tasks     []string
+	published []eventsourcing.Event
+}
+
+type TaskAdded struct {
+	Task string
+}
+
+func (td *todo) Register(f aggregate.RegisterFunc) {
+	f(&TaskAdded{})
+}
+
+func (td *todo) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *TaskAdded:
+		td.Tasks = append(td.Tasks, e.Task)
+	}
+}
+
+func (td *todo) BeforeSave(events []eventsourcing.Event) error {
+	if len(td.Tasks) > 2 {
+		return errTooManyTasks
+	}
+	return nil
+}
+
+func (td *todo) AfterSave(events []eventsourcing.Event) {
+	td.published = append(td.published, events...)
+}
+
+func TestSaveHooks(t *testing.T) {
+	aggregate.Register(&todo{})
+	es := memory.Create()
+
+	td := todo{}
+	aggregate.TrackChange(&td, &TaskAdded{Task: "a"})
+	aggregate.TrackChange(&td, &TaskAdded{Task: "b"})
+	err := aggregate.Save(es, &td)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(td.published) != 2 {
+		t.Fatalf("expected 2 published events was %d", len(td.published))
+	}
+
+	aggregate.TrackChange(&td, &TaskAdded{Task: "c"})
+	err = aggregate.Save(es, &td)
+	if !errors.Is(err, errTooManyTasks) {
+		t.Fatalf("expected too many tasks was %v", err)
+	}
+	if !td.UnsavedEvents() || len(td.published) != 2 {
+		t.Fatal("expected the rejected event to not be saved")
+	}
+}
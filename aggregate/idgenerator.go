@@ -2,6 +2,9 @@ package aggregate
 
 import (
 	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
 )
 
 // idFunc is a global function that generates aggregate id's.
@@ -14,6 +17,71 @@ func SetIDFunc(f func() string) {
 	idFunc = f
 }
 
+// UUIDv4 generates a random UUID version 4, e.g. aggregate.SetIDFunc(aggregate.UUIDv4)
+func UUIDv4() string {
+	b, err := generateRandomBytes(16)
+	if err != nil {
+		return ""
+	}
+	return formatUUID(b, 0x40)
+}
+
+// UUIDv7 generates a UUID version 7 where the first 48 bits are the unix time in milliseconds. The ids sort in the
+// order they were generated, down to the millisecond, which keeps database indexes on the id compact.
+func UUIDv7() string {
+	b, err := generateRandomBytes(16)
+	if err != nil {
+		return ""
+	}
+	putMillis(b, time.Now())
+	return formatUUID(b, 0x70)
+}
+
+// ULID generates a 26 character lexicographically sortable id, a 48 bit millisecond timestamp followed by 80 random
+// bits in Crockford's base32.
+func ULID() string {
+	const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	b, err := generateRandomBytes(16)
+	if err != nil {
+		return ""
+	}
+	putMillis(b, time.Now())
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+
+	// 26 characters of 5 bits holds 130 bits, the two first bits are always zero
+	id := make([]byte, 26)
+	for i := range id {
+		shift := uint(5 * (len(id) - 1 - i))
+		var v uint64
+		switch {
+		case shift >= 64:
+			v = hi >> (shift - 64)
+		case shift == 0:
+			v = lo
+		default:
+			v = lo>>shift | hi<<(64-shift)
+		}
+		id[i] = alphabet[v&31]
+	}
+	return string(id)
+}
+
+// putMillis sets the first 48 bits to the unix time in milliseconds
+func putMillis(b []byte, t time.Time) {
+	ms := uint64(t.UnixMilli())
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (8 * (5 - i)))
+	}
+}
+
+// formatUUID sets the version and variant bits and formats the 16 bytes as a UUID
+func formatUUID(b []byte, version byte) string {
+	b[6] = b[6]&0x0f | version
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
 func randSeq() string {
 	id, err := generateRandomString(20)
 	if err != nil {
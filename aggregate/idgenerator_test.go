@@ -0,0 +1,41 @@
+package aggregate_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/aggregate"
+)
+
+func TestUUIDv4(t *testing.T) {
+	id := aggregate.UUIDv4()
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Fatalf("%q is not a UUID version 4", id)
+	}
+	if id == aggregate.UUIDv4() {
+		t.Fatal("expected unique ids")
+	}
+}
+
+func TestUUIDv7(t *testing.T) {
+	id := aggregate.UUIDv7()
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Fatalf("%q is not a UUID version 7", id)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if next := aggregate.UUIDv7(); next <= id {
+		t.Fatalf("expected %q to sort after %q", next, id)
+	}
+}
+
+func TestULID(t *testing.T) {
+	id := aggregate.ULID()
+	if !regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`).MatchString(id) {
+		t.Fatalf("%q is not a ULID", id)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if next := aggregate.ULID(); next <= id {
+		t.Fatalf("expected %q to sort after %q", next, id)
+	}
+}
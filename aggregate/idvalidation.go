@@ -0,0 +1,83 @@
+package aggregate
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hallgren/eventsourcing"
+)
+
+// IDValidator returns an error if the aggregate id is not valid
+type IDValidator func(id string) error
+
+var (
+	idValidatorsLock sync.RWMutex
+	idValidators     = make(map[string][]IDValidator) // validators by aggregate type
+)
+
+// RegisterIDValidator adds validators of the ids of the aggregate type. The id is validated in SetID and before the
+// aggregate is saved, rejecting bad ids from upstream systems before they are stored.
+//
+//	aggregate.RegisterIDValidator(&Person{}, aggregate.IDPrefix("person-"), aggregate.IDLength(10, 64))
+func RegisterIDValidator(a aggregate, validators ...IDValidator) {
+	idValidatorsLock.Lock()
+	defer idValidatorsLock.Unlock()
+	typ := aggregateType(a)
+	idValidators[typ] = append(idValidators[typ], validators...)
+}
+
+// SetID sets the id of the aggregate after it's validated by the id validators of the aggregate type
+func SetID(a aggregate, id string) error {
+	err := validateID(a, id)
+	if err != nil {
+		return err
+	}
+	return a.root().SetID(id)
+}
+
+// validateID runs the id validators of the aggregate type on the id
+func validateID(a aggregate, id string) error {
+	idValidatorsLock.RLock()
+	validators := idValidators[aggregateType(a)]
+	idValidatorsLock.RUnlock()
+	for _, v := range validators {
+		err := v(id)
+		if err != nil {
+			return fmt.Errorf("%s id %q %w: %v", aggregateType(a), id, eventsourcing.ErrInvalidAggregateID, err)
+		}
+	}
+	return nil
+}
+
+// IDLength validates that the id is between min and max characters long
+func IDLength(min, max int) IDValidator {
+	return func(id string) error {
+		if len(id) < min || len(id) > max {
+			return fmt.Errorf("length %d not between %d and %d", len(id), min, max)
+		}
+		return nil
+	}
+}
+
+// IDPrefix validates that the id starts with the prefix
+func IDPrefix(prefix string) IDValidator {
+	return func(id string) error {
+		if !strings.HasPrefix(id, prefix) {
+			return fmt.Errorf("missing prefix %q", prefix)
+		}
+		return nil
+	}
+}
+
+// IDCharset validates that the id only holds characters in the charset
+func IDCharset(charset string) IDValidator {
+	return func(id string) error {
+		for _, r := range id {
+			if !strings.ContainsRune(charset, r) {
+				return fmt.Errorf("character %q not allowed", r)
+			}
+		}
+		return nil
+	}
+}
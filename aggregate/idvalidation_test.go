@@ -0,0 +1,60 @@
+package aggregate_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+// device aggregate has validated ids
+type device struct {
+	aggregate.Root
+}
+
+type Installed struct{}
+
+func (d *device) Register(f aggregate.RegisterFunc) {
+	f(&Installed{})
+}
+
+func (d *device) Transition(event eventsourcing.Event) {}
+
+func init() {
+	aggregate.RegisterIDValidator(&device{}, aggregate.IDPrefix("dev-"), aggregate.IDLength(5, 10), aggregate.IDCharset("dev-0123456789"))
+}
+
+func TestSetIDValidation(t *testing.T) {
+	for _, id := range []string{"dev-", "abc-123", "dev-12345678", "dev-12a"} {
+		err := aggregate.SetID(&device{}, id)
+		if !errors.Is(err, eventsourcing.ErrInvalidAggregateID) {
+			t.Fatalf("expected invalid aggregate id for %q was %v", id, err)
+		}
+	}
+	d := device{}
+	err := aggregate.SetID(&d, "dev-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.ID() != "dev-123" {
+		t.Fatalf("expected id dev-123 was %q", d.ID())
+	}
+}
+
+func TestSaveIDValidation(t *testing.T) {
+	aggregate.Register(&device{})
+	es := memory.Create()
+
+	d := device{}
+	err := d.SetID("bad")
+	if err != nil {
+		t.Fatal(err)
+	}
+	aggregate.TrackChange(&d, &Installed{})
+	err = aggregate.Save(es, &d)
+	if !errors.Is(err, eventsourcing.ErrInvalidAggregateID) {
+		t.Fatalf("expected invalid aggregate id was %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Update loads the aggregate, calls f to change it and saves the tracked events while holding the lock on the
+// aggregate in the lock store. Concurrent updates of the same aggregate wait for each other instead of failing with
+// core.ErrConcurrency. If the aggregate has no events f gets a new aggregate with the id set.
+//
+// The lock is advisory, aggregates saved without taking the lock can still cause core.ErrConcurrency.
+func Update(ctx context.Context, es core.EventStore, ls core.LockStore, id string, a aggregate, f func() error) (err error) {
+	unlock, err := ls.Lock(ctx, id, aggregateType(a))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		errUnlock := unlock()
+		if err == nil {
+			err = errUnlock
+		}
+	}()
+
+	err = Load(ctx, es, id, a)
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		err = SetID(a, id)
+	}
+	if err != nil {
+		return err
+	}
+	err = f()
+	if err != nil {
+		return err
+	}
+	return SaveContext(ctx, es, a)
+}
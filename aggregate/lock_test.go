@@ -0,0 +1,72 @@
+package aggregate_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	lockmemory "github.com/hallgren/eventsourcing/lockstore/memory"
+)
+
+func TestUpdate(t *testing.T) {
+	es := memory.Create()
+	ls := lockmemory.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := Person{}
+			errs <- aggregate.Update(context.Background(), es, ls, person.ID(), &p, func() error {
+				p.GrowOlder()
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := Person{}
+	err = aggregate.Load(context.Background(), es, person.ID(), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Age != 10 {
+		t.Fatalf("expected age 10 was %d", p.Age)
+	}
+}
+
+func TestUpdateNewAggregate(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+	p := Person{}
+	err := aggregate.Update(context.Background(), es, lockmemory.Create(), "123", &p, func() error {
+		aggregate.TrackChange(&p, &Born{Name: "kalle"})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID() != "123" || p.Version() != 1 {
+		t.Fatalf("unexpected person %+v", p)
+	}
+}
@@ -0,0 +1,74 @@
+package aggregate
+
+import (
+	"context"
+)
+
+// CorrelationIDKey is the metadata key of the correlation id
+const CorrelationIDKey = "correlation_id"
+
+type correlationKey struct{}
+
+// EventOption adds metadata to an event tracked via TrackChange
+type EventOption func(metadata map[string]interface{})
+
+// WithMeta sets the metadata key to the value
+func WithMeta(key string, value interface{}) EventOption {
+	return func(metadata map[string]interface{}) {
+		metadata[key] = value
+	}
+}
+
+// WithCorrelation sets the correlation id from the context, see ContextWithCorrelationID. Nothing is set if the
+// context has no correlation id.
+func WithCorrelation(ctx context.Context) EventOption {
+	return func(metadata map[string]interface{}) {
+		if id, ok := CorrelationID(ctx); ok {
+			metadata[CorrelationIDKey] = id
+		}
+	}
+}
+
+// ContextWithCorrelationID returns a context carrying the correlation id, e.g. the id of the incoming request
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the correlation id from the context
+func CorrelationID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationKey{}).(string)
+	return id, ok
+}
+
+// Enricher adds metadata to every event saved via a repository, like the user or the service version. The context is
+// the one passed to the save.
+type Enricher func(ctx context.Context, metadata map[string]interface{})
+
+// StaticEnricher sets the metadata key to the same value on every event
+func StaticEnricher(key string, value interface{}) Enricher {
+	return func(ctx context.Context, metadata map[string]interface{}) {
+		metadata[key] = value
+	}
+}
+
+// CorrelationEnricher sets the correlation id from the save context on every event
+func CorrelationEnricher() Enricher {
+	return func(ctx context.Context, metadata map[string]interface{}) {
+		WithCorrelation(ctx)(metadata)
+	}
+}
+
+// enrich returns the event metadata merged with the metadata from the enrichers, the event metadata takes precedence
+func enrich(ctx context.Context, metadata map[string]interface{}, enrichers []Enricher) map[string]interface{} {
+	if len(enrichers) == 0 {
+		return metadata
+	}
+	enriched := make(map[string]interface{}, len(metadata))
+	for _, enricher := range enrichers {
+		enricher(ctx, enriched)
+	}
+	for k, v := range metadata {
+		enriched[k] = v
+	}
+	return enriched
+}
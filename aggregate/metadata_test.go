@@ -0,0 +1,72 @@
+package aggregate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestTrackChangeOptions(t *testing.T) {
+	ctx := aggregate.ContextWithCorrelationID(context.Background(), "abc")
+	person := Person{}
+	aggregate.TrackChange(&person, &Born{Name: "kalle"}, aggregate.WithMeta("userID", "123"), aggregate.WithCorrelation(ctx))
+
+	metadata := person.Events()[0].Metadata()
+	if metadata["userID"] != "123" || metadata[aggregate.CorrelationIDKey] != "abc" {
+		t.Fatalf("unexpected metadata %v", metadata)
+	}
+
+	// no correlation id in the context
+	aggregate.TrackChange(&person, &AgedOneYear{}, aggregate.WithCorrelation(context.Background()))
+	if _, ok := person.Events()[1].Metadata()[aggregate.CorrelationIDKey]; ok {
+		t.Fatal("expected no correlation id")
+	}
+}
+
+func TestRepositoryEnrich(t *testing.T) {
+	es := memory.Create()
+	persons := aggregate.NewRepository[Person](es)
+	persons.Enrich(aggregate.StaticEnricher("version", "1.2.3"), aggregate.CorrelationEnricher(), aggregate.StaticEnricher("userID", "enricher"))
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	aggregate.TrackChange(person, &AgedOneYear{}, aggregate.WithMeta("userID", "123"))
+	ctx := aggregate.ContextWithCorrelationID(context.Background(), "abc")
+	err = persons.Save(ctx, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, _, err := aggregate.History(context.Background(), es, person.ID(), &Person{}, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, event := range events {
+		metadata := event.Metadata()
+		if metadata["version"] != "1.2.3" || metadata[aggregate.CorrelationIDKey] != "abc" {
+			t.Fatalf("expected the enriched metadata was %v", metadata)
+		}
+	}
+	// the event metadata takes precedence
+	if events[0].Metadata()["userID"] != "123" || events[1].Metadata()["userID"] != "enricher" {
+		t.Fatalf("unexpected user ids %v %v", events[0].Metadata(), events[1].Metadata())
+	}
+
+	// the correlation id is saved next to the metadata for the event store to index
+	iterator, err := es.ByCorrelationID(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	var correlated int
+	for iterator.Next() {
+		correlated++
+	}
+	if correlated != len(events) {
+		t.Fatalf("expected %d correlated events got %d", len(events), correlated)
+	}
+}
@@ -0,0 +1,71 @@
+package aggregate
+
+import (
+	"context"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// aggregatePointer is the pointer to the aggregate struct T
+type aggregatePointer[T any] interface {
+	*T
+	aggregate
+}
+
+// Get creates the aggregate of type T, via its factory if one is registered, and loads it from its events. It's the typed version of Load where the call
+// site gets the aggregate back instead of passing in a pointer.
+//
+//	person, err := aggregate.Get[Person](ctx, es, id)
+func Get[T any, PT aggregatePointer[T]](ctx context.Context, es core.EventStore, id string) (*T, error) {
+	a := create[T, PT]()
+	err := Load(ctx, es, id, PT(a))
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Repository saves and loads aggregates of type T
+type Repository[T any, PT aggregatePointer[T]] struct {
+	es        core.EventStore
+	enrichers []Enricher
+	buses     []*eventsourcing.EventBus
+}
+
+// NewRepository creates a repository for the aggregate type T using the event store es. The aggregate is registered
+// when the repository is created.
+//
+//	persons := aggregate.NewRepository[Person](es)
+func NewRepository[T any, PT aggregatePointer[T]](es core.EventStore) *Repository[T, PT] {
+	Register(PT(new(T)))
+	return &Repository[T, PT]{es: es}
+}
+
+// Load returns the aggregate with the id
+func (r *Repository[T, PT]) Load(ctx context.Context, id string) (*T, error) {
+	return Get[T, PT](ctx, r.es, id)
+}
+
+// Enrich adds enrichers whose metadata is added to every event saved via the repository
+func (r *Repository[T, PT]) Enrich(enrichers ...Enricher) {
+	r.enrichers = append(r.enrichers, enrichers...)
+}
+
+// PublishTo makes the repository publish the events on the bus after they are saved
+func (r *Repository[T, PT]) PublishTo(bus *eventsourcing.EventBus) {
+	r.buses = append(r.buses, bus)
+}
+
+// Save stores the aggregate events and publishes them on the event buses
+func (r *Repository[T, PT]) Save(ctx context.Context, a *T) error {
+	events := PT(a).root().Events()
+	err := save(ctx, r.es, PT(a), r.enrichers)
+	if err != nil {
+		return err
+	}
+	for _, bus := range r.buses {
+		bus.Publish(ctx, events...)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestGet(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	twin, err := aggregate.Get[Person](context.Background(), es, person.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Name != "kalle" || twin.Version() != 1 {
+		t.Fatalf("unexpected loaded person %+v", twin)
+	}
+
+	_, err = aggregate.Get[Person](context.Background(), es, "none_existing")
+	if !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		t.Fatalf("expected aggregate not found was %v", err)
+	}
+}
+
+func TestRepository(t *testing.T) {
+	persons := aggregate.NewRepository[Person](memory.Create())
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = persons.Save(context.Background(), person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	twin, err := persons.Load(context.Background(), person.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Name != "kalle" || twin.Age != 1 {
+		t.Fatalf("unexpected loaded person %+v", twin)
+	}
+}
+
+func TestRepositoryPublishTo(t *testing.T) {
+	persons := aggregate.NewRepository[Person](memory.Create())
+	bus := eventsourcing.NewEventBus()
+	persons.PublishTo(bus)
+	var published []eventsourcing.Event
+	bus.SubscribeAll(func(ctx context.Context, event eventsourcing.Event) {
+		published = append(published, event)
+	})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = persons.Save(context.Background(), person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(published) != 2 || published[1].AggregateID() != person.ID() {
+		t.Fatalf("expected the 2 saved events to be published was %v", published)
+	}
+}
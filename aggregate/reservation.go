@@ -0,0 +1,72 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// SaveReserved reserves the unique value in the scope for the aggregate before the aggregate events are saved. If the
+// value is reserved by another aggregate core.ErrReserved is returned and no events are saved. If the save fails the
+// reservation is released, unless the aggregate held it before the call. When the aggregate no longer claims the
+// value it's released via the reservation store Release method, the values of a deleted aggregate are released by
+// Delete when the reservation store is registered with ReleaseOnDelete.
+func SaveReserved(ctx context.Context, es core.EventStore, rs core.ReservationStore, scope, value string, a aggregate) error {
+	root := a.root()
+	if len(root.aggregateEvents) == 0 {
+		return nil
+	}
+	id := root.ID()
+
+	r, err := rs.Get(ctx, scope, value)
+	if err != nil && !errors.Is(err, core.ErrReservationNotFound) {
+		return err
+	}
+	held := err == nil && r.AggregateID == id
+
+	err = rs.Reserve(ctx, scope, value, id)
+	if err != nil {
+		return err
+	}
+	err = SaveContext(ctx, es, a)
+	if err != nil {
+		if !held {
+			rs.Release(ctx, scope, value, id)
+		}
+		return err
+	}
+	return nil
+}
+
+var (
+	reservationStoresLock sync.RWMutex
+	reservationStores     = make(map[string][]core.ReservationStore) // reservation stores by aggregate type
+)
+
+// ReleaseOnDelete registers the reservation stores holding values reserved by aggregates of the type. Delete releases
+// the values reserved by the deleted aggregate.
+//
+//	aggregate.ReleaseOnDelete(&User{}, reservationStore)
+func ReleaseOnDelete(a aggregate, stores ...core.ReservationStore) {
+	reservationStoresLock.Lock()
+	defer reservationStoresLock.Unlock()
+	typ := aggregateType(a)
+	reservationStores[typ] = append(reservationStores[typ], stores...)
+}
+
+// releaseReservations releases the values reserved by the aggregate in the reservation stores of the aggregate type
+func releaseReservations(ctx context.Context, a aggregate, id string) error {
+	reservationStoresLock.RLock()
+	stores := reservationStores[aggregateType(a)]
+	reservationStoresLock.RUnlock()
+
+	for _, rs := range stores {
+		err := rs.ReleaseAggregate(ctx, id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	rs "github.com/hallgren/eventsourcing/reservationstore/memory"
+)
+
+type failingStore struct {
+	*memory.Memory
+}
+
+func (f failingStore) Save(events []core.Event) error {
+	return errors.New("save failed")
+}
+
+func TestSaveReserved(t *testing.T) {
+	es := memory.Create()
+	reservations := rs.Create()
+	aggregate.Register(&Person{})
+
+	kalle, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.SaveReserved(context.Background(), es, reservations, "name", "kalle", kalle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	twin, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.SaveReserved(context.Background(), es, reservations, "name", "kalle", twin)
+	if !errors.Is(err, core.ErrReserved) {
+		t.Fatalf("expected reserved error was %v", err)
+	}
+	if !twin.UnsavedEvents() {
+		t.Fatal("expected the events not to be saved")
+	}
+
+	// the aggregate holding the reservation can save again
+	kalle.GrowOlder()
+	err = aggregate.SaveReserved(context.Background(), es, reservations, "name", "kalle", kalle)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveReservedReleaseOnFailure(t *testing.T) {
+	reservations := rs.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.SaveReserved(context.Background(), failingStore{memory.Create()}, reservations, "name", "kalle", person)
+	if err == nil {
+		t.Fatal("expected the save to fail")
+	}
+	_, err = reservations.Get(context.Background(), "name", "kalle")
+	if !errors.Is(err, core.ErrReservationNotFound) {
+		t.Fatalf("expected the reservation to be released was %v", err)
+	}
+}
+
+func TestDeleteReleasesReservations(t *testing.T) {
+	es := memory.Create()
+	reservations := rs.Create()
+	aggregate.Register(&Person{})
+	aggregate.ReleaseOnDelete(&Person{}, reservations)
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.SaveReserved(context.Background(), es, reservations, "name", "kalle", person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Delete(context.Background(), es, person.ID(), &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = reservations.Get(context.Background(), "name", "kalle")
+	if !errors.Is(err, core.ErrReservationNotFound) {
+		t.Fatalf("expected the reservation to be released was %v", err)
+	}
+
+	// a value reserved after the delete is released by the next delete
+	err = reservations.Reserve(context.Background(), "name", "kalle", person.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Delete(context.Background(), es, person.ID(), &Person{})
+	if !errors.Is(err, eventsourcing.ErrAggregateDeleted) {
+		t.Fatalf("expected aggregate deleted was %v", err)
+	}
+	_, err = reservations.Get(context.Background(), "name", "kalle")
+	if !errors.Is(err, core.ErrReservationNotFound) {
+		t.Fatalf("expected the reservation to be released was %v", err)
+	}
+}
@@ -14,14 +14,27 @@ type Root struct {
 	aggregateVersion       eventsourcing.Version
 	aggregateGlobalVersion eventsourcing.Version
 	aggregateEvents        []eventsourcing.Event
+	aggregateStream        string // old aggregate type the events are stored with, empty when stored with the current
+	unhandled              error  // set when Transition marks an event as not handled
 }
 
 const emptyAggregateID = ""
 
 // TrackChange is used internally by behaviour methods to apply a state change to
 // the current instance and also track it in order that it can be persisted later.
-func TrackChange(a aggregate, data interface{}) {
-	TrackChangeWithMetadata(a, data, nil)
+// The options add metadata to the event.
+//
+//	aggregate.TrackChange(p, &Born{}, aggregate.WithMeta("userID", id), aggregate.WithCorrelation(ctx))
+func TrackChange(a aggregate, data interface{}, options ...EventOption) {
+	if len(options) == 0 {
+		TrackChangeWithMetadata(a, data, nil)
+		return
+	}
+	metadata := make(map[string]interface{})
+	for _, option := range options {
+		option(metadata)
+	}
+	TrackChangeWithMetadata(a, data, metadata)
 }
 
 // TrackChangeWithMetadata is used internally by behaviour methods to apply a state change to
@@ -38,27 +51,58 @@ func TrackChangeWithMetadata(a aggregate, data interface{}, metadata map[string]
 		core.Event{
 			AggregateID:   ar.aggregateID,
 			Version:       ar.nextVersion(),
-			AggregateType: aggregateType(a),
+			AggregateType: streamType(a),
 			Timestamp:     time.Now().UTC(),
+			SchemaVersion: schemaVersionOf(data),
 		},
 		data,
 		metadata,
 	)
 	ar.aggregateEvents = append(ar.aggregateEvents, event)
+	// the tombstone is not part of the aggregate state, aggregates marking unknown events as unhandled would fail
+	if isTombstone(event) {
+		return
+	}
 	a.Transition(event)
 }
 
+// isTombstone returns true if the event is the tombstone appended by Delete
+func isTombstone(event eventsourcing.Event) bool {
+	_, ok := event.Data().(*eventsourcing.Tombstone)
+	return ok
+}
+
+// eventSchema is implemented by event data types that version their shape
+type eventSchema interface {
+	SchemaVersion() uint
+}
+
+func schemaVersionOf(data interface{}) uint {
+	if v, ok := data.(eventSchema); ok {
+		return v.SchemaVersion()
+	}
+	return 0
+}
+
 // buildFromHistory builds the aggregate state from events
-func buildFromHistory(a aggregate, events []eventsourcing.Event) {
+func buildFromHistory(a aggregate, events []eventsourcing.Event) error {
 	root := a.root()
 	for _, event := range events {
-		a.Transition(event)
+		if !isTombstone(event) {
+			a.Transition(event)
+		}
+		if root.unhandled != nil {
+			err := root.unhandled
+			root.unhandled = nil
+			return err
+		}
 		//Set the aggregate ID
 		root.aggregateID = event.AggregateID()
 		// Make sure the aggregate is in the correct version (the last event)
 		root.aggregateVersion = event.Version()
 		root.aggregateGlobalVersion = event.GlobalVersion()
 	}
+	return nil
 }
 
 func (ar *Root) nextVersion() core.Version {
@@ -76,12 +120,42 @@ func (ar *Root) update() {
 	}
 }
 
+// saved sets the version of the last unsaved event and the global version from the event store and resets the
+// unsaved events
+func (ar *Root) saved(globalVersion eventsourcing.Version) {
+	lastEvent := ar.aggregateEvents[len(ar.aggregateEvents)-1]
+	ar.aggregateVersion = lastEvent.Version()
+	ar.aggregateGlobalVersion = globalVersion
+	ar.aggregateEvents = []eventsourcing.Event{}
+}
+
 // path return the full name of the aggregate making it unique to other aggregates with
 // the same name but placed in other packages.
 func (ar *Root) path() string {
 	return reflect.TypeOf(ar).Elem().PkgPath()
 }
 
+// Unhandled marks the event as not handled by the aggregate. Call it in the default case of the Transition switch to
+// make the loading of the aggregate fail with an eventsourcing.UnhandledEventError instead of silently skipping
+// events the aggregate doesn't know about. Events tracked via TrackChange make the save fail.
+//
+//	func (p *Person) Transition(event eventsourcing.Event) {
+//		switch e := event.Data().(type) {
+//		case *Born:
+//			p.Name = e.Name
+//		default:
+//			p.Unhandled(event)
+//		}
+//	}
+func (ar *Root) Unhandled(event eventsourcing.Event) {
+	ar.unhandled = &eventsourcing.UnhandledEventError{
+		AggregateType: event.AggregateType(),
+		AggregateID:   event.AggregateID(),
+		Version:       event.Version(),
+		Reason:        event.Reason(),
+	}
+}
+
 // SetID opens up the possibility to set manual aggregate ID from the outside
 func (ar *Root) SetID(id string) error {
 	if ar.aggregateID != emptyAggregateID {
@@ -133,3 +207,12 @@ func (ar *Root) UnsavedEvents() bool {
 func aggregateType(a interface{}) string {
 	return reflect.TypeOf(a).Elem().Name()
 }
+
+// streamType returns the aggregate type the aggregate events are stored with. It differs from the aggregate type when
+// the events were stored before the aggregate was renamed.
+func streamType(a aggregate) string {
+	if stream := a.root().aggregateStream; stream != "" {
+		return stream
+	}
+	return aggregateType(a)
+}
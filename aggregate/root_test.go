@@ -1,6 +1,7 @@
 package aggregate_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,6 +10,8 @@ import (
 
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
 )
 
 // Person aggregate
@@ -228,3 +231,124 @@ func TestIDFuncGeneratingRandomIDs(t *testing.T) {
 		ids[person.ID()] = struct{}{}
 	}
 }
+
+func TestTrackChangeSchemaVersion(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&versioned{})
+	aggregate.Register(&Person{})
+
+	v := versioned{}
+	aggregate.TrackChange(&v, &Renamed{Name: "kalle"})
+	if v.Events()[0].SchemaVersion() != 3 {
+		t.Fatalf("expected schema version 3 from the event data was %d", v.Events()[0].SchemaVersion())
+	}
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if person.Events()[0].SchemaVersion() != 0 {
+		t.Fatalf("expected schema version 0 on unversioned event data was %d", person.Events()[0].SchemaVersion())
+	}
+
+	err = aggregate.Save(es, &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err := es.Get(context.Background(), v.ID(), "versioned", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected the saved event")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.SchemaVersion != 3 {
+		t.Fatalf("expected stored schema version 3 was %d", event.SchemaVersion)
+	}
+}
+
+// counter aggregate handles the Incremented event only
+type counter struct {
+	aggregate.Root
+	Count int
+}
+
+type Incremented struct{}
+
+type Reset struct{}
+
+func (c *counter) Register(f aggregate.RegisterFunc) {
+	f(&Incremented{}, &Reset{})
+}
+
+func (c *counter) Transition(event eventsourcing.Event) {
+	switch event.Data().(type) {
+	case *Incremented:
+		c.Count++
+	default:
+		c.Unhandled(event)
+	}
+}
+
+func TestUnhandledEventOnSave(t *testing.T) {
+	aggregate.Register(&counter{})
+	c := counter{}
+	aggregate.TrackChange(&c, &Incremented{})
+	aggregate.TrackChange(&c, &Reset{})
+
+	err := aggregate.Save(memory.Create(), &c)
+	unhandled := &eventsourcing.UnhandledEventError{}
+	if !errors.As(err, &unhandled) {
+		t.Fatalf("expected unhandled event error was %v", err)
+	}
+	if unhandled.Reason != "Reset" || unhandled.Version != 2 {
+		t.Fatalf("unexpected unhandled event %+v", unhandled)
+	}
+}
+
+func TestUnhandledEventOnLoad(t *testing.T) {
+	aggregate.Register(&counter{})
+	es := memory.Create()
+	err := es.Save([]core.Event{
+		{AggregateID: "123", Version: 1, AggregateType: "counter", Reason: "Incremented", Data: []byte("{}")},
+		{AggregateID: "123", Version: 2, AggregateType: "counter", Reason: "Reset", Data: []byte("{}")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := counter{}
+	err = aggregate.Load(context.Background(), es, "123", &c)
+	unhandled := &eventsourcing.UnhandledEventError{}
+	if !errors.As(err, &unhandled) {
+		t.Fatalf("expected unhandled event error was %v", err)
+	}
+	if unhandled.AggregateID != "123" || unhandled.Reason != "Reset" || unhandled.Version != 2 {
+		t.Fatalf("unexpected unhandled event %+v", unhandled)
+	}
+}
+
+func TestDeleteUnhandled(t *testing.T) {
+	aggregate.Register(&counter{})
+	es := memory.Create()
+	c := counter{}
+	aggregate.TrackChange(&c, &Incremented{})
+	err := aggregate.Save(es, &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the tombstone is not passed to Transition where the counter marks it as unhandled
+	err = aggregate.Delete(context.Background(), es, c.ID(), &counter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Load(context.Background(), es, c.ID(), &counter{})
+	if !errors.Is(err, eventsourcing.ErrAggregateDeleted) {
+		t.Fatalf("expected the aggregate to be deleted was %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package aggregate
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/hallgren/eventsourcing"
+)
+
+// routeKey is the aggregate and event data type an event route is found for
+type routeKey struct {
+	aggregate reflect.Type
+	event     reflect.Type
+}
+
+type routeFunc = func(a, data interface{})
+
+var (
+	routesLock sync.RWMutex
+	routes     = make(map[routeKey]routeFunc) // routes registered via On
+	methods    sync.Map                       // routes to Apply methods by routeKey, nil when there is no method
+)
+
+// On registers f as the handler of the event type E on the aggregate type T. Registering a method expression gives a
+// compile time check of the event type handled by the method.
+//
+//	aggregate.On((*Person).born)
+func On[T any, E any](f func(a *T, event *E)) {
+	routesLock.Lock()
+	defer routesLock.Unlock()
+	routes[routeKey{reflect.TypeOf((*T)(nil)), reflect.TypeOf((*E)(nil))}] = func(a, data interface{}) {
+		f(a.(*T), data.(*E))
+	}
+}
+
+// Route calls the handler of the event on the aggregate, it's meant to be called from Transition instead of a type
+// switch. Handlers registered via On are used before the aggregate method named Apply followed by the event type name,
+// e.g. ApplyBorn(*Born). Returns false if the aggregate has no handler of the event. The tombstone appended by Delete
+// is reported as handled.
+//
+//	func (p *Person) Transition(event eventsourcing.Event) {
+//		if !aggregate.Route(p, event) {
+//			p.Unhandled(event)
+//		}
+//	}
+func Route(a aggregate, event eventsourcing.Event) bool {
+	if isTombstone(event) {
+		return true
+	}
+	key := routeKey{reflect.TypeOf(a), reflect.TypeOf(event.Data())}
+	routesLock.RLock()
+	f, ok := routes[key]
+	routesLock.RUnlock()
+	if !ok {
+		f = method(key)
+	}
+	if f == nil {
+		return false
+	}
+	f(a, event.Data())
+	return true
+}
+
+// method returns the route to the aggregate method Apply<Event> taking the event data as its only argument
+func method(key routeKey) routeFunc {
+	if f, ok := methods.Load(key); ok {
+		return f.(routeFunc)
+	}
+	var f routeFunc
+	if key.event == nil || key.event.Kind() != reflect.Ptr {
+		methods.Store(key, f)
+		return f
+	}
+	m, ok := key.aggregate.MethodByName("Apply" + key.event.Elem().Name())
+	if ok && m.Type.NumIn() == 2 && m.Type.NumOut() == 0 && key.event.AssignableTo(m.Type.In(1)) {
+		f = func(a, data interface{}) {
+			m.Func.Call([]reflect.Value{reflect.ValueOf(a), reflect.ValueOf(data)})
+		}
+	}
+	methods.Store(key, f)
+	return f
+}
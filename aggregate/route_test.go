@@ -0,0 +1,108 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+// account aggregate routes its events to methods instead of a type switch
+type account struct {
+	aggregate.Root
+	Owner   string
+	Balance int
+}
+
+type Opened struct {
+	Owner string
+}
+
+type Deposited struct {
+	Amount int
+}
+
+type Closed struct{}
+
+func (a *account) Register(f aggregate.RegisterFunc) {
+	f(&Opened{}, &Deposited{}, &Closed{})
+}
+
+func (a *account) Transition(event eventsourcing.Event) {
+	if !aggregate.Route(a, event) {
+		a.Unhandled(event)
+	}
+}
+
+// ApplyOpened is found by its name
+func (a *account) ApplyOpened(e *Opened) {
+	a.Owner = e.Owner
+}
+
+// deposited is registered via aggregate.On
+func (a *account) deposited(e *Deposited) {
+	a.Balance += e.Amount
+}
+
+func TestRoute(t *testing.T) {
+	aggregate.Register(&account{})
+	aggregate.On((*account).deposited)
+
+	a := account{}
+	aggregate.TrackChange(&a, &Opened{Owner: "kalle"})
+	aggregate.TrackChange(&a, &Deposited{Amount: 10})
+	aggregate.TrackChange(&a, &Deposited{Amount: 5})
+	if a.Owner != "kalle" || a.Balance != 15 {
+		t.Fatalf("unexpected account %+v", a)
+	}
+
+	es := memory.Create()
+	err := aggregate.Save(es, &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := account{}
+	err = aggregate.Load(context.Background(), es, a.ID(), &loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Owner != "kalle" || loaded.Balance != 15 {
+		t.Fatalf("unexpected loaded account %+v", loaded)
+	}
+}
+
+func TestRouteNoHandler(t *testing.T) {
+	aggregate.Register(&account{})
+	a := account{}
+	aggregate.TrackChange(&a, &Closed{})
+	if aggregate.Route(&a, a.Events()[0]) {
+		t.Fatal("expected no route for the Closed event")
+	}
+}
+
+func TestRouteDelete(t *testing.T) {
+	aggregate.Register(&account{})
+	es := memory.Create()
+	a := account{}
+	aggregate.TrackChange(&a, &Opened{Owner: "kalle"})
+	err := aggregate.Save(es, &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = aggregate.Delete(context.Background(), es, a.ID(), &account{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Load(context.Background(), es, a.ID(), &account{})
+	if !errors.Is(err, eventsourcing.ErrAggregateDeleted) {
+		t.Fatalf("expected the account to be deleted was %v", err)
+	}
+	if !aggregate.Route(&a, eventsourcing.NewEvent(core.Event{Reason: "Tombstone"}, &eventsourcing.Tombstone{}, nil)) {
+		t.Fatal("expected the tombstone to be reported as handled")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"time"
 
 	"github.com/hallgren/eventsourcing"
 	"github.com/hallgren/eventsourcing/core"
@@ -20,6 +21,17 @@ type snapshot interface {
 	DeserializeSnapshot(f SnapshotUnmarshal, d []byte) error
 }
 
+// snapshotSchema is implemented by aggregates that version the shape of their snapshot state
+type snapshotSchema interface {
+	SnapshotSchemaVersion() uint
+}
+
+// snapshotUpcaster is implemented by aggregates that can migrate snapshot state from an older schema version. Returning
+// eventsourcing.ErrSnapshotOutdated discards the snapshot.
+type snapshotUpcaster interface {
+	UpcastSnapshot(version uint, state []byte) ([]byte, error)
+}
+
 type aggregateSnapshot interface {
 	aggregate
 	snapshot
@@ -44,7 +56,12 @@ func getSnapshot(ctx context.Context, ss core.SnapshotStore, id string, s snapsh
 		return err
 	}
 
-	err = s.DeserializeSnapshot(internal.SnapshotEncoder.Deserialize, snap.State)
+	state, err := upcastSnapshot(s, snap)
+	if err != nil {
+		return err
+	}
+
+	err = s.DeserializeSnapshot(internal.SnapshotEncoder.Deserialize, state)
 	if err != nil {
 		return err
 	}
@@ -58,6 +75,32 @@ func getSnapshot(ctx context.Context, ss core.SnapshotStore, id string, s snapsh
 	return nil
 }
 
+// upcastSnapshot returns the snapshot state in the current schema version of the aggregate. A snapshot with an older
+// schema version that can't be upcasted is discarded and reported as not found, making the aggregate to be built from
+// its events instead.
+func upcastSnapshot(s snapshot, snap core.Snapshot) ([]byte, error) {
+	current := schemaVersion(s)
+	if snap.SchemaVersion == current {
+		return snap.State, nil
+	}
+	upcaster, ok := s.(snapshotUpcaster)
+	if !ok || snap.SchemaVersion > current {
+		return nil, core.ErrSnapshotNotFound
+	}
+	state, err := upcaster.UpcastSnapshot(snap.SchemaVersion, snap.State)
+	if errors.Is(err, eventsourcing.ErrSnapshotOutdated) {
+		return nil, core.ErrSnapshotNotFound
+	}
+	return state, err
+}
+
+func schemaVersion(s snapshot) uint {
+	if v, ok := s.(snapshotSchema); ok {
+		return v.SnapshotSchemaVersion()
+	}
+	return 0
+}
+
 // SaveSnapshot will only store the snapshot and will return an error if there are events that are not stored
 func SaveSnapshot(ss core.SnapshotStore, s snapshot) error {
 	root := s.root()
@@ -78,6 +121,8 @@ func SaveSnapshot(ss core.SnapshotStore, s snapshot) error {
 		Version:       core.Version(root.Version()),
 		GlobalVersion: core.Version(root.GlobalVersion()),
 		State:         state,
+		SchemaVersion: schemaVersion(s),
+		Timestamp:     time.Now().UTC(),
 	}
 
 	return ss.Save(snapshot)
@@ -2,6 +2,7 @@ package aggregate_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -181,3 +182,155 @@ func TestSnapshotNoneExported(t *testing.T) {
 		t.Fatalf("exported value differed %s %s", snap.Exported, snap2.Exported)
 	}
 }
+
+type Renamed struct {
+	Name string
+}
+
+// SchemaVersion of the Renamed event data
+func (r *Renamed) SchemaVersion() uint {
+	return 3
+}
+
+// versioned has a snapshot schema in version 2 where the name property was renamed
+type versioned struct {
+	aggregate.Root
+	Name string
+}
+
+type versionedSnapshotV1 struct {
+	FullName string
+}
+
+type versionedSnapshot struct {
+	Name string
+}
+
+func (v *versioned) Transition(e eventsourcing.Event) {
+	switch d := e.Data().(type) {
+	case *Renamed:
+		v.Name = d.Name
+	}
+}
+
+func (v *versioned) Register(f aggregate.RegisterFunc) {
+	f(&Renamed{})
+}
+
+func (v *versioned) SerializeSnapshot(f aggregate.SnapshotMarshal) ([]byte, error) {
+	return f(versionedSnapshot{Name: v.Name})
+}
+
+func (v *versioned) DeserializeSnapshot(f aggregate.SnapshotUnmarshal, b []byte) error {
+	snap := versionedSnapshot{}
+	err := f(b, &snap)
+	if err != nil {
+		return err
+	}
+	v.Name = snap.Name
+	return nil
+}
+
+func (v *versioned) SnapshotSchemaVersion() uint {
+	return 2
+}
+
+func (v *versioned) UpcastSnapshot(version uint, state []byte) ([]byte, error) {
+	if version != 1 {
+		return nil, eventsourcing.ErrSnapshotOutdated
+	}
+	old := versionedSnapshotV1{}
+	err := json.Unmarshal(state, &old)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(versionedSnapshot{Name: old.FullName})
+}
+
+func TestSnapshotSchemaVersion(t *testing.T) {
+	es := memory.Create()
+	ss := snap.Create()
+	aggregate.Register(&versioned{})
+
+	v := versioned{}
+	aggregate.TrackChange(&v, &Renamed{Name: "kalle"})
+	err := aggregate.Save(es, &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.SaveSnapshot(ss, &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ss.Get(context.Background(), v.ID(), "versioned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.SchemaVersion != 2 {
+		t.Fatalf("expected schema version 2 was %d", s.SchemaVersion)
+	}
+}
+
+func TestUpcastSnapshot(t *testing.T) {
+	ss := snap.Create()
+	aggregate.Register(&versioned{})
+
+	err := ss.Save(core.Snapshot{
+		ID:            "123",
+		Type:          "versioned",
+		Version:       1,
+		GlobalVersion: 1,
+		State:         []byte(`{"FullName":"kalle"}`),
+		SchemaVersion: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v := versioned{}
+	err = aggregate.LoadSnapshot(context.Background(), ss, "123", &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "kalle" {
+		t.Fatalf("expected upcasted name kalle was %q", v.Name)
+	}
+}
+
+func TestDiscardOutdatedSnapshot(t *testing.T) {
+	es := memory.Create()
+	ss := snap.Create()
+	aggregate.Register(&versioned{})
+
+	v := versioned{}
+	aggregate.TrackChange(&v, &Renamed{Name: "kalle"})
+	err := aggregate.Save(es, &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// snapshot without schema version that the upcaster can't handle
+	err = ss.Save(core.Snapshot{
+		ID:            v.ID(),
+		Type:          "versioned",
+		Version:       1,
+		GlobalVersion: 1,
+		State:         []byte(`{"Unknown":"anka"}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := versioned{}
+	err = aggregate.LoadSnapshot(context.Background(), ss, v.ID(), &loaded)
+	if !errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		t.Fatalf("expected discarded snapshot to be not found was %v", err)
+	}
+
+	version, err := aggregate.LoadWithSnapshot(context.Background(), es, ss, v.ID(), &loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 1 || loaded.Name != "kalle" {
+		t.Fatalf("expected aggregate built from events, version %d name %q", version, loaded.Name)
+	}
+}
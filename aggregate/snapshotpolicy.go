@@ -0,0 +1,119 @@
+package aggregate
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// SnapshotPolicy decides if a snapshot is taken after the aggregate is saved. It gets the aggregate id and the
+// aggregate version before and after the save.
+type SnapshotPolicy func(id string, from, to eventsourcing.Version) bool
+
+// EveryNEvents takes a snapshot each time the aggregate version passes a multiple of n
+func EveryNEvents(n eventsourcing.Version) SnapshotPolicy {
+	return func(id string, from, to eventsourcing.Version) bool {
+		if n == 0 {
+			return false
+		}
+		return from/n < to/n
+	}
+}
+
+// EveryDuration takes a snapshot on the first save and when d has passed since the last snapshot. The time of the
+// last snapshot is kept in memory per aggregate id.
+func EveryDuration(d time.Duration) SnapshotPolicy {
+	var lock sync.Mutex
+	taken := make(map[string]time.Time)
+	return func(id string, from, to eventsourcing.Version) bool {
+		lock.Lock()
+		defer lock.Unlock()
+
+		last, ok := taken[id]
+		if ok && time.Since(last) < d {
+			return false
+		}
+		taken[id] = time.Now()
+		return true
+	}
+}
+
+// AnyOf takes a snapshot if any of the policies decides so
+func AnyOf(policies ...SnapshotPolicy) SnapshotPolicy {
+	return func(id string, from, to eventsourcing.Version) bool {
+		for _, policy := range policies {
+			if policy(id, from, to) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type snapshotOnSave struct {
+	ss     core.SnapshotStore
+	policy SnapshotPolicy
+}
+
+var (
+	snapshotPoliciesLock sync.RWMutex
+	snapshotPolicies     = make(map[string]snapshotOnSave) // snapshot policy by aggregate type
+)
+
+// SnapshotOnSave registers the snapshot policy of the aggregate type. Save, SaveContext and Repository.Save take a
+// snapshot in the snapshot store when the policy decides so, making the application code free from deciding when to
+// snapshot. The snapshot is best effort, the events are saved when it fails and the failure is logged via
+// eventsourcing.Log. A later registration replaces the earlier.
+//
+//	aggregate.SnapshotOnSave(&Person{}, ss, aggregate.EveryNEvents(100))
+func SnapshotOnSave(a aggregateSnapshot, ss core.SnapshotStore, policy SnapshotPolicy) {
+	snapshotPoliciesLock.Lock()
+	defer snapshotPoliciesLock.Unlock()
+	snapshotPolicies[aggregateType(a)] = snapshotOnSave{ss: ss, policy: policy}
+}
+
+// applySnapshotPolicy takes a snapshot of the saved aggregate if the policy registered for its type decides so.
+// Aggregates that can't be snapshotted are skipped.
+func applySnapshotPolicy(a aggregate, from eventsourcing.Version) {
+	snapshotPoliciesLock.RLock()
+	p, ok := snapshotPolicies[aggregateType(a)]
+	snapshotPoliciesLock.RUnlock()
+	if !ok {
+		return
+	}
+	as, ok := a.(aggregateSnapshot)
+	if !ok {
+		return
+	}
+	err := snapshotWithPolicy(p.ss, p.policy, as, from)
+	if err != nil {
+		eventsourcing.Log().Warn("snapshot on save failed", "aggregate_type", aggregateType(a), "aggregate_id", a.root().ID(), "version", a.root().Version(), "error", err)
+	}
+}
+
+// snapshotWithPolicy saves the snapshot of the saved aggregate if the policy decides so
+func snapshotWithPolicy(ss core.SnapshotStore, policy SnapshotPolicy, a aggregateSnapshot, from eventsourcing.Version) error {
+	root := a.root()
+	if !policy(root.ID(), from, root.Version()) {
+		return nil
+	}
+	return SaveSnapshot(ss, a)
+}
+
+// SaveWithSnapshotPolicy saves the aggregate events and takes a snapshot if the policy decides so. The policy is
+// applied next to the policy registered for the aggregate type via SnapshotOnSave.
+func SaveWithSnapshotPolicy(es core.EventStore, ss core.SnapshotStore, policy SnapshotPolicy, a aggregateSnapshot) error {
+	root := a.root()
+	if len(root.aggregateEvents) == 0 {
+		return nil
+	}
+	from := root.aggregateVersion
+
+	err := Save(es, a)
+	if err != nil {
+		return err
+	}
+	return snapshotWithPolicy(ss, policy, a, from)
+}
@@ -0,0 +1,159 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	snap "github.com/hallgren/eventsourcing/snapshotstore/memory"
+)
+
+func TestEveryNEvents(t *testing.T) {
+	policy := aggregate.EveryNEvents(10)
+	tests := []struct {
+		from, to uint64
+		expected bool
+	}{
+		{0, 1, false},
+		{0, 10, true},
+		{9, 12, true},
+		{10, 19, false},
+		{19, 35, true},
+	}
+	for _, test := range tests {
+		if policy("id", eventsourcing.Version(test.from), eventsourcing.Version(test.to)) != test.expected {
+			t.Fatalf("expected %v from %d to %d", test.expected, test.from, test.to)
+		}
+	}
+}
+
+func TestEveryDuration(t *testing.T) {
+	policy := aggregate.EveryDuration(time.Millisecond * 20)
+	if !policy("id", 0, 1) {
+		t.Fatal("expected snapshot on the first save")
+	}
+	if policy("id", 1, 2) {
+		t.Fatal("expected no snapshot before the duration has passed")
+	}
+	if !policy("other", 0, 1) {
+		t.Fatal("expected snapshot on the first save of another aggregate")
+	}
+	time.Sleep(time.Millisecond * 25)
+	if !policy("id", 2, 3) {
+		t.Fatal("expected snapshot after the duration has passed")
+	}
+}
+
+func TestSaveWithSnapshotPolicy(t *testing.T) {
+	es := memory.Create()
+	ss := snap.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := aggregate.EveryNEvents(3)
+
+	err = aggregate.SaveWithSnapshotPolicy(es, ss, policy, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ss.Get(context.Background(), person.ID(), "Person")
+	if !errors.Is(err, core.ErrSnapshotNotFound) {
+		t.Fatalf("expected no snapshot at version 1 was %v", err)
+	}
+
+	person.GrowOlder()
+	person.GrowOlder()
+	err = aggregate.SaveWithSnapshotPolicy(es, ss, policy, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ss.Get(context.Background(), person.ID(), "Person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != 3 {
+		t.Fatalf("expected snapshot at version 3 was %d", s.Version)
+	}
+}
+
+// snapshottedPerson has a snapshot policy registered via SnapshotOnSave
+type snapshottedPerson struct {
+	Person
+}
+
+func TestSnapshotOnSave(t *testing.T) {
+	es := memory.Create()
+	ss := snap.Create()
+	aggregate.Register(&snapshottedPerson{})
+	aggregate.SnapshotOnSave(&snapshottedPerson{}, ss, aggregate.EveryNEvents(3))
+
+	person := snapshottedPerson{}
+	aggregate.TrackChange(&person, &Born{Name: "kalle"})
+	person.GrowOlder()
+	err := aggregate.Save(es, &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = ss.Get(context.Background(), person.ID(), "snapshottedPerson")
+	if !errors.Is(err, core.ErrSnapshotNotFound) {
+		t.Fatalf("expected no snapshot at version 2 was %v", err)
+	}
+
+	// the policy is applied on saves via the repository
+	persons := aggregate.NewRepository[snapshottedPerson](es)
+	person.GrowOlder()
+	err = persons.Save(context.Background(), &person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ss.Get(context.Background(), person.ID(), "snapshottedPerson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != 3 {
+		t.Fatalf("expected snapshot at version 3 was %d", s.Version)
+	}
+}
+
+// failingSnapshotStore fails to save snapshots
+type failingSnapshotStore struct {
+	core.SnapshotStore
+}
+
+func (failingSnapshotStore) Save(snapshot core.Snapshot) error {
+	return errors.New("snapshot store down")
+}
+
+// unluckyPerson has a snapshot policy registered on a failing snapshot store
+type unluckyPerson struct {
+	Person
+}
+
+func TestSnapshotOnSaveBestEffort(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&unluckyPerson{})
+	aggregate.SnapshotOnSave(&unluckyPerson{}, failingSnapshotStore{}, aggregate.EveryNEvents(1))
+
+	person := unluckyPerson{}
+	aggregate.TrackChange(&person, &Born{Name: "kalle"})
+	err := aggregate.Save(es, &person)
+	if err != nil {
+		t.Fatalf("expected the save to succeed when the snapshot fails was %v", err)
+	}
+	twin := unluckyPerson{}
+	err = aggregate.Load(context.Background(), es, person.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Name != "kalle" {
+		t.Fatalf("expected the saved events was %+v", twin)
+	}
+}
@@ -0,0 +1,69 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Snapshotter takes snapshots in the background by following the global event feed. When an event makes an aggregate
+// cross the snapshot policy the aggregate is loaded and its snapshot saved, keeping the snapshot cost away from the
+// command handling.
+type Snapshotter struct {
+	es     core.EventStore
+	ss     core.SnapshotStore
+	policy SnapshotPolicy
+
+	lock       sync.RWMutex
+	aggregates map[string]reflect.Type
+}
+
+// NewSnapshotter creates a snapshotter that loads aggregates from the event store and saves their snapshots in the
+// snapshot store when the policy decides so.
+func NewSnapshotter(es core.EventStore, ss core.SnapshotStore, policy SnapshotPolicy) *Snapshotter {
+	return &Snapshotter{
+		es:         es,
+		ss:         ss,
+		policy:     policy,
+		aggregates: make(map[string]reflect.Type),
+	}
+}
+
+// Track makes the snapshotter take snapshots of the same aggregate type as a. Events from aggregate types that are not
+// tracked are ignored.
+func (s *Snapshotter) Track(a aggregateSnapshot) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.aggregates[aggregateType(a)] = reflect.TypeOf(a).Elem()
+}
+
+// Handle is the projection callback that decides if the event should result in a new snapshot of its aggregate.
+func (s *Snapshotter) Handle(ctx context.Context, event eventsourcing.Event) error {
+	s.lock.RLock()
+	typ, ok := s.aggregates[event.AggregateType()]
+	s.lock.RUnlock()
+	if !ok {
+		return nil
+	}
+	if !s.policy(event.AggregateID(), event.Version()-1, event.Version()) {
+		return nil
+	}
+	a := newAggregate(typ).(aggregateSnapshot)
+	_, err := LoadWithSnapshot(ctx, s.es, s.ss, event.AggregateID(), a)
+	if errors.Is(err, eventsourcing.ErrAggregateDeleted) {
+		// no need to snapshot deleted aggregates
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return SaveSnapshot(s.ss, a)
+}
+
+// Projection returns a projection running the snapshotter on the events from fetchF
+func (s *Snapshotter) Projection(fetchF func() (core.Iterator, error)) *eventsourcing.Projection {
+	return eventsourcing.NewProjectionWithContext(fetchF, s.Handle)
+}
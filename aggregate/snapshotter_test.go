@@ -0,0 +1,57 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	snap "github.com/hallgren/eventsourcing/snapshotstore/memory"
+)
+
+func TestSnapshotter(t *testing.T) {
+	es := memory.Create()
+	ss := snap.Create()
+	aggregate.Register(&Person{})
+
+	snapshotter := aggregate.NewSnapshotter(es, ss, aggregate.EveryNEvents(3))
+	snapshotter.Track(&Person{})
+	p := snapshotter.Projection(es.All(0, 10))
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	_, err = ss.Get(context.Background(), person.ID(), "Person")
+	if !errors.Is(err, core.ErrSnapshotNotFound) {
+		t.Fatalf("expected no snapshot at version 2 was %v", err)
+	}
+
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	s, err := ss.Get(context.Background(), person.ID(), "Person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != 3 {
+		t.Fatalf("expected snapshot at version 3 was %d", s.Version)
+	}
+}
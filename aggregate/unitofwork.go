@@ -0,0 +1,104 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/internal"
+)
+
+// UnitOfWork collects aggregates changed together and saves their events atomically, either the events of all
+// aggregates are saved or none. The event store has to implement core.BatchSaver.
+type UnitOfWork struct {
+	es         core.EventStore
+	aggregates []aggregate
+}
+
+// NewUnitOfWork creates a unit of work saving the aggregates in the event store
+func NewUnitOfWork(es core.EventStore) *UnitOfWork {
+	return &UnitOfWork{es: es}
+}
+
+// Add adds aggregates to be saved on commit, an aggregate already added is ignored
+func (u *UnitOfWork) Add(aggregates ...aggregate) {
+	for _, a := range aggregates {
+		if !u.added(a) {
+			u.aggregates = append(u.aggregates, a)
+		}
+	}
+}
+
+func (u *UnitOfWork) added(a aggregate) bool {
+	for _, added := range u.aggregates {
+		if added == a {
+			return true
+		}
+	}
+	return false
+}
+
+// Commit saves the unsaved events of the added aggregates in one batch. If neither the event store nor the event stores
+// it wraps implement core.BatchSaver core.ErrBatchNotSupported is returned and no events are saved. The unit of work is emptied when the
+// events are saved.
+func (u *UnitOfWork) Commit(ctx context.Context) error {
+	saver, ok := lookup[core.BatchSaver](u.es)
+	if !ok {
+		return core.ErrBatchNotSupported
+	}
+
+	var changed []aggregate
+	var pending [][]eventsourcing.Event
+	var batches [][]core.Event
+	for _, a := range u.aggregates {
+		root := a.root()
+		if len(root.aggregateEvents) == 0 {
+			continue
+		}
+		if !internal.GlobalRegister.AggregateRegistered(a) {
+			return fmt.Errorf("%s %w", aggregateType(a), eventsourcing.ErrAggregateNotRegistered)
+		}
+		if root.unhandled != nil {
+			return root.unhandled
+		}
+		err := validateID(a, root.aggregateID)
+		if err != nil {
+			return err
+		}
+		events := root.Events()
+		err = beforeSave(a, events)
+		if err != nil {
+			return err
+		}
+		batch, err := toCoreEvents(ctx, events, nil)
+		if err != nil {
+			return err
+		}
+		changed = append(changed, a)
+		pending = append(pending, events)
+		batches = append(batches, batch)
+	}
+	if len(batches) == 0 {
+		u.aggregates = nil
+		return nil
+	}
+
+	err := saver.SaveBatch(ctx, batches)
+	if err != nil {
+		if errors.Is(err, core.ErrConcurrency) {
+			return eventsourcing.ErrConcurrency
+		}
+		return fmt.Errorf("error from event store: %w", err)
+	}
+	for i, a := range changed {
+		batch := batches[i]
+		a.root().saved(eventsourcing.Version(batch[len(batch)-1].GlobalVersion))
+	}
+	u.aggregates = nil
+	for i, a := range changed {
+		afterSave(a, pending[i])
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package aggregate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestUnitOfWork(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	kalle, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	anka, err := CreatePerson("anka")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uow := aggregate.NewUnitOfWork(es)
+	uow.Add(kalle, anka, kalle)
+	err = uow.Commit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kalle.UnsavedEvents() || anka.UnsavedEvents() {
+		t.Fatal("expected the events to be saved")
+	}
+	if kalle.GlobalVersion() != 1 || anka.GlobalVersion() != 2 {
+		t.Fatalf("unexpected global versions %d %d", kalle.GlobalVersion(), anka.GlobalVersion())
+	}
+
+	// a stale aggregate makes the whole unit fail
+	stale := Person{}
+	err = aggregate.Load(context.Background(), es, anka.ID(), &stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anka.GrowOlder()
+	err = aggregate.Save(es, anka)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kalle.GrowOlder()
+	stale.GrowOlder()
+	uow.Add(kalle, &stale)
+	err = uow.Commit(context.Background())
+	if !errors.Is(err, eventsourcing.ErrConcurrency) {
+		t.Fatalf("expected concurrency error was %v", err)
+	}
+	twin := Person{}
+	err = aggregate.Load(context.Background(), es, kalle.ID(), &twin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if twin.Version() != 1 {
+		t.Fatalf("expected no events to be saved when the unit fails, version %d", twin.Version())
+	}
+}
+
+func TestUnitOfWorkNotSupported(t *testing.T) {
+	aggregate.Register(&Person{})
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uow := aggregate.NewUnitOfWork(struct{ core.EventStore }{memory.Create()})
+	uow.Add(person)
+	err = uow.Commit(context.Background())
+	if !errors.Is(err, core.ErrBatchNotSupported) {
+		t.Fatalf("expected batch not supported was %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package eventsourcing
+
+import (
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Annotate attaches an immutable annotation to the event, e.g. from a projection flagging events in a fraud review.
+// The event itself is not changed, the annotation is stored in the annotation store keyed on the event global version.
+func Annotate(as core.AnnotationStore, event Event, name, value string) error {
+	return as.Save(core.Annotation{
+		GlobalVersion: core.Version(event.GlobalVersion()),
+		Name:          name,
+		Value:         value,
+		Timestamp:     time.Now().UTC(),
+	})
+}
@@ -0,0 +1,49 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	as "github.com/hallgren/eventsourcing/annotationstore/memory"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestAnnotate(t *testing.T) {
+	// setup
+	es := memory.Create()
+	annotations := as.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proj := eventsourcing.NewProjection(es.All(0, 10), func(event eventsourcing.Event) error {
+		if event.Reason() == "AgedOneYear" {
+			return eventsourcing.Annotate(annotations, event, "reviewed", "ok")
+		}
+		return nil
+	})
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	found, err := annotations.Find(context.Background(), "reviewed", "ok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].GlobalVersion != 2 || found[1].GlobalVersion != 3 {
+		t.Fatalf("expected the AgedOneYear events to be annotated was %v", found)
+	}
+
+	err = eventsourcing.Annotate(annotations, result.LastHandledEvent, "reviewed", "changed")
+	if !errors.Is(err, core.ErrAnnotationExists) {
+		t.Fatalf("expected annotation exists error was %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type Memory struct {
+	annotations map[core.Version][]core.Annotation
+	lock        sync.Mutex
+}
+
+// Create in memory annotation store
+func Create() *Memory {
+	return &Memory{
+		annotations: make(map[core.Version][]core.Annotation),
+	}
+}
+
+func (m *Memory) Close() {
+
+}
+
+func (m *Memory) Save(annotation core.Annotation) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, a := range m.annotations[annotation.GlobalVersion] {
+		if a.Name == annotation.Name {
+			return core.ErrAnnotationExists
+		}
+	}
+	m.annotations[annotation.GlobalVersion] = append(m.annotations[annotation.GlobalVersion], annotation)
+	return nil
+}
+
+func (m *Memory) Get(ctx context.Context, globalVersion core.Version) ([]core.Annotation, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	annotations := make([]core.Annotation, len(m.annotations[globalVersion]))
+	copy(annotations, m.annotations[globalVersion])
+	return annotations, nil
+}
+
+// Find returns the annotations with the name and value ordered by the global version of the annotated events
+func (m *Memory) Find(ctx context.Context, name, value string) ([]core.Annotation, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var found []core.Annotation
+	for _, annotations := range m.annotations {
+		for _, a := range annotations {
+			if a.Name == name && a.Value == value {
+				found = append(found, a)
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].GlobalVersion < found[j].GlobalVersion
+	})
+	return found, nil
+}
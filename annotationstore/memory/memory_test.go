@@ -0,0 +1,40 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/annotationstore/memory"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.AnnotationStore, func(), error) {
+		as := memory.Create()
+		return as, func() { as.Close() }, nil
+	}
+	testsuite.TestAnnotationStore(t, f)
+}
+
+func TestFind(t *testing.T) {
+	as := memory.Create()
+	for _, a := range []core.Annotation{
+		{GlobalVersion: 7, Name: "invoice", Value: "123", Timestamp: time.Now()},
+		{GlobalVersion: 2, Name: "invoice", Value: "123", Timestamp: time.Now()},
+		{GlobalVersion: 5, Name: "invoice", Value: "456", Timestamp: time.Now()},
+	} {
+		err := as.Save(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	found, err := as.Find(context.Background(), "invoice", "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].GlobalVersion != 2 || found[1].GlobalVersion != 7 {
+		t.Fatalf("expected the annotated events 2 and 7 was %v", found)
+	}
+}
@@ -0,0 +1,139 @@
+// Package audit records administrative operations, like rebuilding a projection or erasing an aggregate, as events in
+// a dedicated system stream. Every target of an operation has its own AuditTrail stream, reading the AuditTrail
+// aggregate type from the event store gives the operations on all targets.
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// The administrative operations recorded by the tools in this repository
+const (
+	Rebuild  = "rebuild"  // a projection is replayed from the start of the event stream
+	Truncate = "truncate" // events or read model rows are removed
+	Erase    = "erase"    // the events of an aggregate are deleted or anonymized
+	Migrate  = "migrate"  // the event store or read model schema is changed
+)
+
+// AggregateType is the aggregate type of the audit trail streams
+const AggregateType = "AuditTrail"
+
+// retries is the number of times a record conflicting with a concurrent record is retried
+const retries = 3
+
+type actorKey struct{}
+
+// Operation is an administrative operation performed on a target
+type Operation struct {
+	Name    string            // Name of the operation, e.g. Rebuild
+	Target  string            // Target is what the operation was performed on, e.g. "projection/orders"
+	Actor   string            // Actor is who performed the operation, taken from the context if blank
+	Reason  string            // Reason the operation was performed
+	Details map[string]string // Details of the operation, e.g. the position a projection was reset to
+	Time    time.Time         // Time the operation was recorded, set from the event timestamp
+}
+
+// Performed is the event recording an operation on the target of the audit trail
+type Performed struct {
+	Name    string
+	Actor   string
+	Reason  string
+	Details map[string]string
+}
+
+// AuditTrail is the aggregate holding the operations performed on one target
+type AuditTrail struct {
+	aggregate.Root
+	Operations []Operation
+}
+
+// Register binds the audit trail events
+func (a *AuditTrail) Register(f aggregate.RegisterFunc) {
+	f(&Performed{})
+}
+
+// Transition appends the performed operation
+func (a *AuditTrail) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Performed:
+		a.Operations = append(a.Operations, Operation{
+			Name:    e.Name,
+			Target:  event.AggregateID(),
+			Actor:   e.Actor,
+			Reason:  e.Reason,
+			Details: e.Details,
+			Time:    event.Timestamp(),
+		})
+	}
+}
+
+// ContextWithActor returns a context carrying the actor, e.g. the user running the tool
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor from the context
+func Actor(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok
+}
+
+// Record appends the operation to the audit trail of its target. The actor is taken from the context when the
+// operation has no actor. Records conflicting with a concurrent record of the same target are retried.
+func Record(ctx context.Context, es core.EventStore, op Operation) error {
+	if op.Name == "" || op.Target == "" {
+		return errors.New("audit: operation name and target are required")
+	}
+	if op.Actor == "" {
+		op.Actor, _ = Actor(ctx)
+	}
+	var err error
+	for i := 0; i < retries; i++ {
+		err = record(ctx, es, op)
+		if !errors.Is(err, core.ErrConcurrency) {
+			return err
+		}
+	}
+	return err
+}
+
+func record(ctx context.Context, es core.EventStore, op Operation) error {
+	trail := AuditTrail{}
+	err := aggregate.Load(ctx, es, op.Target, &trail)
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		err = aggregate.SetID(&trail, op.Target)
+	}
+	if err != nil {
+		return err
+	}
+	aggregate.TrackChange(&trail, &Performed{
+		Name:    op.Name,
+		Actor:   op.Actor,
+		Reason:  op.Reason,
+		Details: op.Details,
+	})
+	return aggregate.SaveContext(ctx, es, &trail)
+}
+
+// History returns the operations performed on the target, oldest first
+func History(ctx context.Context, es core.EventStore, target string) ([]Operation, error) {
+	trail := AuditTrail{}
+	err := aggregate.Load(ctx, es, target, &trail)
+	if errors.Is(err, eventsourcing.ErrAggregateNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return trail.Operations, nil
+}
+
+func init() {
+	aggregate.Register(&AuditTrail{})
+}
@@ -0,0 +1,87 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/audit"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestRecord(t *testing.T) {
+	es := memory.Create()
+	ctx := audit.ContextWithActor(context.Background(), "kalle")
+
+	err := audit.Record(ctx, es, audit.Operation{
+		Name:    audit.Rebuild,
+		Target:  "projection/orders",
+		Reason:  "new read model column",
+		Details: map[string]string{"version": "2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = audit.Record(ctx, es, audit.Operation{Name: audit.Truncate, Target: "projection/orders", Actor: "anka"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = audit.Record(context.Background(), es, audit.Operation{Name: audit.Erase, Target: "Person/123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ops, err := audit.History(context.Background(), es, "projection/orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 {
+		t.Fatalf("expected 2 operations was %d", len(ops))
+	}
+	if ops[0].Name != audit.Rebuild || ops[0].Actor != "kalle" || ops[0].Reason != "new read model column" || ops[0].Details["version"] != "2" {
+		t.Fatalf("unexpected first operation %+v", ops[0])
+	}
+	if ops[0].Target != "projection/orders" || ops[0].Time.IsZero() {
+		t.Fatalf("expected target and time on the operation %+v", ops[0])
+	}
+	if ops[1].Name != audit.Truncate || ops[1].Actor != "anka" {
+		t.Fatalf("unexpected second operation %+v", ops[1])
+	}
+
+	// all operations are in the audit trail streams
+	iter, err := es.All(0, 10)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+	events := 0
+	for iter.Next() {
+		event, err := iter.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.AggregateType != audit.AggregateType {
+			t.Fatalf("expected aggregate type %s was %s", audit.AggregateType, event.AggregateType)
+		}
+		events++
+	}
+	if events != 3 {
+		t.Fatalf("expected 3 audit events was %d", events)
+	}
+}
+
+func TestRecordRequiresNameAndTarget(t *testing.T) {
+	err := audit.Record(context.Background(), memory.Create(), audit.Operation{Name: audit.Migrate})
+	if err == nil {
+		t.Fatal("expected error on blank target")
+	}
+}
+
+func TestHistoryUnknownTarget(t *testing.T) {
+	ops, err := audit.History(context.Background(), memory.Create(), "projection/unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 0 {
+		t.Fatalf("expected no operations was %d", len(ops))
+	}
+}
@@ -0,0 +1,272 @@
+package eventsourcing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// fetchFromFunc returns events in global order starting from the event with the start global version
+type fetchFromFunc func(start core.Version) (core.Iterator, error)
+
+// NewCheckpointProjection creates a projection that keeps its position in a checkpoint store. The position is loaded
+// the first time the projection runs and stored after each fetch. The name is used as the checkpoint key and the
+// version of the projection is stored in the checkpoint. If the version differs from the stored one, OnReset is called
+// and the projection replays the event stream from the beginning.
+func NewCheckpointProjection(name string, version int, cs core.CheckpointStore, fetchF fetchFromFunc, callbackF callbackContextFunc) *Projection {
+	projection := NewPositionProjection(fetchF, callbackF)
+	projection.Name = name
+	projection.Version = version
+	projection.checkpoints = cs
+	return projection
+}
+
+// NewPositionProjection creates a projection that keeps its position in memory. Unlike NewProjection the position is
+// not baked into the fetch function, the fetch function gets the global version of the next event to fetch. It makes
+// it possible to decide where the projection starts via the Start property.
+func NewPositionProjection(fetchF fetchFromFunc, callbackF callbackContextFunc) *Projection {
+	projection := NewProjectionWithContext(nil, callbackF)
+	projection.fetchFrom = fetchF
+	projection.fetchF = func() (core.Iterator, error) {
+		return fetchF(projection.position + 1)
+	}
+	return projection
+}
+
+// StartPosition decides where a projection starts when it has no checkpoint
+type StartPosition struct {
+	head bool
+	from time.Time
+}
+
+var (
+	// StartBeginning starts the projection from the first event in the event stream
+	StartBeginning = StartPosition{}
+	// StartHead starts the projection after the last event in the event stream, only new events are handled
+	StartHead = StartPosition{head: true}
+)
+
+// StartAt starts the projection from the first event with a timestamp at or after t
+func StartAt(t time.Time) StartPosition {
+	return StartPosition{from: t}
+}
+
+// Position returns the global version of the last event handled by the projection. It's safe to call while the
+// projection is running.
+func (p *Projection) Position() core.Version {
+	return core.Version(p.handled.Load())
+}
+
+// LastRun returns the time the projection last completed a fetch without error, the zero time if it has not. It's
+// safe to call while the projection is running.
+func (p *Projection) LastRun() time.Time {
+	t := p.lastRun.Load()
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, t)
+}
+
+// Running returns true while the projection is run via Run, a group or a manager
+func (p *Projection) Running() bool {
+	return p.running.Load()
+}
+
+// CaughtUp returns true when the projection has handled the events up to the head of the event store as of its start,
+// see Head and OnCaughtUp. It's safe to call while the projection is running.
+func (p *Projection) CaughtUp() bool {
+	return p.caughtUp.Load()
+}
+
+// LastError returns the error of the last fetch if it failed, a blank string if it succeeded
+func (p *Projection) LastError() string {
+	if p.failingSince.Load() == 0 {
+		return ""
+	}
+	err, _ := p.lastError.Load().(string)
+	return err
+}
+
+// loadCheckpoint sets the position from the checkpoint store the first time it's called. If there is no checkpoint or
+// if the projection version has changed the position is set from the Start property. On a changed version the
+// read-model is reset via OnReset before.
+func (p *Projection) loadCheckpoint(ctx context.Context) error {
+	if p.loaded {
+		return nil
+	}
+	if p.checkpoints == nil {
+		err := p.start(ctx)
+		if err != nil {
+			return err
+		}
+		p.loaded = true
+		return nil
+	}
+	checkpoint, err := p.checkpoints.Get(ctx, p.Name)
+	if err != nil && !errors.Is(err, core.ErrCheckpointNotFound) {
+		return err
+	}
+	found := err == nil
+	if found && checkpoint.ProjectionVersion == p.Version {
+		p.position = checkpoint.GlobalVersion
+		p.loaded = true
+		return nil
+	}
+	// the projection version has changed, reset the read-model before the event stream is replayed
+	if found && p.OnReset != nil {
+		err = p.OnReset(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	err = p.start(ctx)
+	if err != nil {
+		return err
+	}
+	err = p.saveCheckpoint()
+	if err != nil {
+		return err
+	}
+	p.loaded = true
+	return nil
+}
+
+// start sets the position from the Start property. The head and the first event at the start time are read via the
+// core.GlobalVersionReader and core.TimeRangeReader of the Store, other event stores have the events before the start
+// position fetched but not passed to the callback.
+func (p *Projection) start(ctx context.Context) error {
+	p.position = 0
+	if p.Start == StartBeginning {
+		return nil
+	}
+	if !p.Start.head {
+		if r, ok := lookup[core.TimeRangeReader](p.Store); ok {
+			found, err := p.startAt(ctx, r)
+			if err != nil || found {
+				return err
+			}
+			// no events at or after the start time, start after the last event
+		}
+	}
+	if r, ok := lookup[core.GlobalVersionReader](p.Store); ok {
+		head, err := r.GlobalVersion(ctx)
+		if err != nil {
+			return err
+		}
+		p.position = head
+		return nil
+	}
+	for {
+		iterator, err := p.fetchFrom(p.position + 1)
+		if err != nil {
+			return err
+		}
+		ran := false
+		for iterator.Next() {
+			ran = true
+			event, err := iterator.Value()
+			if err != nil {
+				iterator.Close()
+				return err
+			}
+			if !p.Start.head && !event.Timestamp.Before(p.Start.from) {
+				iterator.Close()
+				return nil
+			}
+			p.position = event.GlobalVersion
+		}
+		iterator.Close()
+		if !ran {
+			return nil
+		}
+	}
+}
+
+// startAt sets the position before the first event with a timestamp at or after the start time, false is returned if
+// there is no such event
+func (p *Projection) startAt(ctx context.Context, r core.TimeRangeReader) (bool, error) {
+	// the time range excludes from, step back to include the events at the start time
+	iterator, err := r.Between(ctx, p.Start.from.Add(-time.Nanosecond), time.Time{})
+	if err != nil {
+		return false, err
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		return false, nil
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		return false, err
+	}
+	p.position = event.GlobalVersion - 1
+	return true, nil
+}
+
+// lookup returns the event store as T, or the first event store it wraps implementing T
+func lookup[T any](es core.EventStore) (T, bool) {
+	for es != nil {
+		if t, ok := es.(T); ok {
+			return t, true
+		}
+		u, ok := es.(core.Unwrapper)
+		if !ok {
+			break
+		}
+		es = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// saveCheckpoint stores the current position in the checkpoint store, or the position before the oldest event held in
+// the buffer
+func (p *Projection) saveCheckpoint() error {
+	position := p.position
+	if p.Buffer != nil {
+		held, ok := p.Buffer.Held()
+		if ok && held <= position {
+			position = held - 1
+		}
+	}
+	return p.checkpoints.Save(core.Checkpoint{
+		Name:              p.Name,
+		ProjectionVersion: p.Version,
+		GlobalVersion:     position,
+	})
+}
+
+// ExportCheckpoints writes all checkpoints in the checkpoint store as JSON to w. Together with ImportCheckpoints it
+// makes it possible to move the projection positions to another environment, e.g. when read-models are restored from
+// a backup taken at a known position.
+func ExportCheckpoints(ctx context.Context, cs core.CheckpointLister, w io.Writer) error {
+	checkpoints, err := cs.All(ctx)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(checkpoints)
+}
+
+// ImportCheckpoints reads checkpoints written by ExportCheckpoints from r and saves them in the checkpoint store.
+// Existing checkpoints with the same name are overwritten.
+func ImportCheckpoints(ctx context.Context, cs core.CheckpointStore, r io.Reader) error {
+	var checkpoints []core.Checkpoint
+	err := json.NewDecoder(r).Decode(&checkpoints)
+	if err != nil {
+		return err
+	}
+	for _, checkpoint := range checkpoints {
+		err = ctx.Err()
+		if err != nil {
+			return err
+		}
+		err = cs.Save(checkpoint)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package eventsourcing_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	cs "github.com/hallgren/eventsourcing/checkpointstore/memory"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestCheckpointProjection(t *testing.T) {
+	// setup
+	es := memory.Create()
+	checkpoints := cs.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 2)()
+	}
+	counter := 0
+	callbackF := func(ctx context.Context, event eventsourcing.Event) error {
+		counter++
+		return nil
+	}
+
+	proj := eventsourcing.NewCheckpointProjection("persons", 1, checkpoints, fetchF, callbackF)
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if counter != 5 {
+		t.Fatalf("expected 5 handled events was %d", counter)
+	}
+
+	checkpoint, err := checkpoints.Get(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.GlobalVersion != 5 {
+		t.Fatalf("expected checkpoint global version 5 was %d", checkpoint.GlobalVersion)
+	}
+
+	// a new projection with the same name and version continues from the checkpoint
+	err = createPersonEvent(es, "anka", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter = 0
+	proj = eventsourcing.NewCheckpointProjection("persons", 1, checkpoints, fetchF, callbackF)
+	result = proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if counter != 1 {
+		t.Fatalf("expected 1 handled event was %d", counter)
+	}
+	if proj.Position() != 6 {
+		t.Fatalf("expected position 6 was %d", proj.Position())
+	}
+}
+
+func TestCheckpointProjectionVersionChange(t *testing.T) {
+	// setup
+	es := memory.Create()
+	checkpoints := cs.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 2)()
+	}
+	counter := 0
+	callbackF := func(ctx context.Context, event eventsourcing.Event) error {
+		counter++
+		return nil
+	}
+
+	proj := eventsourcing.NewCheckpointProjection("persons", 1, checkpoints, fetchF, callbackF)
+	reset := false
+	proj.OnReset = func(ctx context.Context) error {
+		reset = true
+		return nil
+	}
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if reset {
+		t.Fatal("expected no reset on the first run")
+	}
+
+	// bump the version
+	counter = 0
+	proj = eventsourcing.NewCheckpointProjection("persons", 2, checkpoints, fetchF, callbackF)
+	proj.OnReset = func(ctx context.Context) error {
+		reset = true
+		return nil
+	}
+	result = proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if !reset {
+		t.Fatal("expected OnReset to be called when the version changed")
+	}
+	if counter != 5 {
+		t.Fatalf("expected all 5 events to be replayed was %d", counter)
+	}
+	checkpoint, err := checkpoints.Get(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.ProjectionVersion != 2 {
+		t.Fatalf("expected checkpoint projection version 2 was %d", checkpoint.ProjectionVersion)
+	}
+}
+
+func TestPositionProjectionStart(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 5)
+	from := time.Now()
+	err = createPersonEvent(es, "anka", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 2)()
+	}
+	tests := []struct {
+		name     string
+		start    eventsourcing.StartPosition
+		expected int
+	}{
+		{"beginning", eventsourcing.StartBeginning, 7},
+		{"head", eventsourcing.StartHead, 0},
+		{"timestamp", eventsourcing.StartAt(from), 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			counter := 0
+			proj := eventsourcing.NewPositionProjection(fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+				counter++
+				return nil
+			})
+			proj.Start = test.start
+			result := proj.RunToEnd(context.Background())
+			if result.Error != nil {
+				t.Fatal(result.Error)
+			}
+			if counter != test.expected {
+				t.Fatalf("expected %d handled events was %d", test.expected, counter)
+			}
+			if proj.Position() != 7 {
+				t.Fatalf("expected position 7 was %d", proj.Position())
+			}
+		})
+	}
+}
+
+func TestPositionProjectionStartFromStore(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 5)
+	from := time.Now()
+	err = createPersonEvent(es, "anka", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		start    eventsourcing.StartPosition
+		expected core.Version
+	}{
+		{"head", eventsourcing.StartHead, 8},
+		{"timestamp", eventsourcing.StartAt(from), 6},
+		{"timestamp after the last event", eventsourcing.StartAt(time.Now().Add(time.Hour)), 8},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the start position is read from the store, the first fetch starts from it
+			var fetched []core.Version
+			fetchF := func(start core.Version) (core.Iterator, error) {
+				fetched = append(fetched, start)
+				return es.All(start, 2)()
+			}
+			proj := eventsourcing.NewPositionProjection(fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+				return nil
+			})
+			proj.Start = test.start
+			proj.Store = es
+			result := proj.RunToEnd(context.Background())
+			if result.Error != nil {
+				t.Fatal(result.Error)
+			}
+			if len(fetched) == 0 || fetched[0] != test.expected {
+				t.Fatalf("expected the first fetch from %d was %v", test.expected, fetched)
+			}
+			if proj.Position() != 7 {
+				t.Fatalf("expected position 7 was %d", proj.Position())
+			}
+		})
+	}
+}
+
+func TestCheckpointProjectionStartHead(t *testing.T) {
+	// setup
+	es := memory.Create()
+	checkpoints := cs.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 2)()
+	}
+	counter := 0
+	proj := eventsourcing.NewCheckpointProjection("persons", 1, checkpoints, fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+		counter++
+		return nil
+	})
+	proj.Start = eventsourcing.StartHead
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if counter != 0 {
+		t.Fatalf("expected no handled events was %d", counter)
+	}
+
+	// only the new events are handled
+	err = createPersonEvent(es, "anka", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if counter != 1 {
+		t.Fatalf("expected 1 handled event was %d", counter)
+	}
+
+	checkpoint, err := checkpoints.Get(context.Background(), "persons")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.GlobalVersion != 6 {
+		t.Fatalf("expected checkpoint global version 6 was %d", checkpoint.GlobalVersion)
+	}
+}
+
+func TestExportImportCheckpoints(t *testing.T) {
+	source := cs.Create()
+	target := cs.Create()
+
+	checkpoints := []core.Checkpoint{
+		{Name: "orders", ProjectionVersion: 2, GlobalVersion: 100},
+		{Name: "persons", ProjectionVersion: 1, GlobalVersion: 42},
+	}
+	for _, checkpoint := range checkpoints {
+		err := source.Save(checkpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := eventsourcing.ExportCheckpoints(context.Background(), source, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = eventsourcing.ImportCheckpoints(context.Background(), target, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, checkpoint := range checkpoints {
+		c, err := target.Get(context.Background(), checkpoint.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c != checkpoint {
+			t.Fatalf("expected imported checkpoint %v was %v", checkpoint, c)
+		}
+	}
+}
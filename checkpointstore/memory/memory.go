@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type Memory struct {
+	checkpoints map[string]core.Checkpoint
+	lock        sync.Mutex
+}
+
+// Create in memory checkpoint store
+func Create() *Memory {
+	return &Memory{
+		checkpoints: make(map[string]core.Checkpoint),
+	}
+}
+
+func (m *Memory) Close() {
+
+}
+
+func (m *Memory) Get(ctx context.Context, name string) (core.Checkpoint, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	checkpoint, ok := m.checkpoints[name]
+	if !ok {
+		return core.Checkpoint{}, core.ErrCheckpointNotFound
+	}
+	return checkpoint, nil
+}
+
+func (m *Memory) Save(checkpoint core.Checkpoint) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.checkpoints[checkpoint.Name] = checkpoint
+	return nil
+}
+
+// All returns all checkpoints ordered by name
+func (m *Memory) All(ctx context.Context) ([]core.Checkpoint, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	checkpoints := make([]core.Checkpoint, 0, len(m.checkpoints))
+	for _, checkpoint := range m.checkpoints {
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Name < checkpoints[j].Name
+	})
+	return checkpoints, nil
+}
@@ -0,0 +1,35 @@
+package memory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/checkpointstore/memory"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.CheckpointStore, func(), error) {
+		cs := memory.Create()
+		return cs, func() { cs.Close() }, nil
+	}
+	testsuite.TestCheckpointStore(t, f)
+}
+
+func TestAll(t *testing.T) {
+	cs := memory.Create()
+	cs.Save(core.Checkpoint{Name: "b", GlobalVersion: 2})
+	cs.Save(core.Checkpoint{Name: "a", GlobalVersion: 1})
+
+	checkpoints, err := cs.All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 2 {
+		t.Fatalf("expected 2 checkpoints was %d", len(checkpoints))
+	}
+	if checkpoints[0].Name != "a" || checkpoints[1].Name != "b" {
+		t.Fatal("expected checkpoints ordered by name")
+	}
+}
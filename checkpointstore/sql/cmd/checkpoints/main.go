@@ -0,0 +1,65 @@
+// Command checkpoints exports the projection checkpoints in a SQL checkpoint store to a JSON document and imports
+// them into another checkpoint store, e.g. to move the projection positions to another environment when the
+// read-models are restored from a backup.
+//
+//	checkpoints -dsn checkpoints.db export > checkpoints.json
+//	checkpoints -dsn other.db import < checkpoints.json
+//
+// The checkpoint table is created by import if it's missing. Only the sqlite3 driver is registered.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/hallgren/eventsourcing"
+	cs "github.com/hallgren/eventsourcing/checkpointstore/sql"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func main() {
+	driver := flag.String("driver", "sqlite3", "database driver")
+	dsn := flag.String("dsn", "", "data source name of the database holding the checkpoints")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s -dsn <dsn> export|import\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *dsn == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	db, err := sql.Open(*driver, *dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	store := cs.Open(db)
+	defer store.Close()
+
+	err = run(context.Background(), store, flag.Arg(0), os.Stdin, os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run exports the checkpoints to w or imports them from r
+func run(ctx context.Context, store *cs.SQL, command string, r io.Reader, w io.Writer) error {
+	switch command {
+	case "export":
+		return eventsourcing.ExportCheckpoints(ctx, store, w)
+	case "import":
+		err := store.Migrate()
+		if err != nil {
+			return err
+		}
+		return eventsourcing.ImportCheckpoints(ctx, store, r)
+	default:
+		return fmt.Errorf("unknown command %q, expected export or import", command)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	cs "github.com/hallgren/eventsourcing/checkpointstore/sql"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+func open(t *testing.T, file string) *cs.SQL {
+	db, err := sql.Open("sqlite3", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cs.Open(db)
+}
+
+func TestExportImport(t *testing.T) {
+	dir := t.TempDir()
+	source := open(t, filepath.Join(dir, "source.db"))
+	defer source.Close()
+	err := source.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = source.Save(core.Checkpoint{Name: "users", ProjectionVersion: 2, GlobalVersion: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = run(context.Background(), source, "export", nil, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"name":"users","projection_version":2,"global_version":10}]` + "\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %s was %s", expected, buf.String())
+	}
+
+	// the checkpoint table is created on import
+	target := open(t, filepath.Join(dir, "target.db"))
+	defer target.Close()
+	err = run(context.Background(), target, "import", &buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoint, err := target.Get(context.Background(), "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.ProjectionVersion != 2 || checkpoint.GlobalVersion != 10 {
+		t.Fatalf("expected the imported checkpoint was %v", checkpoint)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	store := open(t, filepath.Join(t.TempDir(), "checkpoints.db"))
+	defer store.Close()
+	err := run(context.Background(), store, "delete", nil, nil)
+	if err == nil {
+		t.Fatal("expected error on unknown command")
+	}
+}
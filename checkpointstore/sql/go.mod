@@ -0,0 +1,13 @@
+module github.com/hallgren/eventsourcing/checkpointstore/sql
+
+go 1.21
+
+require (
+	github.com/hallgren/eventsourcing v0.9.0
+	github.com/hallgren/eventsourcing/core v0.5.0
+	github.com/mattn/go-sqlite3 v1.14.27
+)
+
+// replace github.com/hallgren/eventsourcing => ../..
+
+// replace github.com/hallgren/eventsourcing/core => ../../core
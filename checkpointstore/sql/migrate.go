@@ -0,0 +1,34 @@
+package sql
+
+import "context"
+
+// Migrate the database
+func (s *SQL) Migrate() error {
+	sqlStmt := []string{
+		`create table checkpoints (name VARCHAR NOT NULL, projection_version INTEGER NOT NULL, global_version INTEGER NOT NULL, PRIMARY KEY (name));`,
+	}
+	return s.migrate(sqlStmt)
+}
+
+func (s *SQL) migrate(stm []string) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// check if the migration is already done
+	rows, err := tx.Query(`Select count(*) from checkpoints`)
+	if err == nil {
+		rows.Close()
+		return nil
+	}
+
+	for _, b := range stm {
+		_, err := tx.Exec(b)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
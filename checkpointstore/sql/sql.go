@@ -0,0 +1,88 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// SQL is a checkpoint store keeping the projection positions over restarts
+type SQL struct {
+	db *sql.DB
+}
+
+// Open connection to database
+func Open(db *sql.DB) *SQL {
+	return &SQL{
+		db: db,
+	}
+}
+
+// Close the connection
+func (s *SQL) Close() {
+	s.db.Close()
+}
+
+// Save persists the checkpoint
+func (s *SQL) Save(checkpoint core.Checkpoint) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return errors.New(fmt.Sprintf("could not start a write transaction, %v", err))
+	}
+	defer tx.Rollback()
+
+	statement := `UPDATE checkpoints SET projection_version=$1, global_version=$2 WHERE name=$3`
+	res, err := tx.Exec(statement, checkpoint.ProjectionVersion, checkpoint.GlobalVersion, checkpoint.Name)
+	if err != nil {
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		statement = `INSERT INTO checkpoints (name, projection_version, global_version) VALUES ($1, $2, $3)`
+		_, err = tx.Exec(statement, checkpoint.Name, checkpoint.ProjectionVersion, checkpoint.GlobalVersion)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Get returns the checkpoint of the projection
+func (s *SQL) Get(ctx context.Context, name string) (core.Checkpoint, error) {
+	checkpoint := core.Checkpoint{Name: name}
+	statement := `SELECT projection_version, global_version FROM checkpoints WHERE name=$1`
+	err := s.db.QueryRowContext(ctx, statement, name).Scan(&checkpoint.ProjectionVersion, &checkpoint.GlobalVersion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Checkpoint{}, core.ErrCheckpointNotFound
+	}
+	if err != nil {
+		return core.Checkpoint{}, err
+	}
+	return checkpoint, nil
+}
+
+// All returns all checkpoints ordered by name
+func (s *SQL) All(ctx context.Context) ([]core.Checkpoint, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name, projection_version, global_version FROM checkpoints ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checkpoints := []core.Checkpoint{}
+	for rows.Next() {
+		var checkpoint core.Checkpoint
+		err = rows.Scan(&checkpoint.Name, &checkpoint.ProjectionVersion, &checkpoint.GlobalVersion)
+		if err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints, rows.Err()
+}
@@ -0,0 +1,108 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/checkpointstore/sql"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.CheckpointStore, func(), error) {
+		return checkpointstore()
+	}
+	testsuite.TestCheckpointStore(t, f)
+}
+
+func TestMultipleMigrate(t *testing.T) {
+	cs, close, err := checkpointstore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+	err = cs.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "checkpoints.db")
+	open := func() *sql.SQL {
+		db, err := sqldriver.Open("sqlite3", file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cs := sql.Open(db)
+		err = cs.Migrate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cs
+	}
+	cs := open()
+	err := cs.Save(core.Checkpoint{Name: "users", ProjectionVersion: 2, GlobalVersion: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs.Close()
+
+	cs = open()
+	defer cs.Close()
+	checkpoint, err := cs.Get(context.Background(), "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.ProjectionVersion != 2 || checkpoint.GlobalVersion != 10 {
+		t.Fatalf("expected the checkpoint to be kept after reopen was %v", checkpoint)
+	}
+}
+
+func TestAll(t *testing.T) {
+	cs, close, err := checkpointstore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+	cs.Save(core.Checkpoint{Name: "b", GlobalVersion: 2})
+	cs.Save(core.Checkpoint{Name: "a", GlobalVersion: 1})
+
+	checkpoints, err := cs.All(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checkpoints) != 2 {
+		t.Fatalf("expected 2 checkpoints was %d", len(checkpoints))
+	}
+	if checkpoints[0].Name != "a" || checkpoints[1].Name != "b" {
+		t.Fatal("expected checkpoints ordered by name")
+	}
+}
+
+func checkpointstore() (*sql.SQL, func(), error) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	err = db.Ping()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := sql.Open(db)
+	err = store.Migrate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return store, func() {
+		store.Close()
+	}, nil
+}
@@ -0,0 +1,135 @@
+// Package cloudevents converts events to and from CloudEvents 1.0, letting published events interoperate with
+// brokers and platforms speaking CloudEvents like Knative and EventBridge.
+//
+// The event reason is the CloudEvents type, the aggregate id the subject and the data is encoded as JSON. The
+// aggregate type, versions and metadata are carried in extension attributes.
+package cloudevents
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	ce "github.com/cloudevents/sdk-go/v2/event"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Extension attributes carrying the event properties without a CloudEvents attribute
+const (
+	ExtensionAggregateType    = "aggregatetype"
+	ExtensionAggregateVersion = "aggregateversion"
+	ExtensionGlobalVersion    = "globalversion"
+	ExtensionSchemaVersion    = "schemaversion"
+	ExtensionMetadata         = "metadata"
+)
+
+// Converter converts events to and from CloudEvents
+type Converter struct {
+	Source     string // Source is the CloudEvents source of the converted events, e.g. the URI of the service
+	TypePrefix string // TypePrefix is prepended to the reason in the CloudEvents type, e.g. "com.example.person."
+}
+
+// New creates a converter with the CloudEvents source
+func New(source string) *Converter {
+	return &Converter{Source: source}
+}
+
+// ToCloudEvent converts the event. The id is made from the aggregate type, id and version making it unique within the
+// source and stable when the event is converted again.
+func (c *Converter) ToCloudEvent(event eventsourcing.Event) (ce.Event, error) {
+	e := ce.New()
+	e.SetID(fmt.Sprintf("%s-%s-%d", event.AggregateType(), event.AggregateID(), event.Version()))
+	e.SetSource(c.Source)
+	e.SetType(c.TypePrefix + event.Reason())
+	e.SetSubject(event.AggregateID())
+	e.SetTime(event.Timestamp())
+	e.SetExtension(ExtensionAggregateType, event.AggregateType())
+	// the versions are unsigned 64 bit integers not fitting the CloudEvents integer type
+	e.SetExtension(ExtensionAggregateVersion, fmt.Sprint(event.Version()))
+	e.SetExtension(ExtensionSchemaVersion, int32(event.SchemaVersion()))
+	// the global version is set when the event is stored
+	if event.GlobalVersion() != 0 {
+		e.SetExtension(ExtensionGlobalVersion, fmt.Sprint(event.GlobalVersion()))
+	}
+	if len(event.Metadata()) > 0 {
+		metadata, err := json.Marshal(event.Metadata())
+		if err != nil {
+			return e, err
+		}
+		e.SetExtension(ExtensionMetadata, string(metadata))
+	}
+	err := e.SetData(ce.ApplicationJSON, event.Data())
+	if err != nil {
+		return e, err
+	}
+	return e, e.Validate()
+}
+
+// FromCloudEvent converts the CloudEvent back to an event. The event data is decoded into the data type registered on
+// the aggregate type with the reason, CloudEvents from other sources return eventsourcing.ErrEventNotRegistered.
+func (c *Converter) FromCloudEvent(e ce.Event) (eventsourcing.Event, error) {
+	err := e.Validate()
+	if err != nil {
+		return eventsourcing.Event{}, err
+	}
+	if !strings.HasPrefix(e.Type(), c.TypePrefix) {
+		return eventsourcing.Event{}, fmt.Errorf("type %s is missing the prefix %s", e.Type(), c.TypePrefix)
+	}
+	var aggregateType string
+	err = e.ExtensionAs(ExtensionAggregateType, &aggregateType)
+	if err != nil {
+		return eventsourcing.Event{}, err
+	}
+	event := core.Event{
+		AggregateID:   e.Subject(),
+		AggregateType: aggregateType,
+		Reason:        strings.TrimPrefix(e.Type(), c.TypePrefix),
+		Timestamp:     e.Time(),
+	}
+	event.Version, err = version(e, ExtensionAggregateVersion)
+	if err != nil {
+		return eventsourcing.Event{}, err
+	}
+	event.GlobalVersion, err = version(e, ExtensionGlobalVersion)
+	if err != nil {
+		return eventsourcing.Event{}, err
+	}
+	schemaVersion, err := version(e, ExtensionSchemaVersion)
+	if err != nil {
+		return eventsourcing.Event{}, err
+	}
+	event.SchemaVersion = uint(schemaVersion)
+
+	var metadata map[string]interface{}
+	var m string
+	if e.ExtensionAs(ExtensionMetadata, &m) == nil {
+		err = json.Unmarshal([]byte(m), &metadata)
+		if err != nil {
+			return eventsourcing.Event{}, err
+		}
+	}
+	data, err := eventsourcing.NewEventData(event.AggregateType, event.Reason)
+	if err != nil {
+		return eventsourcing.Event{}, err
+	}
+	err = e.DataAs(data)
+	if err != nil {
+		return eventsourcing.Event{}, err
+	}
+	return eventsourcing.NewEvent(event, data, metadata), nil
+}
+
+// version returns the version in the extension attribute, a missing attribute is version zero
+func version(e ce.Event, name string) (core.Version, error) {
+	value, ok := e.Extensions()[name]
+	if !ok {
+		return 0, nil
+	}
+	var v uint64
+	_, err := fmt.Sscan(fmt.Sprint(value), &v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %v", name, value)
+	}
+	return core.Version(v), nil
+}
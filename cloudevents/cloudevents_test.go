@@ -0,0 +1,95 @@
+package cloudevents_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	ce "github.com/cloudevents/sdk-go/v2/event"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/cloudevents"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type Person struct {
+	aggregate.Root
+}
+
+type Born struct {
+	Name string
+}
+
+func (p *Person) Register(f aggregate.RegisterFunc) {
+	f(&Born{})
+}
+
+func (p *Person) Transition(event eventsourcing.Event) {}
+
+func TestRoundTrip(t *testing.T) {
+	aggregate.Register(&Person{})
+	timestamp := time.Now().UTC().Truncate(time.Second)
+	event := eventsourcing.NewEvent(core.Event{
+		AggregateID:   "123",
+		AggregateType: "Person",
+		Version:       1,
+		GlobalVersion: 42,
+		Reason:        "Born",
+		Timestamp:     timestamp,
+	}, &Born{Name: "kalle"}, map[string]interface{}{"tenant": "acme"})
+
+	c := cloudevents.New("https://example.com/persons")
+	c.TypePrefix = "com.example.person."
+	e, err := c.ToCloudEvent(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Type() != "com.example.person.Born" || e.Subject() != "123" || e.Source() != "https://example.com/persons" {
+		t.Fatalf("unexpected cloud event %s", e)
+	}
+	if e.Extensions()[cloudevents.ExtensionGlobalVersion] != "42" {
+		t.Fatalf("expected global version 42 was %v", e.Extensions()[cloudevents.ExtensionGlobalVersion])
+	}
+
+	// send the event over the wire in structured mode
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := ce.New()
+	err = json.Unmarshal(b, &received)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err = c.FromCloudEvent(received)
+	if err != nil {
+		t.Fatal(err)
+	}
+	born, ok := event.Data().(*Born)
+	if !ok || born.Name != "kalle" {
+		t.Fatalf("unexpected data %#v", event.Data())
+	}
+	if event.AggregateID() != "123" || event.AggregateType() != "Person" || event.Reason() != "Born" {
+		t.Fatalf("unexpected event %+v", event.CoreEvent())
+	}
+	if event.Version() != 1 || event.GlobalVersion() != 42 || !event.Timestamp().Equal(timestamp) {
+		t.Fatalf("unexpected event %+v", event.CoreEvent())
+	}
+	if event.Metadata()["tenant"] != "acme" {
+		t.Fatalf("unexpected metadata %v", event.Metadata())
+	}
+}
+
+func TestFromCloudEventNotRegistered(t *testing.T) {
+	e := ce.New()
+	e.SetID("1")
+	e.SetSource("other")
+	e.SetType("Unknown")
+	e.SetExtension(cloudevents.ExtensionAggregateType, "Person")
+	_, err := cloudevents.New("other").FromCloudEvent(e)
+	if !errors.Is(err, eventsourcing.ErrEventNotRegistered) {
+		t.Fatalf("expected event not registered was %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+module github.com/hallgren/eventsourcing/cloudevents
+
+go 1.21
+
+require (
+	github.com/cloudevents/sdk-go/v2 v2.15.2
+	github.com/hallgren/eventsourcing v0.9.0
+	github.com/hallgren/eventsourcing/core v0.5.0
+)
+
+require (
+	github.com/google/go-cmp v0.6.0 // indirect
+	github.com/json-iterator/go v1.1.12 // indirect
+	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/stretchr/testify v1.8.4 // indirect
+)
+
+// replace (
+// 	github.com/hallgren/eventsourcing => ../
+// 	github.com/hallgren/eventsourcing/core => ../core
+// )
@@ -0,0 +1,194 @@
+// Command eventgen generates the event registration boilerplate for aggregates. Event structs are marked with a
+// comment naming the aggregate they belong to.
+//
+//	// Born event
+//	//eventsourcing:event Person
+//	type Born struct {
+//		Name string
+//	}
+//
+// For each aggregate a Register method binding its events and constants for the event reasons, prefixed with the
+// aggregate name as two aggregates can have events with the same reason, are generated. With
+// the -handlers flag a typed handler interface and a dispatch function to use as projection callback is generated as
+// well.
+//
+//	//go:generate go run github.com/hallgren/eventsourcing/cmd/eventgen -handlers
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const marker = "//eventsourcing:event "
+
+// aggregate holds the events marked for an aggregate
+type aggregate struct {
+	Name   string
+	Events []string
+}
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package with the event structs")
+	output := flag.String("output", "events_gen.go", "name of the generated file in the package directory")
+	handlers := flag.Bool("handlers", false, "generate typed projection handler interfaces")
+	flag.Parse()
+
+	pkg, aggregates, err := scan(*dir, *output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(aggregates) == 0 {
+		log.Fatalf("no event structs marked with %q found in %s", strings.TrimSpace(marker), *dir)
+	}
+	src, err := generate(pkg, aggregates, *handlers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(*dir, *output), src, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// scan returns the package name and the aggregates with their marked events found in the go files in dir. The
+// previously generated file is skipped.
+func scan(dir, output string) (string, []aggregate, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return fi.Name() != output && !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(pkgs) != 1 {
+		return "", nil, fmt.Errorf("expected one package in %s found %d", dir, len(pkgs))
+	}
+
+	var pkgName string
+	events := make(map[string][]string)
+	for name, pkg := range pkgs {
+		pkgName = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if _, ok := ts.Type.(*ast.StructType); !ok {
+						continue
+					}
+					doc := ts.Doc
+					if doc == nil {
+						doc = gen.Doc
+					}
+					aggregateName, ok := markedAggregate(doc)
+					if ok {
+						events[aggregateName] = append(events[aggregateName], ts.Name.Name)
+					}
+				}
+			}
+		}
+	}
+
+	// sort to keep the generated file stable between runs
+	aggregates := make([]aggregate, 0, len(events))
+	for name, e := range events {
+		sort.Strings(e)
+		aggregates = append(aggregates, aggregate{Name: name, Events: e})
+	}
+	sort.Slice(aggregates, func(i, j int) bool {
+		return aggregates[i].Name < aggregates[j].Name
+	})
+	return pkgName, aggregates, nil
+}
+
+// markedAggregate returns the aggregate name from the event marker in the doc comment
+func markedAggregate(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, c := range doc.List {
+		if strings.HasPrefix(c.Text, marker) {
+			return strings.TrimSpace(strings.TrimPrefix(c.Text, marker)), true
+		}
+	}
+	return "", false
+}
+
+var tmpl = template.Must(template.New("events").Parse(`// Code generated by eventgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- if .Handlers}}
+	"fmt"
+
+	"github.com/hallgren/eventsourcing"
+{{- end}}
+	"github.com/hallgren/eventsourcing/aggregate"
+)
+{{range .Aggregates}}{{$aggregate := .Name}}
+// Reasons of the {{.Name}} events
+const (
+{{- range .Events}}
+	{{$aggregate}}Reason{{.}} = "{{.}}"
+{{- end}}
+)
+
+// Register binds the {{.Name}} events when the aggregate is registered
+func (a *{{.Name}}) Register(f aggregate.RegisterFunc) {
+	f({{range $i, $e := .Events}}{{if $i}}, {{end}}&{{$e}}{}{{end}})
+}
+{{if $.Handlers}}
+// {{.Name}}Handler handles the {{.Name}} events with typed data
+type {{.Name}}Handler interface {
+{{- range .Events}}
+	On{{.}}(event eventsourcing.Event, data *{{.}}) error
+{{- end}}
+}
+
+// Handle{{.Name}} returns a projection callback dispatching the {{.Name}} events to the handler. Events from other
+// aggregates are ignored.
+func Handle{{.Name}}(h {{.Name}}Handler) func(event eventsourcing.Event) error {
+	return func(event eventsourcing.Event) error {
+		if event.AggregateType() != "{{.Name}}" {
+			return nil
+		}
+		switch data := event.Data().(type) {
+{{- range .Events}}
+		case *{{.}}:
+			return h.On{{.}}(event, data)
+{{- end}}
+		}
+		return fmt.Errorf("unhandled {{.Name}} event %s", event.Reason())
+	}
+}
+{{end}}{{end}}`))
+
+// generate returns the formatted source of the generated file
+func generate(pkg string, aggregates []aggregate, handlers bool) ([]byte, error) {
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, struct {
+		Package    string
+		Aggregates []aggregate
+		Handlers   bool
+	}{pkg, aggregates, handlers})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const source = `package person
+
+import "github.com/hallgren/eventsourcing/aggregate"
+
+type Person struct {
+	aggregate.Root
+}
+
+// Born event
+//eventsourcing:event Person
+type Born struct {
+	Name string
+}
+
+//eventsourcing:event Person
+type AgedOneYear struct{}
+
+// NotAnEvent is not marked
+type NotAnEvent struct{}
+`
+
+func TestScanAndGenerate(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "person.go"), []byte(source), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an old generated file should not be scanned
+	err = os.WriteFile(filepath.Join(dir, "events_gen.go"), []byte("package person\n\nbroken"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, aggregates, err := scan(dir, "events_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg != "person" {
+		t.Fatalf("expected package person was %q", pkg)
+	}
+	if len(aggregates) != 1 || aggregates[0].Name != "Person" {
+		t.Fatalf("expected the Person aggregate was %v", aggregates)
+	}
+	if strings.Join(aggregates[0].Events, ",") != "AgedOneYear,Born" {
+		t.Fatalf("expected sorted marked events was %v", aggregates[0].Events)
+	}
+
+	src, err := generate(pkg, aggregates, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = parser.ParseFile(token.NewFileSet(), "events_gen.go", src, 0)
+	if err != nil {
+		t.Fatalf("generated source does not parse %v\n%s", err, src)
+	}
+	for _, expected := range []string{
+		`PersonReasonAgedOneYear = "AgedOneYear"`,
+		`f(&AgedOneYear{}, &Born{})`,
+		`OnBorn(event eventsourcing.Event, data *Born) error`,
+		`func HandlePerson(h PersonHandler) func(event eventsourcing.Event) error`,
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Fatalf("expected %q in generated source\n%s", expected, src)
+		}
+	}
+}
+
+func TestGenerateWithoutHandlers(t *testing.T) {
+	src, err := generate("person", []aggregate{{Name: "Person", Events: []string{"Born"}}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(src), "PersonHandler") || strings.Contains(string(src), `"fmt"`) {
+		t.Fatalf("expected no handlers in generated source\n%s", src)
+	}
+}
+
+func TestGenerateSameReasonInTwoAggregates(t *testing.T) {
+	src, err := generate("shop", []aggregate{{Name: "Order", Events: []string{"Created"}}, {Name: "Invoice", Events: []string{"Created"}}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`OrderReasonCreated = "Created"`, `InvoiceReasonCreated = "Created"`} {
+		if !strings.Contains(string(src), expected) {
+			t.Fatalf("expected %q in generated source\n%s", expected, src)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package eventsourcing
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// ConsistencyToken captures the global position reached by a write. It's handed to the client after a command and
+// passed back with a query, letting the read side wait until the read-model has incorporated the write.
+type ConsistencyToken struct {
+	GlobalVersion Version
+}
+
+// NewConsistencyToken creates a token covering all the global versions, typically from the GlobalVersion method of
+// the saved aggregates.
+func NewConsistencyToken(globalVersions ...Version) ConsistencyToken {
+	var token ConsistencyToken
+	for _, v := range globalVersions {
+		if v > token.GlobalVersion {
+			token.GlobalVersion = v
+		}
+	}
+	return token
+}
+
+// Merge returns a token covering both tokens
+func (t ConsistencyToken) Merge(other ConsistencyToken) ConsistencyToken {
+	return NewConsistencyToken(t.GlobalVersion, other.GlobalVersion)
+}
+
+// String encodes the token, e.g. to be passed in an HTTP header
+func (t ConsistencyToken) String() string {
+	return strconv.FormatUint(uint64(t.GlobalVersion), 10)
+}
+
+// ParseConsistencyToken decodes a token encoded with String
+func ParseConsistencyToken(s string) (ConsistencyToken, error) {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return ConsistencyToken{}, err
+	}
+	return ConsistencyToken{GlobalVersion: Version(v)}, nil
+}
+
+// Reached returns true if the projection has handled the events up to the token
+func (p *Projection) Reached(token ConsistencyToken) bool {
+	return p.Position() >= core.Version(token.GlobalVersion)
+}
+
+// WaitFor blocks until the projection has handled the events up to the token or the context is done. The projection
+// position is checked with the poll interval. Use a context with a timeout to warn or serve stale data instead of
+// blocking the query for long.
+func (p *Projection) WaitFor(ctx context.Context, token ConsistencyToken, poll time.Duration) error {
+	for !p.Reached(token) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(poll):
+		}
+	}
+	return nil
+}
+
+// HeadToken returns a token at the head of the event store. Waiting for it lets a query read all writes saved before,
+// also the writes of other clients. The projection has to handle the whole global event feed, the position of a
+// projection on a filtered feed may never reach the head.
+func HeadToken(ctx context.Context, es core.GlobalVersionReader) (ConsistencyToken, error) {
+	head, err := es.GlobalVersion(ctx)
+	if err != nil {
+		return ConsistencyToken{}, err
+	}
+	return ConsistencyToken{GlobalVersion: Version(head)}, nil
+}
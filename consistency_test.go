@@ -0,0 +1,98 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestConsistencyToken(t *testing.T) {
+	token := eventsourcing.NewConsistencyToken(3, 7, 5)
+	if token.GlobalVersion != 7 {
+		t.Fatalf("expected global version 7 was %d", token.GlobalVersion)
+	}
+	token = token.Merge(eventsourcing.NewConsistencyToken(9))
+	if token.GlobalVersion != 9 {
+		t.Fatalf("expected global version 9 was %d", token.GlobalVersion)
+	}
+
+	parsed, err := eventsourcing.ParseConsistencyToken(token.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != token {
+		t.Fatalf("expected token %v was %v", token, parsed)
+	}
+	_, err = eventsourcing.ParseConsistencyToken("not a token")
+	if err == nil {
+		t.Fatal("expected error parsing invalid token")
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	proj := eventsourcing.NewProjection(es.All(0, 1), func(event eventsourcing.Event) error {
+		return nil
+	})
+	group := eventsourcing.NewProjectionGroup(proj)
+	group.Pace = time.Millisecond * 10
+	group.Start()
+	defer group.Stop()
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := eventsourcing.NewConsistencyToken(person.GlobalVersion())
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err = proj.WaitFor(ctx, token, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proj.Reached(token) {
+		t.Fatal("expected the projection to have reached the token")
+	}
+
+	// a token ahead of the event stream is never reached
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	err = proj.WaitFor(ctx, eventsourcing.NewConsistencyToken(100), time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded was %v", err)
+	}
+}
+
+func TestHeadToken(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := eventsourcing.HeadToken(context.Background(), es)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.GlobalVersion != person.GlobalVersion() {
+		t.Fatalf("expected the token at global version %d was %d", person.GlobalVersion(), token.GlobalVersion)
+	}
+}
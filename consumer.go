@@ -0,0 +1,139 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// CompetingConsumer shares a named subscription with other consumers, possibly running in other processes. The
+// events are distributed among the consumers in leased ranges of the global event stream and a range is acknowledged
+// when all its events are handled. Ranges not acknowledged before the lease expires, e.g. when a consumer crashed, are
+// handed out to another consumer. The delivery is at-least-once and the order is not guaranteed, making it suitable
+// for side effects like sending emails or calling webhooks.
+type CompetingConsumer struct {
+	Subscription string
+	Name         string
+	TTL          time.Duration // TTL is how long the consumer holds a lease before it can be handed out to another consumer
+	Strict       bool          // Strict indicate if the consumer should return error if the event it fetches is not found in the register
+	leases       core.LeaseStore
+	fetchF       fetchFromFunc
+	callbackF    callbackContextFunc
+}
+
+// NewCompetingConsumer creates a consumer of the subscription. The name has to be unique among the consumers sharing
+// the subscription. The fetch function gets the global version of the first event in the lease.
+func NewCompetingConsumer(subscription, name string, ls core.LeaseStore, fetchF fetchFromFunc, callbackF callbackContextFunc) *CompetingConsumer {
+	return &CompetingConsumer{
+		Subscription: subscription,
+		Name:         name,
+		TTL:          time.Minute, // Default one minute
+		Strict:       true,        // Default strict is active
+		leases:       ls,
+		fetchF:       fetchF,
+		callbackF:    callbackF,
+	}
+}
+
+// Run acquires leases and handle their events until the context is cancelled or the callback returns an error.
+// When there is no lease to acquire it waits the pace before trying again. A lost lease is not an error, the consumer
+// acquires a new lease.
+func (c *CompetingConsumer) Run(ctx context.Context, pace time.Duration) error {
+	for {
+		ran, err := c.RunOnce(ctx)
+		if err != nil {
+			return err
+		}
+		if ran {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pace):
+		}
+	}
+}
+
+// RunOnce acquires one lease and handle its events. It returns true if there were events in the lease.
+// If the callback returns an error the lease is not acknowledged and will be handed out again when it expires.
+// If the lease expired and was handed out to another consumer the events fetched are dropped before they are handled
+// and nil is returned, when it's lost after the events were handled they are delivered again by the other consumer.
+func (c *CompetingConsumer) RunOnce(ctx context.Context) (bool, error) {
+	lease, err := c.leases.Acquire(ctx, c.Subscription, c.Name, c.TTL)
+	if errors.Is(err, core.ErrNoLease) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	events, last, err := c.fetch(lease)
+	if err != nil {
+		return false, err
+	}
+	if lease.To == 0 {
+		// seal the open lease to let other consumers fetch the events after it
+		lease, err = c.leases.Seal(ctx, lease, last)
+		if errors.Is(err, core.ErrLeaseLost) {
+			// another consumer holds the lease, drop the events
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if last < lease.From {
+			// no events to handle
+			return false, nil
+		}
+	}
+
+	for _, event := range events {
+		err = c.callbackF(ctx, event)
+		if err != nil {
+			return false, err
+		}
+	}
+	err = c.leases.Ack(ctx, lease)
+	if errors.Is(err, core.ErrLeaseLost) {
+		// the events are handled again by the consumer holding the lease
+		return true, nil
+	}
+	return true, err
+}
+
+// fetch returns the events in the lease and the global version of the last fetched event
+func (c *CompetingConsumer) fetch(lease core.Lease) ([]Event, core.Version, error) {
+	coreIterator, err := c.fetchF(lease.From)
+	if err != nil {
+		return nil, 0, err
+	}
+	iterator := &Iterator{
+		CoreIterator: coreIterator,
+	}
+	defer iterator.Close()
+
+	var events []Event
+	last := lease.From - 1
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil && !errors.Is(err, ErrEventNotRegistered) {
+			return nil, 0, err
+		}
+		if lease.To != 0 && core.Version(event.GlobalVersion()) > lease.To {
+			break
+		}
+		last = core.Version(event.GlobalVersion())
+		if err != nil {
+			if c.Strict {
+				return nil, 0, fmt.Errorf("event not registered aggregate type: %s, reason: %s, global version: %d, %w", event.AggregateType(), event.Reason(), event.GlobalVersion(), ErrEventNotRegistered)
+			}
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, last, nil
+}
@@ -0,0 +1,186 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	ls "github.com/hallgren/eventsourcing/leasestore/memory"
+)
+
+func TestCompetingConsumers(t *testing.T) {
+	// setup
+	es := memory.Create()
+	leases := ls.Create()
+	aggregate.Register(&Person{})
+
+	// Born + 19 AgedOneYear
+	err := createPersonEvent(es, "kalle", 19)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lock sync.Mutex
+	handled := make(map[eventsourcing.Version]int)
+	callbackF := func(ctx context.Context, event eventsourcing.Event) error {
+		time.Sleep(time.Millisecond)
+		lock.Lock()
+		handled[event.GlobalVersion()]++
+		lock.Unlock()
+		return nil
+	}
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 3)()
+	}
+
+	c1 := eventsourcing.NewCompetingConsumer("emails", "c1", leases, fetchF, callbackF)
+	c2 := eventsourcing.NewCompetingConsumer("emails", "c2", leases, fetchF, callbackF)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*300)
+	defer cancel()
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	for _, c := range []*eventsourcing.CompetingConsumer{c1, c2} {
+		go func(c *eventsourcing.CompetingConsumer) {
+			defer wg.Done()
+			c.Run(ctx, time.Millisecond)
+		}(c)
+	}
+	wg.Wait()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(handled) != 20 {
+		t.Fatalf("expected 20 handled events was %d", len(handled))
+	}
+	for globalVersion, count := range handled {
+		if count != 1 {
+			t.Fatalf("expected event %d to be handled once was %d", globalVersion, count)
+		}
+	}
+}
+
+func TestCompetingConsumerRetryExpiredLease(t *testing.T) {
+	// setup
+	es := memory.Create()
+	leases := ls.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 10)()
+	}
+
+	failing := eventsourcing.NewCompetingConsumer("emails", "c1", leases, fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+		return errors.New("smtp down")
+	})
+	failing.TTL = time.Millisecond * 10
+
+	_, err = failing.RunOnce(context.Background())
+	if err == nil {
+		t.Fatal("expected error from the callback")
+	}
+
+	counter := 0
+	healthy := eventsourcing.NewCompetingConsumer("emails", "c2", leases, fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+		counter++
+		return nil
+	})
+	// the lease is held by the failing consumer until it expires
+	ran, err := healthy.RunOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ran {
+		t.Fatal("expected no events before the lease has expired")
+	}
+
+	time.Sleep(time.Millisecond * 20)
+	ran, err = healthy.RunOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ran || counter != 3 {
+		t.Fatalf("expected the expired lease with 3 events to be handled was %d", counter)
+	}
+}
+
+func TestCompetingConsumerLeaseLost(t *testing.T) {
+	// setup
+	es := memory.Create()
+	leases := ls.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the lease expires while the events are fetched and is taken by another consumer
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		time.Sleep(time.Millisecond * 20)
+		_, err := leases.Acquire(context.Background(), "emails", "c2", time.Minute)
+		if err != nil {
+			return nil, err
+		}
+		return es.All(start, 10)()
+	}
+	handled := 0
+	c := eventsourcing.NewCompetingConsumer("emails", "c1", leases, fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+		handled++
+		return nil
+	})
+	c.TTL = time.Millisecond * 10
+
+	ran, err := c.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("expected the lost lease to not be an error was %v", err)
+	}
+	if ran || handled != 0 {
+		t.Fatalf("expected the events in the lost lease to be dropped was %d handled", handled)
+	}
+}
+
+func TestCompetingConsumerLeaseLostBeforeAck(t *testing.T) {
+	// setup
+	es := memory.Create()
+	leases := ls.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 10)()
+	}
+	// the lease expires while the events are handled and is taken by another consumer
+	handled := 0
+	c := eventsourcing.NewCompetingConsumer("emails", "c1", leases, fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+		handled++
+		if handled == 1 {
+			time.Sleep(time.Millisecond * 20)
+			_, err := leases.Acquire(ctx, "emails", "c2", time.Minute)
+			return err
+		}
+		return nil
+	})
+	c.TTL = time.Millisecond * 10
+
+	ran, err := c.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("expected the lost lease to not be an error was %v", err)
+	}
+	if !ran || handled != 3 {
+		t.Fatalf("expected 3 handled events was %d", handled)
+	}
+}
@@ -0,0 +1,27 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrAnnotationExists returned when the event already has an annotation with the same name
+var ErrAnnotationExists = errors.New("annotation already exists")
+
+// Annotation is an immutable note attached to an event after the fact, e.g. "flagged by fraud review". It's stored
+// separate from the event as the event itself must never be changed.
+type Annotation struct {
+	GlobalVersion Version // global version of the annotated event
+	Name          string
+	Value         string
+	Timestamp     time.Time
+}
+
+// AnnotationStore expose the methods an annotation store must uphold. Save returns ErrAnnotationExists if the event
+// already has an annotation with the same name, annotations are never overwritten. Get returns the annotations of an
+// event in the order they were saved.
+type AnnotationStore interface {
+	Save(annotation Annotation) error
+	Get(ctx context.Context, globalVersion Version) ([]Annotation, error)
+}
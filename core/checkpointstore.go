@@ -0,0 +1,28 @@
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCheckpointNotFound returned when no checkpoint is found in the checkpoint store
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// Checkpoint holds the position of a projection in the global event stream
+type Checkpoint struct {
+	Name              string  `json:"name"`               // name of the projection
+	ProjectionVersion int     `json:"projection_version"` // version of the projection logic
+	GlobalVersion     Version `json:"global_version"`     // global version of the last handled event
+}
+
+// CheckpointStore expose the methods a checkpoint store must uphold
+type CheckpointStore interface {
+	Save(checkpoint Checkpoint) error
+	Get(ctx context.Context, name string) (Checkpoint, error)
+}
+
+// CheckpointLister is implemented by checkpoint stores that can list all their checkpoints
+type CheckpointLister interface {
+	// All returns all checkpoints in the checkpoint store
+	All(ctx context.Context) ([]Checkpoint, error)
+}
@@ -17,4 +17,8 @@ type Event struct {
 	Reason        string // based on the Data type
 	Data          []byte // interface{} on the external Event type
 	Metadata      []byte // map[string]interface{} on the external Event type
+	SchemaVersion uint   // version of the Data shape, zero when not versioned
+
+	CategoryVersion Version // position of the event in the category stream of its aggregate type, zero when the event store keeps no category streams
+	CorrelationID   string  // correlation id of the business transaction the event is part of, empty when not correlated
 }
@@ -3,15 +3,31 @@ package core
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 // ErrConcurrency when the currently saved version of the aggregate differs from the new ones
 var ErrConcurrency = errors.New("concurrency error")
 
-// Iterator is the interface an event store Get needs to return
+// ErrDeleteNotSupported returned when deleting events in an event store that don't implement EventDeleter
+var ErrDeleteNotSupported = errors.New("event store does not support deleting events")
+
+// ErrBatchNotSupported returned when saving events from several aggregates in an event store that don't implement
+// BatchSaver
+var ErrBatchNotSupported = errors.New("event store does not support batch save")
+
+// ErrNoEvents returned when reading the last event of an aggregate without events
+var ErrNoEvents = errors.New("aggregate has no events")
+
+// Iterator is the interface the event store reads return. Event stores stream the events from a cursor or rows, an
+// event is not read before Next is called, so replaying a large aggregate or the global event feed doesn't hold all
+// events in memory. The caller has to Close the iterator to release the cursor.
 type Iterator interface {
+	// Next moves to the next event, false when there are no more events
 	Next() bool
+	// Value returns the current event
 	Value() (Event, error)
+	// Close releases the resources held by the iterator
 	Close()
 }
 
@@ -20,3 +36,136 @@ type EventStore interface {
 	Save(events []Event) error
 	Get(ctx context.Context, id string, aggregateType string, afterVersion Version) (Iterator, error)
 }
+
+// ContextSaver is implemented by event stores that can bound the save with a context. The database calls are
+// canceled when the context is done.
+type ContextSaver interface {
+	SaveContext(ctx context.Context, events []Event) error
+}
+
+// SaveContext saves the events via SaveContext if the event store implements ContextSaver. Other event stores are
+// only called if the context is not already done.
+func SaveContext(ctx context.Context, es EventStore, events []Event) error {
+	if s, ok := es.(ContextSaver); ok {
+		return s.SaveContext(ctx, events)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return es.Save(events)
+}
+
+// EventDeleter is implemented by event stores that can remove events permanently
+type EventDeleter interface {
+	// DeleteEvents removes the aggregate events with a version lower than before
+	DeleteEvents(ctx context.Context, id string, aggregateType string, before Version) error
+}
+
+// Unwrapper is implemented by event stores wrapping another event store. The optional read interfaces, like
+// VersionReader, that the wrapper does not implement are looked up on the wrapped event store.
+type Unwrapper interface {
+	// Unwrap returns the wrapped event store
+	Unwrap() EventStore
+}
+
+// BatchSaver is implemented by event stores that can save the events of several aggregates atomically
+type BatchSaver interface {
+	// SaveBatch saves each batch of aggregate events, either all events are saved or none
+	SaveBatch(ctx context.Context, batches [][]Event) error
+}
+
+// VersionReader is implemented by event stores that can return the version of the aggregate without reading its events
+type VersionReader interface {
+	// LatestVersion returns the version of the last aggregate event, zero if the aggregate has no events
+	LatestVersion(ctx context.Context, id string, aggregateType string) (Version, error)
+}
+
+// GlobalVersionReader is implemented by event stores that can return the head of the global event feed without
+// reading it, e.g. to tell when a projection has caught up
+type GlobalVersionReader interface {
+	// GlobalVersion returns the global version of the last saved event, zero if no events are saved
+	GlobalVersion(ctx context.Context) (Version, error)
+}
+
+// Pager is implemented by event stores that can return the aggregate event stream in pages newest-first
+type Pager interface {
+	// GetPage returns a page of the aggregate events newest-first. The offset is the number of events to skip from
+	// the end of the stream and limit the max number of events in the page. The total number of events in the
+	// aggregate event stream is returned next to the iterator.
+	GetPage(ctx context.Context, id string, aggregateType string, offset, limit uint64) (Iterator, uint64, error)
+}
+
+// ReverseReader is implemented by event stores that can read events newest first, e.g. to show the last events of a
+// stream without reading it from the start
+type ReverseReader interface {
+	// GetDescending returns at most count aggregate events with a version lower than before newest first, a before of
+	// zero starts at the last event
+	GetDescending(ctx context.Context, id string, aggregateType string, before Version, count uint64) (Iterator, error)
+	// AllDescending returns at most count events with a global version lower than before newest first, a before of
+	// zero starts at the last saved event
+	AllDescending(ctx context.Context, before Version, count uint64) (Iterator, error)
+}
+
+// CategoryReader is implemented by event stores keeping a category stream per aggregate type, like the $ce- streams
+// of EventStoreDB. The position of an event in its category stream is its CategoryVersion, consumers of one aggregate
+// type keep a checkpoint on it independent of the events of other aggregate types.
+type CategoryReader interface {
+	// Category returns at most count events of the aggregate type with a category version after the position, in
+	// category order
+	Category(ctx context.Context, aggregateType string, after Version, count uint64) (Iterator, error)
+}
+
+// CorrelationReader is implemented by event stores indexing the CorrelationID of the events, to follow a business
+// transaction across aggregates
+type CorrelationReader interface {
+	// ByCorrelationID returns the events with the correlation id in GlobalEvents order
+	ByCorrelationID(ctx context.Context, correlationID string) (Iterator, error)
+}
+
+// TimeRangeReader is implemented by event stores that can read the events with a timestamp within a time range
+type TimeRangeReader interface {
+	// Between returns the events with a timestamp after from and before to in global order, a zero to has no upper
+	// bound
+	Between(ctx context.Context, from, to time.Time) (Iterator, error)
+}
+
+// LastEventReader is implemented by event stores that can read the newest event of an aggregate without reading the
+// aggregate event stream
+type LastEventReader interface {
+	// LastEvent returns the newest event of the aggregate, ErrNoEvents if the aggregate has no events
+	LastEvent(ctx context.Context, id string, aggregateType string) (Event, error)
+}
+
+// LastEvent returns the newest event of the aggregate via LastEvent if the event store implements LastEventReader.
+// Other event stores have the aggregate event stream read to its end. ErrNoEvents is returned if the aggregate has no
+// events.
+func LastEvent(ctx context.Context, es EventStore, id string, aggregateType string) (Event, error) {
+	for wrapped := es; wrapped != nil; {
+		if r, ok := wrapped.(LastEventReader); ok {
+			return r.LastEvent(ctx, id, aggregateType)
+		}
+		u, ok := wrapped.(Unwrapper)
+		if !ok {
+			break
+		}
+		wrapped = u.Unwrap()
+	}
+	iterator, err := es.Get(ctx, id, aggregateType, 0)
+	if err != nil {
+		return Event{}, err
+	}
+	defer iterator.Close()
+	var last Event
+	found := false
+	for iterator.Next() {
+		last, err = iterator.Value()
+		if err != nil {
+			return Event{}, err
+		}
+		found = true
+	}
+	if !found {
+		return Event{}, ErrNoEvents
+	}
+	return last, nil
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// Filter is a composable selection of events. Event stores translate the filter into native queries where possible
+// and evaluate the rest client-side. The zero value matches all events.
+//
+//	filter := core.ByType("Person").ByReason("Born", "Died").After(t).WithMetadata("tenant", "acme")
+type Filter struct {
+	AggregateTypes []string               // match events on any of the aggregate types
+	Reasons        []string               // match events on any of the reasons
+	From           time.Time              // match events with a timestamp after From
+	To             time.Time              // match events with a timestamp before To
+	Metadata       map[string]interface{} // match events having all the metadata key/values
+}
+
+// ByType returns a filter matching events on any of the aggregate types
+func ByType(aggregateTypes ...string) Filter {
+	return Filter{}.ByType(aggregateTypes...)
+}
+
+// ByReason returns a filter matching events on any of the reasons
+func ByReason(reasons ...string) Filter {
+	return Filter{}.ByReason(reasons...)
+}
+
+// ByType adds aggregate types to the filter
+func (f Filter) ByType(aggregateTypes ...string) Filter {
+	f.AggregateTypes = append(append([]string{}, f.AggregateTypes...), aggregateTypes...)
+	return f
+}
+
+// ByReason adds reasons to the filter
+func (f Filter) ByReason(reasons ...string) Filter {
+	f.Reasons = append(append([]string{}, f.Reasons...), reasons...)
+	return f
+}
+
+// After only match events with a timestamp after t
+func (f Filter) After(t time.Time) Filter {
+	f.From = t
+	return f
+}
+
+// Before only match events with a timestamp before t
+func (f Filter) Before(t time.Time) Filter {
+	f.To = t
+	return f
+}
+
+// WithMetadata only match events having the metadata key with the value. The values are compared on their string
+// representation as the type of the value could change when it's serialized.
+func (f Filter) WithMetadata(key string, value interface{}) Filter {
+	metadata := make(map[string]interface{}, len(f.Metadata)+1)
+	for k, v := range f.Metadata {
+		metadata[k] = v
+	}
+	metadata[key] = value
+	f.Metadata = metadata
+	return f
+}
+
+// IsZero returns true if the filter matches all events
+func (f Filter) IsZero() bool {
+	return len(f.AggregateTypes) == 0 && len(f.Reasons) == 0 && f.From.IsZero() && f.To.IsZero() && len(f.Metadata) == 0
+}
+
+// Match returns true if the event matches the aggregate types, reasons and time of the filter. The metadata is not
+// matched as it's serialized on the event, use MatchMetadata on the deserialized metadata.
+func (f Filter) Match(event Event) bool {
+	if len(f.AggregateTypes) > 0 && !contains(f.AggregateTypes, event.AggregateType) {
+		return false
+	}
+	if len(f.Reasons) > 0 && !contains(f.Reasons, event.Reason) {
+		return false
+	}
+	if !f.From.IsZero() && !event.Timestamp.After(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !event.Timestamp.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// MatchMetadata returns true if the metadata holds all the metadata key/values of the filter
+func (f Filter) MatchMetadata(metadata map[string]interface{}) bool {
+	for k, v := range f.Metadata {
+		value, ok := metadata[k]
+		if !ok || fmt.Sprint(value) != fmt.Sprint(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+func TestFilterMatch(t *testing.T) {
+	now := time.Now()
+	event := core.Event{AggregateType: "Person", Reason: "Born", Timestamp: now}
+
+	tests := []struct {
+		title  string
+		filter core.Filter
+		match  bool
+	}{
+		{"zero value", core.Filter{}, true},
+		{"aggregate type", core.ByType("Person"), true},
+		{"other aggregate type", core.ByType("Order"), false},
+		{"any of the aggregate types", core.ByType("Order", "Person"), true},
+		{"reason", core.ByType("Person").ByReason("Born"), true},
+		{"other reason", core.ByReason("Died"), false},
+		{"after", core.ByReason("Born").After(now.Add(-time.Second)), true},
+		{"not after", core.Filter{}.After(now), false},
+		{"before", core.Filter{}.Before(now.Add(time.Second)), true},
+		{"not before", core.Filter{}.Before(now), false},
+		{"between", core.Filter{}.After(now.Add(-time.Second)).Before(now.Add(time.Second)), true},
+	}
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			if test.filter.Match(event) != test.match {
+				t.Fatalf("expected match to be %t", test.match)
+			}
+		})
+	}
+}
+
+func TestFilterMatchMetadata(t *testing.T) {
+	metadata := map[string]interface{}{"tenant": "acme", "count": float64(1)}
+
+	if !core.ByType("Person").WithMetadata("tenant", "acme").WithMetadata("count", 1).MatchMetadata(metadata) {
+		t.Fatal("expected metadata to match")
+	}
+	if (core.Filter{}).WithMetadata("tenant", "other").MatchMetadata(metadata) {
+		t.Fatal("expected metadata not to match")
+	}
+	if (core.Filter{}).WithMetadata("missing", "acme").MatchMetadata(metadata) {
+		t.Fatal("expected missing metadata not to match")
+	}
+}
+
+func TestFilterIsZero(t *testing.T) {
+	if !(core.Filter{}).IsZero() {
+		t.Fatal("expected zero value to be zero")
+	}
+	if core.ByReason("Born").IsZero() {
+		t.Fatal("expected filter with reason not to be zero")
+	}
+}
+
+func TestFilterIsImmutable(t *testing.T) {
+	base := core.ByType("Person")
+	person := base.WithMetadata("tenant", "acme")
+	order := base.ByType("Order").WithMetadata("tenant", "other")
+
+	if len(base.AggregateTypes) != 1 || base.Metadata != nil {
+		t.Fatal("expected base filter to be unchanged")
+	}
+	if person.Metadata["tenant"] != "acme" {
+		t.Fatal("expected person filter to be unchanged")
+	}
+	if len(order.AggregateTypes) != 2 {
+		t.Fatal("expected order filter to hold two aggregate types")
+	}
+}
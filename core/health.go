@@ -0,0 +1,18 @@
+package core
+
+import "context"
+
+// Health is the result of a health check. It's ready to be encoded as the JSON body of a readiness or liveness probe.
+type Health struct {
+	Name    string            `json:"name"`              // Name of the checked component
+	Healthy bool              `json:"healthy"`           // Healthy is false if the component or any of its checks is unhealthy
+	Error   string            `json:"error,omitempty"`   // Error is the reason the component is unhealthy
+	Details map[string]string `json:"details,omitempty"` // Details like the head of the event store
+	Checks  []Health          `json:"checks,omitempty"`  // Checks are the health of the parts of the component
+}
+
+// HealthChecker is implemented by event stores that can check their connection and that the global event stream is
+// readable
+type HealthChecker interface {
+	Health(ctx context.Context) Health
+}
@@ -0,0 +1,37 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNoLease returned when there is no lease available for the consumer
+var ErrNoLease = errors.New("no lease available")
+
+// ErrLeaseLost returned when the lease has expired and is acquired by another consumer
+var ErrLeaseLost = errors.New("lease lost")
+
+// Lease is a range of global versions in a subscription reserved by a consumer. An open lease has no end (To is zero)
+// and is sealed by the consumer when it knows the last global version it fetched.
+type Lease struct {
+	Subscription string
+	Consumer     string
+	From         Version
+	To           Version
+	Expires      time.Time
+}
+
+// LeaseStore distributes ranges of the global event stream among competing consumers sharing a subscription.
+// Only one open lease can exist per subscription, making the fetch of new events serial while the handling of the
+// leased ranges is done in parallel. Leases not acknowledged before they expire are handed out again.
+type LeaseStore interface {
+	// Acquire returns an expired lease assigned to the consumer or an open lease starting after the last sealed lease.
+	// ErrNoLease is returned if another consumer holds the open lease.
+	Acquire(ctx context.Context, subscription, consumer string, ttl time.Duration) (Lease, error)
+	// Seal sets the end of an open lease making the next range available to other consumers. If to is before the start
+	// of the lease it's released without a range.
+	Seal(ctx context.Context, lease Lease, to Version) (Lease, error)
+	// Ack marks the lease as handled
+	Ack(ctx context.Context, lease Lease) error
+}
@@ -0,0 +1,11 @@
+package core
+
+import "context"
+
+// LockStore hands out exclusive locks on aggregates. It's used to serialize the load and save of aggregates with high
+// contention instead of retrying on ErrConcurrency.
+type LockStore interface {
+	// Lock blocks until the lock on the aggregate is acquired or the context is done. The returned func releases the
+	// lock.
+	Lock(ctx context.Context, id, aggregateType string) (unlock func() error, err error)
+}
@@ -0,0 +1,12 @@
+package core
+
+import "context"
+
+// Outbox holds the saved events that are not yet published to an external system. The events are written to the
+// outbox in the same transaction as they are saved, making sure no event is lost between the save and the publish.
+type Outbox interface {
+	// Pending returns at most count unpublished events in global version order
+	Pending(ctx context.Context, count uint64) (Iterator, error)
+	// Published removes the event with the global version from the outbox
+	Published(ctx context.Context, globalVersion Version) error
+}
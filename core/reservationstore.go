@@ -0,0 +1,30 @@
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrReserved returned when the value is already reserved by another aggregate
+var ErrReserved = errors.New("value already reserved")
+
+// ErrReservationNotFound returned when no reservation is found in the reservation store
+var ErrReservationNotFound = errors.New("reservation not found")
+
+// Reservation claims a unique value within a scope for an aggregate, e.g. an email address among users
+type Reservation struct {
+	Scope       string
+	Value       string
+	AggregateID string
+}
+
+// ReservationStore expose the methods a reservation store must uphold. Reserve has to be atomic and return
+// ErrReserved if the value is reserved by another aggregate, reserving a value already owned by the aggregate is not
+// an error. Release is a no-op if the value is not reserved by the aggregate. ReleaseAggregate releases all values
+// reserved by the aggregate.
+type ReservationStore interface {
+	Reserve(ctx context.Context, scope, value, aggregateID string) error
+	Release(ctx context.Context, scope, value, aggregateID string) error
+	ReleaseAggregate(ctx context.Context, aggregateID string) error
+	Get(ctx context.Context, scope, value string) (Reservation, error)
+}
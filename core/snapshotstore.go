@@ -3,11 +3,15 @@ package core
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 // ErrSnapshotNotFound returned when no snapshot is found in the snapshot store
 var ErrSnapshotNotFound = errors.New("snapshot not found")
 
+// ErrPruneNotSupported returned when pruning a snapshot store that don't implement SnapshotPruner
+var ErrPruneNotSupported = errors.New("snapshot store does not support pruning")
+
 // Snapshot holds current state of an aggregate
 type Snapshot struct {
 	ID            string
@@ -15,6 +19,10 @@ type Snapshot struct {
 	Version       Version
 	GlobalVersion Version
 	State         []byte
+	// SchemaVersion is the version of the State shape, used to detect snapshots taken before the aggregate changed
+	SchemaVersion uint
+	// Timestamp is when the snapshot was taken
+	Timestamp time.Time
 }
 
 // SnapshotStore expose the methods a snapshot store must uphold
@@ -22,3 +30,10 @@ type SnapshotStore interface {
 	Save(snapshot Snapshot) error
 	Get(ctx context.Context, id, aggregateType string) (Snapshot, error)
 }
+
+// SnapshotPruner is implemented by snapshot stores that can delete old snapshots. The stores keep only the latest
+// snapshot per aggregate, pruning removes the snapshots of aggregates not snapshotted since a point in time.
+type SnapshotPruner interface {
+	// Prune deletes the snapshots taken before t and returns the number of deleted snapshots
+	Prune(ctx context.Context, before time.Time) (int, error)
+}
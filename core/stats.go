@@ -0,0 +1,27 @@
+package core
+
+import "context"
+
+// Stats describes the content of an event store for dashboards and capacity planning
+type Stats struct {
+	Events         uint64            // Events is the total number of events
+	Head           Version           // Head is the global version of the last saved event
+	AggregateTypes map[string]uint64 // AggregateTypes is the number of events per aggregate type
+	Size           int64             // Size is the storage size in bytes, -1 when the event store can't tell
+}
+
+// StatsReader is implemented by event stores that can summarize their content
+type StatsReader interface {
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// EventCounter is implemented by event stores that can count events without reading them, e.g. for the progress of a
+// projection rebuild
+type EventCounter interface {
+	// CountAll returns the number of events in the event store
+	CountAll(ctx context.Context) (uint64, error)
+	// CountAggregate returns the number of events of the aggregate
+	CountAggregate(ctx context.Context, id string, aggregateType string) (uint64, error)
+	// CountByReason returns the number of events with the reason
+	CountByReason(ctx context.Context, reason string) (uint64, error)
+}
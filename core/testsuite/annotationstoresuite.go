@@ -0,0 +1,98 @@
+package testsuite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type annotationstoreFunc = func() (core.AnnotationStore, func(), error)
+
+func TestAnnotationStore(t *testing.T, asFunc annotationstoreFunc) {
+	tests := []struct {
+		title string
+		run   func(as core.AnnotationStore) error
+	}{
+		{"should save and get annotations", saveAndGetAnnotations},
+		{"should not overwrite existing annotation", overwriteAnnotation},
+		{"should get no annotations on event without annotations", getNoAnnotations},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			as, closeFunc, err := asFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(as)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+func saveAndGetAnnotations(as core.AnnotationStore) error {
+	timestamp := time.Now().UTC().Truncate(time.Second)
+	annotations := []core.Annotation{
+		{GlobalVersion: 3, Name: "fraud_review", Value: "flagged", Timestamp: timestamp},
+		{GlobalVersion: 3, Name: "invoice", Value: "123", Timestamp: timestamp},
+		{GlobalVersion: 4, Name: "invoice", Value: "123", Timestamp: timestamp},
+	}
+	for _, a := range annotations {
+		err := as.Save(a)
+		if err != nil {
+			return err
+		}
+	}
+
+	got, err := as.Get(context.Background(), 3)
+	if err != nil {
+		return err
+	}
+	if len(got) != 2 {
+		return fmt.Errorf("expected 2 annotations got %d", len(got))
+	}
+	for i := range got {
+		if got[i].GlobalVersion != annotations[i].GlobalVersion || got[i].Name != annotations[i].Name || got[i].Value != annotations[i].Value || !got[i].Timestamp.Equal(annotations[i].Timestamp) {
+			return fmt.Errorf("expected annotation %v got %v", annotations[i], got[i])
+		}
+	}
+	return nil
+}
+
+func overwriteAnnotation(as core.AnnotationStore) error {
+	err := as.Save(core.Annotation{GlobalVersion: 1, Name: "fraud_review", Value: "flagged", Timestamp: time.Now()})
+	if err != nil {
+		return err
+	}
+	err = as.Save(core.Annotation{GlobalVersion: 1, Name: "fraud_review", Value: "cleared", Timestamp: time.Now()})
+	if !errors.Is(err, core.ErrAnnotationExists) {
+		return fmt.Errorf("expected annotation exists error got %v", err)
+	}
+	got, err := as.Get(context.Background(), 1)
+	if err != nil {
+		return err
+	}
+	if len(got) != 1 || got[0].Value != "flagged" {
+		return fmt.Errorf("expected the first annotation to be kept got %v", got)
+	}
+	return nil
+}
+
+func getNoAnnotations(as core.AnnotationStore) error {
+	got, err := as.Get(context.Background(), 10)
+	if err != nil {
+		return err
+	}
+	if len(got) != 0 {
+		return fmt.Errorf("expected no annotations got %d", len(got))
+	}
+	return nil
+}
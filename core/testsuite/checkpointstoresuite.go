@@ -0,0 +1,88 @@
+package testsuite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type checkpointstoreFunc = func() (core.CheckpointStore, func(), error)
+
+func TestCheckpointStore(t *testing.T, csFunc checkpointstoreFunc) {
+	tests := []struct {
+		title string
+		run   func(cs core.CheckpointStore) error
+	}{
+		{"should save and get checkpoint", saveAndGetCheckpoint},
+		{"should overwrite existing checkpoint", overwriteCheckpoint},
+		{"should get error when getting none existing checkpoint", getNoneExistingCheckpoint},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			cs, closeFunc, err := csFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(cs)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+func saveAndGetCheckpoint(cs core.CheckpointStore) error {
+	checkpoint := core.Checkpoint{
+		Name:              "projection",
+		ProjectionVersion: 2,
+		GlobalVersion:     10,
+	}
+
+	err := cs.Save(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	c, err := cs.Get(context.Background(), "projection")
+	if err != nil {
+		return err
+	}
+	if c != checkpoint {
+		return fmt.Errorf("expected checkpoint %v got %v", checkpoint, c)
+	}
+	return nil
+}
+
+func overwriteCheckpoint(cs core.CheckpointStore) error {
+	err := cs.Save(core.Checkpoint{Name: "projection", ProjectionVersion: 1, GlobalVersion: 10})
+	if err != nil {
+		return err
+	}
+	err = cs.Save(core.Checkpoint{Name: "projection", ProjectionVersion: 1, GlobalVersion: 20})
+	if err != nil {
+		return err
+	}
+
+	c, err := cs.Get(context.Background(), "projection")
+	if err != nil {
+		return err
+	}
+	if c.GlobalVersion != 20 {
+		return fmt.Errorf("expected global version 20 got %d", c.GlobalVersion)
+	}
+	return nil
+}
+
+func getNoneExistingCheckpoint(cs core.CheckpointStore) error {
+	_, err := cs.Get(context.Background(), "none_existing")
+	if !errors.Is(err, core.ErrCheckpointNotFound) {
+		return errors.New("expect checkpoint not found error")
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"strconv"
 	"sync"
 	"testing"
 	"time"
@@ -59,7 +60,7 @@ func testEvents(aggregateID string) []core.Event {
 	metadata["test"] = "hello"
 	history := []core.Event{
 		{AggregateID: aggregateID, Version: 1, AggregateType: aggregateType, Timestamp: timestamp, Reason: "FrequentFlierAccountCreated", Data: eventToByte(&FrequentFlierAccountCreated{AccountId: "1234567", OpeningMiles: 10000, OpeningTierPoints: 0}), Metadata: eventToByte(metadata)},
-		{AggregateID: aggregateID, Version: 2, AggregateType: aggregateType, Timestamp: timestamp, Reason: "StatusMatched", Data: eventToByte(&StatusMatched{NewStatus: StatusSilver}), Metadata: eventToByte(metadata)},
+		{AggregateID: aggregateID, Version: 2, AggregateType: aggregateType, Timestamp: timestamp, Reason: "StatusMatched", Data: eventToByte(&StatusMatched{NewStatus: StatusSilver}), Metadata: eventToByte(metadata), SchemaVersion: 2},
 		{AggregateID: aggregateID, Version: 3, AggregateType: aggregateType, Timestamp: timestamp, Reason: "FlightTaken", Data: eventToByte(&FlightTaken{MilesAdded: 2525, TierPointsAdded: 5}), Metadata: eventToByte(metadata)},
 		{AggregateID: aggregateID, Version: 4, AggregateType: aggregateType, Timestamp: timestamp, Reason: "FlightTaken", Data: eventToByte(&FlightTaken{MilesAdded: 2512, TierPointsAdded: 5}), Metadata: eventToByte(metadata)},
 		{AggregateID: aggregateID, Version: 5, AggregateType: aggregateType, Timestamp: timestamp, Reason: "FlightTaken", Data: eventToByte(&FlightTaken{MilesAdded: 5600, TierPointsAdded: 5}), Metadata: eventToByte(metadata)},
@@ -137,6 +138,12 @@ func saveAndGetEvents(es core.EventStore) error {
 		return errors.New("wrong events returned")
 	}
 
+	for i, event := range testEvents(aggregateID) {
+		if fetchedEvents[i].SchemaVersion != event.SchemaVersion {
+			return fmt.Errorf("exp schema version %d got %d", event.SchemaVersion, fetchedEvents[i].SchemaVersion)
+		}
+	}
+
 	// Add more events to the same aggregate event stream
 	err = es.Save(testEventsPartTwo(aggregateID))
 	if err != nil {
@@ -330,3 +337,728 @@ func setGlobalVersionOnSavedEvents(es eventsourcing.EventStore) error {
 	return nil
 }
 */
+
+type eventdeleterFunc = func() (core.EventStore, core.EventDeleter, func(), error)
+
+// TestEventDeleter runs the tests for event stores implementing core.EventDeleter
+func TestEventDeleter(t *testing.T, f eventdeleterFunc) {
+	es, deleter, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	aggregateID := AggregateID()
+	otherID := AggregateID()
+	err = es.Save(testEvents(aggregateID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{testEventOtherAggregate(otherID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = deleter.DeleteEvents(context.Background(), aggregateID, aggregateType, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err := es.Get(context.Background(), aggregateID, aggregateType, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []core.Version
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, event.Version)
+	}
+	iterator.Close()
+	if len(versions) != 2 || versions[0] != 5 || versions[1] != 6 {
+		t.Fatalf("expected the events with version 5 and 6 to be kept got %v", versions)
+	}
+
+	// the stream continues after the kept events
+	err = es.Save(testEventsPartTwo(aggregateID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// events from other aggregates are kept
+	iterator, err = es.Get(context.Background(), otherID, aggregateType, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected the other aggregate event to be kept")
+	}
+}
+
+type batchsaverFunc = func() (core.EventStore, core.BatchSaver, func(), error)
+
+// TestBatchSaver runs the tests for event stores implementing core.BatchSaver
+func TestBatchSaver(t *testing.T, f batchsaverFunc) {
+	es, saver, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	aggregateID := AggregateID()
+	otherID := AggregateID()
+	// the batches can hold events from the same aggregate
+	err = saver.SaveBatch(context.Background(), [][]core.Event{
+		testEvents(aggregateID),
+		{testEventOtherAggregate(otherID)},
+		testEventsPartTwo(aggregateID),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := countEvents(t, es, aggregateID); count != 8 {
+		t.Fatalf("expected 8 events got %d", count)
+	}
+
+	// the other aggregate is in the wrong version, no events are saved
+	failID := AggregateID()
+	err = saver.SaveBatch(context.Background(), [][]core.Event{
+		testEvents(failID),
+		{testEventOtherAggregate(otherID)},
+	})
+	if !errors.Is(err, core.ErrConcurrency) {
+		t.Fatalf("expected concurrency error got %v", err)
+	}
+	if count := countEvents(t, es, failID); count != 0 {
+		t.Fatalf("expected no events to be saved got %d", count)
+	}
+}
+
+func countEvents(t *testing.T, es core.EventStore, aggregateID string) int {
+	iterator, err := es.Get(context.Background(), aggregateID, aggregateType, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	count := 0
+	for iterator.Next() {
+		count++
+	}
+	return count
+}
+
+type versionreaderFunc = func() (core.EventStore, core.VersionReader, func(), error)
+
+// TestVersionReader runs the tests for event stores implementing core.VersionReader
+func TestVersionReader(t *testing.T, f versionreaderFunc) {
+	es, reader, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	aggregateID := AggregateID()
+	version, err := reader.LatestVersion(context.Background(), aggregateID, aggregateType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 {
+		t.Fatalf("expected version 0 of aggregate without events got %d", version)
+	}
+
+	err = es.Save(testEvents(aggregateID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err = reader.LatestVersion(context.Background(), aggregateID, aggregateType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 6 {
+		t.Fatalf("expected version 6 got %d", version)
+	}
+}
+
+type healthcheckerFunc = func() (core.EventStore, core.HealthChecker, func(), error)
+
+// TestHealthChecker tests event stores implementing core.HealthChecker
+func TestHealthChecker(t *testing.T, f healthcheckerFunc) {
+	es, checker, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	head := func() uint64 {
+		health := checker.Health(context.Background())
+		if !health.Healthy {
+			t.Fatalf("expected healthy event store got %+v", health)
+		}
+		h, err := strconv.ParseUint(health.Details["head"], 10, 64)
+		if err != nil {
+			t.Fatalf("expected the head in the details got %+v", health)
+		}
+		return h
+	}
+	before := head()
+	err = es.Save(testEvents(AggregateID()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after := head(); after != before+6 {
+		t.Fatalf("expected the head to move from %d to %d got %d", before, before+6, after)
+	}
+}
+
+type statsreaderFunc = func() (core.EventStore, core.StatsReader, func(), error)
+
+// TestStatsReader tests event stores implementing core.StatsReader
+func TestStatsReader(t *testing.T, f statsreaderFunc) {
+	es, reader, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	before, err := reader.Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save(testEvents(AggregateID()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	passenger := core.Event{AggregateID: AggregateID(), Version: 1, AggregateType: "Passenger", Timestamp: timestamp, Reason: "Registered", Data: []byte("{}")}
+	err = es.Save([]core.Event{passenger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := reader.Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Events != before.Events+7 {
+		t.Fatalf("expected %d events got %d", before.Events+7, after.Events)
+	}
+	if after.Head != before.Head+7 {
+		t.Fatalf("expected head %d got %d", before.Head+7, after.Head)
+	}
+	if after.AggregateTypes[aggregateType] != before.AggregateTypes[aggregateType]+6 {
+		t.Fatalf("expected %d events on %s got %d", before.AggregateTypes[aggregateType]+6, aggregateType, after.AggregateTypes[aggregateType])
+	}
+	if after.AggregateTypes["Passenger"] != before.AggregateTypes["Passenger"]+1 {
+		t.Fatalf("expected %d events on Passenger got %d", before.AggregateTypes["Passenger"]+1, after.AggregateTypes["Passenger"])
+	}
+}
+
+type pagerFunc = func() (core.EventStore, core.Pager, func(), error)
+
+// TestPager runs the tests for event stores implementing core.Pager
+func TestPager(t *testing.T, f pagerFunc) {
+	es, pager, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	aggregateID := AggregateID()
+	err = es.Save(testEvents(aggregateID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{testEventOtherAggregate(AggregateID())})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, total, err := pager.GetPage(context.Background(), aggregateID, aggregateType, 2, 3)
+	if total != 6 {
+		t.Fatalf("expected total 6 got %d", total)
+	}
+	versions, err := readVersions(iterator, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(versions) != "[4 3 2]" {
+		t.Fatalf("expected the versions [4 3 2] got %v", versions)
+	}
+
+	iterator, total, err = pager.GetPage(context.Background(), aggregateID, aggregateType, 4, 10)
+	if total != 6 {
+		t.Fatalf("expected total 6 got %d", total)
+	}
+	versions, err = readVersions(iterator, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(versions) != "[2 1]" {
+		t.Fatalf("expected the versions [2 1] got %v", versions)
+	}
+
+	iterator, total, err = pager.GetPage(context.Background(), aggregateID, aggregateType, 6, 10)
+	if total != 6 {
+		t.Fatalf("expected total 6 got %d", total)
+	}
+	versions, err = readVersions(iterator, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 0 {
+		t.Fatalf("expected no events past the first event got %v", versions)
+	}
+
+	iterator, total, err = pager.GetPage(context.Background(), AggregateID(), aggregateType, 0, 10)
+	if total != 0 {
+		t.Fatalf("expected total 0 of an aggregate without events got %d", total)
+	}
+	versions, err = readVersions(iterator, err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 0 {
+		t.Fatalf("expected no events of an aggregate without events got %v", versions)
+	}
+}
+
+type reversereaderFunc = func() (core.EventStore, core.ReverseReader, func(), error)
+
+// TestReverseReader runs the tests for event stores implementing core.ReverseReader
+func TestReverseReader(t *testing.T, f reversereaderFunc) {
+	es, reader, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	aggregateID := AggregateID()
+	err = es.Save(testEvents(aggregateID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := testEventOtherAggregate(AggregateID())
+	err = es.Save([]core.Event{other})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := readVersions(reader.GetDescending(context.Background(), aggregateID, aggregateType, 0, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(versions) != "[6 5 4]" {
+		t.Fatalf("expected the versions [6 5 4] got %v", versions)
+	}
+	versions, err = readVersions(reader.GetDescending(context.Background(), aggregateID, aggregateType, 3, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(versions) != "[2 1]" {
+		t.Fatalf("expected the versions [2 1] got %v", versions)
+	}
+	versions, err = readVersions(reader.GetDescending(context.Background(), AggregateID(), aggregateType, 0, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 0 {
+		t.Fatalf("expected no events of an aggregate without events got %v", versions)
+	}
+
+	iterator, err := reader.AllDescending(context.Background(), 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []core.Event
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	iterator.Close()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events got %d", len(events))
+	}
+	if events[0].AggregateID != other.AggregateID || events[1].AggregateID != aggregateID || events[1].Version != 6 {
+		t.Fatalf("expected the last saved events newest first got %s version %d", events[1].AggregateID, events[1].Version)
+	}
+	if events[1].GlobalVersion >= events[0].GlobalVersion {
+		t.Fatalf("expected descending global versions got %d before %d", events[0].GlobalVersion, events[1].GlobalVersion)
+	}
+
+	iterator, err = reader.AllDescending(context.Background(), events[1].GlobalVersion, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected an event before the global version")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.GlobalVersion != events[1].GlobalVersion-1 || event.Version != 5 {
+		t.Fatalf("expected version 5 with global version %d got version %d with global version %d", events[1].GlobalVersion-1, event.Version, event.GlobalVersion)
+	}
+	if iterator.Next() {
+		t.Fatal("expected only one event")
+	}
+}
+
+// readVersions returns the versions of the events in the iterator
+func readVersions(iterator core.Iterator, err error) ([]core.Version, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+	var versions []core.Version
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, event.Version)
+	}
+	return versions, nil
+}
+
+type eventcounterFunc = func() (core.EventStore, core.EventCounter, func(), error)
+
+// TestEventCounter runs the tests for event stores implementing core.EventCounter
+func TestEventCounter(t *testing.T, f eventcounterFunc) {
+	es, counter, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	all, err := counter.CountAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	taken, err := counter.CountByReason(context.Background(), "FlightTaken")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	aggregateID := AggregateID()
+	count, err := counter.CountAggregate(context.Background(), aggregateID, aggregateType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no events of an aggregate without events got %d", count)
+	}
+	err = es.Save(testEvents(aggregateID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{testEventOtherAggregate(AggregateID())})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err = counter.CountAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != all+7 {
+		t.Fatalf("expected %d events got %d", all+7, count)
+	}
+	count, err = counter.CountAggregate(context.Background(), aggregateID, aggregateType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 6 {
+		t.Fatalf("expected 6 aggregate events got %d", count)
+	}
+	count, err = counter.CountByReason(context.Background(), "FlightTaken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != taken+4 {
+		t.Fatalf("expected %d FlightTaken events got %d", taken+4, count)
+	}
+}
+
+type categoryreaderFunc = func() (core.EventStore, core.CategoryReader, func(), error)
+
+// TestCategoryReader runs the tests for event stores implementing core.CategoryReader
+func TestCategoryReader(t *testing.T, f categoryreaderFunc) {
+	es, reader, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	// an aggregate type of its own keeps the category stream free from the events of other tests
+	category := "Category" + AggregateID()
+	first := core.Event{AggregateID: AggregateID(), Version: 1, AggregateType: category, Timestamp: timestamp, Reason: "Created", Data: []byte("{}")}
+	second := core.Event{AggregateID: AggregateID(), Version: 1, AggregateType: category, Timestamp: timestamp, Reason: "Created", Data: []byte("{}")}
+	err = es.Save([]core.Event{first})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save(testEvents(AggregateID()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{second, {AggregateID: second.AggregateID, Version: 2, AggregateType: category, Timestamp: timestamp, Reason: "Renamed", Data: []byte("{}")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := readEvents(reader.Category(context.Background(), category, 0, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events in the category got %d", len(events))
+	}
+	for i, event := range events {
+		if event.AggregateType != category {
+			t.Fatalf("expected aggregate type %s got %s", category, event.AggregateType)
+		}
+		if event.CategoryVersion != core.Version(i+1) {
+			t.Fatalf("expected category version %d got %d", i+1, event.CategoryVersion)
+		}
+	}
+	if events[0].AggregateID != first.AggregateID || events[2].Reason != "Renamed" {
+		t.Fatalf("expected the events in the order they were saved")
+	}
+	if events[1].GlobalVersion <= events[0].GlobalVersion+6 {
+		t.Fatalf("expected the global versions of the other events between the category events")
+	}
+
+	events, err = readEvents(reader.Category(context.Background(), category, 1, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].CategoryVersion != 2 || events[0].AggregateID != second.AggregateID {
+		t.Fatalf("expected the event with category version 2")
+	}
+	events, err = readEvents(reader.Category(context.Background(), "Category"+AggregateID(), 0, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events in an empty category got %d", len(events))
+	}
+}
+
+// readEvents returns the events in the iterator
+func readEvents(iterator core.Iterator, err error) ([]core.Event, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+	var events []core.Event
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+type lasteventreaderFunc = func() (core.EventStore, core.LastEventReader, func(), error)
+
+// TestLastEventReader runs the tests for event stores implementing core.LastEventReader, the event store is also used
+// to test the core.LastEvent fallback reading the aggregate event stream
+func TestLastEventReader(t *testing.T, f lasteventreaderFunc) {
+	es, reader, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	aggregateID := AggregateID()
+	_, err = reader.LastEvent(context.Background(), aggregateID, aggregateType)
+	if !errors.Is(err, core.ErrNoEvents) {
+		t.Fatalf("expected no events got %v", err)
+	}
+	err = es.Save(testEvents(aggregateID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{testEventOtherAggregate(AggregateID())})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := reader.LastEvent(context.Background(), aggregateID, aggregateType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.AggregateID != aggregateID || event.Version != 6 || event.Reason != "FlightTaken" {
+		t.Fatalf("expected the event with version 6 got %s version %d", event.AggregateID, event.Version)
+	}
+
+	// an event store without LastEvent reads the whole event stream
+	stream := struct{ core.EventStore }{es}
+	fallback, err := core.LastEvent(context.Background(), stream, aggregateID, aggregateType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fallback.Version != event.Version || fallback.GlobalVersion != event.GlobalVersion {
+		t.Fatalf("expected the fallback to return version %d got %d", event.Version, fallback.Version)
+	}
+	_, err = core.LastEvent(context.Background(), stream, AggregateID(), aggregateType)
+	if !errors.Is(err, core.ErrNoEvents) {
+		t.Fatalf("expected no events got %v", err)
+	}
+}
+
+type correlationreaderFunc = func() (core.EventStore, core.CorrelationReader, func(), error)
+
+// TestCorrelationReader runs the tests for event stores implementing core.CorrelationReader
+func TestCorrelationReader(t *testing.T, f correlationreaderFunc) {
+	es, reader, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	correlationID := AggregateID()
+	first := testEvents(AggregateID())[:2]
+	for i := range first {
+		first[i].CorrelationID = correlationID
+	}
+	second := testEventOtherAggregate(AggregateID())
+	second.CorrelationID = correlationID
+	other := testEventOtherAggregate(AggregateID())
+	other.CorrelationID = AggregateID()
+
+	err = es.Save(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save(testEvents(AggregateID()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := readEvents(reader.ByCorrelationID(context.Background(), correlationID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 correlated events got %d", len(events))
+	}
+	for i, event := range events {
+		if event.CorrelationID != correlationID {
+			t.Fatalf("expected correlation id %s got %s", correlationID, event.CorrelationID)
+		}
+		if i > 0 && event.GlobalVersion <= events[i-1].GlobalVersion {
+			t.Fatalf("expected the correlated events in global order")
+		}
+	}
+	if events[0].AggregateID != first[0].AggregateID || events[1].Version != 2 || events[2].AggregateID != second.AggregateID {
+		t.Fatalf("expected the events of both aggregates in the order they were saved")
+	}
+
+	events, err = readEvents(reader.ByCorrelationID(context.Background(), AggregateID()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events for an unknown correlation id got %d", len(events))
+	}
+}
+
+type globalversionreaderFunc = func() (core.EventStore, core.GlobalVersionReader, func(), error)
+
+// TestGlobalVersionReader runs the tests for event stores implementing core.GlobalVersionReader
+func TestGlobalVersionReader(t *testing.T, f globalversionreaderFunc) {
+	es, reader, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	head, err := reader.GlobalVersion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := testEvents(AggregateID())
+	err = es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := reader.GlobalVersion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after <= head {
+		t.Fatalf("expected the head to move from %d got %d", head, after)
+	}
+	if after != events[len(events)-1].GlobalVersion {
+		t.Fatalf("expected the head to be the global version %d of the last saved event got %d", events[len(events)-1].GlobalVersion, after)
+	}
+
+	// reading the head doesn't move it
+	again, err := reader.GlobalVersion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != after {
+		t.Fatalf("expected the head %d got %d", after, again)
+	}
+}
+
+type timerangereaderFunc = func() (core.EventStore, core.TimeRangeReader, func(), error)
+
+// TestTimeRangeReader runs the tests for event stores implementing core.TimeRangeReader
+func TestTimeRangeReader(t *testing.T, f timerangereaderFunc) {
+	es, reader, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	// a time range not used by the other tests, the events are saved in a zone east of UTC
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	base := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(seededRand.Intn(100000)) * time.Hour).In(zone)
+	id := AggregateID()
+	var events []core.Event
+	for i, offset := range []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond, 2 * time.Second} {
+		events = append(events, core.Event{AggregateID: id, Version: core.Version(i + 1), AggregateType: aggregateType, Timestamp: base.Add(offset), Reason: "FlightTaken", Data: []byte("{}")})
+	}
+	err = es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the range is within one second and given in UTC
+	inRange, err := readEvents(reader.Between(context.Background(), base.Add(50*time.Millisecond).UTC(), base.Add(150*time.Millisecond).UTC()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inRange) != 1 {
+		t.Fatalf("expected one event in the sub second range got %d", len(inRange))
+	}
+	if inRange[0].Version != 2 || !inRange[0].Timestamp.Equal(events[1].Timestamp) {
+		t.Fatalf("expected the event with version 2 at %v got version %d at %v", events[1].Timestamp, inRange[0].Version, inRange[0].Timestamp)
+	}
+
+	inRange, err = readEvents(reader.Between(context.Background(), base.Add(150*time.Millisecond), base.Add(3*time.Second)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inRange) != 2 || inRange[0].Version != 3 || inRange[1].Version != 4 {
+		t.Fatalf("expected the events with version 3 and 4 got %d events", len(inRange))
+	}
+}
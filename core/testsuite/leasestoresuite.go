@@ -0,0 +1,147 @@
+package testsuite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type leasestoreFunc = func() (core.LeaseStore, func(), error)
+
+func TestLeaseStore(t *testing.T, lsFunc leasestoreFunc) {
+	tests := []struct {
+		title string
+		run   func(ls core.LeaseStore) error
+	}{
+		{"should only hand out one open lease", oneOpenLease},
+		{"should open the next lease after seal", openNextLeaseAfterSeal},
+		{"should release lease sealed before its start", releaseEmptyLease},
+		{"should hand out expired lease to other consumer", handOutExpiredLease},
+		{"should not hand out acknowledged lease", ackLease},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			ls, closeFunc, err := lsFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(ls)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+func oneOpenLease(ls core.LeaseStore) error {
+	lease, err := ls.Acquire(context.Background(), "sub", "c1", time.Minute)
+	if err != nil {
+		return err
+	}
+	if lease.From != 1 || lease.To != 0 {
+		return fmt.Errorf("expected open lease from 1 got %d-%d", lease.From, lease.To)
+	}
+	_, err = ls.Acquire(context.Background(), "sub", "c2", time.Minute)
+	if !errors.Is(err, core.ErrNoLease) {
+		return fmt.Errorf("expected ErrNoLease got %v", err)
+	}
+	return nil
+}
+
+func openNextLeaseAfterSeal(ls core.LeaseStore) error {
+	lease, err := ls.Acquire(context.Background(), "sub", "c1", time.Minute)
+	if err != nil {
+		return err
+	}
+	lease, err = ls.Seal(context.Background(), lease, 10)
+	if err != nil {
+		return err
+	}
+	if lease.To != 10 {
+		return fmt.Errorf("expected sealed lease to end at 10 got %d", lease.To)
+	}
+	next, err := ls.Acquire(context.Background(), "sub", "c2", time.Minute)
+	if err != nil {
+		return err
+	}
+	if next.From != 11 || next.Consumer != "c2" {
+		return fmt.Errorf("expected open lease from 11 for c2 got %d for %s", next.From, next.Consumer)
+	}
+	return nil
+}
+
+func releaseEmptyLease(ls core.LeaseStore) error {
+	lease, err := ls.Acquire(context.Background(), "sub", "c1", time.Minute)
+	if err != nil {
+		return err
+	}
+	_, err = ls.Seal(context.Background(), lease, lease.From-1)
+	if err != nil {
+		return err
+	}
+	next, err := ls.Acquire(context.Background(), "sub", "c2", time.Minute)
+	if err != nil {
+		return err
+	}
+	if next.From != lease.From {
+		return fmt.Errorf("expected open lease from %d got %d", lease.From, next.From)
+	}
+	return nil
+}
+
+func handOutExpiredLease(ls core.LeaseStore) error {
+	lease, err := ls.Acquire(context.Background(), "sub", "c1", time.Millisecond*10)
+	if err != nil {
+		return err
+	}
+	lease, err = ls.Seal(context.Background(), lease, 5)
+	if err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond * 20)
+
+	expired, err := ls.Acquire(context.Background(), "sub", "c2", time.Minute)
+	if err != nil {
+		return err
+	}
+	if expired.From != 1 || expired.To != 5 || expired.Consumer != "c2" {
+		return fmt.Errorf("expected expired lease 1-5 for c2 got %d-%d for %s", expired.From, expired.To, expired.Consumer)
+	}
+	err = ls.Ack(context.Background(), lease)
+	if !errors.Is(err, core.ErrLeaseLost) {
+		return fmt.Errorf("expected ErrLeaseLost when acknowledging a lost lease got %v", err)
+	}
+	return ls.Ack(context.Background(), expired)
+}
+
+func ackLease(ls core.LeaseStore) error {
+	lease, err := ls.Acquire(context.Background(), "sub", "c1", time.Millisecond*10)
+	if err != nil {
+		return err
+	}
+	lease, err = ls.Seal(context.Background(), lease, 5)
+	if err != nil {
+		return err
+	}
+	err = ls.Ack(context.Background(), lease)
+	if err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond * 20)
+
+	next, err := ls.Acquire(context.Background(), "sub", "c2", time.Minute)
+	if err != nil {
+		return err
+	}
+	if next.From != 6 {
+		return fmt.Errorf("expected open lease from 6 got %d", next.From)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package testsuite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type lockstoreFunc = func() (core.LockStore, func(), error)
+
+// TestLockStore runs the tests for lock stores
+func TestLockStore(t *testing.T, lsFunc lockstoreFunc) {
+	tests := []struct {
+		title string
+		run   func(ls core.LockStore) error
+	}{
+		{"should not lock a locked aggregate", lockLocked},
+		{"should lock again after unlock", lockAfterUnlock},
+		{"should lock other aggregates", lockOtherAggregate},
+		{"should serialize concurrent locks", concurrentLocks},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			ls, closeFunc, err := lsFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(ls)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+func lockLocked(ls core.LockStore) error {
+	id := AggregateID()
+	unlock, err := ls.Lock(context.Background(), id, aggregateType)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = ls.Lock(ctx, id, aggregateType)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("expected deadline exceeded got %v", err)
+	}
+	return nil
+}
+
+func lockAfterUnlock(ls core.LockStore) error {
+	id := AggregateID()
+	unlock, err := ls.Lock(context.Background(), id, aggregateType)
+	if err != nil {
+		return err
+	}
+	err = unlock()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	unlock, err = ls.Lock(ctx, id, aggregateType)
+	if err != nil {
+		return err
+	}
+	return unlock()
+}
+
+func lockOtherAggregate(ls core.LockStore) error {
+	unlock, err := ls.Lock(context.Background(), AggregateID(), aggregateType)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	unlockOther, err := ls.Lock(ctx, AggregateID(), aggregateType)
+	if err != nil {
+		return err
+	}
+	return unlockOther()
+}
+
+func concurrentLocks(ls core.LockStore) error {
+	id := AggregateID()
+	var wg sync.WaitGroup
+	var errLock sync.Mutex
+	var lockErr error
+	holders := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock, err := ls.Lock(context.Background(), id, aggregateType)
+			errLock.Lock()
+			defer errLock.Unlock()
+			if err != nil {
+				lockErr = err
+				return
+			}
+			holders++
+			if holders > 1 {
+				lockErr = errors.New("more than one holder of the lock")
+			}
+			holders--
+			unlock()
+		}()
+	}
+	wg.Wait()
+	return lockErr
+}
@@ -0,0 +1,202 @@
+package testsuite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type reservationstoreFunc = func() (core.ReservationStore, func(), error)
+
+func TestReservationStore(t *testing.T, rsFunc reservationstoreFunc) {
+	tests := []struct {
+		title string
+		run   func(rs core.ReservationStore) error
+	}{
+		{"should reserve and get reservation", reserveAndGet},
+		{"should get error when reserved by other aggregate", reservedByOther},
+		{"should reserve again by the same aggregate", reserveAgain},
+		{"should release reservation", releaseReservation},
+		{"should not release reservation owned by other aggregate", releaseOwnedByOther},
+		{"should release all reservations of the aggregate", releaseAggregate},
+		{"should not mix up scope and value", scopeAndValue},
+		{"should get error when getting none existing reservation", getNoneExistingReservation},
+		{"should only let one of concurrent reservations succeed", concurrentReservations},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			rs, closeFunc, err := rsFunc()
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = test.run(rs)
+			if err != nil {
+				// make use of t.Error instead of t.Fatal to make sure the closeFunc is executed
+				t.Error(err)
+			}
+			closeFunc()
+		})
+	}
+}
+
+func reserveAndGet(rs core.ReservationStore) error {
+	err := rs.Reserve(context.Background(), "email", "kalle@example.com", "1")
+	if err != nil {
+		return err
+	}
+	r, err := rs.Get(context.Background(), "email", "kalle@example.com")
+	if err != nil {
+		return err
+	}
+	expected := core.Reservation{Scope: "email", Value: "kalle@example.com", AggregateID: "1"}
+	if r != expected {
+		return fmt.Errorf("expected reservation %v got %v", expected, r)
+	}
+	return nil
+}
+
+func reservedByOther(rs core.ReservationStore) error {
+	err := rs.Reserve(context.Background(), "email", "kalle@example.com", "1")
+	if err != nil {
+		return err
+	}
+	err = rs.Reserve(context.Background(), "email", "kalle@example.com", "2")
+	if !errors.Is(err, core.ErrReserved) {
+		return fmt.Errorf("expected reserved error got %v", err)
+	}
+	// the same value in another scope is not reserved
+	return rs.Reserve(context.Background(), "username", "kalle@example.com", "2")
+}
+
+func reserveAgain(rs core.ReservationStore) error {
+	err := rs.Reserve(context.Background(), "email", "kalle@example.com", "1")
+	if err != nil {
+		return err
+	}
+	return rs.Reserve(context.Background(), "email", "kalle@example.com", "1")
+}
+
+func releaseReservation(rs core.ReservationStore) error {
+	err := rs.Reserve(context.Background(), "email", "kalle@example.com", "1")
+	if err != nil {
+		return err
+	}
+	err = rs.Release(context.Background(), "email", "kalle@example.com", "1")
+	if err != nil {
+		return err
+	}
+	_, err = rs.Get(context.Background(), "email", "kalle@example.com")
+	if !errors.Is(err, core.ErrReservationNotFound) {
+		return fmt.Errorf("expected reservation not found error got %v", err)
+	}
+	// the released value can be reserved by another aggregate
+	return rs.Reserve(context.Background(), "email", "kalle@example.com", "2")
+}
+
+func releaseOwnedByOther(rs core.ReservationStore) error {
+	err := rs.Reserve(context.Background(), "email", "kalle@example.com", "1")
+	if err != nil {
+		return err
+	}
+	err = rs.Release(context.Background(), "email", "kalle@example.com", "2")
+	if err != nil {
+		return err
+	}
+	r, err := rs.Get(context.Background(), "email", "kalle@example.com")
+	if err != nil {
+		return err
+	}
+	if r.AggregateID != "1" {
+		return fmt.Errorf("expected reservation owned by 1 got %s", r.AggregateID)
+	}
+	return nil
+}
+
+func releaseAggregate(rs core.ReservationStore) error {
+	err := rs.Reserve(context.Background(), "email", "kalle@example.com", "1")
+	if err != nil {
+		return err
+	}
+	err = rs.Reserve(context.Background(), "username", "kalle", "1")
+	if err != nil {
+		return err
+	}
+	err = rs.Reserve(context.Background(), "username", "anka", "2")
+	if err != nil {
+		return err
+	}
+	err = rs.ReleaseAggregate(context.Background(), "1")
+	if err != nil {
+		return err
+	}
+	for _, r := range []core.Reservation{{Scope: "email", Value: "kalle@example.com"}, {Scope: "username", Value: "kalle"}} {
+		_, err = rs.Get(context.Background(), r.Scope, r.Value)
+		if !errors.Is(err, core.ErrReservationNotFound) {
+			return fmt.Errorf("expected %s %s to be released got %v", r.Scope, r.Value, err)
+		}
+	}
+	r, err := rs.Get(context.Background(), "username", "anka")
+	if err != nil {
+		return err
+	}
+	if r.AggregateID != "2" {
+		return fmt.Errorf("expected reservation owned by 2 got %s", r.AggregateID)
+	}
+	return nil
+}
+
+func scopeAndValue(rs core.ReservationStore) error {
+	err := rs.Reserve(context.Background(), "user_name", "kalle", "1")
+	if err != nil {
+		return err
+	}
+	// joining scope and value with the separator gives the same string
+	err = rs.Reserve(context.Background(), "user", "name_kalle", "2")
+	if err != nil {
+		return fmt.Errorf("expected value in other scope to be free got %v", err)
+	}
+	r, err := rs.Get(context.Background(), "user", "name_kalle")
+	if err != nil {
+		return err
+	}
+	if r.AggregateID != "2" {
+		return fmt.Errorf("expected reservation owned by 2 got %s", r.AggregateID)
+	}
+	return nil
+}
+
+func getNoneExistingReservation(rs core.ReservationStore) error {
+	_, err := rs.Get(context.Background(), "email", "none_existing")
+	if !errors.Is(err, core.ErrReservationNotFound) {
+		return errors.New("expect reservation not found error")
+	}
+	return nil
+}
+
+func concurrentReservations(rs core.ReservationStore) error {
+	var lock sync.Mutex
+	succeeded := 0
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			err := rs.Reserve(context.Background(), "email", "kalle@example.com", id)
+			if err == nil {
+				lock.Lock()
+				succeeded++
+				lock.Unlock()
+			}
+		}(fmt.Sprintf("%d", i))
+	}
+	wg.Wait()
+	if succeeded != 1 {
+		return fmt.Errorf("expected one reservation to succeed got %d", succeeded)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hallgren/eventsourcing/core"
 )
@@ -43,6 +44,8 @@ func saveAndGetSnapshot(ss core.SnapshotStore) error {
 		Version:       1,
 		GlobalVersion: 1,
 		State:         []byte("123"),
+		SchemaVersion: 2,
+		Timestamp:     time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
 	}
 
 	err := ss.Save(snapshot)
@@ -67,6 +70,14 @@ func saveAndGetSnapshot(ss core.SnapshotStore) error {
 		return fmt.Errorf("exp global version %d got %d", snapshot.GlobalVersion, s.GlobalVersion)
 	}
 
+	if s.SchemaVersion != snapshot.SchemaVersion {
+		return fmt.Errorf("exp schema version %d got %d", snapshot.SchemaVersion, s.SchemaVersion)
+	}
+
+	if !s.Timestamp.Equal(snapshot.Timestamp) {
+		return fmt.Errorf("exp timestamp %v got %v", snapshot.Timestamp, s.Timestamp)
+	}
+
 	s, err = ss.Get(context.Background(), "none_existing_id", "person")
 	if !errors.Is(err, core.ErrSnapshotNotFound) {
 		return err
@@ -81,3 +92,45 @@ func getNoneExistingSnapshot(ss core.SnapshotStore) error {
 	}
 	return nil
 }
+
+type snapshotprunerFunc = func() (core.SnapshotStore, core.SnapshotPruner, func(), error)
+
+// TestSnapshotPruner runs the tests for snapshot stores implementing core.SnapshotPruner
+func TestSnapshotPruner(t *testing.T, f snapshotprunerFunc) {
+	ss, pruner, closeFunc, err := f()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+
+	now := time.Now().UTC()
+	snapshots := []core.Snapshot{
+		{ID: "old", Type: "person", Version: 1, GlobalVersion: 1, State: []byte("1"), Timestamp: now.Add(-time.Hour * 48)},
+		{ID: "older", Type: "person", Version: 1, GlobalVersion: 2, State: []byte("2"), Timestamp: now.Add(-time.Hour * 72)},
+		{ID: "new", Type: "person", Version: 1, GlobalVersion: 3, State: []byte("3"), Timestamp: now},
+	}
+	for _, snapshot := range snapshots {
+		err = ss.Save(snapshot)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleted, err := pruner.Prune(context.Background(), now.Add(-time.Hour*24))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 pruned snapshots got %d", deleted)
+	}
+	for _, id := range []string{"old", "older"} {
+		_, err = ss.Get(context.Background(), id, "person")
+		if !errors.Is(err, core.ErrSnapshotNotFound) {
+			t.Fatalf("expected snapshot %q to be pruned got %v", id, err)
+		}
+	}
+	_, err = ss.Get(context.Background(), "new", "person")
+	if err != nil {
+		t.Fatalf("expected snapshot new to be kept got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package eventsourcing
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Cursor is an opaque position in the global event feed or in an aggregate event stream. It's a string safe to use in
+// URLs, HTTP APIs hand it out with a page of events and get it back to read the next page without exposing the global
+// versions of the event store. The empty cursor is the start of the feed or stream.
+type Cursor string
+
+// cursor is the content of a Cursor, the aggregate type and id are blank for the global event feed
+type cursor struct {
+	AggregateType string       `json:"t,omitempty"`
+	AggregateID   string       `json:"id,omitempty"`
+	Version       core.Version `json:"v"`
+}
+
+// Page is a page of events read from a cursor
+type Page struct {
+	Events []core.Event // Events in the page in the order they were saved
+	Next   Cursor       // Next is the cursor of the page after this one, the passed cursor if the page is empty
+	More   bool         // More is true if there were events after the page when it was read
+}
+
+// FeedPage reads the page of at most size events in the global event feed after the cursor. The fetchF returns the
+// events from the global version it's passed, e.g. a filtered feed of the event store.
+//
+//	page, err := eventsourcing.FeedPage(func(start core.Version) (core.Iterator, error) {
+//		return es.All(start, 101)
+//	}, eventsourcing.Cursor(r.URL.Query().Get("cursor")), 100)
+func FeedPage(fetchF func(start core.Version) (core.Iterator, error), c Cursor, size int) (Page, error) {
+	position, err := c.decode("", "")
+	if err != nil {
+		return Page{}, err
+	}
+	iterator, err := fetchF(position.Version + 1)
+	if err != nil {
+		return Page{}, err
+	}
+	page, last, err := readPage(iterator, size, func(event core.Event) core.Version {
+		return event.GlobalVersion
+	})
+	if err != nil {
+		return Page{}, err
+	}
+	page.Next = c
+	if last != 0 {
+		page.Next = cursor{Version: last}.encode()
+	}
+	return page, nil
+}
+
+// StreamPage reads the page of at most size events in the aggregate event stream after the cursor. A cursor of
+// another event stream returns ErrInvalidCursor.
+func StreamPage(ctx context.Context, es core.EventStore, aggregateType, id string, c Cursor, size int) (Page, error) {
+	position, err := c.decode(aggregateType, id)
+	if err != nil {
+		return Page{}, err
+	}
+	iterator, err := es.Get(ctx, id, aggregateType, position.Version)
+	if err != nil {
+		return Page{}, err
+	}
+	page, last, err := readPage(iterator, size, func(event core.Event) core.Version {
+		return event.Version
+	})
+	if err != nil {
+		return Page{}, err
+	}
+	page.Next = c
+	if last != 0 {
+		page.Next = cursor{AggregateType: aggregateType, AggregateID: id, Version: last}.encode()
+	}
+	return page, nil
+}
+
+// readPage reads size events from the iterator and one more to tell if there are more events. The position of the
+// last event in the page is returned, zero if the page is empty.
+func readPage(iterator core.Iterator, size int, position func(event core.Event) core.Version) (Page, core.Version, error) {
+	defer iterator.Close()
+	page := Page{}
+	var last core.Version
+	for iterator.Next() {
+		if len(page.Events) >= size {
+			page.More = true
+			break
+		}
+		event, err := iterator.Value()
+		if err != nil {
+			return Page{}, 0, err
+		}
+		page.Events = append(page.Events, event)
+		last = position(event)
+	}
+	return page, last, nil
+}
+
+func (c cursor) encode() Cursor {
+	// the struct can always be marshaled
+	b, _ := json.Marshal(c)
+	return Cursor(base64.RawURLEncoding.EncodeToString(b))
+}
+
+// decode returns the position of the cursor, it has to be a cursor of the event stream
+func (c Cursor) decode(aggregateType, id string) (cursor, error) {
+	if c == "" {
+		return cursor{AggregateType: aggregateType, AggregateID: id}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(string(c))
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+	position := cursor{}
+	err = json.Unmarshal(b, &position)
+	if err != nil {
+		return cursor{}, fmt.Errorf("%w: %s", ErrInvalidCursor, err)
+	}
+	if position.AggregateType != aggregateType || position.AggregateID != id {
+		return cursor{}, fmt.Errorf("%w: the cursor belongs to another event stream", ErrInvalidCursor)
+	}
+	return position, nil
+}
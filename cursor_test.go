@@ -0,0 +1,92 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestFeedPage(t *testing.T) {
+	es := memory.Create()
+	err := createPersonEvent(es, "kalle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 10)()
+	}
+
+	var cursor eventsourcing.Cursor
+	var versions []core.Version
+	for i := 0; i < 3; i++ {
+		page, err := eventsourcing.FeedPage(fetchF, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, event := range page.Events {
+			versions = append(versions, event.GlobalVersion)
+		}
+		if page.More != (i < 2) {
+			t.Fatalf("expected more to be %t on page %d", i < 2, i)
+		}
+		cursor = page.Next
+	}
+	if len(versions) != 5 || versions[0] != 1 || versions[4] != 5 {
+		t.Fatalf("expected the global versions 1 to 5 was %v", versions)
+	}
+
+	// the cursor of the last page is kept until new events are saved
+	page, err := eventsourcing.FeedPage(fetchF, cursor, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Events) != 0 || page.Next != cursor {
+		t.Fatalf("expected an empty page with the same cursor was %d events", len(page.Events))
+	}
+}
+
+func TestStreamPage(t *testing.T) {
+	es := memory.Create()
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := eventsourcing.FeedPage(func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 1)()
+	}, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := page.Events[0].AggregateID
+
+	page, err = eventsourcing.StreamPage(context.Background(), es, "Person", id, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Events) != 2 || !page.More {
+		t.Fatalf("expected a full page with more events was %d events", len(page.Events))
+	}
+	page, err = eventsourcing.StreamPage(context.Background(), es, "Person", id, page.Next, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Events) != 1 || page.Events[0].Version != 3 || page.More {
+		t.Fatalf("expected the last event with version 3 was %d events", len(page.Events))
+	}
+
+	// cursors are bound to their event stream
+	_, err = eventsourcing.StreamPage(context.Background(), es, "Person", "other", page.Next, 2)
+	if !errors.Is(err, eventsourcing.ErrInvalidCursor) {
+		t.Fatalf("expected invalid cursor was %v", err)
+	}
+	_, err = eventsourcing.FeedPage(func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 1)()
+	}, "not a cursor", 1)
+	if !errors.Is(err, eventsourcing.ErrInvalidCursor) {
+		t.Fatalf("expected invalid cursor was %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+// Package debug serves the live internals of the event sourcing setup as JSON: the registered aggregates and events,
+// the projections with their positions, the open event bus subscriptions and the event store statistics. It answers
+// questions like "why isn't my read model updating" in production without attaching a debugger.
+package debug
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Handler is an http.Handler dumping the internals as JSON. All fields are optional, the parts without a value are
+// left out of the dump.
+//
+//	http.Handle("/debug/eventsourcing", &debug.Handler{Manager: manager, Bus: bus, Stores: map[string]core.StatsReader{"events": es}})
+type Handler struct {
+	Manager     *eventsourcing.ProjectionManager // Manager lists the projections it runs
+	Projections []*eventsourcing.Projection      // Projections run outside the manager, e.g. in a group
+	Bus         *eventsourcing.EventBus          // Bus counts the open subscriptions
+	Stores      map[string]core.StatsReader      // Stores are the event stores by name to include the statistics of
+}
+
+// Dump is the state of the internals at the time it was taken
+type Dump struct {
+	Time          time.Time             `json:"time"`
+	Aggregates    map[string][]string   `json:"aggregates"`              // Aggregates are the registered aggregate types with their event reasons
+	Projections   []Projection          `json:"projections,omitempty"`   // Projections ordered by name
+	Subscriptions *int                  `json:"subscriptions,omitempty"` // Subscriptions is the number of open subscriptions on the bus
+	Stores        map[string]StoreStats `json:"stores,omitempty"`        // Stores are the statistics of the event stores by name
+}
+
+// Projection is the state of a projection
+type Projection struct {
+	Name      string    `json:"name"`
+	Position  uint64    `json:"position"` // Position is the global version of the last handled event
+	Running   bool      `json:"running"`
+	CaughtUp  bool      `json:"caught_up"`            // CaughtUp is set when the projection has reached the head as of its start
+	LastRun   time.Time `json:"last_run"`             // LastRun is the time of the last successful fetch
+	LastError string    `json:"last_error,omitempty"` // LastError is set while the projection is failing
+}
+
+// StoreStats is the statistics of an event store, or the error reading them
+type StoreStats struct {
+	Events         uint64            `json:"events"`
+	Head           uint64            `json:"head"`
+	AggregateTypes map[string]uint64 `json:"aggregate_types"`
+	Size           int64             `json:"size"`
+	Error          string            `json:"error,omitempty"`
+}
+
+// Dump takes the state of the internals
+func (h *Handler) Dump(ctx context.Context) Dump {
+	d := Dump{
+		Time:       time.Now(),
+		Aggregates: aggregate.Registered(),
+	}
+	projections := h.Projections
+	if h.Manager != nil {
+		projections = append(h.Manager.Projections(), projections...)
+	}
+	for _, p := range projections {
+		d.Projections = append(d.Projections, Projection{
+			Name:      p.Name,
+			Position:  uint64(p.Position()),
+			Running:   p.Running(),
+			CaughtUp:  p.CaughtUp(),
+			LastRun:   p.LastRun(),
+			LastError: p.LastError(),
+		})
+	}
+	sort.SliceStable(d.Projections, func(i, j int) bool {
+		return d.Projections[i].Name < d.Projections[j].Name
+	})
+	if h.Bus != nil {
+		subscriptions := h.Bus.Subscriptions()
+		d.Subscriptions = &subscriptions
+	}
+	if len(h.Stores) > 0 {
+		d.Stores = make(map[string]StoreStats, len(h.Stores))
+		for name, s := range h.Stores {
+			stats, err := s.Stats(ctx)
+			if err != nil {
+				d.Stores[name] = StoreStats{Error: err.Error()}
+				continue
+			}
+			d.Stores[name] = StoreStats{
+				Events:         stats.Events,
+				Head:           uint64(stats.Head),
+				AggregateTypes: stats.AggregateTypes,
+				Size:           stats.Size,
+			}
+		}
+	}
+	return d
+}
+
+// ServeHTTP writes the dump as indented JSON
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(h.Dump(r.Context()))
+}
+
+// Publish exports the dump as the expvar variable with the name, served on /debug/vars by the expvar package. Like
+// expvar.Publish it panics if the name is already in use.
+func (h *Handler) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return h.Dump(context.Background())
+	}))
+}
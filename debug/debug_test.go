@@ -0,0 +1,104 @@
+package debug_test
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/debug"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+type Person struct {
+	aggregate.Root
+}
+
+type Born struct {
+	Name string
+}
+
+type AgedOneYear struct{}
+
+func (p *Person) Register(f aggregate.RegisterFunc) {
+	f(&Born{}, &AgedOneYear{})
+}
+
+func (p *Person) Transition(event eventsourcing.Event) {}
+
+func TestHandler(t *testing.T) {
+	aggregate.Register(&Person{})
+	es := memory.Create()
+	p := Person{}
+	aggregate.TrackChange(&p, &Born{Name: "kalle"})
+	aggregate.TrackChange(&p, &AgedOneYear{})
+	err := aggregate.Save(es, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proj := eventsourcing.NewPositionProjection(func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 10)()
+	}, func(ctx context.Context, event eventsourcing.Event) error {
+		return nil
+	})
+	proj.Name = "persons"
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	bus := eventsourcing.NewEventBus()
+	sub := bus.Listen(1)
+	defer sub.Close()
+
+	h := &debug.Handler{
+		Projections: []*eventsourcing.Projection{proj},
+		Bus:         bus,
+		Stores:      map[string]core.StatsReader{"events": es},
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/eventsourcing", nil))
+
+	dump := debug.Dump{}
+	err = json.NewDecoder(rec.Body).Decode(&dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reasons := dump.Aggregates["Person"]
+	if len(reasons) != 3 || reasons[0] != "Born" || reasons[1] != "AgedOneYear" || reasons[2] != "Tombstone" {
+		t.Fatalf("unexpected Person events %v", reasons)
+	}
+	if len(dump.Projections) != 1 {
+		t.Fatalf("expected 1 projection was %d", len(dump.Projections))
+	}
+	if dump.Projections[0].Name != "persons" || dump.Projections[0].Position != 2 || dump.Projections[0].Running || dump.Projections[0].LastRun.IsZero() {
+		t.Fatalf("unexpected projection %+v", dump.Projections[0])
+	}
+	if dump.Subscriptions == nil || *dump.Subscriptions != 1 {
+		t.Fatalf("expected 1 subscription was %v", dump.Subscriptions)
+	}
+	if dump.Stores["events"].Events != 2 || dump.Stores["events"].Head != 2 {
+		t.Fatalf("unexpected store stats %+v", dump.Stores["events"])
+	}
+}
+
+func TestPublish(t *testing.T) {
+	h := &debug.Handler{}
+	h.Publish("eventsourcing")
+	v := expvar.Get("eventsourcing")
+	if v == nil {
+		t.Fatal("expected the dump to be published")
+	}
+	dump := debug.Dump{}
+	err := json.Unmarshal([]byte(v.String()), &dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dump.Time.IsZero() {
+		t.Fatal("expected the time of the dump")
+	}
+}
@@ -0,0 +1,167 @@
+// Package avro is an Avro encoder for event data using a Confluent compatible schema registry. The serialized data
+// follows the Confluent wire format, a zero magic byte and the four byte schema id followed by the Avro binary data,
+// making the events readable by consumers on an Avro based data platform.
+//
+// Types without a registered schema, like the event metadata, are serialized with the fallback encoder.
+package avro
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+)
+
+const magicByte = 0
+
+// fallback has the same methods as the eventsourcing.Encoder interface
+type fallback interface {
+	Serialize(v interface{}) ([]byte, error)
+	Deserialize(data []byte, v interface{}) error
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) Serialize(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonEncoder) Deserialize(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type writer struct {
+	subject string
+	schema  avro.Schema
+	id      int
+}
+
+// Encoder serialize and deserialize registered types with Avro
+type Encoder struct {
+	registry Registry
+	// Fallback serialize the types without a registered schema, defaults to JSON. The serialized data must not begin
+	// with a zero byte.
+	Fallback fallback
+
+	lock    sync.RWMutex
+	writers map[reflect.Type]writer
+	readers map[int]avro.Schema
+}
+
+// New creates an Avro encoder resolving schemas from the registry
+func New(registry Registry) *Encoder {
+	return &Encoder{
+		registry: registry,
+		Fallback: jsonEncoder{},
+		writers:  make(map[reflect.Type]writer),
+		readers:  make(map[int]avro.Schema),
+	}
+}
+
+// Register binds the type of v to the Avro schema registered under subject in the schema registry
+func (e *Encoder) Register(v interface{}, subject, schema string) error {
+	s, err := avro.Parse(schema)
+	if err != nil {
+		return err
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.writers[typeOf(v)] = writer{subject: subject, schema: s}
+	return nil
+}
+
+// Serialize encodes v with its registered schema in the Confluent wire format
+func (e *Encoder) Serialize(v interface{}) ([]byte, error) {
+	w, ok, err := e.writer(v)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return e.Fallback.Serialize(v)
+	}
+	data, err := avro.Marshal(w.schema, v)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 5, 5+len(data))
+	b[0] = magicByte
+	binary.BigEndian.PutUint32(b[1:], uint32(w.id))
+	return append(b, data...), nil
+}
+
+// Deserialize decodes data with the writer schema it was serialized with
+func (e *Encoder) Deserialize(data []byte, v interface{}) error {
+	if len(data) == 0 || data[0] != magicByte {
+		return e.Fallback.Deserialize(data, v)
+	}
+	if len(data) < 5 {
+		return errors.New("avro data is shorter than the wire format header")
+	}
+	schema, err := e.reader(int(binary.BigEndian.Uint32(data[1:5])))
+	if err != nil {
+		return err
+	}
+	return avro.Unmarshal(schema, data[5:], v)
+}
+
+// writer returns the writer of the type of v, registering the schema in the registry on first use. False is returned
+// for types without a schema.
+func (e *Encoder) writer(v interface{}) (writer, bool, error) {
+	typ := typeOf(v)
+	e.lock.RLock()
+	w, ok := e.writers[typ]
+	e.lock.RUnlock()
+	if !ok {
+		return writer{}, false, nil
+	}
+	if w.id != 0 {
+		return w, true, nil
+	}
+
+	id, err := e.registry.Register(context.Background(), w.subject, w.schema.String())
+	if err != nil {
+		return writer{}, false, fmt.Errorf("could not register schema for subject %s, %w", w.subject, err)
+	}
+	w.id = id
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.writers[typ] = w
+	e.readers[id] = w.schema
+	return w, true, nil
+}
+
+// reader returns the writer schema with the id, fetching it from the registry on first use
+func (e *Encoder) reader(id int) (avro.Schema, error) {
+	e.lock.RLock()
+	schema, ok := e.readers[id]
+	e.lock.RUnlock()
+	if ok {
+		return schema, nil
+	}
+	s, err := e.registry.Schema(context.Background(), id)
+	if err != nil {
+		return nil, fmt.Errorf("could not get schema %d, %w", id, err)
+	}
+	schema, err = avro.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.readers[id] = schema
+	return schema, nil
+}
+
+func typeOf(v interface{}) reflect.Type {
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
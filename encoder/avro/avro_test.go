@@ -0,0 +1,109 @@
+package avro_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/encoder/avro"
+)
+
+const bornSchema = `{"type":"record","name":"Born","namespace":"person","fields":[{"name":"name","type":"string"},{"name":"age","type":"int"}]}`
+
+type Born struct {
+	Name string `avro:"name"`
+	Age  int    `avro:"age"`
+}
+
+// registry is a minimal Confluent compatible schema registry
+type registry struct {
+	lock    sync.Mutex
+	schemas []string
+}
+
+func (r *registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	switch {
+	case req.Method == http.MethodPost && strings.HasPrefix(req.URL.Path, "/subjects/"):
+		var body struct {
+			Schema string `json:"schema"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		r.schemas = append(r.schemas, body.Schema)
+		json.NewEncoder(w).Encode(map[string]int{"id": len(r.schemas)})
+	case req.Method == http.MethodGet && strings.HasPrefix(req.URL.Path, "/schemas/ids/"):
+		id, _ := strconv.Atoi(strings.TrimPrefix(req.URL.Path, "/schemas/ids/"))
+		if id < 1 || id > len(r.schemas) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"schema": r.schemas[id-1]})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSerializeWireFormat(t *testing.T) {
+	server := httptest.NewServer(&registry{})
+	defer server.Close()
+
+	e := avro.New(avro.NewClient(server.URL))
+	err := e.Register(&Born{}, "person-born-value", bornSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := e.Serialize(&Born{Name: "kalle", Age: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b[0] != 0 || b[1] != 0 || b[2] != 0 || b[3] != 0 || b[4] != 1 {
+		t.Fatalf("expected wire format header with schema id 1 was %v", b[:5])
+	}
+
+	// a new encoder has to fetch the writer schema from the registry
+	e2 := avro.New(avro.NewClient(server.URL))
+	born := Born{}
+	err = e2.Deserialize(b, &born)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if born.Name != "kalle" || born.Age != 1 {
+		t.Fatalf("unexpected deserialized event %+v", born)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	server := httptest.NewServer(&registry{})
+	defer server.Close()
+
+	e := avro.New(avro.NewClient(server.URL))
+	b, err := e.Serialize(map[string]interface{}{"user": "kalle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := map[string]interface{}{}
+	err = e.Deserialize(b, &metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata["user"] != "kalle" {
+		t.Fatalf("unexpected deserialized metadata %v", metadata)
+	}
+}
+
+func TestUnknownSchema(t *testing.T) {
+	server := httptest.NewServer(&registry{})
+	defer server.Close()
+
+	e := avro.New(avro.NewClient(server.URL))
+	born := Born{}
+	err := e.Deserialize([]byte{0, 0, 0, 0, 9, 1}, &born)
+	if err == nil {
+		t.Fatal("expected error on unknown schema id")
+	}
+}
@@ -0,0 +1,84 @@
+package avro
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// Registry resolves the schemas
+type Registry interface {
+	// Register returns the id of the schema under the subject, registering it if it's new
+	Register(ctx context.Context, subject, schema string) (int, error)
+	// Schema returns the schema with the id
+	Schema(ctx context.Context, id int) (string, error)
+}
+
+// Client is a Registry talking to a Confluent compatible schema registry
+type Client struct {
+	url        string
+	HTTPClient *http.Client
+}
+
+// NewClient creates a schema registry client, url is the base url of the registry e.g. http://localhost:8081
+func NewClient(url string) *Client {
+	return &Client{
+		url:        url,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+type schemaRequest struct {
+	Schema string `json:"schema"`
+}
+
+type schemaResponse struct {
+	ID     int    `json:"id"`
+	Schema string `json:"schema"`
+}
+
+// Register registers the schema under the subject and returns its id. The registry returns the id of an already
+// registered identical schema.
+func (c *Client) Register(ctx context.Context, subject, schema string) (int, error) {
+	body, err := json.Marshal(schemaRequest{Schema: schema})
+	if err != nil {
+		return 0, err
+	}
+	var res schemaResponse
+	err = c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", body, &res)
+	if err != nil {
+		return 0, err
+	}
+	return res.ID, nil
+}
+
+// Schema returns the schema with the id
+func (c *Client) Schema(ctx context.Context, id int) (string, error) {
+	var res schemaResponse
+	err := c.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &res)
+	if err != nil {
+		return "", err
+	}
+	return res.Schema, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("schema registry %s %s returned status %d", method, path, res.StatusCode)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
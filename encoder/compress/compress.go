@@ -0,0 +1,46 @@
+// Package compress wraps an event encoder to compress large serialized event data. The codec is recognized from the
+// magic bytes of the compressed payload making events stored before compression was enabled readable.
+package compress
+
+import (
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/snapshotstore/compress"
+)
+
+// Encoder compresses the payload serialized by the wrapped encoder
+type Encoder struct {
+	encoder    eventsourcing.Encoder
+	compressor compress.Compressor
+	// MinSize is the smallest serialized payload in bytes that is compressed, smaller payloads are stored as is
+	MinSize int
+}
+
+// Wrap returns an encoder compressing the payloads serialized by e with the compressor, the compressors from the
+// snapshotstore/compress package are used for events as well.
+func Wrap(e eventsourcing.Encoder, compressor compress.Compressor) *Encoder {
+	return &Encoder{
+		encoder:    e,
+		compressor: compressor,
+	}
+}
+
+// Serialize serializes v with the wrapped encoder and compresses the result if it's larger than MinSize
+func (e *Encoder) Serialize(v interface{}) ([]byte, error) {
+	data, err := e.encoder.Serialize(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < e.MinSize {
+		return data, nil
+	}
+	return e.compressor.Compress(data)
+}
+
+// Deserialize decompresses data if it's compressed and deserializes it with the wrapped encoder
+func (e *Encoder) Deserialize(data []byte, v interface{}) error {
+	data, err := e.compressor.Decompress(data)
+	if err != nil {
+		return err
+	}
+	return e.encoder.Deserialize(data, v)
+}
@@ -0,0 +1,68 @@
+package compress_test
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/encoder/compress"
+	sscompress "github.com/hallgren/eventsourcing/snapshotstore/compress"
+)
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) Serialize(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonEncoder) Deserialize(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type DocumentUploaded struct {
+	Content string
+}
+
+func TestCompressLargePayload(t *testing.T) {
+	e := compress.Wrap(jsonEncoder{}, sscompress.Gzip(gzip.DefaultCompression))
+	e.MinSize = 1024
+
+	event := DocumentUploaded{Content: strings.Repeat("document ", 1000)}
+	data, err := e.Serialize(&event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) >= len(event.Content) {
+		t.Fatalf("expected compressed payload smaller than %d was %d", len(event.Content), len(data))
+	}
+	deserialized := DocumentUploaded{}
+	err = e.Deserialize(data, &deserialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deserialized.Content != event.Content {
+		t.Fatal("expected deserialized content to equal the original")
+	}
+}
+
+func TestSmallPayloadNotCompressed(t *testing.T) {
+	e := compress.Wrap(jsonEncoder{}, sscompress.Gzip(gzip.DefaultCompression))
+	e.MinSize = 1024
+
+	data, err := e.Serialize(&DocumentUploaded{Content: "small"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"Content":"small"}` {
+		t.Fatalf("expected small payload stored as is was %q", data)
+	}
+	deserialized := DocumentUploaded{}
+	err = e.Deserialize(data, &deserialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deserialized.Content != "small" {
+		t.Fatalf("expected content small was %q", deserialized.Content)
+	}
+}
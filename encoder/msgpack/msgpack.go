@@ -0,0 +1,22 @@
+// Package msgpack is a MessagePack encoder for event and snapshot data. It produces smaller payloads and is faster
+// than the default JSON encoder.
+//
+//	eventsourcing.SetEventEncoder(msgpack.Encoder{})
+package msgpack
+
+import (
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Encoder serialize and deserialize with MessagePack
+type Encoder struct{}
+
+// Serialize encodes v as MessagePack
+func (e Encoder) Serialize(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// Deserialize decodes the MessagePack data into v
+func (e Encoder) Deserialize(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
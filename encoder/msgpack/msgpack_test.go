@@ -0,0 +1,53 @@
+package msgpack_test
+
+import (
+	"testing"
+
+	"github.com/hallgren/eventsourcing/encoder/msgpack"
+)
+
+type Born struct {
+	Name string
+	Age  int
+}
+
+func TestSerializeData(t *testing.T) {
+	e := msgpack.Encoder{}
+	b, err := e.Serialize(&Born{Name: "kalle", Age: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	born := Born{}
+	err = e.Deserialize(b, &born)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if born.Name != "kalle" || born.Age != 1 {
+		t.Fatalf("unexpected deserialized event %+v", born)
+	}
+}
+
+func TestSerializeMetadata(t *testing.T) {
+	e := msgpack.Encoder{}
+	b, err := e.Serialize(map[string]interface{}{"user": "kalle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := map[string]interface{}{}
+	err = e.Deserialize(b, &metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata["user"] != "kalle" {
+		t.Fatalf("unexpected deserialized metadata %v", metadata)
+	}
+}
+
+func TestDeserializeInvalidData(t *testing.T) {
+	e := msgpack.Encoder{}
+	born := Born{}
+	err := e.Deserialize([]byte{0xc1}, &born)
+	if err == nil {
+		t.Fatal("expected error on invalid data")
+	}
+}
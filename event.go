@@ -1,10 +1,13 @@
 package eventsourcing
 
 import (
+	"encoding/json"
+	"fmt"
 	"reflect"
 	"time"
 
 	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/internal"
 )
 
 // Version is the event version used in event.Version and event.GlobalVersion
@@ -20,6 +23,16 @@ func NewEvent(e core.Event, data interface{}, metadata map[string]interface{}) E
 	return Event{event: e, data: data, metadata: metadata}
 }
 
+// NewEventData returns a pointer to a new value of the event data type registered on the aggregate type with the
+// reason. It's used to decode events received from outside the event store into their registered type.
+func NewEventData(aggregateType, reason string) (interface{}, error) {
+	f, ok := internal.GlobalRegister.EventRegistered(core.Event{AggregateType: aggregateType, Reason: reason})
+	if !ok {
+		return nil, fmt.Errorf("aggregate type: %s, reason: %s, %w", aggregateType, reason, ErrEventNotRegistered)
+	}
+	return f(), nil
+}
+
 func (e Event) Data() interface{} {
 	return e.data
 }
@@ -38,7 +51,7 @@ func (e Event) AggregateID() string {
 
 func (e Event) Reason() string {
 	if e.data == nil {
-		return ""
+		return e.event.Reason
 	}
 	return reflect.TypeOf(e.data).Elem().Name()
 }
@@ -54,3 +67,49 @@ func (e Event) Timestamp() time.Time {
 func (e Event) GlobalVersion() Version {
 	return Version(e.event.GlobalVersion)
 }
+
+// SchemaVersion is the version of the event data shape when the event was stored
+func (e Event) SchemaVersion() uint {
+	return e.event.SchemaVersion
+}
+
+// CategoryVersion is the position of the event in the category stream of its aggregate type, zero if the event store
+// keeps no category streams
+func (e Event) CategoryVersion() Version {
+	return Version(e.event.CategoryVersion)
+}
+
+// CoreEvent returns the event as stored in the event store, with the serialized data and metadata. Events tracked on
+// an aggregate are not serialized until they are saved and have no data and metadata.
+func (e Event) CoreEvent() core.Event {
+	return e.event
+}
+
+// eventJSON is the JSON representation of the event sent to external consumers
+type eventJSON struct {
+	AggregateID   string                 `json:"aggregate_id"`
+	AggregateType string                 `json:"aggregate_type"`
+	Version       Version                `json:"version"`
+	GlobalVersion Version                `json:"global_version"`
+	Reason        string                 `json:"reason"`
+	Timestamp     time.Time              `json:"timestamp"`
+	SchemaVersion uint                   `json:"schema_version"`
+	Data          interface{}            `json:"data"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// MarshalJSON encodes the event properties, data and metadata as JSON independent of the event encoder. It's the
+// format events are sent to external consumers like webhooks.
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal(eventJSON{
+		AggregateID:   e.AggregateID(),
+		AggregateType: e.AggregateType(),
+		Version:       e.Version(),
+		GlobalVersion: e.GlobalVersion(),
+		Reason:        e.Reason(),
+		Timestamp:     e.Timestamp(),
+		SchemaVersion: e.SchemaVersion(),
+		Data:          e.Data(),
+		Metadata:      e.Metadata(),
+	})
+}
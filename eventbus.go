@@ -0,0 +1,63 @@
+package eventsourcing
+
+import (
+	"context"
+	"sync"
+)
+
+// EventHandler reacts on an event published on the event bus
+type EventHandler func(ctx context.Context, event Event)
+
+// EventBus passes saved events synchronously to the handlers subscribing to them within the process. It lets other
+// parts of the application react on events without polling the event store. Use projections for handlers that can't
+// miss events, events published on the bus are lost if the process stops.
+type EventBus struct {
+	lock          sync.RWMutex
+	handlers      []EventHandler
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewEventBus creates an event bus without subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// SubscribeAll adds a handler getting all events published on the bus
+func (b *EventBus) SubscribeAll(handler EventHandler) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Subscribe adds a handler getting the events with data of type T published on the bus
+//
+//	eventsourcing.Subscribe(bus, func(ctx context.Context, event eventsourcing.Event, born *Born) {
+//		welcome(born.Name)
+//	})
+func Subscribe[T any](b *EventBus, handler func(ctx context.Context, event Event, data T)) {
+	b.SubscribeAll(func(ctx context.Context, event Event) {
+		if data, ok := event.Data().(T); ok {
+			handler(ctx, event, data)
+		}
+	})
+}
+
+// Publish calls the subscribing handlers with the events in the order the events and handlers are passed and sends
+// the events to the matching subscriptions. The call returns when all handlers are done.
+func (b *EventBus) Publish(ctx context.Context, events ...Event) {
+	b.lock.RLock()
+	handlers := b.handlers
+	subscriptions := make([]*Subscription, 0, len(b.subscriptions))
+	for s := range b.subscriptions {
+		subscriptions = append(subscriptions, s)
+	}
+	b.lock.RUnlock()
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(ctx, event)
+		}
+		for _, s := range subscriptions {
+			s.send(ctx, event)
+		}
+	}
+}
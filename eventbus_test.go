@@ -0,0 +1,34 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+)
+
+func TestEventBus(t *testing.T) {
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+
+	bus := eventsourcing.NewEventBus()
+	var all []string
+	bus.SubscribeAll(func(ctx context.Context, event eventsourcing.Event) {
+		all = append(all, event.Reason())
+	})
+	var names []string
+	eventsourcing.Subscribe(bus, func(ctx context.Context, event eventsourcing.Event, born *Born) {
+		names = append(names, born.Name)
+	})
+
+	bus.Publish(context.Background(), person.Events()...)
+	if len(all) != 2 || all[0] != "Born" || all[1] != "AgedOneYear" {
+		t.Fatalf("expected Born and AgedOneYear was %v", all)
+	}
+	if len(names) != 1 || names[0] != "kalle" {
+		t.Fatalf("expected the born kalle was %v", names)
+	}
+}
@@ -2,7 +2,9 @@ package eventsourcing
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/internal"
 )
 
@@ -27,19 +29,103 @@ var (
 
 	// ErrUnsavedEvents aggregate events must be saved before creating snapshot
 	ErrUnsavedEvents = errors.New("aggregate holds unsaved events")
+
+	// ErrSnapshotOutdated returned from a snapshot upcaster to discard a snapshot with an older schema version
+	ErrSnapshotOutdated = errors.New("snapshot schema version is outdated")
+
+	// ErrAggregateDeleted returned when loading an aggregate that is deleted
+	ErrAggregateDeleted = errors.New("aggregate deleted")
+
+	// ErrCommandNotHandled returned when a command is dispatched without a registered handler
+	ErrCommandNotHandled = errors.New("command not handled")
+
+	// ErrInvalidAggregateID returned when the aggregate id is rejected by the id validators of the aggregate type
+	ErrInvalidAggregateID = errors.New("invalid aggregate id")
+
+	// ErrInvalidCursor returned when a cursor can't be decoded or belongs to another event stream
+	ErrInvalidCursor = errors.New("invalid cursor")
 )
 
+// payloadSnippetSize is the max number of bytes of the raw payload included in a DeserializationError
+const payloadSnippetSize = 64
+
+// DeserializationError is returned when the data or metadata of a stored event can't be deserialized.
+// It holds the context of the event making it possible to find the event that broke.
+type DeserializationError struct {
+	AggregateType string
+	AggregateID   string
+	Version       Version
+	GlobalVersion Version
+	Reason        string
+	Payload       string // the beginning of the raw payload that could not be deserialized
+	Err           error
+}
+
+func newDeserializationError(event core.Event, payload []byte, err error) *DeserializationError {
+	if len(payload) > payloadSnippetSize {
+		payload = payload[:payloadSnippetSize]
+	}
+	return &DeserializationError{
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Version:       Version(event.Version),
+		GlobalVersion: Version(event.GlobalVersion),
+		Reason:        event.Reason,
+		Payload:       string(payload),
+		Err:           err,
+	}
+}
+
+func (e *DeserializationError) Error() string {
+	return fmt.Sprintf("could not deserialize event aggregate type: %s, id: %s, version: %d, reason: %s, payload: %q, %v", e.AggregateType, e.AggregateID, e.Version, e.Reason, e.Payload, e.Err)
+}
+
+// Unwrap returns the underlying encoder error
+func (e *DeserializationError) Unwrap() error {
+	return e.Err
+}
+
+// UnhandledEventError is returned when the Transition method of the aggregate marks an event as not handled via
+// Root.Unhandled. It catches event types that were added without a case in Transition.
+type UnhandledEventError struct {
+	AggregateType string
+	AggregateID   string
+	Version       Version
+	Reason        string
+}
+
+func (e *UnhandledEventError) Error() string {
+	return fmt.Sprintf("event not handled by aggregate type: %s, id: %s, version: %d, reason: %s", e.AggregateType, e.AggregateID, e.Version, e.Reason)
+}
+
 // Encoder is the interface used to Serialize/Deserialize events and snapshots
 type Encoder interface {
 	Serialize(v interface{}) ([]byte, error)
 	Deserialize(data []byte, v interface{}) error
 }
 
+// EncoderJSON is the default encoder based on encoding/json, useful when wrapping the default encoder. Set Strict to
+// fail the deserialization of events with fields that are not in the event struct.
+type EncoderJSON = internal.EncoderJSON
+
+// UnknownFieldError is returned by the strict EncoderJSON, wrapped in a DeserializationError when loading events
+type UnknownFieldError = internal.UnknownFieldError
+
+// Tombstone is the event appended when an aggregate is deleted. It's registered on every aggregate and reaches the
+// projections like any other event, making it possible to react on removed aggregates.
+type Tombstone = internal.Tombstone
+
 // SetEventEncoder change the default JSON encoder that serialize/deserialize events
 func SetEventEncoder(e Encoder) {
 	internal.EventEncoder = e
 }
 
+// SetAggregateEventEncoder sets the encoder for the events of one aggregate type, overriding the encoder set by
+// SetEventEncoder. The aggregate type is the name of the aggregate struct.
+func SetAggregateEventEncoder(aggregateType string, e Encoder) {
+	internal.SetAggregateEventEncoder(aggregateType, e)
+}
+
 // SetSnapshotEncoder change the default JSON encoder that seialize/deserialize snapshots
 func SetSnapshotEncoder(e Encoder) {
 	internal.SnapshotEncoder = e
@@ -14,6 +14,8 @@ import (
 
 const (
 	globalEventOrderBucketName = "global_event_order"
+	categoriesBucketName       = "categories"   // holds a bucket per aggregate type with the events in category order
+	correlationsBucketName     = "correlations" // holds a bucket per correlation id with the events in global order
 )
 
 // BBolt is the eventstore handler
@@ -30,6 +32,10 @@ type boltEvent struct {
 	Timestamp     time.Time
 	Data          []byte
 	Metadata      []byte // map[string]interface{}
+	SchemaVersion uint   `json:",omitempty"`
+
+	CategoryVersion uint64 `json:",omitempty"`
+	CorrelationID   string `json:",omitempty"`
 }
 
 // MustOpenBBolt opens the event stream found in the given file. If the file is not found it will be created and
@@ -47,7 +53,10 @@ func MustOpenBBolt(dbFile string) *BBolt {
 		if _, err := tx.CreateBucketIfNotExists([]byte(globalEventOrderBucketName)); err != nil {
 			return errors.New("could not create global event order bucket")
 		}
-		return nil
+		if _, err := tx.CreateBucketIfNotExists([]byte(correlationsBucketName)); err != nil {
+			return errors.New("could not create correlations bucket")
+		}
+		return migrateCategories(tx)
 	})
 	if err != nil {
 		panic(err)
@@ -63,22 +72,44 @@ func (e *BBolt) Save(events []core.Event) error {
 	if len(events) == 0 {
 		return nil
 	}
+	return e.SaveBatch(context.Background(), [][]core.Event{events})
+}
 
-	// get bucket name from first event
-	aggregateType := events[0].AggregateType
-	aggregateID := events[0].AggregateID
-	bucketRef := bucketRef(aggregateType, aggregateID)
-
+// SaveBatch saves the events of several aggregates in one transaction, either all events are saved or none
+func (e *BBolt) SaveBatch(ctx context.Context, batches [][]core.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	tx, err := e.db.Begin(true)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	for _, events := range batches {
+		err = e.saveTx(tx, events)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// saveTx saves the events of one aggregate in the transaction
+func (e *BBolt) saveTx(tx *bbolt.Tx, events []core.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	// get bucket name from first event
+	aggregateType := events[0].AggregateType
+	aggregateID := events[0].AggregateID
+	bucketRef := bucketRef(aggregateType, aggregateID)
+
 	evBucket := tx.Bucket(bucketRef)
 	if evBucket == nil {
 		// Ensure that we have a bucket named events_aggregateType_aggregateID for the given aggregate
-		err = e.createBucket(bucketRef, tx)
+		err := e.createBucket(bucketRef, tx)
 		if err != nil {
 			return errors.New("could not create aggregate events bucket")
 		}
@@ -107,6 +138,11 @@ func (e *BBolt) Save(events []core.Event) error {
 		return errors.New("global bucket not found")
 	}
 
+	category, err := categoryBucket(tx, aggregateType)
+	if err != nil {
+		return err
+	}
+
 	var globalSequence uint64
 	for i, event := range events {
 		sequence, err := evBucket.NextSequence()
@@ -122,6 +158,11 @@ func (e *BBolt) Save(events []core.Event) error {
 			return errors.New("could not get next sequence for global bucket")
 		}
 
+		categorySequence, err := category.NextSequence()
+		if err != nil {
+			return fmt.Errorf("could not get next sequence for the category %s", aggregateType)
+		}
+
 		// build the internal bolt event
 		bEvent := boltEvent{
 			AggregateID:   event.AggregateID,
@@ -132,6 +173,10 @@ func (e *BBolt) Save(events []core.Event) error {
 			Timestamp:     event.Timestamp,
 			Metadata:      event.Metadata,
 			Data:          event.Data,
+			SchemaVersion: event.SchemaVersion,
+
+			CategoryVersion: categorySequence,
+			CorrelationID:   event.CorrelationID,
 		}
 
 		value, err := json.Marshal(bEvent)
@@ -147,11 +192,25 @@ func (e *BBolt) Save(events []core.Event) error {
 		if err != nil {
 			return errors.New(fmt.Sprintf("could not save global sequence pointer for %#v", string(bucketRef)))
 		}
+		err = category.Put(itob(categorySequence), value)
+		if err != nil {
+			return fmt.Errorf("could not save the event in the category %s", aggregateType)
+		}
+		if event.CorrelationID != "" {
+			correlation, err := correlationBucket(tx, event.CorrelationID)
+			if err != nil {
+				return err
+			}
+			err = correlation.Put(itob(globalSequence), value)
+			if err != nil {
+				return fmt.Errorf("could not save the event in the correlation %s", event.CorrelationID)
+			}
+		}
 
 		// override the event in the slice exposing the GlobalVersion to the caller
 		events[i].GlobalVersion = core.Version(globalSequence)
 	}
-	return tx.Commit()
+	return nil
 }
 
 // Get aggregate events
@@ -170,8 +229,195 @@ func (e *BBolt) Get(ctx context.Context, id string, aggregateType string, afterV
 	return &iterator{tx: tx, cursor: cursor, startPosition: position(afterVersion)}, nil
 }
 
+// LatestVersion returns the version of the last aggregate event, zero if the aggregate has no events
+func (e *BBolt) LatestVersion(ctx context.Context, id string, aggregateType string) (core.Version, error) {
+	var version core.Version
+	err := e.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(bucketRef(aggregateType, id))
+		if bucket == nil {
+			return nil
+		}
+		// the aggregate bucket sequence is the same as the event version
+		k, _ := bucket.Cursor().Last()
+		if k != nil {
+			version = core.Version(binary.BigEndian.Uint64(k))
+		}
+		return nil
+	})
+	return version, err
+}
+
+// GlobalVersion returns the global version of the last saved event, zero if no events are saved
+func (e *BBolt) GlobalVersion(ctx context.Context) (core.Version, error) {
+	var head core.Version
+	err := e.db.View(func(tx *bbolt.Tx) error {
+		globalBucket := tx.Bucket([]byte(globalEventOrderBucketName))
+		if globalBucket == nil {
+			return errors.New("global bucket not found")
+		}
+		// the global bucket sequence is the global version of the last saved event
+		head = core.Version(globalBucket.Sequence())
+		return nil
+	})
+	return head, err
+}
+
+// GetPage returns a page of the aggregate events newest-first. The offset is the number of events to skip from
+// the end of the stream and limit the max number of events in the page. The total number of events in the
+// aggregate event stream is returned next to the iterator.
+func (e *BBolt) GetPage(ctx context.Context, id string, aggregateType string, offset, limit uint64) (core.Iterator, uint64, error) {
+	tx, err := e.db.Begin(false)
+	if err != nil {
+		return nil, 0, err
+	}
+	bucket := tx.Bucket(bucketRef(aggregateType, id))
+	if bucket == nil {
+		tx.Rollback()
+		// no aggregate event stream
+		return core.ZeroIterator{}, 0, nil
+	}
+	cursor := bucket.Cursor()
+
+	// the aggregate bucket sequence is the same as the event version
+	var total uint64
+	k, _ := cursor.Last()
+	if k != nil {
+		total = binary.BigEndian.Uint64(k)
+	}
+	if offset >= total || limit == 0 {
+		tx.Rollback()
+		return core.ZeroIterator{}, total, nil
+	}
+	// the page starts offset versions below the last event
+	return descending(tx, cursor, core.Version(total-offset+1), limit), total, nil
+}
+
+// Category returns at most count events of the aggregate type with a category version after the position, in category
+// order
+func (e *BBolt) Category(ctx context.Context, aggregateType string, after core.Version, count uint64) (core.Iterator, error) {
+	if count == 0 {
+		return core.ZeroIterator{}, nil
+	}
+	tx, err := e.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	var bucket *bbolt.Bucket
+	if categories := tx.Bucket([]byte(categoriesBucketName)); categories != nil {
+		bucket = categories.Bucket([]byte(aggregateType))
+	}
+	if bucket == nil {
+		tx.Rollback()
+		// no events of the aggregate type
+		return core.ZeroIterator{}, nil
+	}
+	return &iterator{tx: tx, cursor: bucket.Cursor(), startPosition: position(after), limit: count}, nil
+}
+
+// ByCorrelationID returns the events with the correlation id in GlobalEvents order
+func (e *BBolt) ByCorrelationID(ctx context.Context, correlationID string) (core.Iterator, error) {
+	tx, err := e.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	var bucket *bbolt.Bucket
+	if correlations := tx.Bucket([]byte(correlationsBucketName)); correlations != nil {
+		bucket = correlations.Bucket([]byte(correlationID))
+	}
+	if bucket == nil {
+		tx.Rollback()
+		// no events with the correlation id
+		return core.ZeroIterator{}, nil
+	}
+	return &iterator{tx: tx, cursor: bucket.Cursor(), startPosition: position(0)}, nil
+}
+
+// GetDescending returns at most count aggregate events with a version lower than before newest first, a before of
+// zero starts at the last event
+func (e *BBolt) GetDescending(ctx context.Context, id string, aggregateType string, before core.Version, count uint64) (core.Iterator, error) {
+	if count == 0 {
+		return core.ZeroIterator{}, nil
+	}
+	tx, err := e.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	bucket := tx.Bucket(bucketRef(aggregateType, id))
+	if bucket == nil {
+		tx.Rollback()
+		// no aggregate event stream
+		return core.ZeroIterator{}, nil
+	}
+	return descending(tx, bucket.Cursor(), before, count), nil
+}
+
+// descending iterates over at most count events in the cursor with a key lower than before newest first, a before of
+// zero starts at the last key
+func descending(tx *bbolt.Tx, cursor *bbolt.Cursor, before core.Version, count uint64) core.Iterator {
+	return &iterator{tx: tx, cursor: cursor, startPosition: beforePosition(before), reverse: true, before: true, limit: count}
+}
+
+// LastEvent returns the newest event of the aggregate, core.ErrNoEvents if the aggregate has no events
+func (e *BBolt) LastEvent(ctx context.Context, id string, aggregateType string) (core.Event, error) {
+	iterator, err := e.GetDescending(ctx, id, aggregateType, 0, 1)
+	if err != nil {
+		return core.Event{}, err
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		return core.Event{}, core.ErrNoEvents
+	}
+	return iterator.Value()
+}
+
+// AllDescending returns at most count events with a global version lower than before newest first, a before of zero
+// starts at the last saved event
+func (e *BBolt) AllDescending(ctx context.Context, before core.Version, count uint64) (core.Iterator, error) {
+	if count == 0 {
+		return core.ZeroIterator{}, nil
+	}
+	tx, err := e.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	globalBucket := tx.Bucket([]byte(globalEventOrderBucketName))
+	return &iterator{tx: tx, cursor: globalBucket.Cursor(), startPosition: beforePosition(before), reverse: true, before: true, limit: count}, nil
+}
+
+// beforePosition returns the key the descending iterators start before, nil starts at the last key
+func beforePosition(before core.Version) []byte {
+	if before == 0 {
+		return nil
+	}
+	return itob(uint64(before))
+}
+
 // All iterate over event in GlobalEvents order
 func (e *BBolt) All(start uint64) (core.Iterator, error) {
+	return e.AllWithFilter(start, core.Filter{})
+}
+
+// AllByType iterate over the events of the aggregate types in GlobalEvents order
+func (e *BBolt) AllByType(start uint64, aggregateTypes ...string) (core.Iterator, error) {
+	return e.AllWithFilter(start, core.ByType(aggregateTypes...))
+}
+
+// AllByReason iterate over the events with any of the reasons in GlobalEvents order
+func (e *BBolt) AllByReason(start uint64, reasons ...string) (core.Iterator, error) {
+	return e.AllWithFilter(start, core.ByReason(reasons...))
+}
+
+// Between iterate over the events with a timestamp after from and before to in GlobalEvents order
+func (e *BBolt) Between(ctx context.Context, from, to time.Time) (core.Iterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.AllWithFilter(0, core.Filter{}.After(from).Before(to))
+}
+
+// AllWithFilter iterate over the events matching the filter in GlobalEvents order. The events are matched while
+// iterating the global bucket, the metadata part of the filter is not evaluated as the metadata is serialized.
+func (e *BBolt) AllWithFilter(start uint64, filter core.Filter) (core.Iterator, error) {
 	tx, err := e.db.Begin(false)
 	if err != nil {
 		return nil, err
@@ -180,7 +426,64 @@ func (e *BBolt) All(start uint64) (core.Iterator, error) {
 	globalBucket := tx.Bucket([]byte(globalEventOrderBucketName))
 	cursor := globalBucket.Cursor()
 
-	return &iterator{tx: tx, cursor: cursor, startPosition: position(core.Version(start))}, nil
+	return &iterator{tx: tx, cursor: cursor, startPosition: position(core.Version(start)), filter: filter}, nil
+}
+
+// DeleteEvents removes the aggregate events with a version lower than before from the aggregate and global buckets
+func (e *BBolt) DeleteEvents(ctx context.Context, id string, aggregateType string, before core.Version) error {
+	return e.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(bucketRef(aggregateType, id))
+		if bucket == nil {
+			return nil
+		}
+		globalBucket := tx.Bucket([]byte(globalEventOrderBucketName))
+		if globalBucket == nil {
+			return errors.New("global bucket not found")
+		}
+
+		category, err := categoryBucket(tx, aggregateType)
+		if err != nil {
+			return err
+		}
+
+		// collect the keys before deleting as the cursor is not stable when deleting while iterating
+		var keys, globalKeys, categoryKeys [][]byte
+		var correlationIDs []string
+		cursor := bucket.Cursor()
+		for k, obj := cursor.First(); k != nil && binary.BigEndian.Uint64(k) < uint64(before); k, obj = cursor.Next() {
+			event := boltEvent{}
+			err := json.Unmarshal(obj, &event)
+			if err != nil {
+				return fmt.Errorf("could not deserialize event, %v", err)
+			}
+			keys = append(keys, k)
+			globalKeys = append(globalKeys, itob(event.GlobalVersion))
+			categoryKeys = append(categoryKeys, itob(event.CategoryVersion))
+			correlationIDs = append(correlationIDs, event.CorrelationID)
+		}
+		for i := range keys {
+			if err := bucket.Delete(keys[i]); err != nil {
+				return err
+			}
+			if err := globalBucket.Delete(globalKeys[i]); err != nil {
+				return err
+			}
+			if err := category.Delete(categoryKeys[i]); err != nil {
+				return err
+			}
+			if correlationIDs[i] == "" {
+				continue
+			}
+			correlation, err := correlationBucket(tx, correlationIDs[i])
+			if err != nil {
+				return err
+			}
+			if err := correlation.Delete(globalKeys[i]); err != nil {
+				return err
+			}
+		}
+		return ctx.Err()
+	})
 }
 
 // Close closes the event stream and the underlying database
@@ -188,6 +491,88 @@ func (e *BBolt) Close() error {
 	return e.db.Close()
 }
 
+// categoryBucket returns the bucket of the category stream of the aggregate type, it's created if it doesn't exist
+func categoryBucket(tx *bbolt.Tx, aggregateType string) (*bbolt.Bucket, error) {
+	categories := tx.Bucket([]byte(categoriesBucketName))
+	if categories == nil {
+		return nil, errors.New("categories bucket not found")
+	}
+	bucket, err := categories.CreateBucketIfNotExists([]byte(aggregateType))
+	if err != nil {
+		return nil, fmt.Errorf("could not create the category bucket for %s: %w", aggregateType, err)
+	}
+	return bucket, nil
+}
+
+// correlationBucket returns the bucket of the events with the correlation id, it's created if it doesn't exist
+func correlationBucket(tx *bbolt.Tx, correlationID string) (*bbolt.Bucket, error) {
+	correlations := tx.Bucket([]byte(correlationsBucketName))
+	if correlations == nil {
+		return nil, errors.New("correlations bucket not found")
+	}
+	bucket, err := correlations.CreateBucketIfNotExists([]byte(correlationID))
+	if err != nil {
+		return nil, fmt.Errorf("could not create the correlation bucket for %s: %w", correlationID, err)
+	}
+	return bucket, nil
+}
+
+// migrateCategories creates the categories bucket. Events saved before the category streams existed are added to the
+// category stream of their aggregate type in global order.
+func migrateCategories(tx *bbolt.Tx) error {
+	if tx.Bucket([]byte(categoriesBucketName)) != nil {
+		return nil
+	}
+	_, err := tx.CreateBucket([]byte(categoriesBucketName))
+	if err != nil {
+		return errors.New("could not create categories bucket")
+	}
+	globalBucket := tx.Bucket([]byte(globalEventOrderBucketName))
+
+	// collect the events before they are updated as the cursor is not stable when writing while iterating
+	var events []boltEvent
+	err = globalBucket.ForEach(func(k, obj []byte) error {
+		event := boltEvent{}
+		err := json.Unmarshal(obj, &event)
+		if err != nil {
+			return fmt.Errorf("could not deserialize event, %v", err)
+		}
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		category, err := categoryBucket(tx, event.AggregateType)
+		if err != nil {
+			return err
+		}
+		event.CategoryVersion, err = category.NextSequence()
+		if err != nil {
+			return err
+		}
+		value, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if err = category.Put(itob(event.CategoryVersion), value); err != nil {
+			return err
+		}
+		if err = globalBucket.Put(itob(event.GlobalVersion), value); err != nil {
+			return err
+		}
+		aggregateBucket := tx.Bucket(bucketRef(event.AggregateType, event.AggregateID))
+		if aggregateBucket == nil {
+			return fmt.Errorf("aggregate bucket of %s %s not found", event.AggregateType, event.AggregateID)
+		}
+		if err = aggregateBucket.Put(itob(event.Version), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CreateBucket creates a bucket
 func (e *BBolt) createBucket(bucketRef []byte, tx *bbolt.Tx) error {
 	// Ensure that we have a bucket named event_type for the given type
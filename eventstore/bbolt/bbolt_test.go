@@ -1,8 +1,10 @@
 package bbolt_test
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/core/testsuite"
@@ -20,3 +22,263 @@ func TestSuite(t *testing.T) {
 	}
 	testsuite.Test(t, f)
 }
+
+func TestEventDeleter(t *testing.T) {
+	f := func() (core.EventStore, core.EventDeleter, func(), error) {
+		dbFile := "bolt_delete.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestEventDeleter(t, f)
+}
+
+func TestBatchSaver(t *testing.T) {
+	f := func() (core.EventStore, core.BatchSaver, func(), error) {
+		dbFile := "bolt_batch.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestBatchSaver(t, f)
+}
+
+func TestVersionReader(t *testing.T) {
+	f := func() (core.EventStore, core.VersionReader, func(), error) {
+		dbFile := "bolt_version.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestVersionReader(t, f)
+}
+
+func TestReverseReader(t *testing.T) {
+	f := func() (core.EventStore, core.ReverseReader, func(), error) {
+		dbFile := "bolt_reverse.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestReverseReader(t, f)
+}
+
+func TestTimeRangeReader(t *testing.T) {
+	f := func() (core.EventStore, core.TimeRangeReader, func(), error) {
+		dbFile := "bolt_time.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestTimeRangeReader(t, f)
+}
+
+func TestLastEventReader(t *testing.T) {
+	f := func() (core.EventStore, core.LastEventReader, func(), error) {
+		dbFile := "bolt_last.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestLastEventReader(t, f)
+}
+
+func TestCorrelationReader(t *testing.T) {
+	f := func() (core.EventStore, core.CorrelationReader, func(), error) {
+		dbFile := "bolt_correlation.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestCorrelationReader(t, f)
+}
+
+func TestGlobalVersionReader(t *testing.T) {
+	f := func() (core.EventStore, core.GlobalVersionReader, func(), error) {
+		dbFile := "bolt_head.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestGlobalVersionReader(t, f)
+}
+
+func TestPager(t *testing.T) {
+	f := func() (core.EventStore, core.Pager, func(), error) {
+		dbFile := "bolt_page.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestPager(t, f)
+}
+
+func TestAllWithFilter(t *testing.T) {
+	dbFile := "bolt.db"
+	es := bbolt.MustOpenBBolt(dbFile)
+	defer func() {
+		es.Close()
+		os.Remove(dbFile)
+	}()
+
+	now := time.Now()
+	err := es.Save([]core.Event{
+		{AggregateID: "filter", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: now.Add(-time.Hour)},
+		{AggregateID: "filter", AggregateType: "Person", Version: 2, Reason: "AgedOneYear", Timestamp: now},
+		{AggregateID: "filter", AggregateType: "Person", Version: 3, Reason: "Born", Timestamp: now},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{{AggregateID: "filter", AggregateType: "Order", Version: 1, Reason: "Born", Timestamp: now}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := es.AllWithFilter(0, core.ByType("Person").ByReason("Born").After(now.Add(-time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected an event")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Version != 3 || event.AggregateType != "Person" {
+		t.Fatalf("expected the Person event with version 3 was %s version %d", event.AggregateType, event.Version)
+	}
+	if iterator.Next() {
+		t.Fatal("expected only one event to match the filter")
+	}
+}
+
+func TestAllByType(t *testing.T) {
+	dbFile := "bolt.db"
+	es := bbolt.MustOpenBBolt(dbFile)
+	defer func() {
+		es.Close()
+		os.Remove(dbFile)
+	}()
+
+	err := es.Save([]core.Event{{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{{AggregateID: "123", AggregateType: "Order", Version: 1, Reason: "Created", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := es.AllByType(0, "Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected the Order event")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.AggregateType != "Order" {
+		t.Fatalf("expected aggregate type Order was %s", event.AggregateType)
+	}
+	if iterator.Next() {
+		t.Fatal("expected only the Order event")
+	}
+}
+
+func TestAllByReason(t *testing.T) {
+	dbFile := "bolt.db"
+	es := bbolt.MustOpenBBolt(dbFile)
+	defer func() {
+		es.Close()
+		os.Remove(dbFile)
+	}()
+
+	err := es.Save([]core.Event{
+		{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: time.Now()},
+		{AggregateID: "123", AggregateType: "Person", Version: 2, Reason: "AgedOneYear", Timestamp: time.Now()},
+		{AggregateID: "123", AggregateType: "Person", Version: 3, Reason: "Deceased", Timestamp: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := es.AllByReason(0, "Born", "Deceased")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	var reasons []string
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		reasons = append(reasons, event.Reason)
+	}
+	if len(reasons) != 2 || reasons[0] != "Born" || reasons[1] != "Deceased" {
+		t.Fatalf("expected the Born and Deceased events was %v", reasons)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	dbFile := "bolt.db"
+	es := bbolt.MustOpenBBolt(dbFile)
+	defer func() {
+		es.Close()
+		os.Remove(dbFile)
+	}()
+
+	now := time.Now()
+	err := es.Save([]core.Event{
+		{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: now.Add(-2 * time.Hour)},
+		{AggregateID: "123", AggregateType: "Person", Version: 2, Reason: "AgedOneYear", Timestamp: now.Add(-time.Hour)},
+		{AggregateID: "123", AggregateType: "Person", Version: 3, Reason: "AgedOneYear", Timestamp: now},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := es.Between(context.Background(), now.Add(-90*time.Minute), now.Add(-30*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected an event in the time range")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Version != 2 {
+		t.Fatalf("expected the event with version 2 was %d", event.Version)
+	}
+	if iterator.Next() {
+		t.Fatal("expected only one event in the time range")
+	}
+}
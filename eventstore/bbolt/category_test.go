@@ -0,0 +1,81 @@
+package bbolt_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/eventstore/bbolt"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestCategoryReader(t *testing.T) {
+	f := func() (core.EventStore, core.CategoryReader, func(), error) {
+		dbFile := "bolt_category.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestCategoryReader(t, f)
+}
+
+func TestMigrateCategories(t *testing.T) {
+	dbFile := "bolt_migrate_category.db"
+	defer os.Remove(dbFile)
+	es := bbolt.MustOpenBBolt(dbFile)
+	err := es.Save([]core.Event{{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{{AggregateID: "123", AggregateType: "Order", Version: 1, Reason: "Created", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{{AggregateID: "123", AggregateType: "Person", Version: 2, Reason: "AgedOneYear", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	es.Close()
+
+	// remove the category streams as in a database from before they existed
+	db, err := bolt.Open(dbFile, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket([]byte("categories"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	es = bbolt.MustOpenBBolt(dbFile)
+	defer es.Close()
+	err = es.Save([]core.Event{{AggregateID: "456", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err := es.Category(context.Background(), "Person", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	var versions []string
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, fmt.Sprintf("%s:%d", event.AggregateID, event.CategoryVersion))
+	}
+	if fmt.Sprint(versions) != "[123:1 123:2 456:3]" {
+		t.Fatalf("expected the migrated events before the new event was %v", versions)
+	}
+}
@@ -0,0 +1,66 @@
+package bbolt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+)
+
+// CountAll returns the number of events in the global event order
+func (e *BBolt) CountAll(ctx context.Context) (uint64, error) {
+	var count uint64
+	err := e.db.View(func(tx *bbolt.Tx) error {
+		globalBucket := tx.Bucket([]byte(globalEventOrderBucketName))
+		if globalBucket == nil {
+			return errors.New("global bucket not found")
+		}
+		count = uint64(globalBucket.Stats().KeyN)
+		return nil
+	})
+	return count, err
+}
+
+// CountAggregate returns the number of events in the aggregate bucket
+func (e *BBolt) CountAggregate(ctx context.Context, id string, aggregateType string) (uint64, error) {
+	var count uint64
+	err := e.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(bucketRef(aggregateType, id))
+		if bucket == nil {
+			// no aggregate event stream
+			return nil
+		}
+		count = uint64(bucket.Stats().KeyN)
+		return nil
+	})
+	return count, err
+}
+
+// CountByReason returns the number of events with the reason. The reason is not indexed, the events in the global
+// event order are read to count them.
+func (e *BBolt) CountByReason(ctx context.Context, reason string) (uint64, error) {
+	var count uint64
+	err := e.db.View(func(tx *bbolt.Tx) error {
+		globalBucket := tx.Bucket([]byte(globalEventOrderBucketName))
+		if globalBucket == nil {
+			return errors.New("global bucket not found")
+		}
+		return globalBucket.ForEach(func(k, obj []byte) error {
+			// only the reason is decoded
+			var event struct {
+				Reason string
+			}
+			err := json.Unmarshal(obj, &event)
+			if err != nil {
+				return fmt.Errorf("could not deserialize event, %v", err)
+			}
+			if event.Reason == reason {
+				count++
+			}
+			return ctx.Err()
+		})
+	})
+	return count, err
+}
@@ -0,0 +1,22 @@
+package bbolt_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/eventstore/bbolt"
+)
+
+func TestEventCounter(t *testing.T) {
+	f := func() (core.EventStore, core.EventCounter, func(), error) {
+		dbFile := "bolt_count.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestEventCounter(t, f)
+}
@@ -0,0 +1,31 @@
+package bbolt
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/hallgren/eventsourcing/core"
+	"go.etcd.io/bbolt"
+)
+
+// Health reads the global version of the last saved event, the head of the global event stream
+func (e *BBolt) Health(ctx context.Context) core.Health {
+	health := core.Health{Name: "bbolt"}
+	var head uint64
+	err := e.db.View(func(tx *bbolt.Tx) error {
+		globalBucket := tx.Bucket([]byte(globalEventOrderBucketName))
+		if globalBucket == nil {
+			return errors.New("global bucket not found")
+		}
+		head = globalBucket.Sequence()
+		return nil
+	})
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	health.Healthy = true
+	health.Details = map[string]string{"head": strconv.FormatUint(head, 10)}
+	return health
+}
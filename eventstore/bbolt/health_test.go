@@ -0,0 +1,34 @@
+package bbolt_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/eventstore/bbolt"
+)
+
+func TestHealthChecker(t *testing.T) {
+	f := func() (core.EventStore, core.HealthChecker, func(), error) {
+		dbFile := "bolt_health.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestHealthChecker(t, f)
+}
+
+func TestHealthClosed(t *testing.T) {
+	dbFile := "bolt_health_closed.db"
+	es := bbolt.MustOpenBBolt(dbFile)
+	defer os.Remove(dbFile)
+	es.Close()
+	health := es.Health(context.Background())
+	if health.Healthy || health.Error == "" {
+		t.Fatalf("expected unhealthy event store on a closed database got %+v", health)
+	}
+}
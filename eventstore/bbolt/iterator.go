@@ -14,6 +14,11 @@ type iterator struct {
 	cursor        *bbolt.Cursor
 	startPosition []byte
 	value         []byte
+	reverse       bool   // step backwards from the start position
+	before        bool   // start at the last key lower than the start position, a nil start position starts at the last key
+	limit         uint64 // max number of events to iterate, zero is no limit
+	count         uint64
+	filter        core.Filter
 }
 
 // Close closes the iterator
@@ -22,19 +27,70 @@ func (i *iterator) Close() {
 }
 
 func (i *iterator) Next() bool {
+	if i.limit > 0 && i.count >= i.limit {
+		return false
+	}
 	// first time Next is called go to the start position
 	if i.value == nil {
-		_, i.value = i.cursor.Seek(i.startPosition)
+		i.value = i.start()
 	} else {
-		_, i.value = i.cursor.Next()
+		i.step()
+	}
+
+	// step over events not matching the filter
+	for i.value != nil && !i.match() {
+		i.step()
 	}
 
 	if i.value == nil {
 		return false
 	}
+	i.count++
 	return true
 }
 
+// start moves the cursor to the first event of the iterator
+func (i *iterator) start() []byte {
+	if !i.before {
+		_, value := i.cursor.Seek(i.startPosition)
+		return value
+	}
+	if i.startPosition == nil {
+		_, value := i.cursor.Last()
+		return value
+	}
+	k, value := i.cursor.Seek(i.startPosition)
+	if k == nil {
+		// all keys are lower than the start position
+		_, value = i.cursor.Last()
+		return value
+	}
+	_, value = i.cursor.Prev()
+	return value
+}
+
+// step moves the cursor one step in the iterator direction
+func (i *iterator) step() {
+	if i.reverse {
+		_, i.value = i.cursor.Prev()
+	} else {
+		_, i.value = i.cursor.Next()
+	}
+}
+
+// match returns true if the current value match the iterator filter
+func (i *iterator) match() bool {
+	if i.filter.IsZero() {
+		return true
+	}
+	event, err := i.Value()
+	if err != nil {
+		// let the Value method return the error to the caller
+		return true
+	}
+	return i.filter.Match(event)
+}
+
 // Next return the next event
 func (i *iterator) Value() (core.Event, error) {
 	bEvent := boltEvent{}
@@ -52,6 +108,10 @@ func (i *iterator) Value() (core.Event, error) {
 		Metadata:      bEvent.Metadata,
 		Data:          bEvent.Data,
 		Reason:        bEvent.Reason,
+		SchemaVersion: bEvent.SchemaVersion,
+
+		CategoryVersion: core.Version(bEvent.CategoryVersion),
+		CorrelationID:   bEvent.CorrelationID,
 	}
 	return event, nil
 }
@@ -0,0 +1,40 @@
+package bbolt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hallgren/eventsourcing/core"
+	"go.etcd.io/bbolt"
+)
+
+// Stats counts the events per aggregate type from the global event order and reads the size of the database
+func (e *BBolt) Stats(ctx context.Context) (core.Stats, error) {
+	stats := core.Stats{
+		AggregateTypes: make(map[string]uint64),
+	}
+	err := e.db.View(func(tx *bbolt.Tx) error {
+		globalBucket := tx.Bucket([]byte(globalEventOrderBucketName))
+		if globalBucket == nil {
+			return errors.New("global bucket not found")
+		}
+		stats.Head = core.Version(globalBucket.Sequence())
+		stats.Size = tx.Size()
+		return globalBucket.ForEach(func(k, obj []byte) error {
+			// only the aggregate type is decoded
+			var event struct {
+				AggregateType string
+			}
+			err := json.Unmarshal(obj, &event)
+			if err != nil {
+				return fmt.Errorf("could not deserialize event, %v", err)
+			}
+			stats.Events++
+			stats.AggregateTypes[event.AggregateType]++
+			return ctx.Err()
+		})
+	})
+	return stats, err
+}
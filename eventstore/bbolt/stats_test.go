@@ -0,0 +1,22 @@
+package bbolt_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/eventstore/bbolt"
+)
+
+func TestStatsReader(t *testing.T) {
+	f := func() (core.EventStore, core.StatsReader, func(), error) {
+		dbFile := "bolt_stats.db"
+		es := bbolt.MustOpenBBolt(dbFile)
+		return es, es, func() {
+			es.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestStatsReader(t, f)
+}
@@ -0,0 +1,426 @@
+package couchbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+const (
+	globalKey     = "global"
+	streamPrefix  = "stream::"
+	eventPrefix   = "event::"
+	versionPrefix = "version::"
+)
+
+// ErrReservationExpired is returned when a save took longer than the reservation timeout, the global feed may have
+// skipped its reserved global versions. The events are not saved.
+var ErrReservationExpired = errors.New("couchbase: reserved global versions expired")
+
+// Couchbase is the event store handler
+type Couchbase struct {
+	collection *gocb.Collection
+	// ReservationTimeout is the time a save has from reserving global versions until the stream document is
+	// updated. Reserved global versions without a saved event after the timeout are skipped by the global feed.
+	ReservationTimeout time.Duration
+}
+
+// stream is the document holding the current version of an aggregate. It's replaced with CAS to detect concurrent
+// writes to the same aggregate.
+type stream struct {
+	Version uint64 `json:"version"`
+	// Last holds the global versions of the events of the last save, the events before have a document keyed on the
+	// stream and version. It keeps the stream document from growing with the aggregate.
+	Last []uint64 `json:"last,omitempty"`
+	// GlobalVersions holds the global versions of the first events of streams written by earlier releases
+	GlobalVersions []uint64 `json:"global_versions,omitempty"`
+}
+
+// globalVersion returns the global version of the event with the version if the stream document holds it
+func (s stream) globalVersion(version uint64) (uint64, bool) {
+	if version >= 1 && version <= uint64(len(s.GlobalVersions)) {
+		return s.GlobalVersions[version-1], true
+	}
+	first := s.Version - uint64(len(s.Last)) + 1
+	if len(s.Last) > 0 && version >= first && version <= s.Version {
+		return s.Last[version-first], true
+	}
+	return 0, false
+}
+
+// document is the stored event, a skipped document marks a reserved global version that was never used. A pending
+// document is written before the stream document is updated, it's part of the global feed when the stream document
+// holds it.
+type document struct {
+	AggregateID   string    `json:"aggregate_id"`
+	AggregateType string    `json:"aggregate_type"`
+	Version       uint64    `json:"version"`
+	GlobalVersion uint64    `json:"global_version"`
+	Reason        string    `json:"reason"`
+	Timestamp     time.Time `json:"timestamp"`
+	Data          []byte    `json:"data"`
+	Metadata      []byte    `json:"metadata"`
+	SchemaVersion uint      `json:"schema_version,omitempty"`
+	Skipped       bool      `json:"skipped,omitempty"`
+	Pending       bool      `json:"pending,omitempty"`
+	Written       time.Time `json:"written,omitempty"` // the reservation of the global version is older
+}
+
+// Open binds the event store to the collection
+func Open(collection *gocb.Collection) *Couchbase {
+	return &Couchbase{
+		collection:         collection,
+		ReservationTimeout: time.Minute,
+	}
+}
+
+// Save persists events to the collection
+func (c *Couchbase) Save(events []core.Event) error {
+	return c.SaveContext(context.Background(), events)
+}
+
+// SaveContext persists events to the collection. The event documents are written as pending before the stream
+// document is replaced, a save failing before leaves skipped documents and a save failing after leaves pending
+// documents the global feed settles from the stream document. The global feed is never halted by a failed save.
+func (c *Couchbase) SaveContext(ctx context.Context, events []core.Event) error {
+	// If no event return no error
+	if len(events) == 0 {
+		return nil
+	}
+	aggregateID := events[0].AggregateID
+	aggregateType := events[0].AggregateType
+	key := streamKey(aggregateType, aggregateID)
+
+	s, cas, err := c.stream(ctx, key)
+	if err != nil {
+		return err
+	}
+	if core.Version(s.Version) != events[0].Version-1 {
+		return core.ErrConcurrency
+	}
+	err = c.indexLast(ctx, key, s)
+	if err != nil {
+		return err
+	}
+
+	// reserve a range in the global sequence
+	count := uint64(len(events))
+	res, err := c.collection.Binary().Increment(globalKey, &gocb.IncrementOptions{Initial: int64(count), Delta: count, Context: ctx})
+	if err != nil {
+		return err
+	}
+	first := res.Content() - count + 1
+	written := time.Now().UTC()
+	last := make([]uint64, 0, count)
+	for i := range events {
+		events[i].GlobalVersion = core.Version(first + uint64(i))
+		last = append(last, uint64(events[i].GlobalVersion))
+	}
+
+	for _, event := range events {
+		doc := newDocument(event)
+		doc.Pending = true
+		doc.Written = written
+		// insert as the global feed marks an expired reservation as skipped
+		_, err = c.collection.Insert(eventKey(uint64(event.GlobalVersion)), doc, &gocb.InsertOptions{Context: ctx})
+		if err != nil {
+			c.skip(ctx, first, count)
+			if errors.Is(err, gocb.ErrDocumentExists) {
+				return ErrReservationExpired
+			}
+			return err
+		}
+	}
+	// the global feed skips pending documents older than the reservation timeout, leave a margin for the replace
+	if time.Since(written) > c.ReservationTimeout/2 {
+		c.skip(ctx, first, count)
+		return ErrReservationExpired
+	}
+
+	s.Version = uint64(events[len(events)-1].Version)
+	s.Last = last
+	if cas == 0 {
+		_, err = c.collection.Insert(key, s, &gocb.InsertOptions{Context: ctx})
+	} else {
+		_, err = c.collection.Replace(key, s, &gocb.ReplaceOptions{Cas: cas, Context: ctx})
+	}
+	if errors.Is(err, gocb.ErrDocumentExists) || errors.Is(err, gocb.ErrCasMismatch) {
+		c.skip(ctx, first, count)
+		return core.ErrConcurrency
+	}
+	if err != nil {
+		// the stream document could still be replaced, the pending documents are settled by the global feed
+		return err
+	}
+
+	// the events are saved, a failed commit of the pending documents is settled by the global feed
+	for _, event := range events {
+		doc := newDocument(event)
+		doc.Written = written
+		c.collection.Upsert(eventKey(uint64(event.GlobalVersion)), doc, &gocb.UpsertOptions{Context: ctx})
+	}
+	return nil
+}
+
+// indexLast writes the documents keyed on the stream and version of the events of the last save, before the stream
+// document is replaced and forgets their global versions
+func (c *Couchbase) indexLast(ctx context.Context, key string, s stream) error {
+	for i, globalVersion := range s.Last {
+		version := s.Version - uint64(len(s.Last)) + 1 + uint64(i)
+		doc, _, found, err := c.document(ctx, eventKey(globalVersion))
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("couchbase: event document %d of %s not found", globalVersion, key)
+		}
+		doc.Pending = false
+		_, err = c.collection.Insert(versionKey(key, version), doc, &gocb.InsertOptions{Context: ctx})
+		if err != nil && !errors.Is(err, gocb.ErrDocumentExists) {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip marks the reserved global versions as skipped to not halt the global feed. It's done also when the context
+// is canceled as the context could be the cause of the failed save.
+func (c *Couchbase) skip(ctx context.Context, first, count uint64) {
+	ctx = context.WithoutCancel(ctx)
+	now := time.Now().UTC()
+	for i := uint64(0); i < count; i++ {
+		c.collection.Upsert(eventKey(first+i), skipped(first+i, now), &gocb.UpsertOptions{Context: ctx})
+	}
+}
+
+// LatestVersion returns the version of the last aggregate event from the stream document, zero if the aggregate has no
+// events
+func (c *Couchbase) LatestVersion(ctx context.Context, id string, aggregateType string) (core.Version, error) {
+	s, _, err := c.stream(ctx, streamKey(aggregateType, id))
+	if err != nil {
+		return 0, err
+	}
+	return core.Version(s.Version), nil
+}
+
+// Get the events from the aggregate stream after the version
+func (c *Couchbase) Get(ctx context.Context, id string, aggregateType string, afterVersion core.Version) (core.Iterator, error) {
+	key := streamKey(aggregateType, id)
+	s, _, err := c.stream(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(afterVersion) >= s.Version {
+		return core.ZeroIterator{}, nil
+	}
+	version := uint64(afterVersion)
+	return &iterator{next: func() (document, bool, error) {
+		for version < s.Version {
+			version++
+			docKey := versionKey(key, version)
+			if globalVersion, ok := s.globalVersion(version); ok {
+				docKey = eventKey(globalVersion)
+			}
+			doc, _, found, err := c.document(ctx, docKey)
+			if err != nil {
+				return document{}, false, err
+			}
+			if !found {
+				return document{}, false, fmt.Errorf("couchbase: event document %s not found", docKey)
+			}
+			return doc, true, nil
+		}
+		return document{}, false, nil
+	}}, nil
+}
+
+// All iterate over the global event feed starting from the start global version. The events are read with one key
+// lookup per global version, it's not a change feed of the database. The feed ends on the first event that is
+// reserved but not yet saved, making the events visible in global version order. Reserved global versions of saves
+// that failed or expired are skipped.
+func (c *Couchbase) All(start core.Version, count uint64) (core.Iterator, error) {
+	ctx := context.Background()
+	head, err := c.GlobalVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	last := uint64(head)
+	if start == 0 {
+		start = 1
+	}
+	if count == 0 || uint64(start) > last {
+		return core.ZeroIterator{}, nil
+	}
+	// the events are fetched one by one while iterating, stop at count global versions or the current head
+	if uint64(start)+count-1 >= uint64(start) && uint64(start)+count-1 < last {
+		last = uint64(start) + count - 1
+	}
+	next := uint64(start)
+	return &iterator{next: func() (document, bool, error) {
+		for next <= last {
+			doc, cas, found, err := c.document(ctx, eventKey(next))
+			if err != nil {
+				return document{}, false, err
+			}
+			if !found {
+				expired, err := c.expireHole(ctx, next, uint64(head))
+				if err != nil || !expired {
+					// the event is reserved but not yet saved
+					return document{}, false, err
+				}
+				// read the skipped document, or the event if it was saved in between
+				continue
+			}
+			if doc.Pending {
+				settled, err := c.settle(ctx, &doc, cas)
+				if err != nil || !settled {
+					return document{}, false, err
+				}
+			}
+			next++
+			if doc.Skipped {
+				continue
+			}
+			return doc, true, nil
+		}
+		return document{}, false, nil
+	}}, nil
+}
+
+// expireHole marks the missing event document of the reserved global version as skipped if the reservation is older
+// than the reservation timeout. The reservation is at least as old as the event document of a later global version.
+// The skipped document is inserted to not overwrite an event saved in between.
+func (c *Couchbase) expireHole(ctx context.Context, globalVersion, head uint64) (bool, error) {
+	for v := globalVersion + 1; v <= head; v++ {
+		doc, _, found, err := c.document(ctx, eventKey(v))
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			continue
+		}
+		if time.Since(doc.Written) < c.ReservationTimeout {
+			return false, nil
+		}
+		_, err = c.collection.Insert(eventKey(globalVersion), skipped(globalVersion, time.Now().UTC()), &gocb.InsertOptions{Context: ctx})
+		if err != nil && !errors.Is(err, gocb.ErrDocumentExists) {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// settle decides a pending document from the stream document. It's saved when the stream document holds its global
+// version and skipped when the stream document holds another event on its version or when it's older than the
+// reservation timeout. A pending document not settled belongs to a save in flight.
+func (c *Couchbase) settle(ctx context.Context, doc *document, cas gocb.Cas) (bool, error) {
+	key := streamKey(doc.AggregateType, doc.AggregateID)
+	s, _, err := c.stream(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	saved := false
+	taken := s.Version >= doc.Version
+	if taken {
+		globalVersion, ok := s.globalVersion(doc.Version)
+		if !ok {
+			versionDoc, _, found, err := c.document(ctx, versionKey(key, doc.Version))
+			if err != nil {
+				return false, err
+			}
+			globalVersion, ok = versionDoc.GlobalVersion, found
+		}
+		saved = ok && globalVersion == doc.GlobalVersion
+	}
+	switch {
+	case saved:
+		doc.Pending = false
+	case taken || time.Since(doc.Written) >= c.ReservationTimeout:
+		*doc = skipped(doc.GlobalVersion, doc.Written)
+	default:
+		return false, nil
+	}
+	// store the settled document, a failed replace is settled again by the next read
+	c.collection.Replace(eventKey(doc.GlobalVersion), doc, &gocb.ReplaceOptions{Cas: cas, Context: ctx})
+	return true, nil
+}
+
+// GlobalVersion returns the last reserved global version, zero if no events are saved. An event reserved by a save in
+// flight is not yet in the global event feed.
+func (c *Couchbase) GlobalVersion(ctx context.Context) (core.Version, error) {
+	res, err := c.collection.Get(globalKey, &gocb.GetOptions{Context: ctx})
+	if errors.Is(err, gocb.ErrDocumentNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	var head uint64
+	err = res.Content(&head)
+	return core.Version(head), err
+}
+
+// stream returns the stream document and its CAS, a zero CAS means the stream does not exist
+func (c *Couchbase) stream(ctx context.Context, key string) (stream, gocb.Cas, error) {
+	var s stream
+	res, err := c.collection.Get(key, &gocb.GetOptions{Context: ctx})
+	if errors.Is(err, gocb.ErrDocumentNotFound) {
+		return s, 0, nil
+	}
+	if err != nil {
+		return s, 0, err
+	}
+	err = res.Content(&s)
+	return s, res.Cas(), err
+}
+
+// document returns the event document and its CAS, false if it doesn't exist
+func (c *Couchbase) document(ctx context.Context, key string) (document, gocb.Cas, bool, error) {
+	var doc document
+	res, err := c.collection.Get(key, &gocb.GetOptions{Context: ctx})
+	if errors.Is(err, gocb.ErrDocumentNotFound) {
+		return doc, 0, false, nil
+	}
+	if err != nil {
+		return doc, 0, false, err
+	}
+	err = res.Content(&doc)
+	return doc, res.Cas(), err == nil, err
+}
+
+func newDocument(event core.Event) document {
+	return document{
+		AggregateID:   event.AggregateID,
+		AggregateType: event.AggregateType,
+		Version:       uint64(event.Version),
+		GlobalVersion: uint64(event.GlobalVersion),
+		Reason:        event.Reason,
+		Timestamp:     event.Timestamp,
+		Data:          event.Data,
+		Metadata:      event.Metadata,
+		SchemaVersion: event.SchemaVersion,
+	}
+}
+
+// skipped returns the document marking the global version as skipped
+func skipped(globalVersion uint64, written time.Time) document {
+	return document{GlobalVersion: globalVersion, Skipped: true, Written: written}
+}
+
+func streamKey(aggregateType, aggregateID string) string {
+	return streamPrefix + aggregateType + "::" + aggregateID
+}
+
+func eventKey(globalVersion uint64) string {
+	return fmt.Sprintf("%s%d", eventPrefix, globalVersion)
+}
+
+// versionKey is the key of the event document with the version in the stream
+func versionKey(streamKey string, version uint64) string {
+	return fmt.Sprintf("%s%d::%s", versionPrefix, version, streamKey)
+}
@@ -0,0 +1,90 @@
+package couchbase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	es "github.com/hallgren/eventsourcing/eventstore/couchbase"
+)
+
+const (
+	username = "Administrator"
+	password = "password"
+	bucket   = "events"
+)
+
+func TestSuite(t *testing.T) {
+	ctx := context.Background()
+
+	req := testcontainers.ContainerRequest{
+		Image:        "couchbase/server:community",
+		ExposedPorts: []string{"8091/tcp", "11210/tcp"},
+		WaitingFor:   wait.ForHTTP("/ui/index.html").WithPort("8091/tcp"),
+	}
+
+	container, err := testcontainers.GenericContainer(
+		ctx,
+		testcontainers.GenericContainerRequest{
+			ContainerRequest: req,
+			Started:          true,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer container.Terminate(ctx)
+
+	commands := [][]string{
+		{"couchbase-cli", "cluster-init", "-c", "localhost", "--cluster-username", username, "--cluster-password", password, "--services", "data", "--cluster-ramsize", "256"},
+		{"couchbase-cli", "bucket-create", "-c", "localhost", "-u", username, "-p", password, "--bucket", bucket, "--bucket-type", "couchbase", "--bucket-ramsize", "128", "--wait"},
+	}
+	for _, cmd := range commands {
+		code, _, err := container.Exec(ctx, cmd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != 0 {
+			t.Fatalf("command %v exited with %d", cmd, code)
+		}
+	}
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := container.MappedPort(ctx, "11210")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cluster, err := gocb.Connect("couchbase://"+host+":"+port.Port(), gocb.ClusterOptions{
+		Authenticator: gocb.PasswordAuthenticator{Username: username, Password: password},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cluster.Close(nil)
+
+	b := cluster.Bucket(bucket)
+	err = b.WaitUntilReady(time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := func() (core.EventStore, func(), error) {
+		return es.Open(b.DefaultCollection()), func() {}, nil
+	}
+	testsuite.Test(t, f)
+
+	testsuite.TestVersionReader(t, func() (core.EventStore, core.VersionReader, func(), error) {
+		store := es.Open(b.DefaultCollection())
+		return store, store, func() {}, nil
+	})
+}
@@ -0,0 +1,54 @@
+package couchbase
+
+import (
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type iterator struct {
+	next func() (document, bool, error) // returns the next event document, false when there are no more events
+	done bool
+	doc  document
+	err  error
+}
+
+// Next fetches the next event document. It returns false when there are no more events or when the next event
+// is reserved but not yet saved.
+func (i *iterator) Next() bool {
+	if i.done {
+		return false
+	}
+	doc, ok, err := i.next()
+	if err != nil {
+		// return the error from Value
+		i.err = err
+		i.done = true
+		return true
+	}
+	if !ok {
+		i.done = true
+		return false
+	}
+	i.doc = doc
+	return true
+}
+
+// Value returns the event
+func (i *iterator) Value() (core.Event, error) {
+	if i.err != nil {
+		return core.Event{}, i.err
+	}
+	return core.Event{
+		AggregateID:   i.doc.AggregateID,
+		AggregateType: i.doc.AggregateType,
+		Version:       core.Version(i.doc.Version),
+		GlobalVersion: core.Version(i.doc.GlobalVersion),
+		Reason:        i.doc.Reason,
+		Timestamp:     i.doc.Timestamp,
+		Data:          i.doc.Data,
+		Metadata:      i.doc.Metadata,
+		SchemaVersion: i.doc.SchemaVersion,
+	}, nil
+}
+
+// Close is a no-op as the documents are fetched one by one
+func (i *iterator) Close() {}
@@ -0,0 +1,203 @@
+// Package encrypt wraps an event store to encrypt the event data with a key per aggregate. Deleting the key of an
+// aggregate makes its personal data unreadable without changing the immutable events, known as crypto-shredding.
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// magic prefix the encrypted data to separate it from events saved before encryption was enabled
+var magic = []byte("esenc1")
+
+// Store is an event store that encrypts the event data with the key of the aggregate
+type Store struct {
+	es   core.EventStore
+	keys KeyStore
+	// Shredded replaces the data of events where the aggregate key is deleted, it defaults to an empty JSON object
+	// making the default encoder return the event data with zero values.
+	Shredded []byte
+}
+
+// Wrap returns an event store encrypting the event data with keys from the key store before it's saved in es
+func Wrap(es core.EventStore, keys KeyStore) *Store {
+	return &Store{
+		es:       es,
+		keys:     keys,
+		Shredded: []byte("{}"),
+	}
+}
+
+// Save encrypts the event data and saves the events in the underlying store
+func (s *Store) Save(events []core.Event) error {
+	return s.SaveContext(context.Background(), events)
+}
+
+// SaveContext encrypts the event data and saves the events in the underlying store bound by the context
+func (s *Store) SaveContext(ctx context.Context, events []core.Event) error {
+	encrypted, err := s.encrypt(ctx, events)
+	if err != nil {
+		return err
+	}
+	err = core.SaveContext(ctx, s.es, encrypted)
+	if err != nil {
+		return err
+	}
+	// expose the global version set by the underlying store to the caller
+	for i := range events {
+		events[i].GlobalVersion = encrypted[i].GlobalVersion
+	}
+	return nil
+}
+
+// SaveBatch encrypts the event data of each batch and saves the batches in the underlying store,
+// core.ErrBatchNotSupported is returned if the underlying store is not a core.BatchSaver
+func (s *Store) SaveBatch(ctx context.Context, batches [][]core.Event) error {
+	saver, ok := s.es.(core.BatchSaver)
+	if !ok {
+		return core.ErrBatchNotSupported
+	}
+	encrypted := make([][]core.Event, len(batches))
+	for i, events := range batches {
+		var err error
+		encrypted[i], err = s.encrypt(ctx, events)
+		if err != nil {
+			return err
+		}
+	}
+	err := saver.SaveBatch(ctx, encrypted)
+	if err != nil {
+		return err
+	}
+	for i, events := range batches {
+		for j := range events {
+			events[j].GlobalVersion = encrypted[i][j].GlobalVersion
+		}
+	}
+	return nil
+}
+
+// Unwrap returns the underlying event store
+func (s *Store) Unwrap() core.EventStore {
+	return s.es
+}
+
+// encrypt returns a copy of the events with the data encrypted with the key of the aggregate
+func (s *Store) encrypt(ctx context.Context, events []core.Event) ([]core.Event, error) {
+	encrypted := make([]core.Event, len(events))
+	for i, event := range events {
+		key, err := s.keys.GetOrCreate(ctx, event.AggregateType, event.AggregateID)
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		_, err = io.ReadFull(rand.Reader, nonce)
+		if err != nil {
+			return nil, err
+		}
+		// magic | nonce | cipher text
+		data := make([]byte, 0, len(magic)+len(nonce)+len(event.Data)+gcm.Overhead())
+		data = append(data, magic...)
+		data = append(data, nonce...)
+		event.Data = gcm.Seal(data, nonce, event.Data, additionalData(event))
+		encrypted[i] = event
+	}
+	return encrypted, nil
+}
+
+// Get returns the events of the aggregate with their data decrypted
+func (s *Store) Get(ctx context.Context, id string, aggregateType string, afterVersion core.Version) (core.Iterator, error) {
+	it, err := s.es.Get(ctx, id, aggregateType, afterVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &iterator{ctx: ctx, iterator: it, store: s}, nil
+}
+
+// Feed decrypts the events from a global event feed like the All method on the event stores
+func (s *Store) Feed(fetchF func() (core.Iterator, error)) func() (core.Iterator, error) {
+	return func() (core.Iterator, error) {
+		it, err := fetchF()
+		if err != nil {
+			return nil, err
+		}
+		return &iterator{ctx: context.Background(), iterator: it, store: s}, nil
+	}
+}
+
+func (s *Store) decrypt(ctx context.Context, event core.Event) (core.Event, error) {
+	if !bytes.HasPrefix(event.Data, magic) {
+		return event, nil
+	}
+	key, err := s.keys.Get(ctx, event.AggregateType, event.AggregateID)
+	if errors.Is(err, ErrKeyNotFound) {
+		event.Data = s.Shredded
+		return event, nil
+	} else if err != nil {
+		return core.Event{}, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return core.Event{}, err
+	}
+	data := event.Data[len(magic):]
+	if len(data) < gcm.NonceSize() {
+		return core.Event{}, errors.New("could not decrypt event, malformed data")
+	}
+	event.Data, err = gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], additionalData(event))
+	if err != nil {
+		return core.Event{}, fmt.Errorf("could not decrypt event, %w", err)
+	}
+	return event, nil
+}
+
+// additionalData binds the encrypted data to the event it belongs to
+func additionalData(event core.Event) []byte {
+	return []byte(event.AggregateType + "_" + event.AggregateID + "_" + strconv.FormatUint(uint64(event.Version), 10))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+type iterator struct {
+	ctx      context.Context
+	iterator core.Iterator
+	store    *Store
+}
+
+// Next steps to the next event
+func (i *iterator) Next() bool {
+	return i.iterator.Next()
+}
+
+// Value returns the event with its data decrypted
+func (i *iterator) Value() (core.Event, error) {
+	event, err := i.iterator.Value()
+	if err != nil {
+		return core.Event{}, err
+	}
+	return i.store.decrypt(i.ctx, event)
+}
+
+// Close closes the underlying iterator
+func (i *iterator) Close() {
+	i.iterator.Close()
+}
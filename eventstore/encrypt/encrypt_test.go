@@ -0,0 +1,148 @@
+package encrypt_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/eventstore/encrypt"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.EventStore, func(), error) {
+		return encrypt.Wrap(memory.Create(), encrypt.NewMemoryKeys()), func() {}, nil
+	}
+	testsuite.Test(t, f)
+}
+
+func TestBatchSaver(t *testing.T) {
+	f := func() (core.EventStore, core.BatchSaver, func(), error) {
+		es := encrypt.Wrap(memory.Create(), encrypt.NewMemoryKeys())
+		return es, es, func() {}, nil
+	}
+	testsuite.TestBatchSaver(t, f)
+}
+
+func TestEncryptedBatch(t *testing.T) {
+	es := memory.Create()
+	store := encrypt.Wrap(es, encrypt.NewMemoryKeys())
+
+	batches := [][]core.Event{
+		{event("123", 1, `{"Name":"kalle"}`)},
+		{event("456", 1, `{"Name":"anka"}`)},
+	}
+	err := store.SaveBatch(context.Background(), batches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batches[0][0].GlobalVersion != 1 || batches[1][0].GlobalVersion != 2 {
+		t.Fatalf("expected global versions 1 and 2 was %d and %d", batches[0][0].GlobalVersion, batches[1][0].GlobalVersion)
+	}
+	if bytes.Contains(get(t, es, "456").Data, []byte("anka")) {
+		t.Fatal("expected encrypted data in the underlying store")
+	}
+	if string(get(t, store, "456").Data) != `{"Name":"anka"}` {
+		t.Fatal("expected decrypted data")
+	}
+}
+
+func event(id string, version core.Version, data string) core.Event {
+	return core.Event{AggregateID: id, AggregateType: "Person", Version: version, Reason: "Born", Data: []byte(data)}
+}
+
+func get(t *testing.T, es core.EventStore, id string) core.Event {
+	t.Helper()
+	iterator, err := es.Get(context.Background(), id, "Person", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatalf("expected event on aggregate %s", id)
+	}
+	e, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestEncryptedData(t *testing.T) {
+	es := memory.Create()
+	store := encrypt.Wrap(es, encrypt.NewMemoryKeys())
+
+	events := []core.Event{event("123", 1, `{"Name":"kalle"}`)}
+	err := store.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events[0].GlobalVersion != 1 {
+		t.Fatalf("expected global version 1 was %d", events[0].GlobalVersion)
+	}
+	if bytes.Contains(get(t, es, "123").Data, []byte("kalle")) {
+		t.Fatal("expected encrypted data in the underlying store")
+	}
+	if string(get(t, store, "123").Data) != `{"Name":"kalle"}` {
+		t.Fatal("expected decrypted data")
+	}
+}
+
+func TestCryptoShredding(t *testing.T) {
+	es := memory.Create()
+	keys := encrypt.NewMemoryKeys()
+	store := encrypt.Wrap(es, keys)
+
+	err := store.Save([]core.Event{event("123", 1, `{"Name":"kalle"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Save([]core.Event{event("456", 1, `{"Name":"anka"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = keys.Delete(context.Background(), "Person", "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(get(t, store, "123").Data) != "{}" {
+		t.Fatal("expected shredded data on aggregate with deleted key")
+	}
+	if string(get(t, store, "456").Data) != `{"Name":"anka"}` {
+		t.Fatal("expected other aggregates to be readable")
+	}
+
+	iterator, err := store.Feed(es.All(0, 10))()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	data := []string{}
+	for iterator.Next() {
+		e, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = append(data, string(e.Data))
+	}
+	if len(data) != 2 || data[0] != "{}" || data[1] != `{"Name":"anka"}` {
+		t.Fatalf("unexpected event data from the feed %v", data)
+	}
+}
+
+func TestUnencryptedData(t *testing.T) {
+	es := memory.Create()
+	store := encrypt.Wrap(es, encrypt.NewMemoryKeys())
+
+	// event saved before encryption was enabled
+	err := es.Save([]core.Event{event("123", 1, `{"Name":"kalle"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(get(t, store, "123").Data) != `{"Name":"kalle"}` {
+		t.Fatal("expected unencrypted data to be read as is")
+	}
+}
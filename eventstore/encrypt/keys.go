@@ -0,0 +1,74 @@
+package encrypt
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrKeyNotFound returned from the key store when the aggregate has no key, a deleted key is not found
+var ErrKeyNotFound = errors.New("encryption key not found")
+
+// KeyStore holds the encryption key of each aggregate
+type KeyStore interface {
+	// GetOrCreate returns the key of the aggregate and creates it if it doesn't exist, used when events are saved
+	GetOrCreate(ctx context.Context, aggregateType, aggregateID string) ([]byte, error)
+	// Get returns the key of the aggregate or ErrKeyNotFound
+	Get(ctx context.Context, aggregateType, aggregateID string) ([]byte, error)
+	// Delete removes the key making the encrypted event data of the aggregate unreadable
+	Delete(ctx context.Context, aggregateType, aggregateID string) error
+}
+
+// MemoryKeys is a key store holding the keys in memory
+type MemoryKeys struct {
+	lock sync.Mutex
+	keys map[string][]byte
+}
+
+// NewMemoryKeys creates an in memory key store generating AES-256 keys
+func NewMemoryKeys() *MemoryKeys {
+	return &MemoryKeys{
+		keys: make(map[string][]byte),
+	}
+}
+
+// GetOrCreate returns the key of the aggregate and creates it if it doesn't exist
+func (m *MemoryKeys) GetOrCreate(ctx context.Context, aggregateType, aggregateID string) ([]byte, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key, ok := m.keys[aggregateType+"_"+aggregateID]
+	if ok {
+		return key, nil
+	}
+	key = make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, key)
+	if err != nil {
+		return nil, err
+	}
+	m.keys[aggregateType+"_"+aggregateID] = key
+	return key, nil
+}
+
+// Get returns the key of the aggregate
+func (m *MemoryKeys) Get(ctx context.Context, aggregateType, aggregateID string) ([]byte, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key, ok := m.keys[aggregateType+"_"+aggregateID]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// Delete removes the key of the aggregate
+func (m *MemoryKeys) Delete(ctx context.Context, aggregateType, aggregateID string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.keys, aggregateType+"_"+aggregateID)
+	return nil
+}
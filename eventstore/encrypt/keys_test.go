@@ -0,0 +1,42 @@
+package encrypt_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/eventstore/encrypt"
+)
+
+func TestMemoryKeys(t *testing.T) {
+	keys := encrypt.NewMemoryKeys()
+	ctx := context.Background()
+
+	_, err := keys.Get(ctx, "Person", "123")
+	if !errors.Is(err, encrypt.ErrKeyNotFound) {
+		t.Fatalf("expected key not found was %v", err)
+	}
+	created, err := keys.GetOrCreate(ctx, "Person", "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 32 {
+		t.Fatalf("expected 32 byte key was %d", len(created))
+	}
+	key, err := keys.GetOrCreate(ctx, "Person", "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, created) {
+		t.Fatal("expected the existing key")
+	}
+	err = keys.Delete(ctx, "Person", "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = keys.Get(ctx, "Person", "123")
+	if !errors.Is(err, encrypt.ErrKeyNotFound) {
+		t.Fatalf("expected key not found after delete was %v", err)
+	}
+}
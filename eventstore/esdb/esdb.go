@@ -2,6 +2,10 @@ package esdb
 
 import (
 	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
 
 	"github.com/EventStore/EventStore-Client-Go/v4/esdb"
 	"github.com/hallgren/eventsourcing/core"
@@ -9,6 +13,9 @@ import (
 
 const streamSeparator = "-"
 
+// schemaSeparator separates the reason from the schema version in the esdb event type
+const schemaSeparator = ".v"
+
 // ESDB is the event store handler
 type ESDB struct {
 	client      *esdb.Client
@@ -30,6 +37,11 @@ func Open(client *esdb.Client, jsonSerializer bool) *ESDB {
 
 // Save persists events to the database
 func (es *ESDB) Save(events []core.Event) error {
+	return es.SaveContext(context.Background(), events)
+}
+
+// SaveContext persists events to the database, the append is canceled if the context is done
+func (es *ESDB) SaveContext(ctx context.Context, events []core.Event) error {
 	// If no event return no error
 	if len(events) == 0 {
 		return nil
@@ -45,7 +57,7 @@ func (es *ESDB) Save(events []core.Event) error {
 	for i, event := range events {
 		eventData := esdb.EventData{
 			ContentType: es.contentType,
-			EventType:   event.Reason,
+			EventType:   eventType(event.Reason, event.SchemaVersion),
 			Data:        event.Data,
 			Metadata:    event.Metadata,
 		}
@@ -60,7 +72,7 @@ func (es *ESDB) Save(events []core.Event) error {
 	} else if version == 1 {
 		streamOptions.ExpectedRevision = esdb.NoStream{}
 	}
-	wr, err := es.client.AppendToStream(context.Background(), stream, streamOptions, esdbEvents...)
+	wr, err := es.client.AppendToStream(ctx, stream, streamOptions, esdbEvents...)
 	if err != nil {
 		if err, ok := esdb.FromError(err); !ok {
 			if err.Code() == esdb.ErrorCodeWrongExpectedVersion {
@@ -93,6 +105,78 @@ func (es *ESDB) Get(ctx context.Context, id string, aggregateType string, afterV
 	return &iterator{stream: stream}, nil
 }
 
+// LatestVersion returns the version of the last aggregate event by reading the stream backwards, zero if the aggregate
+// has no events
+func (es *ESDB) LatestVersion(ctx context.Context, id string, aggregateType string) (core.Version, error) {
+	readStream, err := es.client.ReadStream(ctx, stream(aggregateType, id), esdb.ReadStreamOptions{Direction: esdb.Backwards, From: esdb.End{}}, 1)
+	if err != nil {
+		if err, ok := esdb.FromError(err); !ok {
+			if err.Code() == esdb.ErrorCodeResourceNotFound {
+				return 0, nil
+			}
+		}
+		return 0, err
+	}
+	defer readStream.Close()
+	event, err := readStream.Recv()
+	if errors.Is(err, io.EOF) {
+		return 0, nil
+	} else if err != nil {
+		if err, ok := esdb.FromError(err); !ok {
+			if err.Code() == esdb.ErrorCodeResourceNotFound {
+				return 0, nil
+			}
+		}
+		return 0, err
+	}
+	// +1 as the eventsourcing Version starts on 1 but the esdb event version starts on 0
+	return core.Version(event.OriginalEvent().EventNumber) + 1, nil
+}
+
+// GlobalVersion returns the commit position of the last saved event by reading $all backwards, zero if no events are
+// saved. The events of system streams are skipped. It's the same position Save sets as the GlobalVersion of the events.
+func (es *ESDB) GlobalVersion(ctx context.Context) (core.Version, error) {
+	readStream, err := es.client.ReadAll(ctx, esdb.ReadAllOptions{Direction: esdb.Backwards, From: esdb.End{}}, ^uint64(0))
+	if err != nil {
+		return 0, err
+	}
+	defer readStream.Close()
+	for {
+		event, err := readStream.Recv()
+		if errors.Is(err, io.EOF) {
+			return 0, nil
+		} else if err != nil {
+			return 0, err
+		}
+		if strings.HasPrefix(event.OriginalEvent().StreamID, "$") {
+			continue
+		}
+		return core.Version(event.OriginalEvent().Position.Commit), nil
+	}
+}
+
 func stream(aggregateType, aggregateID string) string {
 	return aggregateType + streamSeparator + aggregateID
 }
+
+// eventType adds the schema version to the reason as esdb has no field for it, events without schema version keeps
+// the reason as event type
+func eventType(reason string, schemaVersion uint) string {
+	if schemaVersion == 0 {
+		return reason
+	}
+	return reason + schemaSeparator + strconv.FormatUint(uint64(schemaVersion), 10)
+}
+
+// parseEventType returns the reason and schema version from the esdb event type
+func parseEventType(typ string) (string, uint) {
+	i := strings.LastIndex(typ, schemaSeparator)
+	if i == -1 {
+		return typ, 0
+	}
+	v, err := strconv.ParseUint(typ[i+len(schemaSeparator):], 10, 64)
+	if err != nil {
+		return typ, 0
+	}
+	return typ[:i], uint(v)
+}
@@ -57,4 +57,12 @@ func TestSuite(t *testing.T) {
 		}, nil
 	}
 	testsuite.Test(t, f)
+
+	testsuite.TestVersionReader(t, func() (core.EventStore, core.VersionReader, func(), error) {
+		store, closeFunc, err := f()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return store, store.(core.VersionReader), closeFunc, nil
+	})
 }
@@ -6,7 +6,7 @@ toolchain go1.23.7
 
 require (
 	github.com/EventStore/EventStore-Client-Go/v4 v4.2.0
-	github.com/hallgren/eventsourcing/core v0.4.0
+	github.com/hallgren/eventsourcing/core v0.5.0
 	github.com/testcontainers/testcontainers-go v0.36.0
 )
 
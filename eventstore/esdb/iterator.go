@@ -30,6 +30,7 @@ func (i *iterator) Next() bool {
 // Value returns the event from the stream
 func (i *iterator) Value() (core.Event, error) {
 	stream := strings.Split(i.event.Event.StreamID, streamSeparator)
+	reason, schemaVersion := parseEventType(i.event.Event.EventType)
 
 	event := core.Event{
 		AggregateID:   stream[1],
@@ -38,7 +39,8 @@ func (i *iterator) Value() (core.Event, error) {
 		Timestamp:     i.event.Event.CreatedDate,
 		Data:          i.event.Event.Data,
 		Metadata:      i.event.Event.UserMetadata,
-		Reason:        i.event.Event.EventType,
+		Reason:        reason,
+		SchemaVersion: schemaVersion,
 		// Can't get the global version when using the ReadStream method
 		//GlobalVersion: core.Version(event.Event.Position.Commit),
 	}
@@ -2,19 +2,31 @@ package memory
 
 import "github.com/hallgren/eventsourcing/core"
 
+// iterator steps over a slice of the stored events. The stored slices are only appended to, or replaced when events
+// are deleted, the iterator shares them with the event store instead of copying the events.
 type iterator struct {
 	events   []core.Event
 	position int
 	event    core.Event
+	filter   core.Filter // events not matching the filter are skipped
+	limit    uint64      // max number of events to iterate, zero is no limit
+	count    uint64
 }
 
 func (i *iterator) Next() bool {
-	if len(i.events) <= i.position {
+	if i.limit > 0 && i.count >= i.limit {
 		return false
 	}
-	i.event = i.events[i.position]
-	i.position++
-	return true
+	for i.position < len(i.events) {
+		event := i.events[i.position]
+		i.position++
+		if i.filter.Match(event) {
+			i.event = event
+			i.count++
+			return true
+		}
+	}
+	return false
 }
 
 func (i *iterator) Value() (core.Event, error) {
@@ -2,7 +2,10 @@ package memory
 
 import (
 	"context"
+	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/hallgren/eventsourcing/core"
 )
@@ -11,6 +14,10 @@ import (
 type Memory struct {
 	aggregateEvents map[string][]core.Event // The memory structure where we store aggregate events
 	eventsInOrder   []core.Event            // The global event order
+	categories      map[string][]core.Event // The category streams by aggregate type
+	categoryVersion map[string]core.Version // The category version of the last saved event by aggregate type
+	correlations    map[string][]core.Event // The correlated events by correlation id
+	globalVersion   core.Version            // The global version of the last saved event
 	lock            sync.Mutex
 }
 
@@ -19,110 +26,355 @@ func Create() *Memory {
 	return &Memory{
 		aggregateEvents: make(map[string][]core.Event),
 		eventsInOrder:   make([]core.Event, 0),
+		categories:      make(map[string][]core.Event),
+		categoryVersion: make(map[string]core.Version),
+		correlations:    make(map[string][]core.Event),
 	}
 }
 
 // Save an aggregate (its events)
 func (e *Memory) Save(events []core.Event) error {
-	// Return if there is no events to save
-	if len(events) == 0 {
-		return nil
+	return e.SaveBatch(context.Background(), [][]core.Event{events})
+}
+
+// SaveBatch saves the events of several aggregates, either all events are saved or none
+func (e *Memory) SaveBatch(ctx context.Context, batches [][]core.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
 	// make sure its thread safe
 	e.lock.Lock()
 	defer e.lock.Unlock()
 
-	// get bucket name from first event
-	aggregateType := events[0].AggregateType
-	aggregateID := events[0].AggregateID
-	bucketName := aggregateKey(aggregateType, aggregateID)
-
-	evBucket := e.aggregateEvents[bucketName]
-	currentVersion := core.Version(0)
-
-	if len(evBucket) > 0 {
-		// Last version in the list
-		lastEvent := evBucket[len(evBucket)-1]
-		currentVersion = lastEvent.Version
+	// check the versions of all batches before any event is saved, the batches could hold events from the same
+	// aggregate
+	versions := make(map[string]core.Version)
+	for _, events := range batches {
+		if len(events) == 0 {
+			continue
+		}
+		key := aggregateKey(events[0].AggregateType, events[0].AggregateID)
+		currentVersion, ok := versions[key]
+		if !ok {
+			currentVersion = e.currentVersion(key)
+		}
+		// Make sure no other has saved event to the same aggregate concurrently
+		if currentVersion+1 != events[0].Version {
+			return core.ErrConcurrency
+		}
+		versions[key] = events[len(events)-1].Version
 	}
 
-	// Make sure no other has saved event to the same aggregate concurrently
-	if core.Version(currentVersion)+1 != events[0].Version {
-		return core.ErrConcurrency
+	for _, events := range batches {
+		for i, event := range events {
+			e.globalVersion++
+			event.GlobalVersion = e.globalVersion
+			e.categoryVersion[event.AggregateType]++
+			event.CategoryVersion = e.categoryVersion[event.AggregateType]
+			key := aggregateKey(event.AggregateType, event.AggregateID)
+			e.aggregateEvents[key] = append(e.aggregateEvents[key], event)
+			e.eventsInOrder = append(e.eventsInOrder, event)
+			e.categories[event.AggregateType] = append(e.categories[event.AggregateType], event)
+			if event.CorrelationID != "" {
+				e.correlations[event.CorrelationID] = append(e.correlations[event.CorrelationID], event)
+			}
+			// override the event in the slice exposing the GlobalVersion to the caller
+			events[i].GlobalVersion = event.GlobalVersion
+		}
 	}
+	return nil
+}
 
-	for i, event := range events {
-		// set the global version on the event +1 as if the event was already on the eventsInOrder slice
-		event.GlobalVersion = core.Version(len(e.eventsInOrder) + 1)
-		evBucket = append(evBucket, event)
-		e.eventsInOrder = append(e.eventsInOrder, event)
-		// override the event in the slice exposing the GlobalVersion to the caller
-		events[i].GlobalVersion = event.GlobalVersion
+// currentVersion returns the version of the last event in the aggregate bucket
+func (e *Memory) currentVersion(key string) core.Version {
+	evBucket := e.aggregateEvents[key]
+	if len(evBucket) == 0 {
+		return 0
 	}
-
-	e.aggregateEvents[bucketName] = evBucket
-	return nil
+	return evBucket[len(evBucket)-1].Version
 }
 
 // Get aggregate events
 func (e *Memory) Get(ctx context.Context, id string, aggregateType string, afterVersion core.Version) (core.Iterator, error) {
-	var events []core.Event
 	// make sure its thread safe
 	e.lock.Lock()
 	defer e.lock.Unlock()
 
-	for _, e := range e.aggregateEvents[aggregateKey(aggregateType, id)] {
-		if e.Version > afterVersion {
-			events = append(events, e)
+	// the aggregate events are in version order, skip the events up to the after version
+	stream := e.aggregateEvents[aggregateKey(aggregateType, id)]
+	i := sort.Search(len(stream), func(i int) bool {
+		return stream[i].Version > afterVersion
+	})
+	return &iterator{events: stream[i:len(stream):len(stream)]}, ctx.Err()
+}
+
+// LatestVersion returns the version of the last aggregate event, zero if the aggregate has no events
+func (e *Memory) LatestVersion(ctx context.Context, id string, aggregateType string) (core.Version, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.currentVersion(aggregateKey(aggregateType, id)), ctx.Err()
+}
+
+// GlobalVersion returns the global version of the last saved event, zero if no events are saved
+func (e *Memory) GlobalVersion(ctx context.Context) (core.Version, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.globalVersion, ctx.Err()
+}
+
+// GetPage returns a page of the aggregate events newest-first. The offset is the number of events to skip from
+// the end of the stream and limit the max number of events in the page. The total number of events in the
+// aggregate event stream is returned next to the iterator.
+func (e *Memory) GetPage(ctx context.Context, id string, aggregateType string, offset, limit uint64) (core.Iterator, uint64, error) {
+	// make sure its thread safe
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	stream := e.aggregateEvents[aggregateKey(aggregateType, id)]
+	total := uint64(len(stream))
+	if offset >= total {
+		return core.ZeroIterator{}, total, ctx.Err()
+	}
+
+	// the versions in the stream are consecutive, the page starts offset versions below the last event
+	before := stream[total-1].Version - core.Version(offset) + 1
+	return &iterator{events: descending(stream, before, limit, eventVersion)}, total, ctx.Err()
+}
+
+// GetDescending returns at most count aggregate events with a version lower than before newest first, a before of
+// zero starts at the last event
+func (e *Memory) GetDescending(ctx context.Context, id string, aggregateType string, before core.Version, count uint64) (core.Iterator, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return &iterator{events: descending(e.aggregateEvents[aggregateKey(aggregateType, id)], before, count, eventVersion)}, ctx.Err()
+}
+
+// eventVersion returns the aggregate version of the event
+func eventVersion(event core.Event) core.Version {
+	return event.Version
+}
+
+// LastEvent returns the newest event of the aggregate, core.ErrNoEvents if the aggregate has no events
+func (e *Memory) LastEvent(ctx context.Context, id string, aggregateType string) (core.Event, error) {
+	iterator, err := e.GetDescending(ctx, id, aggregateType, 0, 1)
+	if err != nil {
+		return core.Event{}, err
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		return core.Event{}, core.ErrNoEvents
+	}
+	return iterator.Value()
+}
+
+// AllDescending returns at most count events with a global version lower than before newest first, a before of zero
+// starts at the last saved event
+func (e *Memory) AllDescending(ctx context.Context, before core.Version, count uint64) (core.Iterator, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return &iterator{events: descending(e.eventsInOrder, before, count, func(event core.Event) core.Version {
+		return event.GlobalVersion
+	})}, ctx.Err()
+}
+
+// descending returns at most count of the ordered events with a version lower than before, newest first
+func descending(events []core.Event, before core.Version, count uint64, version func(event core.Event) core.Version) []core.Event {
+	i := len(events)
+	if before > 0 {
+		i = sort.Search(len(events), func(i int) bool {
+			return version(events[i]) >= before
+		})
+	}
+	result := make([]core.Event, 0)
+	for i--; i >= 0 && uint64(len(result)) < count; i-- {
+		result = append(result, events[i])
+	}
+	return result
+}
+
+// DeleteEvents removes the aggregate events with a version lower than before
+func (e *Memory) DeleteEvents(ctx context.Context, id string, aggregateType string, before core.Version) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	key := aggregateKey(aggregateType, id)
+	kept := make([]core.Event, 0)
+	for _, event := range e.aggregateEvents[key] {
+		if event.Version >= before {
+			kept = append(kept, event)
 		}
 	}
-	return &iterator{events: events}, ctx.Err()
+	e.aggregateEvents[key] = kept
+
+	inOrder := make([]core.Event, 0, len(e.eventsInOrder))
+	for _, event := range e.eventsInOrder {
+		if event.AggregateID == id && event.AggregateType == aggregateType && event.Version < before {
+			continue
+		}
+		inOrder = append(inOrder, event)
+	}
+	e.eventsInOrder = inOrder
+
+	category := make([]core.Event, 0, len(e.categories[aggregateType]))
+	for _, event := range e.categories[aggregateType] {
+		if event.AggregateID == id && event.Version < before {
+			continue
+		}
+		category = append(category, event)
+	}
+	e.categories[aggregateType] = category
+
+	for correlationID, events := range e.correlations {
+		correlated := make([]core.Event, 0, len(events))
+		for _, event := range events {
+			if event.AggregateID == id && event.AggregateType == aggregateType && event.Version < before {
+				continue
+			}
+			correlated = append(correlated, event)
+		}
+		if len(correlated) == 0 {
+			delete(e.correlations, correlationID)
+			continue
+		}
+		e.correlations[correlationID] = correlated
+	}
+	return nil
 }
 
 // Close does nothing
 func (e *Memory) Close() {}
 
+// Stats counts the events in memory, the size is not measured
+func (e *Memory) Stats(ctx context.Context) (core.Stats, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	stats := core.Stats{
+		Events:         uint64(len(e.eventsInOrder)),
+		Head:           e.globalVersion,
+		AggregateTypes: make(map[string]uint64),
+		Size:           -1,
+	}
+	for _, event := range e.eventsInOrder {
+		stats.AggregateTypes[event.AggregateType]++
+	}
+	return stats, nil
+}
+
+// CountAll returns the number of events in the event store
+func (e *Memory) CountAll(ctx context.Context) (uint64, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return uint64(len(e.eventsInOrder)), ctx.Err()
+}
+
+// CountAggregate returns the number of events of the aggregate
+func (e *Memory) CountAggregate(ctx context.Context, id string, aggregateType string) (uint64, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return uint64(len(e.aggregateEvents[aggregateKey(aggregateType, id)])), ctx.Err()
+}
+
+// CountByReason returns the number of events with the reason
+func (e *Memory) CountByReason(ctx context.Context, reason string) (uint64, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	var count uint64
+	for _, event := range e.eventsInOrder {
+		if event.Reason == reason {
+			count++
+		}
+	}
+	return count, ctx.Err()
+}
+
+// Health is always healthy, the details hold the global version of the last saved event
+func (e *Memory) Health(ctx context.Context) core.Health {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return core.Health{
+		Name:    "memory",
+		Healthy: true,
+		Details: map[string]string{"head": strconv.FormatUint(uint64(e.globalVersion), 10)},
+	}
+}
+
 // aggregateKey generates a key to store events against from aggregateType and aggregateID
 func aggregateKey(aggregateType, aggregateID string) string {
 	return aggregateType + "_" + aggregateID
 }
 
-// globalEvents returns count events in order globally from the start position
-func (e *Memory) globalEvents(start core.Version, count uint64) ([]core.Event, error) {
-	events := make([]core.Event, 0, count)
+// globalEvents returns an iterator of count events matching the filter in order globally from the start position, a
+// count of zero has no limit
+func (e *Memory) globalEvents(start core.Version, count uint64, filter core.Filter) *iterator {
 	// make sure its thread safe
 	e.lock.Lock()
 	defer e.lock.Unlock()
 
-	for _, e := range e.eventsInOrder {
-		// find start position and append until counter is 0
-		if e.GlobalVersion >= start {
-			events = append(events, e)
-			count--
-			if count == 0 {
-				break
-			}
-		}
-	}
-	return events, nil
+	// the events are in global version order, skip the events before the start position
+	i := sort.Search(len(e.eventsInOrder), func(i int) bool {
+		return e.eventsInOrder[i].GlobalVersion >= start
+	})
+	n := len(e.eventsInOrder)
+	return &iterator{events: e.eventsInOrder[i:n:n], filter: filter, limit: count}
 }
 
 // All iterate over all events in GlobalEvents order
 func (m *Memory) All(start core.Version, count uint64) func() (core.Iterator, error) {
-	return func() (core.Iterator, error) {
-		events, err := m.globalEvents(start, count)
-		if err != nil {
-			return nil, err
-		}
+	return m.AllWithFilter(start, count, core.Filter{})
+}
 
-		// no events to fetch
-		if len(events) == 0 {
-			return core.ZeroIterator{}, nil
-		}
+// AllByType iterate over the events of the aggregate types in GlobalEvents order
+func (m *Memory) AllByType(start core.Version, count uint64, aggregateTypes ...string) func() (core.Iterator, error) {
+	return m.AllWithFilter(start, count, core.ByType(aggregateTypes...))
+}
+
+// AllByReason iterate over the events with any of the reasons in GlobalEvents order
+func (m *Memory) AllByReason(start core.Version, count uint64, reasons ...string) func() (core.Iterator, error) {
+	return m.AllWithFilter(start, count, core.ByReason(reasons...))
+}
 
-		// next time the function is called it will start from the last fetched event +1
-		start = events[len(events)-1].GlobalVersion + 1
-		return &iterator{events: events}, nil
+// Between iterate over the events with a timestamp after from and before to in GlobalEvents order
+func (m *Memory) Between(ctx context.Context, from, to time.Time) (core.Iterator, error) {
+	return m.globalEvents(0, 0, core.Filter{}.After(from).Before(to)), ctx.Err()
+}
+
+// Category returns at most count events of the aggregate type with a category version after the position, in category
+// order
+func (m *Memory) Category(ctx context.Context, aggregateType string, after core.Version, count uint64) (core.Iterator, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	category := m.categories[aggregateType]
+	i := sort.Search(len(category), func(i int) bool {
+		return category[i].CategoryVersion > after
+	})
+	if count == 0 {
+		return core.ZeroIterator{}, ctx.Err()
+	}
+	return &iterator{events: category[i:len(category):len(category)], limit: count}, ctx.Err()
+}
+
+// ByCorrelationID returns the events with the correlation id in GlobalEvents order
+func (m *Memory) ByCorrelationID(ctx context.Context, correlationID string) (core.Iterator, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	events := m.correlations[correlationID]
+	return &iterator{events: events[:len(events):len(events)]}, ctx.Err()
+}
+
+// AllWithFilter iterate over the events matching the filter in GlobalEvents order. The metadata part of the filter is
+// not evaluated as the metadata is serialized.
+func (m *Memory) AllWithFilter(start core.Version, count uint64, filter core.Filter) func() (core.Iterator, error) {
+	var last *iterator
+	return func() (core.Iterator, error) {
+		// next time the function is called it will start from the last iterated event +1
+		if last != nil && last.count > 0 {
+			start = last.event.GlobalVersion + 1
+		}
+		last = m.globalEvents(start, count, filter)
+		return last, nil
 	}
 }
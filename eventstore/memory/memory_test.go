@@ -1,7 +1,9 @@
 package memory_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/core/testsuite"
@@ -15,3 +17,291 @@ func TestSuite(t *testing.T) {
 	}
 	testsuite.Test(t, f)
 }
+
+func TestEventDeleter(t *testing.T) {
+	f := func() (core.EventStore, core.EventDeleter, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestEventDeleter(t, f)
+}
+
+func TestBatchSaver(t *testing.T) {
+	f := func() (core.EventStore, core.BatchSaver, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestBatchSaver(t, f)
+}
+
+func TestVersionReader(t *testing.T) {
+	f := func() (core.EventStore, core.VersionReader, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestVersionReader(t, f)
+}
+
+func TestReverseReader(t *testing.T) {
+	f := func() (core.EventStore, core.ReverseReader, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestReverseReader(t, f)
+}
+
+func TestLastEventReader(t *testing.T) {
+	f := func() (core.EventStore, core.LastEventReader, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestLastEventReader(t, f)
+}
+
+func TestCorrelationReader(t *testing.T) {
+	f := func() (core.EventStore, core.CorrelationReader, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestCorrelationReader(t, f)
+}
+
+func TestTimeRangeReader(t *testing.T) {
+	f := func() (core.EventStore, core.TimeRangeReader, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestTimeRangeReader(t, f)
+}
+
+func TestGlobalVersionReader(t *testing.T) {
+	f := func() (core.EventStore, core.GlobalVersionReader, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestGlobalVersionReader(t, f)
+}
+
+func TestPager(t *testing.T) {
+	f := func() (core.EventStore, core.Pager, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestPager(t, f)
+}
+
+func TestAllWithFilter(t *testing.T) {
+	es := memory.Create()
+	defer es.Close()
+
+	err := es.Save([]core.Event{
+		{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born"},
+		{AggregateID: "123", AggregateType: "Person", Version: 2, Reason: "AgedOneYear"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{{AggregateID: "123", AggregateType: "Order", Version: 1, Reason: "Created"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchF := es.AllWithFilter(0, 10, core.ByType("Person").ByReason("Born", "Created"))
+	iterator, err := fetchF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected the Born event")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Reason != "Born" {
+		t.Fatalf("expected reason Born was %s", event.Reason)
+	}
+	if iterator.Next() {
+		t.Fatal("expected only one event to match the filter")
+	}
+}
+
+func TestAllResumesAfterIteratedEvent(t *testing.T) {
+	es := memory.Create()
+	defer es.Close()
+
+	events := make([]core.Event, 0, 5)
+	for i := 1; i <= 5; i++ {
+		events = append(events, core.Event{AggregateID: "123", AggregateType: "Person", Version: core.Version(i), Reason: "AgedOneYear"})
+	}
+	err := es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchF := es.All(0, 10)
+	iterator, err := fetchF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stop after two events, the next fetch continues from the third
+	iterator.Next()
+	iterator.Next()
+	iterator.Close()
+
+	// events saved after the first fetch are read by the next
+	err = es.Save([]core.Event{{AggregateID: "123", AggregateType: "Person", Version: 6, Reason: "AgedOneYear"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err = fetchF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	var versions []core.Version
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, event.GlobalVersion)
+	}
+	if len(versions) != 4 || versions[0] != 3 || versions[3] != 6 {
+		t.Fatalf("expected the global versions 3 to 6 was %v", versions)
+	}
+}
+
+func TestHealthChecker(t *testing.T) {
+	f := func() (core.EventStore, core.HealthChecker, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestHealthChecker(t, f)
+}
+
+func TestStatsReader(t *testing.T) {
+	f := func() (core.EventStore, core.StatsReader, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestStatsReader(t, f)
+}
+
+func TestAllByType(t *testing.T) {
+	es := memory.Create()
+	defer es.Close()
+
+	for i := 1; i <= 3; i++ {
+		err := es.Save([]core.Event{{AggregateID: "123", AggregateType: "Person", Version: core.Version(i), Reason: "Born"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = es.Save([]core.Event{{AggregateID: "123", AggregateType: "Order", Version: core.Version(i), Reason: "Created"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	iterator, err := es.AllByType(3, 10, "Order")()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	var versions []core.Version
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.AggregateType != "Order" {
+			t.Fatalf("expected aggregate type Order was %s", event.AggregateType)
+		}
+		versions = append(versions, event.GlobalVersion)
+	}
+	if len(versions) != 2 || versions[0] != 4 || versions[1] != 6 {
+		t.Fatalf("expected the Order events with global version 4 and 6 was %v", versions)
+	}
+}
+
+func TestAllByReason(t *testing.T) {
+	es := memory.Create()
+	defer es.Close()
+
+	err := es.Save([]core.Event{
+		{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born"},
+		{AggregateID: "123", AggregateType: "Person", Version: 2, Reason: "AgedOneYear"},
+		{AggregateID: "123", AggregateType: "Person", Version: 3, Reason: "Deceased"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := es.AllByReason(0, 10, "Born", "Deceased")()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	var reasons []string
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		reasons = append(reasons, event.Reason)
+	}
+	if len(reasons) != 2 || reasons[0] != "Born" || reasons[1] != "Deceased" {
+		t.Fatalf("expected the Born and Deceased events was %v", reasons)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	es := memory.Create()
+	defer es.Close()
+
+	now := time.Now()
+	err := es.Save([]core.Event{
+		{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: now.Add(-2 * time.Hour)},
+		{AggregateID: "123", AggregateType: "Person", Version: 2, Reason: "AgedOneYear", Timestamp: now.Add(-time.Hour)},
+		{AggregateID: "123", AggregateType: "Person", Version: 3, Reason: "AgedOneYear", Timestamp: now},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := es.Between(context.Background(), now.Add(-90*time.Minute), now.Add(-30*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected an event in the time range")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Version != 2 {
+		t.Fatalf("expected the event with version 2 was %d", event.Version)
+	}
+	if iterator.Next() {
+		t.Fatal("expected only one event in the time range")
+	}
+}
+
+func TestEventCounter(t *testing.T) {
+	f := func() (core.EventStore, core.EventCounter, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestEventCounter(t, f)
+}
+
+func TestCategoryReader(t *testing.T) {
+	f := func() (core.EventStore, core.CategoryReader, func(), error) {
+		es := memory.Create()
+		return es, es, func() { es.Close() }, nil
+	}
+	testsuite.TestCategoryReader(t, f)
+}
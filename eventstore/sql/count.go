@@ -0,0 +1,26 @@
+package sql
+
+import (
+	"context"
+)
+
+// CountAll returns the number of events in the event store
+func (s *SQL) CountAll(ctx context.Context) (uint64, error) {
+	return s.count(ctx, `Select count(*) from events`)
+}
+
+// CountAggregate returns the number of events of the aggregate, counted on the id and type index
+func (s *SQL) CountAggregate(ctx context.Context, id string, aggregateType string) (uint64, error) {
+	return s.count(ctx, `Select count(*) from events where id=? and type=?`, id, aggregateType)
+}
+
+// CountByReason returns the number of events with the reason, counted on the reason index
+func (s *SQL) CountByReason(ctx context.Context, reason string) (uint64, error) {
+	return s.count(ctx, `Select count(*) from events where reason=?`, reason)
+}
+
+func (s *SQL) count(ctx context.Context, stm string, args ...interface{}) (uint64, error) {
+	var count uint64
+	err := s.db.QueryRowContext(ctx, stm, args...).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,16 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+)
+
+func TestEventCounter(t *testing.T) {
+	f := func() (core.EventStore, core.EventCounter, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestEventCounter(t, f)
+}
@@ -0,0 +1,27 @@
+package sql
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Health pings the database and reads the global version of the last saved event, the head of the global event stream
+func (s *SQL) Health(ctx context.Context) core.Health {
+	health := core.Health{Name: "sql"}
+	err := s.db.PingContext(ctx)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	var head uint64
+	err = s.db.QueryRowContext(ctx, `Select coalesce(max(seq), 0) from events`).Scan(&head)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	health.Healthy = true
+	health.Details = map[string]string{"head": strconv.FormatUint(head, 10)}
+	return health
+}
@@ -0,0 +1,29 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+)
+
+func TestHealthChecker(t *testing.T) {
+	f := func() (core.EventStore, core.HealthChecker, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestHealthChecker(t, f)
+}
+
+func TestHealthClosed(t *testing.T) {
+	es, closeFunc, err := eventstore(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	closeFunc()
+	health := es.Health(context.Background())
+	if health.Healthy || health.Error == "" {
+		t.Fatalf("expected unhealthy event store on a closed database got %+v", health)
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 type iterator struct {
 	rows *sql.Rows
+	// tx is set when the rows are read in a transaction owned by the iterator
+	tx *sql.Tx
 }
 
 // Next return true if there are more data
@@ -22,8 +24,11 @@ func (i *iterator) Value() (core.Event, error) {
 	var version core.Version
 	var id, reason, typ, timestamp string
 	var data, metadata []byte
+	var schemaVersion uint
+	var categoryVersion core.Version
+	var correlationID string
 
-	if err := i.rows.Scan(&globalVersion, &id, &version, &reason, &typ, &timestamp, &data, &metadata); err != nil {
+	if err := i.rows.Scan(&globalVersion, &id, &version, &reason, &typ, &timestamp, &data, &metadata, &schemaVersion, &categoryVersion, &correlationID); err != nil {
 		return core.Event{}, err
 	}
 
@@ -41,6 +46,10 @@ func (i *iterator) Value() (core.Event, error) {
 		Data:          data,
 		Metadata:      metadata,
 		Reason:        reason,
+		SchemaVersion: schemaVersion,
+
+		CategoryVersion: categoryVersion,
+		CorrelationID:   correlationID,
 	}
 	return event, nil
 }
@@ -48,4 +57,7 @@ func (i *iterator) Value() (core.Event, error) {
 // Close closes the iterator
 func (i *iterator) Close() {
 	i.rows.Close()
+	if i.tx != nil {
+		i.tx.Rollback()
+	}
 }
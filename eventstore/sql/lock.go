@@ -0,0 +1,45 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+)
+
+// AdvisoryLockStore locks aggregates with Postgres transaction level advisory locks. The lock is held by a transaction
+// that is open until the lock is released, each held lock occupies one connection from the pool.
+//
+// It requires Postgres, unlike the event store it's not usable with sqlite. Other databases have no advisory locks and
+// Lock returns the error from the database.
+type AdvisoryLockStore struct {
+	db *sql.DB
+}
+
+// NewAdvisoryLockStore creates a lock store taking the advisory locks in the Postgres database
+func NewAdvisoryLockStore(db *sql.DB) *AdvisoryLockStore {
+	return &AdvisoryLockStore{db: db}
+}
+
+// Lock blocks until the advisory lock on the aggregate is acquired or the context is done
+func (l *AdvisoryLockStore) Lock(ctx context.Context, id, aggregateType string) (func() error, error) {
+	// the transaction is not bound to the context, it would be rolled back and the lock released when the context is
+	// done while the lock is held
+	tx, err := l.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, lockKey(id, aggregateType))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	// the lock is released when the transaction ends
+	return tx.Rollback, nil
+}
+
+// lockKey hashes the aggregate to the bigint key of the advisory lock
+func lockKey(id, aggregateType string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(aggregateType + "_" + id))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,25 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/eventstore/sql"
+)
+
+// the advisory locks are Postgres only, on sqlite the lock fails instead of leaving the aggregate unlocked
+func TestAdvisoryLockStoreRequiresPostgres(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	locks := sql.NewAdvisoryLockStore(db)
+	unlock, err := locks.Lock(context.Background(), "123", "Person")
+	if err == nil {
+		unlock()
+		t.Fatal("expected the lock to fail on sqlite")
+	}
+}
@@ -2,9 +2,35 @@ package sql
 
 import (
 	"context"
+	"time"
 )
 
-const createTable = `create table events (seq INTEGER PRIMARY KEY AUTOINCREMENT, id VARCHAR NOT NULL, version INTEGER, reason VARCHAR, type VARCHAR, timestamp VARCHAR, data BLOB, metadata BLOB);`
+const createTable = `create table events (seq INTEGER PRIMARY KEY AUTOINCREMENT, id VARCHAR NOT NULL, version INTEGER, reason VARCHAR, type VARCHAR, timestamp VARCHAR, data BLOB, metadata BLOB, schema_version INTEGER NOT NULL DEFAULT 0);`
+
+const addSchemaVersion = `alter table events add column schema_version INTEGER NOT NULL DEFAULT 0;`
+
+// feedIndexes lets the global feed filtered on aggregate type, reason or time scan an index instead of the whole table
+var feedIndexes = []string{
+	`create index if not exists type_seq on events (type, seq);`,
+	`create index if not exists reason_seq on events (reason, seq);`,
+	`create index if not exists timestamp_seq on events (timestamp, seq);`,
+}
+
+// categoryStms adds the category_seq column and the categories table holding the position of each category stream
+var categoryStms = []string{
+	`alter table events add column category_seq INTEGER;`,
+	`create table if not exists categories (type VARCHAR PRIMARY KEY, position INTEGER NOT NULL);`,
+	`update events set category_seq = (select count(*) from events e where e.type = events.type and e.seq <= events.seq);`,
+	`insert into categories (type, position) select type, max(category_seq) from events group by type;`,
+	`create unique index type_category_seq on events (type, category_seq);`,
+}
+
+// correlationStms adds the correlation_id column and its index, the events saved before the column existed are not
+// correlated as the metadata they were saved with is serialized
+var correlationStms = []string{
+	`alter table events add column correlation_id VARCHAR;`,
+	`create index if not exists correlation_seq on events (correlation_id, seq);`,
+}
 
 // Migrate the database
 func (s *SQL) Migrate() error {
@@ -13,7 +39,133 @@ func (s *SQL) Migrate() error {
 		`create unique index id_type_version on events (id, type, version);`,
 		`create index id_type on events (id, type);`,
 	}
-	return s.migrate(sqlStmt)
+	err := s.migrate(sqlStmt)
+	if err != nil {
+		return err
+	}
+	err = s.migrateSchemaVersion()
+	if err != nil {
+		return err
+	}
+	err = s.migrateIndexes()
+	if err != nil {
+		return err
+	}
+	err = s.migrateCategories()
+	if err != nil {
+		return err
+	}
+	err = s.migrateCorrelations()
+	if err != nil {
+		return err
+	}
+	return s.migrateTimestamps()
+}
+
+// migrateTimestamps rewrites the timestamps stored in RFC3339 with second precision and the zone of the writer, by
+// earlier versions, to UTC with fixed precision to make them comparable as strings with the later ones
+func (s *SQL) migrateTimestamps() error {
+	rows, err := s.db.Query(`Select seq, timestamp from events where length(timestamp) <> ?`, len(timestampLayout))
+	if err != nil {
+		return err
+	}
+	stored := make(map[int64]string)
+	for rows.Next() {
+		var seq int64
+		var ts string
+		err = rows.Scan(&seq, &ts)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		stored[seq] = ts
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil || len(stored) == 0 {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for seq, ts := range stored {
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`update events set timestamp=? where seq=?`, timestamp(t), seq)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// migrateCorrelations adds the correlation_id column to event tables created before it existed
+func (s *SQL) migrateCorrelations() error {
+	rows, err := s.db.Query(`Select correlation_id from events limit 1`)
+	if err == nil {
+		rows.Close()
+		return nil
+	}
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stm := range correlationStms {
+		_, err := tx.Exec(stm)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// migrateCategories adds the category streams. The events saved before the category_seq column existed get their
+// position in the category stream of their aggregate type in global order.
+func (s *SQL) migrateCategories() error {
+	rows, err := s.db.Query(`Select category_seq from events limit 1`)
+	if err == nil {
+		rows.Close()
+		return nil
+	}
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stm := range categoryStms {
+		_, err := tx.Exec(stm)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// migrateIndexes adds the indexes of the filtered global feed, also to event tables created before they existed
+func (s *SQL) migrateIndexes() error {
+	for _, stm := range feedIndexes {
+		_, err := s.db.Exec(stm)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateSchemaVersion adds the schema_version column to event tables created before it existed
+func (s *SQL) migrateSchemaVersion() error {
+	rows, err := s.db.Query(`Select schema_version from events limit 1`)
+	if err == nil {
+		rows.Close()
+		return nil
+	}
+	_, err = s.db.Exec(addSchemaVersion)
+	return err
 }
 
 func (s *SQL) migrate(stm []string) error {
@@ -0,0 +1,40 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+const (
+	createOutbox    = `create table if not exists outbox (seq INTEGER PRIMARY KEY);`
+	insertOutboxStm = `Insert into outbox (seq) values ($1)`
+)
+
+// EnableOutbox creates the outbox table if it doesn't exist and makes the event store write the global version of
+// every saved event to the outbox in the same transaction as the event. The outbox is read by a relay publishing the
+// events to an external system.
+func (s *SQL) EnableOutbox() error {
+	_, err := s.db.Exec(createOutbox)
+	if err != nil {
+		return err
+	}
+	s.outbox = true
+	return nil
+}
+
+// Pending returns at most count events in the outbox in global version order
+func (s *SQL) Pending(ctx context.Context, count uint64) (core.Iterator, error) {
+	selectStm := `Select e.seq, e.id, e.version, e.reason, e.type, e.timestamp, e.data, e.metadata, e.schema_version, coalesce(e.category_seq, 0), coalesce(e.correlation_id, '') from events e join outbox o on e.seq = o.seq order by e.seq asc LIMIT ?`
+	rows, err := s.db.QueryContext(ctx, selectStm, count)
+	if err != nil {
+		return nil, err
+	}
+	return &iterator{rows: rows}, nil
+}
+
+// Published removes the event from the outbox
+func (s *SQL) Published(ctx context.Context, globalVersion core.Version) error {
+	_, err := s.db.ExecContext(ctx, `Delete from outbox where seq=?`, globalVersion)
+	return err
+}
@@ -0,0 +1,77 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/sql"
+)
+
+func TestOutbox(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file:outbox?mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	es := sql.Open(db)
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.EnableOutbox()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := []core.Event{
+		{AggregateID: "1", AggregateType: "User", Version: 1, Reason: "Registered", Timestamp: time.Now()},
+		{AggregateID: "1", AggregateType: "User", Version: 2, Reason: "Renamed", Timestamp: time.Now()},
+	}
+	err = es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the failing transaction rolls back the outbox together with the events
+	err = es.WithTx(func(tx *sqldriver.Tx) error {
+		return errors.New("rollback")
+	}).Save([]core.Event{{AggregateID: "2", AggregateType: "User", Version: 1, Reason: "Registered", Timestamp: time.Now()}})
+	if err == nil {
+		t.Fatal("expected error from the transaction function")
+	}
+
+	pending := func() []core.Version {
+		iterator, err := es.Pending(context.Background(), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer iterator.Close()
+		var versions []core.Version
+		for iterator.Next() {
+			event, err := iterator.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			versions = append(versions, event.GlobalVersion)
+		}
+		return versions
+	}
+	versions := pending()
+	if len(versions) != 2 || versions[0] != events[0].GlobalVersion || versions[1] != events[1].GlobalVersion {
+		t.Fatalf("expected the saved events in the outbox was %v", versions)
+	}
+
+	err = es.Published(context.Background(), events[0].GlobalVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions = pending()
+	if len(versions) != 1 || versions[0] != events[1].GlobalVersion {
+		t.Fatalf("expected the unpublished event in the outbox was %v", versions)
+	}
+}
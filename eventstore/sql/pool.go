@@ -0,0 +1,81 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Pool configures the connection pool of the database, zero values leave the setting unchanged
+type Pool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// SetPool applies the pool configuration to the database
+func (s *SQL) SetPool(p Pool) {
+	if p.MaxOpenConns > 0 {
+		s.db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		s.db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		s.db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+}
+
+// Warmup opens conns connections to the database and prepares the statements used by Save and Get. It's meant to be
+// called after a deploy, before the event store takes traffic, to not pay the cold start latency on the first
+// commands. The connections are kept idle in the pool if the pool allows that many idle connections.
+func (s *SQL) Warmup(ctx context.Context, conns int) error {
+	opened := make([]*sql.Conn, 0, conns)
+	defer func() {
+		// return the connections to the pool
+		for _, conn := range opened {
+			conn.Close()
+		}
+	}()
+	for i := 0; i < conns; i++ {
+		conn, err := s.db.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		opened = append(opened, conn)
+		err = conn.PingContext(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	queries := []string{selectVersionStm, insertStm, selectEventsStm, insertCategoryStm, nextCategoryStm}
+	if s.outbox {
+		queries = append(queries, insertOutboxStm)
+	}
+	for _, query := range queries {
+		_, err := s.stmt(ctx, query)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stmt returns the prepared statement of the query, the statement is prepared the first time it's used
+func (s *SQL) stmt(ctx context.Context, query string) (*sql.Stmt, error) {
+	s.stmtLock.Lock()
+	defer s.stmtLock.Unlock()
+
+	if stmt, ok := s.stmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := s.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if s.stmts == nil {
+		s.stmts = make(map[string]*sql.Stmt)
+	}
+	s.stmts[query] = stmt
+	return stmt, nil
+}
@@ -3,24 +3,38 @@ package sql
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/hallgren/eventsourcing/core"
 )
 
+const (
+	// eventColumns are the columns read by the iterator
+	eventColumns      = `seq, id, version, reason, type, timestamp, data, metadata, schema_version, coalesce(category_seq, 0), coalesce(correlation_id, '')`
+	selectVersionStm  = `Select version from events where id=? and type=? order by version desc limit 1`
+	insertStm         = `Insert into events (id, version, reason, type, timestamp, data, metadata, schema_version, category_seq, correlation_id) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	selectEventsStm   = `Select ` + eventColumns + ` from events where id=? and type=? and version>? order by version asc`
+	insertCategoryStm = `Insert into categories (type, position) values ($1, 0) on conflict (type) do nothing`
+	nextCategoryStm   = `Update categories set position=position+$1 where type=$2 returning position`
+)
+
 // SQL event store handler
 type SQL struct {
-	db   *sql.DB
-	lock *sync.Mutex
+	db       *sql.DB
+	lock     *sync.Mutex
+	stmtLock *sync.Mutex
+	stmts    map[string]*sql.Stmt // prepared statements cached by query
+	outbox   bool                 // write the saved events to the outbox table
 }
 
 // Open connection to database
 func Open(db *sql.DB) *SQL {
 	return &SQL{
-		db: db,
+		db:       db,
+		stmtLock: &sync.Mutex{},
 	}
 }
 
@@ -36,20 +50,72 @@ func Open(db *sql.DB) *SQL {
 // This is usually easier if all writers are part of the same operating system process."
 func OpenWithSingelWriter(db *sql.DB) *SQL {
 	return &SQL{
-		db:   db,
-		lock: &sync.Mutex{},
+		db:       db,
+		lock:     &sync.Mutex{},
+		stmtLock: &sync.Mutex{},
 	}
 }
 
-// Close the connection
+// Close the prepared statements and the connection
 func (s *SQL) Close() {
+	s.stmtLock.Lock()
+	for _, stmt := range s.stmts {
+		stmt.Close()
+	}
+	s.stmts = nil
+	s.stmtLock.Unlock()
 	s.db.Close()
 }
 
 // Save persists events to the database
 func (s *SQL) Save(events []core.Event) error {
+	return s.save(context.Background(), [][]core.Event{events}, nil)
+}
+
+// SaveContext persists events to the database, the transaction is rolled back if the context is done
+func (s *SQL) SaveContext(ctx context.Context, events []core.Event) error {
+	return s.save(ctx, [][]core.Event{events}, nil)
+}
+
+// WithTx returns an event store where f is called within the same transaction as the saved events. It makes it
+// possible to enlist application writes, like a table reserving unique emails, that are committed together with the
+// events. If f returns an error the transaction is rolled back and the error is returned from Save. The events have
+// their GlobalVersion set when f is called.
+func (s *SQL) WithTx(f func(tx *sql.Tx) error) core.EventStore {
+	return &txStore{SQL: s, txF: f}
+}
+
+// txStore is the event store returned from WithTx
+type txStore struct {
+	*SQL
+	txF func(tx *sql.Tx) error
+}
+
+// Save persists events and calls the transaction function in the same transaction
+func (s *txStore) Save(events []core.Event) error {
+	return s.save(context.Background(), [][]core.Event{events}, s.txF)
+}
+
+// SaveContext persists events and calls the transaction function in the same transaction bound by the context
+func (s *txStore) SaveContext(ctx context.Context, events []core.Event) error {
+	return s.save(ctx, [][]core.Event{events}, s.txF)
+}
+
+// SaveBatch persists the events of several aggregates in one transaction, either all events are saved or none
+func (s *SQL) SaveBatch(ctx context.Context, batches [][]core.Event) error {
+	return s.save(ctx, batches, nil)
+}
+
+// save persists the event batches and calls txF, if not nil, before the transaction is committed
+func (s *SQL) save(ctx context.Context, batches [][]core.Event, txF func(tx *sql.Tx) error) error {
 	// If no event return no error
-	if len(events) == 0 {
+	empty := true
+	for _, events := range batches {
+		if len(events) > 0 {
+			empty = false
+		}
+	}
+	if empty {
 		return nil
 	}
 
@@ -58,19 +124,98 @@ func (s *SQL) Save(events []core.Event) error {
 		s.lock.Lock()
 		defer s.lock.Unlock()
 	}
-	aggregateID := events[0].AggregateID
-	aggregateType := events[0].AggregateType
 
-	tx, err := s.db.BeginTx(context.Background(), nil)
+	// prepare the statements before the transaction holds a connection from the pool
+	selectStmt, err := s.stmt(ctx, selectVersionStm)
+	if err != nil {
+		return err
+	}
+	insertStmt, err := s.stmt(ctx, insertStm)
+	if err != nil {
+		return err
+	}
+
+	insertCategoryStmt, err := s.stmt(ctx, insertCategoryStm)
+	if err != nil {
+		return err
+	}
+	nextCategoryStmt, err := s.stmt(ctx, nextCategoryStm)
+	if err != nil {
+		return err
+	}
+
+	var outboxStmt *sql.Stmt
+	if s.outbox {
+		outboxStmt, err = s.stmt(ctx, insertOutboxStm)
+		if err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return errors.New(fmt.Sprintf("could not start a write transaction, %v", err))
+		return fmt.Errorf("could not start a write transaction, %w", err)
 	}
 	defer tx.Rollback()
 
+	selectVersion := tx.StmtContext(ctx, selectStmt)
+	insert := tx.StmtContext(ctx, insertStmt)
+	category := categoryStmts{
+		insert: tx.StmtContext(ctx, insertCategoryStmt),
+		next:   tx.StmtContext(ctx, nextCategoryStmt),
+	}
+	var insertOutbox *sql.Stmt
+	if outboxStmt != nil {
+		insertOutbox = tx.StmtContext(ctx, outboxStmt)
+	}
+	for _, events := range batches {
+		err = saveTx(ctx, selectVersion, insert, insertOutbox, category, events)
+		if err != nil {
+			return err
+		}
+	}
+	if txF != nil {
+		err = txF(tx)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// categoryStmts are the transaction statements moving the position of a category stream
+type categoryStmts struct {
+	insert *sql.Stmt // insert the category if it's not in the categories table
+	next   *sql.Stmt // move the position of the category and return it
+}
+
+// reserve moves the position of the category stream of the aggregate type n events forward and returns the category
+// version of the first event
+func (c categoryStmts) reserve(ctx context.Context, aggregateType string, n int) (core.Version, error) {
+	_, err := c.insert.ExecContext(ctx, aggregateType)
+	if err != nil {
+		return 0, err
+	}
+	var position core.Version
+	err = c.next.QueryRowContext(ctx, n, aggregateType).Scan(&position)
+	if err != nil {
+		return 0, err
+	}
+	return position - core.Version(n) + 1, nil
+}
+
+// saveTx inserts the events of one aggregate with the transaction statements, the events are added to the outbox if
+// insertOutbox is not nil
+func saveTx(ctx context.Context, selectVersion, insert, insertOutbox *sql.Stmt, category categoryStmts, events []core.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	aggregateID := events[0].AggregateID
+	aggregateType := events[0].AggregateType
+
 	var currentVersion core.Version
 	var version int
-	selectStm := `Select version from events where id=? and type=? order by version desc limit 1`
-	err = tx.QueryRow(selectStm, aggregateID, aggregateType).Scan(&version)
+	err := selectVersion.QueryRowContext(ctx, aggregateID, aggregateType).Scan(&version)
 	if err != nil && err != sql.ErrNoRows {
 		return err
 	} else if err == sql.ErrNoRows {
@@ -86,39 +231,261 @@ func (s *SQL) Save(events []core.Event) error {
 		return core.ErrConcurrency
 	}
 
-	var lastInsertedID int64
-	insert := `Insert into events (id, version, reason, type, timestamp, data, metadata) values ($1, $2, $3, $4, $5, $6, $7)`
+	categoryVersion, err := category.reserve(ctx, aggregateType, len(events))
+	if err != nil {
+		return err
+	}
 	for i, event := range events {
-		res, err := tx.Exec(insert, event.AggregateID, event.Version, event.Reason, event.AggregateType, event.Timestamp.Format(time.RFC3339), event.Data, event.Metadata)
+		res, err := insert.ExecContext(ctx, event.AggregateID, event.Version, event.Reason, event.AggregateType, timestamp(event.Timestamp), event.Data, event.Metadata, event.SchemaVersion, categoryVersion+core.Version(i), correlationID(event))
 		if err != nil {
 			return err
 		}
-		lastInsertedID, err = res.LastInsertId()
+		lastInsertedID, err := res.LastInsertId()
 		if err != nil {
 			return err
 		}
 		// override the event in the slice exposing the GlobalVersion to the caller
 		events[i].GlobalVersion = core.Version(lastInsertedID)
+		if insertOutbox != nil {
+			_, err = insertOutbox.ExecContext(ctx, lastInsertedID)
+			if err != nil {
+				return err
+			}
+		}
 	}
-	return tx.Commit()
+	return nil
+}
+
+// correlationID returns the correlation id of the event, nil (NULL) for events not correlated
+func correlationID(event core.Event) interface{} {
+	if event.CorrelationID == "" {
+		return nil
+	}
+	return event.CorrelationID
 }
 
 // Get the events from database
 func (s *SQL) Get(ctx context.Context, id string, aggregateType string, afterVersion core.Version) (core.Iterator, error) {
-	selectStm := `Select seq, id, version, reason, type, timestamp, data, metadata from events where id=? and type=? and version>? order by version asc`
-	rows, err := s.db.QueryContext(ctx, selectStm, id, aggregateType, afterVersion)
+	selectStmt, err := s.stmt(ctx, selectEventsStm)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := selectStmt.QueryContext(ctx, id, aggregateType, afterVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &iterator{rows: rows}, nil
+}
+
+// LatestVersion returns the version of the last aggregate event, zero if the aggregate has no events
+func (s *SQL) LatestVersion(ctx context.Context, id string, aggregateType string) (core.Version, error) {
+	selectStmt, err := s.stmt(ctx, selectVersionStm)
+	if err != nil {
+		return 0, err
+	}
+	var version core.Version
+	err = selectStmt.QueryRowContext(ctx, id, aggregateType).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return version, err
+}
+
+// GlobalVersion returns the global version of the last saved event, zero if no events are saved
+func (s *SQL) GlobalVersion(ctx context.Context) (core.Version, error) {
+	var head core.Version
+	err := s.db.QueryRowContext(ctx, `Select coalesce(max(seq), 0) from events`).Scan(&head)
+	return head, err
+}
+
+// GetPage returns a page of the aggregate events newest-first. The offset is the number of events to skip from
+// the end of the stream and limit the max number of events in the page. The total number of events in the
+// aggregate event stream is returned next to the iterator.
+func (s *SQL) GetPage(ctx context.Context, id string, aggregateType string, offset, limit uint64) (core.Iterator, uint64, error) {
+	// count and select in the same transaction to keep the total in line with the page
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, 0, err
+	}
+	var total uint64
+	var last core.Version
+	countStm := `Select count(*), coalesce(max(version), 0) from events where id=? and type=?`
+	err = tx.QueryRowContext(ctx, countStm, id, aggregateType).Scan(&total, &last)
+	if err != nil {
+		tx.Rollback()
+		return nil, 0, err
+	}
+	if offset >= total || limit == 0 {
+		tx.Rollback()
+		return core.ZeroIterator{}, total, nil
+	}
+
+	// the versions in the stream are consecutive, the page starts offset versions below the last event
+	rows, err := descending(ctx, tx, id, aggregateType, last-core.Version(offset)+1, limit)
+	if err != nil {
+		tx.Rollback()
+		return nil, 0, err
+	}
+	return &iterator{rows: rows, tx: tx}, total, nil
+}
+
+// GetDescending returns at most count aggregate events with a version lower than before newest first, a before of
+// zero starts at the last event
+func (s *SQL) GetDescending(ctx context.Context, id string, aggregateType string, before core.Version, count uint64) (core.Iterator, error) {
+	rows, err := descending(ctx, s.db, id, aggregateType, before, count)
+	if err != nil {
+		return nil, err
+	}
+	return &iterator{rows: rows}, nil
+}
+
+// querier is the query part shared by sql.DB and sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// descending selects at most count aggregate events with a version lower than before newest first, a before of zero
+// starts at the last event
+func descending(ctx context.Context, q querier, id string, aggregateType string, before core.Version, count uint64) (*sql.Rows, error) {
+	selectStm := `Select ` + eventColumns + ` from events where id=? and type=?`
+	args := []interface{}{id, aggregateType}
+	if before > 0 {
+		selectStm += ` and version<?`
+		args = append(args, before)
+	}
+	selectStm += ` order by version desc LIMIT ?`
+	args = append(args, count)
+	return q.QueryContext(ctx, selectStm, args...)
+}
+
+// LastEvent returns the newest event of the aggregate, core.ErrNoEvents if the aggregate has no events
+func (s *SQL) LastEvent(ctx context.Context, id string, aggregateType string) (core.Event, error) {
+	iterator, err := s.GetDescending(ctx, id, aggregateType, 0, 1)
+	if err != nil {
+		return core.Event{}, err
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		return core.Event{}, core.ErrNoEvents
+	}
+	return iterator.Value()
+}
+
+// AllDescending returns at most count events with a global version lower than before newest first, a before of zero
+// starts at the last saved event
+func (s *SQL) AllDescending(ctx context.Context, before core.Version, count uint64) (core.Iterator, error) {
+	selectStm := `Select ` + eventColumns + ` from events`
+	args := []interface{}{}
+	if before > 0 {
+		selectStm += ` where seq<?`
+		args = append(args, before)
+	}
+	selectStm += ` order by seq desc LIMIT ?`
+	args = append(args, count)
+	rows, err := s.db.QueryContext(ctx, selectStm, args...)
 	if err != nil {
 		return nil, err
 	}
 	return &iterator{rows: rows}, nil
 }
 
+// DeleteEvents removes the aggregate events with a version lower than before
+func (s *SQL) DeleteEvents(ctx context.Context, id string, aggregateType string, before core.Version) error {
+	_, err := s.db.ExecContext(ctx, `Delete from events where id=? and type=? and version<?`, id, aggregateType, before)
+	return err
+}
+
 // All iterate over all event in GlobalEvents order
 func (s *SQL) All(start core.Version, count uint64) (core.Iterator, error) {
-	selectStm := `Select seq, id, version, reason, type, timestamp, data, metadata from events where seq >= ? order by seq asc LIMIT ?`
-	rows, err := s.db.Query(selectStm, start, count)
+	return s.AllWithFilter(context.Background(), start, count, core.Filter{})
+}
+
+// AllByType iterate over the events of the aggregate types in GlobalEvents order
+func (s *SQL) AllByType(start core.Version, count uint64, aggregateTypes ...string) (core.Iterator, error) {
+	return s.AllWithFilter(context.Background(), start, count, core.ByType(aggregateTypes...))
+}
+
+// AllByReason iterate over the events with any of the reasons in GlobalEvents order
+//
+//	es.AllByReason(start, count, "Born", "Deceased")
+func (s *SQL) AllByReason(start core.Version, count uint64, reasons ...string) (core.Iterator, error) {
+	return s.AllWithFilter(context.Background(), start, count, core.ByReason(reasons...))
+}
+
+// Between iterate over the events with a timestamp after from and before to in GlobalEvents order
+func (s *SQL) Between(ctx context.Context, from, to time.Time) (core.Iterator, error) {
+	return s.AllWithFilter(ctx, 0, 0, core.Filter{}.After(from).Before(to))
+}
+
+// Category returns at most count events of the aggregate type with a category version after the position, in category
+// order
+func (s *SQL) Category(ctx context.Context, aggregateType string, after core.Version, count uint64) (core.Iterator, error) {
+	selectStm := `Select ` + eventColumns + ` from events where type=? and category_seq>? order by category_seq asc LIMIT ?`
+	rows, err := s.db.QueryContext(ctx, selectStm, aggregateType, after, count)
+	if err != nil {
+		return nil, err
+	}
+	return &iterator{rows: rows}, nil
+}
+
+// ByCorrelationID returns the events with the correlation id in GlobalEvents order
+func (s *SQL) ByCorrelationID(ctx context.Context, correlationID string) (core.Iterator, error) {
+	selectStm := `Select ` + eventColumns + ` from events where correlation_id=? order by seq asc`
+	rows, err := s.db.QueryContext(ctx, selectStm, correlationID)
 	if err != nil {
 		return nil, err
 	}
 	return &iterator{rows: rows}, nil
 }
+
+// AllWithFilter iterate over the events matching the filter in GlobalEvents order, a count of zero has no limit. The
+// aggregate types, reasons and time is part of the query, the metadata part of the filter is not evaluated as the metadata is serialized.
+func (s *SQL) AllWithFilter(ctx context.Context, start core.Version, count uint64, filter core.Filter) (core.Iterator, error) {
+	selectStm := `Select ` + eventColumns + ` from events where seq >= ?`
+	args := []interface{}{start}
+	if len(filter.AggregateTypes) > 0 {
+		selectStm += ` and type in (` + placeholders(len(filter.AggregateTypes)) + `)`
+		for _, t := range filter.AggregateTypes {
+			args = append(args, t)
+		}
+	}
+	if len(filter.Reasons) > 0 {
+		selectStm += ` and reason in (` + placeholders(len(filter.Reasons)) + `)`
+		for _, r := range filter.Reasons {
+			args = append(args, r)
+		}
+	}
+	if !filter.From.IsZero() {
+		// the timestamp is stored in UTC with a fixed precision that can be compared as a string
+		selectStm += ` and timestamp > ?`
+		args = append(args, timestamp(filter.From))
+	}
+	if !filter.To.IsZero() {
+		selectStm += ` and timestamp < ?`
+		args = append(args, timestamp(filter.To))
+	}
+	selectStm += ` order by seq asc`
+	if count > 0 {
+		selectStm += ` LIMIT ?`
+		args = append(args, count)
+	}
+
+	rows, err := s.db.QueryContext(ctx, selectStm, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &iterator{rows: rows}, nil
+}
+
+// timestampLayout is RFC3339 in UTC with nanoseconds of fixed width, making the stored timestamps comparable as strings
+const timestampLayout = "2006-01-02T15:04:05.000000000Z"
+
+// timestamp formats t to be stored or compared with the stored timestamps
+func timestamp(t time.Time) string {
+	return t.UTC().Format(timestampLayout)
+}
+
+// placeholders returns n comma separated query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
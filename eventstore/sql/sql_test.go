@@ -1,10 +1,12 @@
 package sql_test
 
 import (
+	"context"
 	sqldriver "database/sql"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/core/testsuite"
@@ -19,6 +21,78 @@ func TestSuite(t *testing.T) {
 	testsuite.Test(t, f)
 }
 
+func TestEventDeleter(t *testing.T) {
+	f := func() (core.EventStore, core.EventDeleter, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestEventDeleter(t, f)
+}
+
+func TestBatchSaver(t *testing.T) {
+	f := func() (core.EventStore, core.BatchSaver, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestBatchSaver(t, f)
+}
+
+func TestVersionReader(t *testing.T) {
+	f := func() (core.EventStore, core.VersionReader, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestVersionReader(t, f)
+}
+
+func TestReverseReader(t *testing.T) {
+	f := func() (core.EventStore, core.ReverseReader, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestReverseReader(t, f)
+}
+
+func TestLastEventReader(t *testing.T) {
+	f := func() (core.EventStore, core.LastEventReader, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestLastEventReader(t, f)
+}
+
+func TestCategoryReader(t *testing.T) {
+	f := func() (core.EventStore, core.CategoryReader, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestCategoryReader(t, f)
+}
+
+func TestCorrelationReader(t *testing.T) {
+	f := func() (core.EventStore, core.CorrelationReader, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestCorrelationReader(t, f)
+}
+
+func TestTimeRangeReader(t *testing.T) {
+	f := func() (core.EventStore, core.TimeRangeReader, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestTimeRangeReader(t, f)
+}
+
+func TestGlobalVersionReader(t *testing.T) {
+	f := func() (core.EventStore, core.GlobalVersionReader, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestGlobalVersionReader(t, f)
+}
+
 func TestSuiteSingelWriter(t *testing.T) {
 	f := func() (core.EventStore, func(), error) {
 		return eventstore(true)
@@ -38,6 +112,301 @@ func TestMultipleMigrate(t *testing.T) {
 	}
 }
 
+func TestMigrateSchemaVersion(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared&_schema_version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	// table layout from before the schema_version column was introduced
+	_, err = db.Exec(`create table events (seq INTEGER PRIMARY KEY AUTOINCREMENT, id VARCHAR NOT NULL, version INTEGER, reason VARCHAR, type VARCHAR, timestamp VARCHAR, data BLOB, metadata BLOB);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`Insert into events (id, version, reason, type, timestamp, data, metadata) values ('123', 1, 'Born', 'Person', '2024-01-02T03:04:05Z', '{}', '{}')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	es := sql.Open(db)
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err := es.Get(context.Background(), "123", "Person", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected the event stored before the migration")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.SchemaVersion != 0 {
+		t.Fatalf("expected schema version 0 on migrated event was %d", event.SchemaVersion)
+	}
+}
+
+func TestMigrateTimestamps(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file:timestamps?mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	es := sql.Open(db)
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// timestamps stored in RFC3339 with second precision and the zone of the writer
+	_, err = db.Exec(`Insert into events (id, version, reason, type, timestamp, data, metadata) values
+		('123', 1, 'Born', 'Person', '2024-01-02T05:04:05+02:00', '{}', '{}'),
+		('123', 2, 'AgedOneYear', 'Person', '2024-01-02T03:04:06Z', '{}', '{}')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2024, 1, 2, 3, 4, 4, 500000000, time.UTC)
+	iterator, err := es.Between(context.Background(), from, from.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected the event stored before the migration in the time range")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Version != 1 || !event.Timestamp.Equal(from.Add(500*time.Millisecond)) {
+		t.Fatalf("expected the event with version 1 was %d at %v", event.Version, event.Timestamp)
+	}
+	if iterator.Next() {
+		t.Fatal("expected only one event in the time range")
+	}
+}
+
+func TestMigrateCategories(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file:categories?mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	// table layout from before the category_seq column was introduced
+	_, err = db.Exec(`create table events (seq INTEGER PRIMARY KEY AUTOINCREMENT, id VARCHAR NOT NULL, version INTEGER, reason VARCHAR, type VARCHAR, timestamp VARCHAR, data BLOB, metadata BLOB, schema_version INTEGER NOT NULL DEFAULT 0);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`Insert into events (id, version, reason, type, timestamp, data, metadata) values
+		('123', 1, 'Born', 'Person', '2024-01-02T03:04:05Z', '{}', '{}'),
+		('123', 1, 'Created', 'Order', '2024-01-02T03:04:05Z', '{}', '{}'),
+		('123', 2, 'AgedOneYear', 'Person', '2024-01-02T03:04:05Z', '{}', '{}')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	es := sql.Open(db)
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the category stream continues after the migrated events
+	err = es.Save([]core.Event{{AggregateID: "456", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err := es.Category(context.Background(), "Person", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	var versions []core.Version
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, event.CategoryVersion)
+	}
+	if fmt.Sprint(versions) != "[1 2 3]" {
+		t.Fatalf("expected the category versions [1 2 3] was %v", versions)
+	}
+}
+
+func TestPager(t *testing.T) {
+	f := func() (core.EventStore, core.Pager, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestPager(t, f)
+}
+
+func TestAllWithFilter(t *testing.T) {
+	es, close, err := eventstore(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+
+	now := time.Now()
+	err = es.Save([]core.Event{
+		{AggregateID: "filter", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: now.Add(-time.Hour)},
+		{AggregateID: "filter", AggregateType: "Person", Version: 2, Reason: "AgedOneYear", Timestamp: now},
+		{AggregateID: "filter", AggregateType: "Person", Version: 3, Reason: "Born", Timestamp: now},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{{AggregateID: "filter", AggregateType: "Order", Version: 1, Reason: "Born", Timestamp: now}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := es.AllWithFilter(context.Background(), 0, 10, core.ByType("Person").ByReason("Born").After(now.Add(-time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected an event")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Version != 3 || event.AggregateType != "Person" {
+		t.Fatalf("expected the Person event with version 3 was %s version %d", event.AggregateType, event.Version)
+	}
+	if iterator.Next() {
+		t.Fatal("expected only one event to match the filter")
+	}
+}
+
+func TestWithTx(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file:withtx?mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	es := sql.Open(db)
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`create table emails (email varchar(255) primary key, id varchar(255))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claim := func(email, id string) func(tx *sqldriver.Tx) error {
+		return func(tx *sqldriver.Tx) error {
+			_, err := tx.Exec(`insert into emails (email, id) values (?, ?)`, email, id)
+			return err
+		}
+	}
+
+	err = es.WithTx(claim("kalle@example.com", "1")).Save([]core.Event{{AggregateID: "1", AggregateType: "User", Version: 1, Reason: "Registered", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the unique email is already claimed, the events are rolled back together with the claim
+	err = es.WithTx(claim("kalle@example.com", "2")).Save([]core.Event{{AggregateID: "2", AggregateType: "User", Version: 1, Reason: "Registered", Timestamp: time.Now()}})
+	if err == nil {
+		t.Fatal("expected error when the email is already claimed")
+	}
+	iterator, err := es.Get(context.Background(), "2", "User", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if iterator.Next() {
+		t.Fatal("expected no events to be saved when the transaction function fails")
+	}
+}
+
+func TestSaveContext(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file:savecontext?mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	es := sql.Open(db)
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = es.SaveContext(ctx, []core.Event{{AggregateID: "1", AggregateType: "User", Version: 1, Reason: "Registered", Timestamp: time.Now()}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled was %v", err)
+	}
+	iterator, err := es.Get(context.Background(), "1", "User", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if iterator.Next() {
+		t.Fatal("expected no events to be saved when the context is canceled")
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file:warmup?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	es := sql.Open(db)
+	defer es.Close()
+	es.SetPool(sql.Pool{MaxOpenConns: 4, MaxIdleConns: 4, ConnMaxLifetime: time.Minute})
+
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Warmup(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Stats().Idle < 3 {
+		t.Fatalf("expected at least 3 idle connections was %d", db.Stats().Idle)
+	}
+
+	// the prepared statements are used by Save and Get
+	err = es.Save([]core.Event{{AggregateID: "warm", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err := es.Get(context.Background(), "warm", "Person", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected the saved event")
+	}
+}
+
 func eventstore(singelWriter bool) (*sql.SQL, func(), error) {
 	var es *sql.SQL
 	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
@@ -64,3 +433,144 @@ func eventstore(singelWriter bool) (*sql.SQL, func(), error) {
 		es.Close()
 	}, nil
 }
+
+func TestAllByType(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file:bytype?mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	es := sql.Open(db)
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the indexes are added to event tables that already exist
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index string
+	err = db.QueryRow(`select name from sqlite_master where type='index' and name='type_seq'`).Scan(&index)
+	if err != nil {
+		t.Fatalf("expected the type_seq index %v", err)
+	}
+
+	err = es.Save([]core.Event{{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = es.Save([]core.Event{{AggregateID: "123", AggregateType: "Order", Version: 1, Reason: "Created", Timestamp: time.Now()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err := es.AllByType(0, 10, "Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected the Order event")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.AggregateType != "Order" || event.GlobalVersion != 2 {
+		t.Fatalf("expected the Order event with global version 2 was %s %d", event.AggregateType, event.GlobalVersion)
+	}
+	if iterator.Next() {
+		t.Fatal("expected only the Order event")
+	}
+}
+
+func TestAllByReason(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file:byreason?mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	es := sql.Open(db)
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var index string
+	err = db.QueryRow(`select name from sqlite_master where type='index' and name='reason_seq'`).Scan(&index)
+	if err != nil {
+		t.Fatalf("expected the reason_seq index %v", err)
+	}
+
+	err = es.Save([]core.Event{
+		{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: time.Now()},
+		{AggregateID: "123", AggregateType: "Person", Version: 2, Reason: "AgedOneYear", Timestamp: time.Now()},
+		{AggregateID: "123", AggregateType: "Person", Version: 3, Reason: "Deceased", Timestamp: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err := es.AllByReason(0, 10, "Born", "Deceased")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	var reasons []string
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		reasons = append(reasons, event.Reason)
+	}
+	if len(reasons) != 2 || reasons[0] != "Born" || reasons[1] != "Deceased" {
+		t.Fatalf("expected the Born and Deceased events was %v", reasons)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file:between?mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	es := sql.Open(db)
+	err = es.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	err = es.Save([]core.Event{
+		{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: now.Add(-2 * time.Hour)},
+		{AggregateID: "123", AggregateType: "Person", Version: 2, Reason: "AgedOneYear", Timestamp: now.Add(-time.Hour)},
+		{AggregateID: "123", AggregateType: "Person", Version: 3, Reason: "AgedOneYear", Timestamp: now},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := es.Between(context.Background(), now.Add(-90*time.Minute), now.Add(-30*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if !iterator.Next() {
+		t.Fatal("expected an event in the time range")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Version != 2 {
+		t.Fatalf("expected the event with version 2 was %d", event.Version)
+	}
+	if iterator.Next() {
+		t.Fatal("expected only one event in the time range")
+	}
+}
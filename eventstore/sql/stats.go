@@ -0,0 +1,50 @@
+package sql
+
+import (
+	"context"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// sizeStms are tried in order to read the storage size of the events, the first query the database understands is used
+var sizeStms = []string{
+	`Select page_count * page_size from pragma_page_count(), pragma_page_size()`, // sqlite, the size of the database file
+	`Select pg_total_relation_size('events')`,                                    // postgres
+}
+
+// Stats counts the events per aggregate type and reads the storage size on sqlite and postgres
+func (s *SQL) Stats(ctx context.Context) (core.Stats, error) {
+	stats := core.Stats{
+		AggregateTypes: make(map[string]uint64),
+		Size:           -1,
+	}
+	err := s.db.QueryRowContext(ctx, `Select count(*), coalesce(max(seq), 0) from events`).Scan(&stats.Events, &stats.Head)
+	if err != nil {
+		return stats, err
+	}
+	rows, err := s.db.QueryContext(ctx, `Select type, count(*) from events group by type`)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var aggregateType string
+		var count uint64
+		err = rows.Scan(&aggregateType, &count)
+		if err != nil {
+			return stats, err
+		}
+		stats.AggregateTypes[aggregateType] = count
+	}
+	if err = rows.Err(); err != nil {
+		return stats, err
+	}
+	for _, stm := range sizeStms {
+		var size int64
+		if s.db.QueryRowContext(ctx, stm).Scan(&size) == nil {
+			stats.Size = size
+			break
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,32 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+)
+
+func TestStatsReader(t *testing.T) {
+	f := func() (core.EventStore, core.StatsReader, func(), error) {
+		es, closeFunc, err := eventstore(false)
+		return es, es, closeFunc, err
+	}
+	testsuite.TestStatsReader(t, f)
+}
+
+func TestStatsSize(t *testing.T) {
+	es, closeFunc, err := eventstore(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFunc()
+	stats, err := es.Stats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Size <= 0 {
+		t.Fatalf("expected the sqlite database size got %d", stats.Size)
+	}
+}
@@ -0,0 +1,113 @@
+package eventsourcing
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// CompositePosition is the position of a federated feed, the global version of the last handled event in each event
+// store in the order the fetch functions are given to the federation.
+type CompositePosition []core.Version
+
+// String encodes the position, e.g. to be stored next to the read-model
+func (p CompositePosition) String() string {
+	parts := make([]string, len(p))
+	for i, v := range p {
+		parts[i] = strconv.FormatUint(uint64(v), 10)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseCompositePosition decodes a position encoded with String
+func ParseCompositePosition(s string) (CompositePosition, error) {
+	if s == "" {
+		return CompositePosition{}, nil
+	}
+	parts := strings.Split(s, ",")
+	p := make(CompositePosition, len(parts))
+	for i, part := range parts {
+		v, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		p[i] = core.Version(v)
+	}
+	return p, nil
+}
+
+// Federation feeds one projection from multiple independent event stores, e.g. one database per bounded context.
+// Unlike MergeFetch it keeps a composite position with the last handled event in each store, making it possible to
+// continue the federated feed after a restart.
+type Federation struct {
+	fetchFs  []fetchFromFunc
+	lock     sync.Mutex
+	position CompositePosition
+}
+
+// NewFederation creates a federated feed starting after the position. A nil position starts from the beginning of
+// all event stores.
+func NewFederation(position CompositePosition, fetchFs ...fetchFromFunc) *Federation {
+	p := make(CompositePosition, len(fetchFs))
+	copy(p, position)
+	return &Federation{
+		fetchFs:  fetchFs,
+		position: p,
+	}
+}
+
+// Fetch is the fetch function of the projection. It fetches from each event store after its position and merges the
+// events ordered by timestamp. The position of an event is committed when the projection steps to the next event,
+// meaning that an event whose callback returned an error is fetched again.
+func (f *Federation) Fetch() (core.Iterator, error) {
+	position := f.Position()
+	iterators := make([]core.Iterator, 0, len(f.fetchFs))
+	for i, fetchF := range f.fetchFs {
+		iterator, err := fetchF(position[i] + 1)
+		if err != nil {
+			for _, i := range iterators {
+				i.Close()
+			}
+			return nil, err
+		}
+		iterators = append(iterators, iterator)
+	}
+	return &federatedIterator{
+		mergeIterator: MergeIterators(iterators...).(*mergeIterator),
+		federation:    f,
+	}, nil
+}
+
+// Position returns a copy of the composite position
+func (f *Federation) Position() CompositePosition {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	p := make(CompositePosition, len(f.position))
+	copy(p, f.position)
+	return p
+}
+
+func (f *Federation) commit(source int, globalVersion core.Version) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.position[source] = globalVersion
+}
+
+// federatedIterator commits the position of the current event when it steps to the next
+type federatedIterator struct {
+	*mergeIterator
+	federation *Federation
+	current    bool // true if there is a current event not yet committed
+}
+
+func (i *federatedIterator) Next() bool {
+	if i.current {
+		i.federation.commit(i.source, i.event.GlobalVersion)
+	}
+	next := i.mergeIterator.Next()
+	i.current = next && i.err == nil
+	return next
+}
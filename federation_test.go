@@ -0,0 +1,106 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestFederation(t *testing.T) {
+	aggregate.Register(&Person{})
+	es1 := memory.Create()
+	es2 := memory.Create()
+
+	start := time.Now()
+	born := func(id string, offset time.Duration) []core.Event {
+		return []core.Event{{AggregateID: id, AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: start.Add(offset), Data: []byte(`{"Name":"` + id + `"}`)}}
+	}
+	for _, err := range []error{
+		es1.Save(born("a", 0)),
+		es2.Save(born("b", time.Second)),
+		es1.Save(born("c", time.Second*2)),
+		es2.Save(born("d", time.Second*3)),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	fetch := func(es *memory.Memory) func(start core.Version) (core.Iterator, error) {
+		return func(start core.Version) (core.Iterator, error) {
+			return es.All(start, 10)()
+		}
+	}
+
+	federation := eventsourcing.NewFederation(nil, fetch(es1), fetch(es2))
+	names := ""
+	fail := true
+	callbackErr := errors.New("callback error")
+	proj := eventsourcing.NewProjection(federation.Fetch, func(event eventsourcing.Event) error {
+		name := event.Data().(*Born).Name
+		if name == "c" && fail {
+			fail = false
+			return callbackErr
+		}
+		names += name
+		return nil
+	})
+
+	result := proj.RunToEnd(context.Background())
+	if !errors.Is(result.Error, callbackErr) {
+		t.Fatalf("expected callback error was %v", result.Error)
+	}
+	if federation.Position().String() != "1,1" {
+		t.Fatalf("expected position 1,1 was %s", federation.Position())
+	}
+
+	// the failing event is fetched again
+	result = proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if names != "abcd" {
+		t.Fatalf("expected events ordered by timestamp abcd was %s", names)
+	}
+	if federation.Position().String() != "2,2" {
+		t.Fatalf("expected position 2,2 was %s", federation.Position())
+	}
+
+	// a new federation continues from the stored position
+	position, err := eventsourcing.ParseCompositePosition(federation.Position().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	es2.Save([]core.Event{{AggregateID: "e", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: start.Add(time.Second * 4), Data: []byte(`{"Name":"e"}`)}})
+	names = ""
+	proj = eventsourcing.NewProjection(eventsourcing.NewFederation(position, fetch(es1), fetch(es2)).Fetch, func(event eventsourcing.Event) error {
+		names += event.Data().(*Born).Name
+		return nil
+	})
+	result = proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if names != "e" {
+		t.Fatalf("expected only the new event e was %s", names)
+	}
+}
+
+func TestParseCompositePosition(t *testing.T) {
+	_, err := eventsourcing.ParseCompositePosition("1,x")
+	if err == nil {
+		t.Fatal("expected error parsing invalid position")
+	}
+	p, err := eventsourcing.ParseCompositePosition("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p) != 0 {
+		t.Fatalf("expected empty position was %v", p)
+	}
+}
@@ -1,7 +1,7 @@
 module github.com/hallgren/eventsourcing
 
-go 1.19
+go 1.21
 
-require github.com/hallgren/eventsourcing/core v0.4.0
+require github.com/hallgren/eventsourcing/core v0.5.0
 
 // replace github.com/hallgren/eventsourcing/core => ./core
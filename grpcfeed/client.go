@@ -0,0 +1,70 @@
+package grpcfeed
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// Client calls the EventFeed service
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient creates a client calling the service over the connection
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// ReadAll returns at most count events starting with the event with the global version from
+func (c *Client) ReadAll(ctx context.Context, from, count uint64) ([]*Event, error) {
+	events := &Events{}
+	err := c.conn.Invoke(ctx, "/"+serviceName+"/ReadAll", &ReadAllRequest{FromGlobalVersion: from, Count: count}, events, CallOption())
+	if err != nil {
+		return nil, err
+	}
+	return events.Events, nil
+}
+
+// ReadStream returns the events of the aggregate after the version
+func (c *Client) ReadStream(ctx context.Context, id, aggregateType string, afterVersion uint64) ([]*Event, error) {
+	events := &Events{}
+	err := c.conn.Invoke(ctx, "/"+serviceName+"/ReadStream", &ReadStreamRequest{AggregateID: id, AggregateType: aggregateType, AfterVersion: afterVersion}, events, CallOption())
+	if err != nil {
+		return nil, err
+	}
+	return events.Events, nil
+}
+
+// Subscription receives the events streamed by Subscribe
+type Subscription struct {
+	stream grpc.ClientStream
+}
+
+// Subscribe starts streaming the events starting with the event with the global version from, cancel the context to
+// end the subscription
+func (c *Client) Subscribe(ctx context.Context, from uint64) (*Subscription, error) {
+	stream, err := c.conn.NewStream(ctx, &serviceDesc.Streams[0], "/"+serviceName+"/Subscribe", CallOption())
+	if err != nil {
+		return nil, err
+	}
+	err = stream.SendMsg(&SubscribeRequest{FromGlobalVersion: from})
+	if err != nil {
+		return nil, err
+	}
+	err = stream.CloseSend()
+	if err != nil {
+		return nil, err
+	}
+	return &Subscription{stream: stream}, nil
+}
+
+// Recv blocks until the next event is received
+func (s *Subscription) Recv() (*Event, error) {
+	event := &Event{}
+	err := s.stream.RecvMsg(event)
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
@@ -0,0 +1,48 @@
+package grpcfeed
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// codec encodes the feed messages in the protobuf wire format and other protobuf messages with the protobuf library,
+// making it possible to serve the feed next to generated services on the same server
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	switch m := v.(type) {
+	case message:
+		return m.marshal(), nil
+	case proto.Message:
+		return proto.Marshal(m)
+	}
+	return nil, fmt.Errorf("grpcfeed: can't marshal %T", v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	switch m := v.(type) {
+	case message:
+		return m.unmarshal(data)
+	case proto.Message:
+		return proto.Unmarshal(data, m)
+	}
+	return fmt.Errorf("grpcfeed: can't unmarshal %T", v)
+}
+
+func (codec) Name() string {
+	return "proto"
+}
+
+// ServerOption returns the option the gRPC server serving the feed has to be created with
+//
+//	s := grpc.NewServer(grpcfeed.ServerOption())
+func ServerOption() grpc.ServerOption {
+	return grpc.ForceServerCodec(codec{})
+}
+
+// CallOption returns the option Go clients have to call the feed with, it's added by the Client
+func CallOption() grpc.CallOption {
+	return grpc.ForceCodec(codec{})
+}
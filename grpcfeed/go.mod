@@ -0,0 +1,22 @@
+module github.com/hallgren/eventsourcing/grpcfeed
+
+go 1.23.0
+
+require (
+	github.com/hallgren/eventsourcing v0.9.0
+	github.com/hallgren/eventsourcing/core v0.5.0
+	google.golang.org/grpc v1.69.2
+	google.golang.org/protobuf v1.36.2
+)
+
+require (
+	golang.org/x/net v0.30.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
+	golang.org/x/text v0.19.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20241015192408-796eee8c2d53 // indirect
+)
+
+// replace (
+// 	github.com/hallgren/eventsourcing => ../
+// 	github.com/hallgren/eventsourcing/core => ../core
+// )
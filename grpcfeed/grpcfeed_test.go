@@ -0,0 +1,102 @@
+package grpcfeed_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	"github.com/hallgren/eventsourcing/grpcfeed"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func setup(t *testing.T) (*memory.Memory, *grpcfeed.Client, func()) {
+	es := memory.Create()
+	server := grpcfeed.NewServer(es, func(start core.Version, count uint64) (core.Iterator, error) {
+		return es.All(start, count)()
+	})
+	server.Pace = 10 * time.Millisecond
+
+	lis := bufconn.Listen(1024 * 1024)
+	gs := grpc.NewServer(grpcfeed.ServerOption())
+	server.Register(gs)
+	go gs.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet", grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return es, grpcfeed.NewClient(conn), func() {
+		conn.Close()
+		gs.Stop()
+	}
+}
+
+func saveEvents(t *testing.T, es *memory.Memory, id string, versions ...core.Version) {
+	var events []core.Event
+	for _, v := range versions {
+		events = append(events, core.Event{AggregateID: id, AggregateType: "Person", Version: v, Reason: "AgedOneYear", Timestamp: time.Now(), Data: []byte("{}")})
+	}
+	err := es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadAllAndReadStream(t *testing.T) {
+	es, client, closeFunc := setup(t)
+	defer closeFunc()
+	saveEvents(t, es, "1", 1, 2)
+	saveEvents(t, es, "2", 1)
+
+	events, err := client.ReadAll(context.Background(), 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].GlobalVersion != 2 || events[1].AggregateID != "2" {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	event := events[0].CoreEvent()
+	if event.Reason != "AgedOneYear" || string(event.Data) != "{}" || event.Timestamp.IsZero() {
+		t.Fatalf("unexpected event %+v", event)
+	}
+
+	events, err = client.ReadStream(context.Background(), "1", "Person", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Version != 2 {
+		t.Fatalf("expected version 2 of aggregate 1 was %+v", events)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	es, client, closeFunc := setup(t)
+	defer closeFunc()
+	saveEvents(t, es, "1", 1, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub, err := client.Subscribe(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the event saved while subscribing is streamed
+	saveEvents(t, es, "2", 1)
+
+	for _, expected := range []uint64{2, 3} {
+		event, err := sub.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.GlobalVersion != expected {
+			t.Fatalf("expected global version %d was %d", expected, event.GlobalVersion)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package grpcfeed
+
+import (
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// message is implemented by the messages in feed.proto, they are encoded in the protobuf wire format
+type message interface {
+	marshal() []byte
+	unmarshal(b []byte) error
+}
+
+// ReadAllRequest is the request of ReadAll
+type ReadAllRequest struct {
+	FromGlobalVersion uint64
+	Count             uint64
+}
+
+// ReadStreamRequest is the request of ReadStream
+type ReadStreamRequest struct {
+	AggregateID   string
+	AggregateType string
+	AfterVersion  uint64
+}
+
+// SubscribeRequest is the request of Subscribe
+type SubscribeRequest struct {
+	FromGlobalVersion uint64
+}
+
+// Event is the event as stored, the data and metadata are serialized with the encoder of the event store
+type Event struct {
+	GlobalVersion     uint64
+	AggregateID       string
+	AggregateType     string
+	Version           uint64
+	Reason            string
+	TimestampUnixNano int64
+	Data              []byte
+	Metadata          []byte
+	SchemaVersion     uint32
+}
+
+// Events is the response of ReadAll and ReadStream
+type Events struct {
+	Events []*Event
+}
+
+// newEvent converts the stored event to its message
+func newEvent(e core.Event) *Event {
+	return &Event{
+		GlobalVersion:     uint64(e.GlobalVersion),
+		AggregateID:       e.AggregateID,
+		AggregateType:     e.AggregateType,
+		Version:           uint64(e.Version),
+		Reason:            e.Reason,
+		TimestampUnixNano: e.Timestamp.UnixNano(),
+		Data:              e.Data,
+		Metadata:          e.Metadata,
+		SchemaVersion:     uint32(e.SchemaVersion),
+	}
+}
+
+// CoreEvent converts the message to the stored event
+func (e *Event) CoreEvent() core.Event {
+	return core.Event{
+		GlobalVersion: core.Version(e.GlobalVersion),
+		AggregateID:   e.AggregateID,
+		AggregateType: e.AggregateType,
+		Version:       core.Version(e.Version),
+		Reason:        e.Reason,
+		Timestamp:     time.Unix(0, e.TimestampUnixNano).UTC(),
+		Data:          e.Data,
+		Metadata:      e.Metadata,
+		SchemaVersion: uint(e.SchemaVersion),
+	}
+}
+
+func (r *ReadAllRequest) marshal() []byte {
+	var b []byte
+	b = appendVarint(b, 1, r.FromGlobalVersion)
+	b = appendVarint(b, 2, r.Count)
+	return b
+}
+
+func (r *ReadAllRequest) unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeVarint(typ, b, &r.FromGlobalVersion)
+		case 2:
+			return consumeVarint(typ, b, &r.Count)
+		}
+		return skip(num, typ, b)
+	})
+}
+
+func (r *ReadStreamRequest) marshal() []byte {
+	var b []byte
+	b = appendString(b, 1, r.AggregateID)
+	b = appendString(b, 2, r.AggregateType)
+	b = appendVarint(b, 3, r.AfterVersion)
+	return b
+}
+
+func (r *ReadStreamRequest) unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		switch num {
+		case 1:
+			return consumeString(typ, b, &r.AggregateID)
+		case 2:
+			return consumeString(typ, b, &r.AggregateType)
+		case 3:
+			return consumeVarint(typ, b, &r.AfterVersion)
+		}
+		return skip(num, typ, b)
+	})
+}
+
+func (r *SubscribeRequest) marshal() []byte {
+	return appendVarint(nil, 1, r.FromGlobalVersion)
+}
+
+func (r *SubscribeRequest) unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num == 1 {
+			return consumeVarint(typ, b, &r.FromGlobalVersion)
+		}
+		return skip(num, typ, b)
+	})
+}
+
+func (e *Event) marshal() []byte {
+	var b []byte
+	b = appendVarint(b, 1, e.GlobalVersion)
+	b = appendString(b, 2, e.AggregateID)
+	b = appendString(b, 3, e.AggregateType)
+	b = appendVarint(b, 4, e.Version)
+	b = appendString(b, 5, e.Reason)
+	b = appendVarint(b, 6, uint64(e.TimestampUnixNano))
+	b = appendBytes(b, 7, e.Data)
+	b = appendBytes(b, 8, e.Metadata)
+	b = appendVarint(b, 9, uint64(e.SchemaVersion))
+	return b
+}
+
+func (e *Event) unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		var v uint64
+		switch num {
+		case 1:
+			return consumeVarint(typ, b, &e.GlobalVersion)
+		case 2:
+			return consumeString(typ, b, &e.AggregateID)
+		case 3:
+			return consumeString(typ, b, &e.AggregateType)
+		case 4:
+			return consumeVarint(typ, b, &e.Version)
+		case 5:
+			return consumeString(typ, b, &e.Reason)
+		case 6:
+			n, err := consumeVarint(typ, b, &v)
+			e.TimestampUnixNano = int64(v)
+			return n, err
+		case 7:
+			return consumeBytes(typ, b, &e.Data)
+		case 8:
+			return consumeBytes(typ, b, &e.Metadata)
+		case 9:
+			n, err := consumeVarint(typ, b, &v)
+			e.SchemaVersion = uint32(v)
+			return n, err
+		}
+		return skip(num, typ, b)
+	})
+}
+
+func (e *Events) marshal() []byte {
+	var b []byte
+	for _, event := range e.Events {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, event.marshal())
+	}
+	return b
+}
+
+func (e *Events) unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+		if num != 1 {
+			return skip(num, typ, b)
+		}
+		var data []byte
+		n, err := consumeBytes(typ, b, &data)
+		if err != nil {
+			return n, err
+		}
+		event := &Event{}
+		err = event.unmarshal(data)
+		e.Events = append(e.Events, event)
+		return n, err
+	})
+}
+
+// appendVarint appends the field, zero values are not encoded as in proto3
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// consumeFields calls f with the value of each field, f returns the length of the consumed value
+func consumeFields(b []byte, f func(num protowire.Number, typ protowire.Type, b []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		n, err := f(num, typ, b)
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func consumeVarint(typ protowire.Type, b []byte, v *uint64) (int, error) {
+	if typ != protowire.VarintType {
+		return 0, protowire.ParseError(-1)
+	}
+	value, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*v = value
+	return n, nil
+}
+
+func consumeString(typ protowire.Type, b []byte, v *string) (int, error) {
+	var value []byte
+	n, err := consumeBytes(typ, b, &value)
+	*v = string(value)
+	return n, err
+}
+
+func consumeBytes(typ protowire.Type, b []byte, v *[]byte) (int, error) {
+	if typ != protowire.BytesType {
+		return 0, protowire.ParseError(-1)
+	}
+	value, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*v = append([]byte(nil), value...)
+	return n, nil
+}
+
+// skip consumes the value of an unknown field
+func skip(num protowire.Number, typ protowire.Type, b []byte) (int, error) {
+	n := protowire.ConsumeFieldValue(num, typ, b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	return n, nil
+}
@@ -0,0 +1,164 @@
+// Package grpcfeed exposes the events of an event store as a gRPC service, giving services in other languages a
+// stable contract to consume the events. The contract is in feed.proto.
+package grpcfeed
+
+import (
+	"context"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"google.golang.org/grpc"
+)
+
+// FetchFunc returns events in global version order starting with the event with the global version start
+type FetchFunc func(start core.Version, count uint64) (core.Iterator, error)
+
+// Server implements the EventFeed service
+type Server struct {
+	Pace      time.Duration // Pace is the delay before a subscription reads the event store again when it has all events
+	BatchSize uint64        // BatchSize is the number of events a subscription reads from the event store at a time
+	es        core.EventStore
+	fetchF    FetchFunc
+}
+
+// NewServer creates the service reading the aggregate events from the event store and the global event feed via
+// fetchF
+func NewServer(es core.EventStore, fetchF FetchFunc) *Server {
+	return &Server{
+		Pace:      time.Second,
+		BatchSize: 100,
+		es:        es,
+		fetchF:    fetchF,
+	}
+}
+
+// Register adds the service to the gRPC server, the server has to be created with the ServerOption
+func (s *Server) Register(gs *grpc.Server) {
+	gs.RegisterService(&serviceDesc, s)
+}
+
+// ReadAll returns at most count events starting with the event with the global version from
+func (s *Server) ReadAll(ctx context.Context, req *ReadAllRequest) (*Events, error) {
+	iterator, err := s.fetchF(core.Version(req.FromGlobalVersion), req.Count)
+	if err != nil {
+		return nil, err
+	}
+	return collect(iterator, req.Count)
+}
+
+// ReadStream returns the events of the aggregate after the version
+func (s *Server) ReadStream(ctx context.Context, req *ReadStreamRequest) (*Events, error) {
+	iterator, err := s.es.Get(ctx, req.AggregateID, req.AggregateType, core.Version(req.AfterVersion))
+	if err != nil {
+		return nil, err
+	}
+	return collect(iterator, 0)
+}
+
+// Subscribe streams the events starting with the event with the global version from until the call is canceled
+func (s *Server) Subscribe(req *SubscribeRequest, stream grpc.ServerStream) error {
+	ctx := stream.Context()
+	next := core.Version(req.FromGlobalVersion)
+	for {
+		iterator, err := s.fetchF(next, s.BatchSize)
+		if err != nil {
+			return err
+		}
+		events, err := collect(iterator, s.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events.Events {
+			err = stream.SendMsg(event)
+			if err != nil {
+				return err
+			}
+			next = core.Version(event.GlobalVersion) + 1
+		}
+		if uint64(len(events.Events)) == s.BatchSize {
+			// read the next batch right away
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.Pace):
+		}
+	}
+}
+
+// collect reads at most count events from the iterator, all events if count is zero
+func collect(iterator core.Iterator, count uint64) (*Events, error) {
+	defer iterator.Close()
+	events := &Events{}
+	for (count == 0 || uint64(len(events.Events)) < count) && iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			return nil, err
+		}
+		events.Events = append(events.Events, newEvent(event))
+	}
+	return events, nil
+}
+
+// service is implemented by Server, it's the handler type of the service description
+type service interface {
+	ReadAll(ctx context.Context, req *ReadAllRequest) (*Events, error)
+	ReadStream(ctx context.Context, req *ReadStreamRequest) (*Events, error)
+	Subscribe(req *SubscribeRequest, stream grpc.ServerStream) error
+}
+
+const serviceName = "eventsourcing.feed.v1.EventFeed"
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*service)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ReadAll",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := &ReadAllRequest{}
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(service).ReadAll(ctx, req.(*ReadAllRequest))
+				}
+				if interceptor == nil {
+					return handler(ctx, req)
+				}
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/ReadAll"}, handler)
+			},
+		},
+		{
+			MethodName: "ReadStream",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := &ReadStreamRequest{}
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(service).ReadStream(ctx, req.(*ReadStreamRequest))
+				}
+				if interceptor == nil {
+					return handler(ctx, req)
+				}
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + serviceName + "/ReadStream"}, handler)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			ServerStreams: true,
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				req := &SubscribeRequest{}
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return srv.(service).Subscribe(req, stream)
+			},
+		},
+	},
+	Metadata: "feed.proto",
+}
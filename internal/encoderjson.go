@@ -1,15 +1,45 @@
 package internal
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+)
 
-type EncoderJSON struct{}
+type EncoderJSON struct {
+	// Strict makes the deserialization fail with an *UnknownFieldError when the data has fields that are not in the
+	// type it's deserialized into.
+	Strict bool
+}
 
 func (e EncoderJSON) Serialize(v interface{}) ([]byte, error) {
 	return json.Marshal(v)
 }
 
 func (e EncoderJSON) Deserialize(data []byte, v interface{}) error {
-	return json.Unmarshal(data, v)
+	if !e.Strict {
+		return json.Unmarshal(data, v)
+	}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.DisallowUnknownFields()
+	err := d.Decode(v)
+	// encoding/json has no typed error for unknown fields
+	if err != nil && strings.HasPrefix(err.Error(), unknownFieldPrefix) {
+		return &UnknownFieldError{Field: strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldPrefix), `"`)}
+	}
+	return err
+}
+
+const unknownFieldPrefix = "json: unknown field "
+
+// UnknownFieldError is returned from the strict JSON encoder when the data holds a field the type don't have
+type UnknownFieldError struct {
+	Field string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return "unknown field " + e.Field
 }
 
 type encoder interface {
@@ -20,5 +50,53 @@ type encoder interface {
 // global encoder used for events
 var EventEncoder encoder = EncoderJSON{}
 
+// encoders used for the events of specific aggregate types, replacing the global event encoder
+var aggregateEventEncoders = map[string]encoder{}
+var aggregateEventEncodersLock sync.RWMutex
+
+// SetAggregateEventEncoder binds the encoder to the events of the aggregate type
+func SetAggregateEventEncoder(aggregateType string, e encoder) {
+	aggregateEventEncodersLock.Lock()
+	defer aggregateEventEncodersLock.Unlock()
+	aggregateEventEncoders[aggregateType] = e
+}
+
+// EventEncoderFor returns the encoder of the aggregate type or the global event encoder if it has none. Old aggregate
+// types has to be resolved by the caller.
+func EventEncoderFor(aggregateType string) encoder {
+	aggregateEventEncodersLock.RLock()
+	defer aggregateEventEncodersLock.RUnlock()
+	if e, ok := aggregateEventEncoders[aggregateType]; ok {
+		return e
+	}
+	return EventEncoder
+}
+
 // global encoder used for snapshots
 var SnapshotEncoder encoder = EncoderJSON{}
+
+type metadataCodec interface {
+	Encode(metadata map[string]interface{}) ([]byte, error)
+	Decode(data []byte) (map[string]interface{}, error)
+}
+
+// MetadataCodec replaces the event encoder for the event metadata when set
+var MetadataCodec metadataCodec
+
+// EncodeMetadata serialize the metadata with the metadata codec or the event encoder of the aggregate type
+func EncodeMetadata(aggregateType string, metadata map[string]interface{}) ([]byte, error) {
+	if MetadataCodec != nil {
+		return MetadataCodec.Encode(metadata)
+	}
+	return EventEncoderFor(aggregateType).Serialize(metadata)
+}
+
+// DecodeMetadata deserialize the metadata with the metadata codec or the event encoder of the aggregate type
+func DecodeMetadata(aggregateType string, data []byte) (map[string]interface{}, error) {
+	if MetadataCodec != nil {
+		return MetadataCodec.Decode(data)
+	}
+	metadata := make(map[string]interface{})
+	err := EventEncoderFor(aggregateType).Deserialize(data, &metadata)
+	return metadata, err
+}
@@ -10,7 +10,10 @@ type registerFunc = func() interface{}
 
 type register struct {
 	eventsF    map[string]registerFunc
-	aggregates map[string]struct{}
+	aggregates map[string]registerFunc // aggregate factories by aggregate type
+	aliases    map[string]string       // aggregate type by old aggregate type
+	oldTypes   map[string][]string     // old aggregate types by aggregate type
+	reasons    map[string][]string     // registered event reasons by aggregate type
 }
 
 // Aggregate interface to use the aggregate root specific methods
@@ -29,7 +32,10 @@ func ResetRegister() {
 func newRegister() *register {
 	return &register{
 		eventsF:    make(map[string]registerFunc),
-		aggregates: make(map[string]struct{}),
+		aggregates: make(map[string]registerFunc),
+		aliases:    make(map[string]string),
+		oldTypes:   make(map[string][]string),
+		reasons:    make(map[string][]string),
 	}
 }
 
@@ -43,19 +49,90 @@ func (r *register) AggregateRegistered(a aggregate) bool {
 // EventRegistered return the func to generate the correct event data type and true if it exists
 // otherwise false.
 func (r *register) EventRegistered(event core.Event) (registerFunc, bool) {
-	d, ok := r.eventsF[event.AggregateType+"_"+event.Reason]
+	d, ok := r.eventsF[r.ResolveType(event.AggregateType)+"_"+event.Reason]
 	return d, ok
 }
 
+// NewAggregate returns a new aggregate of the aggregate type, or an old name of it, from its factory and true if the
+// aggregate is registered otherwise false.
+func (r *register) NewAggregate(aggregateType string) (interface{}, bool) {
+	f, ok := r.aggregates[r.ResolveType(aggregateType)]
+	if !ok || f == nil {
+		return nil, false
+	}
+	return f(), true
+}
+
+// SetFactory replaces the func creating new aggregates of the aggregate type
+func (r *register) SetFactory(aggregateType string, f func() interface{}) {
+	r.aggregates[aggregateType] = f
+}
+
+// RegisterTypeAlias makes events stored with the old aggregate type resolve to the aggregate type
+func (r *register) RegisterTypeAlias(aggregateType, old string) {
+	if _, ok := r.aliases[old]; ok {
+		return
+	}
+	r.aliases[old] = aggregateType
+	r.oldTypes[aggregateType] = append(r.oldTypes[aggregateType], old)
+}
+
+// ResolveType returns the aggregate type an old aggregate type is renamed to, other types are returned as is
+func (r *register) ResolveType(aggregateType string) string {
+	if t, ok := r.aliases[aggregateType]; ok {
+		return t
+	}
+	return aggregateType
+}
+
+// OldTypes returns the old names of the aggregate type in the order they were registered
+func (r *register) OldTypes(aggregateType string) []string {
+	return r.oldTypes[aggregateType]
+}
+
+// Registered returns the registered aggregate types with the reasons of their events in the order they were registered
+func (r *register) Registered() map[string][]string {
+	registered := make(map[string][]string, len(r.aggregates))
+	for aggregateType := range r.aggregates {
+		registered[aggregateType] = append([]string{}, r.reasons[aggregateType]...)
+	}
+	return registered
+}
+
+// RegisterReasonAlias makes stored events with the old reason resolve to the event type. The event has to be
+// registered on the aggregate before the alias.
+func (r *register) RegisterReasonAlias(aggregateType, old string, event interface{}) bool {
+	f := eventToFunc(event)
+	reason := reflect.TypeOf(f()).Elem().Name()
+	if _, ok := r.eventsF[aggregateType+"_"+reason]; !ok {
+		return false
+	}
+	r.eventsF[aggregateType+"_"+old] = f
+	return true
+}
+
 // Register store the aggregate and calls the aggregate method Register to Register the aggregate events.
 func (r *register) Register(a aggregate) {
-	typ := reflect.TypeOf(a).Elem().Name()
-	fu := r.RegisterAggregate(typ)
+	t := reflect.TypeOf(a).Elem()
+	fu := r.RegisterAggregate(t.Name())
+	// keep the factory of an aggregate registered again
+	if r.aggregates[t.Name()] == nil {
+		r.aggregates[t.Name()] = func() interface{} {
+			return reflect.New(t).Interface()
+		}
+	}
 	a.Register(fu)
+	// every aggregate can be deleted
+	fu(&Tombstone{})
 }
 
+// Tombstone is the event marking the aggregate as deleted
+type Tombstone struct{}
+
 func (r *register) RegisterAggregate(aggregateType string) func(events ...interface{}) {
-	r.aggregates[aggregateType] = struct{}{}
+	if _, ok := r.aggregates[aggregateType]; !ok {
+		r.aggregates[aggregateType] = nil
+	}
 
 	// fe is a helper function to make the event type registration simpler
 	fe := func(events ...interface{}) []registerFunc {
@@ -71,12 +148,30 @@ func (r *register) RegisterAggregate(aggregateType string) func(events ...interf
 		for _, f := range eventsF {
 			event := f()
 			reason := reflect.TypeOf(event).Elem().Name()
+			if _, ok := r.eventsF[aggregateType+"_"+reason]; !ok {
+				r.reasons[aggregateType] = append(r.reasons[aggregateType], reason)
+			}
 			r.eventsF[aggregateType+"_"+reason] = f
 		}
 	}
 }
 
+// TypedEvent is a registration of an event type that creates new events without reflection
+type TypedEvent struct {
+	New registerFunc
+}
+
+// NewTypedEvent captures the type T in the func creating new events
+func NewTypedEvent[T any]() TypedEvent {
+	return TypedEvent{New: func() interface{} {
+		return new(T)
+	}}
+}
+
 func eventToFunc(event interface{}) registerFunc {
+	if typed, ok := event.(TypedEvent); ok {
+		return typed.New
+	}
 	return func() interface{} {
 		// return a new instance of the event
 		return reflect.New(reflect.TypeOf(event).Elem()).Interface()
@@ -1,6 +1,8 @@
 package eventsourcing
 
 import (
+	"context"
+
 	"github.com/hallgren/eventsourcing/core"
 	"github.com/hallgren/eventsourcing/internal"
 )
@@ -10,6 +12,17 @@ type Iterator struct {
 	CoreIterator core.Iterator
 }
 
+// AggregateEvents returns an iterator of the deserialized events of one aggregate stream with a version after
+// fromVersion. It walks the history of the aggregate without building the aggregate state, e.g. for audit views. The
+// events and the aggregate have to be registered.
+func AggregateEvents(ctx context.Context, es core.EventStore, id, aggregateType string, fromVersion Version) (*Iterator, error) {
+	iterator, err := es.Get(ctx, id, aggregateType, core.Version(fromVersion))
+	if err != nil {
+		return nil, err
+	}
+	return &Iterator{CoreIterator: iterator}, nil
+}
+
 // Close the underlaying iterator
 func (i *Iterator) Close() {
 	i.CoreIterator.Close()
@@ -24,21 +37,25 @@ func (i *Iterator) Value() (Event, error) {
 	if err != nil {
 		return Event{}, err
 	}
+	// expose events stored with an old aggregate type with the type it's renamed to
+	event.AggregateType = internal.GlobalRegister.ResolveType(event.AggregateType)
 	// apply the event to the aggregate
 	f, found := internal.GlobalRegister.EventRegistered(event)
 	if !found {
-		return Event{}, ErrEventNotRegistered
+		// return the event without data to expose what event that is not registered
+		return Event{event: event}, ErrEventNotRegistered
 	}
 	data := f()
-	err = internal.EventEncoder.Deserialize(event.Data, &data)
+	encoder := internal.EventEncoderFor(event.AggregateType)
+	err = encoder.Deserialize(event.Data, &data)
 	if err != nil {
-		return Event{}, err
+		return Event{}, newDeserializationError(event, event.Data, err)
 	}
 	metadata := make(map[string]interface{})
 	if event.Metadata != nil {
-		err = internal.EventEncoder.Deserialize(event.Metadata, &metadata)
+		metadata, err = internal.DecodeMetadata(event.AggregateType, event.Metadata)
 		if err != nil {
-			return Event{}, err
+			return Event{}, newDeserializationError(event, event.Metadata, err)
 		}
 	}
 	return Event{
@@ -0,0 +1,126 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestDeserializationError(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := es.Save([]core.Event{{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Data: []byte(`{"Name": broken}`)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	coreIterator, err := es.All(0, 1)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator := eventsourcing.Iterator{CoreIterator: coreIterator}
+	defer iterator.Close()
+
+	if !iterator.Next() {
+		t.Fatal("expected an event")
+	}
+	_, err = iterator.Value()
+
+	var deserializationErr *eventsourcing.DeserializationError
+	if !errors.As(err, &deserializationErr) {
+		t.Fatalf("expected DeserializationError got %v", err)
+	}
+	if deserializationErr.AggregateID != "123" || deserializationErr.Version != 1 || deserializationErr.Reason != "Born" {
+		t.Fatalf("missing event context on error %v", deserializationErr)
+	}
+	if deserializationErr.Payload != `{"Name": broken}` {
+		t.Fatalf("expected raw payload on error was %q", deserializationErr.Payload)
+	}
+}
+
+func TestStrictJSONUnknownField(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+	eventsourcing.SetAggregateEventEncoder("Person", eventsourcing.EncoderJSON{Strict: true})
+	defer eventsourcing.SetAggregateEventEncoder("Person", eventsourcing.EncoderJSON{})
+
+	err := es.Save([]core.Event{{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Data: []byte(`{"Name":"kalle","Nickname":"kl"}`)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	coreIterator, err := es.All(0, 1)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator := eventsourcing.Iterator{CoreIterator: coreIterator}
+	defer iterator.Close()
+
+	if !iterator.Next() {
+		t.Fatal("expected an event")
+	}
+	_, err = iterator.Value()
+
+	var unknownFieldErr *eventsourcing.UnknownFieldError
+	if !errors.As(err, &unknownFieldErr) {
+		t.Fatalf("expected UnknownFieldError got %v", err)
+	}
+	if unknownFieldErr.Field != "Nickname" {
+		t.Fatalf("expected unknown field Nickname was %q", unknownFieldErr.Field)
+	}
+
+	// the default encoder drops the unknown field
+	eventsourcing.SetAggregateEventEncoder("Person", eventsourcing.EncoderJSON{})
+	coreIterator, err = es.All(0, 1)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator = eventsourcing.Iterator{CoreIterator: coreIterator}
+	if !iterator.Next() {
+		t.Fatal("expected an event")
+	}
+	_, err = iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAggregateEvents(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := eventsourcing.AggregateEvents(context.Background(), es, person.ID(), "Person", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	var versions []eventsourcing.Version
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := event.Data().(*AgedOneYear); !ok {
+			t.Fatalf("expected AgedOneYear was %T", event.Data())
+		}
+		versions = append(versions, event.Version())
+	}
+	if len(versions) != 2 || versions[0] != 2 || versions[1] != 3 {
+		t.Fatalf("expected the versions after 1 was %v", versions)
+	}
+}
@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type subscription struct {
+	next   core.Version                // start of the next open lease
+	open   *core.Lease                 // the open lease, nil if no consumer holds it
+	leases map[core.Version]core.Lease // sealed leases not acknowledged keyed on the start
+}
+
+// Memory is a lease store keeping the leases in memory, the leases are lost when the process stops
+type Memory struct {
+	subscriptions map[string]*subscription
+	lock          sync.Mutex
+}
+
+// Create in memory lease store
+func Create() *Memory {
+	return &Memory{
+		subscriptions: make(map[string]*subscription),
+	}
+}
+
+// Close is a no-op, the memory lease store holds no resources
+func (m *Memory) Close() {}
+
+// Acquire returns an expired lease assigned to the consumer or the open lease
+func (m *Memory) Acquire(ctx context.Context, subscriptionName, consumer string, ttl time.Duration) (core.Lease, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	now := time.Now()
+	s := m.subscription(subscriptionName)
+	for from, lease := range s.leases {
+		if lease.Expires.Before(now) {
+			lease.Consumer = consumer
+			lease.Expires = now.Add(ttl)
+			s.leases[from] = lease
+			return lease, nil
+		}
+	}
+	if s.open != nil && s.open.Expires.After(now) {
+		return core.Lease{}, core.ErrNoLease
+	}
+	s.open = &core.Lease{
+		Subscription: subscriptionName,
+		Consumer:     consumer,
+		From:         s.next,
+		Expires:      now.Add(ttl),
+	}
+	return *s.open, nil
+}
+
+// Seal sets the end of the open lease
+func (m *Memory) Seal(ctx context.Context, lease core.Lease, to core.Version) (core.Lease, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	s := m.subscription(lease.Subscription)
+	if s.open == nil || s.open.Consumer != lease.Consumer || s.open.From != lease.From {
+		return core.Lease{}, core.ErrLeaseLost
+	}
+	s.open = nil
+	if to < lease.From {
+		// no events in the lease
+		return lease, nil
+	}
+	lease.To = to
+	s.leases[lease.From] = lease
+	s.next = to + 1
+	return lease, nil
+}
+
+// Ack removes the lease
+func (m *Memory) Ack(ctx context.Context, lease core.Lease) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	s := m.subscription(lease.Subscription)
+	l, ok := s.leases[lease.From]
+	if !ok || l.Consumer != lease.Consumer {
+		return core.ErrLeaseLost
+	}
+	delete(s.leases, lease.From)
+	return nil
+}
+
+// subscription returns the subscription state and creates it if it's missing
+func (m *Memory) subscription(name string) *subscription {
+	s, ok := m.subscriptions[name]
+	if !ok {
+		s = &subscription{
+			next:   1,
+			leases: make(map[core.Version]core.Lease),
+		}
+		m.subscriptions[name] = s
+	}
+	return s
+}
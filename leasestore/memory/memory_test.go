@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/leasestore/memory"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.LeaseStore, func(), error) {
+		ls := memory.Create()
+		return ls, func() { ls.Close() }, nil
+	}
+	testsuite.TestLeaseStore(t, f)
+}
@@ -0,0 +1,36 @@
+package sql
+
+import "context"
+
+// Migrate the database
+func (s *SQL) Migrate() error {
+	sqlStmt := []string{
+		`create table lease_subscriptions (subscription VARCHAR NOT NULL, next INTEGER NOT NULL, PRIMARY KEY (subscription));`,
+		`create table leases (subscription VARCHAR NOT NULL, from_version INTEGER NOT NULL, to_version INTEGER NOT NULL, consumer VARCHAR NOT NULL, expires INTEGER NOT NULL);`,
+		`create unique index subscription_from on leases (subscription, from_version);`,
+	}
+	return s.migrate(sqlStmt)
+}
+
+func (s *SQL) migrate(stm []string) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// check if the migration is already done
+	rows, err := tx.Query(`Select count(*) from leases`)
+	if err == nil {
+		rows.Close()
+		return nil
+	}
+
+	for _, b := range stm {
+		_, err := tx.Exec(b)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
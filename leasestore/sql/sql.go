@@ -0,0 +1,171 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// SQL is a lease store surviving restarts of the consumers. The open lease is the lease row with to_version 0.
+type SQL struct {
+	db *sql.DB
+}
+
+// Open connection to database
+func Open(db *sql.DB) *SQL {
+	return &SQL{
+		db: db,
+	}
+}
+
+// Close the connection
+func (s *SQL) Close() {
+	s.db.Close()
+}
+
+// Acquire returns an expired lease assigned to the consumer or the open lease
+func (s *SQL) Acquire(ctx context.Context, subscription, consumer string, ttl time.Duration) (core.Lease, error) {
+	// the subscription row is inserted by the first consumer, a concurrent insert of the same subscription is a no-op
+	_, err := s.db.ExecContext(ctx, `INSERT INTO lease_subscriptions (subscription, next) VALUES ($1, 1) ON CONFLICT (subscription) DO NOTHING`, subscription)
+	if err != nil {
+		return core.Lease{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.Lease{}, fmt.Errorf("could not start a write transaction, %w", err)
+	}
+	defer tx.Rollback()
+
+	next, err := lock(ctx, tx, subscription)
+	if err != nil {
+		return core.Lease{}, err
+	}
+
+	now := time.Now()
+	lease := core.Lease{
+		Subscription: subscription,
+		Consumer:     consumer,
+		Expires:      now.Add(ttl),
+	}
+	var current string
+	var expires int64
+	statement := `SELECT from_version, to_version FROM leases WHERE subscription=$1 AND to_version<>0 AND expires<$2 ORDER BY from_version LIMIT 1`
+	err = tx.QueryRowContext(ctx, statement, subscription, now.UnixNano()).Scan(&lease.From, &lease.To)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return core.Lease{}, err
+	}
+	if err == nil {
+		// hand out the expired lease
+		return lease, take(ctx, tx, lease)
+	}
+
+	statement = `SELECT from_version, consumer, expires FROM leases WHERE subscription=$1 AND to_version=0`
+	err = tx.QueryRowContext(ctx, statement, subscription).Scan(&lease.From, &current, &expires)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return core.Lease{}, err
+	}
+	if err == nil {
+		if expires >= now.UnixNano() {
+			return core.Lease{}, core.ErrNoLease
+		}
+		// hand out the expired open lease
+		return lease, take(ctx, tx, lease)
+	}
+
+	lease.From = next
+	statement = `INSERT INTO leases (subscription, from_version, to_version, consumer, expires) VALUES ($1, $2, 0, $3, $4)`
+	_, err = tx.ExecContext(ctx, statement, subscription, lease.From, consumer, lease.Expires.UnixNano())
+	if err != nil {
+		return core.Lease{}, err
+	}
+	return lease, tx.Commit()
+}
+
+// Seal sets the end of the open lease
+func (s *SQL) Seal(ctx context.Context, lease core.Lease, to core.Version) (core.Lease, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return core.Lease{}, fmt.Errorf("could not start a write transaction, %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = lock(ctx, tx, lease.Subscription)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Lease{}, core.ErrLeaseLost
+	}
+	if err != nil {
+		return core.Lease{}, err
+	}
+	var consumer string
+	statement := `SELECT consumer FROM leases WHERE subscription=$1 AND from_version=$2 AND to_version=0`
+	err = tx.QueryRowContext(ctx, statement, lease.Subscription, lease.From).Scan(&consumer)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && consumer != lease.Consumer) {
+		return core.Lease{}, core.ErrLeaseLost
+	}
+	if err != nil {
+		return core.Lease{}, err
+	}
+
+	if to < lease.From {
+		// no events in the lease
+		_, err = tx.ExecContext(ctx, `DELETE FROM leases WHERE subscription=$1 AND from_version=$2`, lease.Subscription, lease.From)
+		if err != nil {
+			return core.Lease{}, err
+		}
+		return lease, tx.Commit()
+	}
+	lease.To = to
+	_, err = tx.ExecContext(ctx, `UPDATE leases SET to_version=$1 WHERE subscription=$2 AND from_version=$3`, lease.To, lease.Subscription, lease.From)
+	if err != nil {
+		return core.Lease{}, err
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE lease_subscriptions SET next=$1 WHERE subscription=$2`, lease.To+1, lease.Subscription)
+	if err != nil {
+		return core.Lease{}, err
+	}
+	return lease, tx.Commit()
+}
+
+// Ack removes the lease
+func (s *SQL) Ack(ctx context.Context, lease core.Lease) error {
+	statement := `DELETE FROM leases WHERE subscription=$1 AND from_version=$2 AND consumer=$3 AND to_version<>0`
+	res, err := s.db.ExecContext(ctx, statement, lease.Subscription, lease.From, lease.Consumer)
+	if err != nil {
+		return err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return core.ErrLeaseLost
+	}
+	return nil
+}
+
+// lock updates the subscription row to serialise the consumers of the subscription and returns the start of the next
+// open lease
+func lock(ctx context.Context, tx *sql.Tx, subscription string) (core.Version, error) {
+	_, err := tx.ExecContext(ctx, `UPDATE lease_subscriptions SET next=next WHERE subscription=$1`, subscription)
+	if err != nil {
+		return 0, err
+	}
+	var next core.Version
+	err = tx.QueryRowContext(ctx, `SELECT next FROM lease_subscriptions WHERE subscription=$1`, subscription).Scan(&next)
+	return next, err
+}
+
+// take assigns the lease to its new consumer
+func take(ctx context.Context, tx *sql.Tx, lease core.Lease) error {
+	statement := `UPDATE leases SET consumer=$1, expires=$2 WHERE subscription=$3 AND from_version=$4`
+	_, err := tx.ExecContext(ctx, statement, lease.Consumer, lease.Expires.UnixNano(), lease.Subscription, lease.From)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
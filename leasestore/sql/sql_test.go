@@ -0,0 +1,70 @@
+package sql_test
+
+import (
+	"context"
+	sqldriver "database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/leasestore/sql"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.LeaseStore, func(), error) {
+		return leasestore()
+	}
+	testsuite.TestLeaseStore(t, f)
+}
+
+func TestMultipleMigrate(t *testing.T) {
+	ls, close, err := leasestore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+	err = ls.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireWithoutTables(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file:notables?mode=memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ls := sql.Open(db)
+	defer ls.Close()
+
+	_, err = ls.Acquire(context.Background(), "orders", "c1", time.Minute)
+	if err == nil || errors.Is(err, core.ErrNoLease) {
+		t.Fatalf("expected the missing table error was %v", err)
+	}
+}
+
+func leasestore() (*sql.SQL, func(), error) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	err = db.Ping()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := sql.Open(db)
+	err = store.Migrate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return store, func() {
+		store.Close()
+	}, nil
+}
@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory is a lock store holding the aggregate locks in memory, it serializes the access to aggregates within one
+// process
+type Memory struct {
+	lock  sync.Mutex
+	locks map[string]*aggregateLock
+}
+
+// aggregateLock is held by the one sending on the channel, waiting is counted to know when the lock can be removed
+type aggregateLock struct {
+	ch      chan struct{}
+	waiting int
+}
+
+// Create in memory lock store
+func Create() *Memory {
+	return &Memory{
+		locks: make(map[string]*aggregateLock),
+	}
+}
+
+// Lock blocks until the lock on the aggregate is acquired or the context is done
+func (m *Memory) Lock(ctx context.Context, id, aggregateType string) (func() error, error) {
+	key := aggregateType + "_" + id
+
+	m.lock.Lock()
+	l, ok := m.locks[key]
+	if !ok {
+		l = &aggregateLock{ch: make(chan struct{}, 1)}
+		m.locks[key] = l
+	}
+	l.waiting++
+	m.lock.Unlock()
+
+	select {
+	case l.ch <- struct{}{}:
+	case <-ctx.Done():
+		m.release(key, l)
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() error {
+		once.Do(func() {
+			<-l.ch
+			m.release(key, l)
+		})
+		return nil
+	}, nil
+}
+
+// release removes the lock when no one holds or waits for it
+func (m *Memory) release(key string, l *aggregateLock) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	l.waiting--
+	if l.waiting == 0 {
+		delete(m.locks, key)
+	}
+}
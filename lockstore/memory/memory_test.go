@@ -0,0 +1,16 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/lockstore/memory"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.LockStore, func(), error) {
+		return memory.Create(), func() {}, nil
+	}
+	testsuite.TestLockStore(t, f)
+}
@@ -0,0 +1,41 @@
+package eventsourcing
+
+import "sync"
+
+// Logger receives the log records of the work running in the background, like projections, their restarts and the
+// retries of publishers. The args are alternating keys and values. *slog.Logger implements it.
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+var (
+	loggerLock sync.RWMutex
+	logger     Logger = defaultLogger{}
+)
+
+// SetLogger replaces the logger, set it to nil to turn the logging off. The default logger writes to slog.Default.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	loggerLock.Lock()
+	defer loggerLock.Unlock()
+	logger = l
+}
+
+// Log returns the logger set via SetLogger, it's used by the packages extending the projections
+func Log() Logger {
+	loggerLock.RLock()
+	defer loggerLock.RUnlock()
+	return logger
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, args ...any) {}
+func (nopLogger) Info(msg string, args ...any)  {}
+func (nopLogger) Warn(msg string, args ...any)  {}
+func (nopLogger) Error(msg string, args ...any) {}
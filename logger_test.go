@@ -0,0 +1,101 @@
+package eventsourcing_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+type record struct {
+	level string
+	msg   string
+	args  []any
+}
+
+type recordingLogger struct {
+	lock    sync.Mutex
+	records []record
+}
+
+func (l *recordingLogger) add(level, msg string, args []any) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.records = append(l.records, record{level: level, msg: msg, args: args})
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) { l.add("debug", msg, args) }
+func (l *recordingLogger) Info(msg string, args ...any)  { l.add("info", msg, args) }
+func (l *recordingLogger) Warn(msg string, args ...any)  { l.add("warn", msg, args) }
+func (l *recordingLogger) Error(msg string, args ...any) { l.add("error", msg, args) }
+
+func (l *recordingLogger) levels() []string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var levels []string
+	for _, r := range l.records {
+		levels = append(levels, r.level)
+	}
+	return levels
+}
+
+func TestLoggerProjectionManager(t *testing.T) {
+	logger := &recordingLogger{}
+	eventsourcing.SetLogger(logger)
+	defer eventsourcing.SetLogger(nil)
+
+	p := eventsourcing.NewProjection(func() (core.Iterator, error) {
+		return nil, errors.New("fetch failed")
+	}, func(e eventsourcing.Event) error { return nil })
+	p.Name = "p"
+
+	m := eventsourcing.NewProjectionManager()
+	m.Backoff = time.Hour
+	err := m.Add(p, eventsourcing.RestartAlways)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Start()
+	// the projection fails at once and waits for the restart
+	for i := 0; len(logger.levels()) < 2 && i < 100; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	m.Stop()
+
+	levels := fmt.Sprint(logger.levels())
+	if levels != "[debug warn]" {
+		t.Fatalf("expected the start and restart to be logged was %s", levels)
+	}
+	r := logger.records[1]
+	if r.msg != "projection failed, restarting" || fmt.Sprint(r.args[:2]) != "[projection p]" {
+		t.Fatalf("unexpected record %+v", r)
+	}
+}
+
+func TestLoggerProjectionGroup(t *testing.T) {
+	logger := &recordingLogger{}
+	eventsourcing.SetLogger(logger)
+	defer eventsourcing.SetLogger(nil)
+
+	p := eventsourcing.NewProjection(func() (core.Iterator, error) {
+		return nil, errors.New("fetch failed")
+	}, func(e eventsourcing.Event) error { return nil })
+	p.Name = "p"
+
+	g := eventsourcing.NewProjectionGroup(p)
+	g.Start()
+	err := <-g.ErrChan
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	g.Stop()
+
+	levels := fmt.Sprint(logger.levels())
+	if levels != "[debug error]" {
+		t.Fatalf("expected the start and failure to be logged was %s", levels)
+	}
+}
@@ -0,0 +1,199 @@
+package eventsourcing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// RestartPolicy decides if a projection that stopped on an error is restarted by the ProjectionManager
+type RestartPolicy int
+
+const (
+	// RestartNever leaves the projection stopped and reports the error from Wait
+	RestartNever RestartPolicy = iota
+	// RestartAlways restarts the projection after the manager Backoff duration
+	RestartAlways
+	// RestartBackoff restarts the projection after a delay that doubles on every failure up to the manager MaxBackoff
+	RestartBackoff
+)
+
+// ErrProjectionExists is returned when a projection is added with a name already used in the manager
+var ErrProjectionExists = errors.New("projection name already exists")
+
+// ProjectionManager owns named projections and restarts them according to their restart policy when they stop on an
+// error. Unlike the ProjectionGroup, a failing projection does not have to be watched on an error channel.
+type ProjectionManager struct {
+	Pace            time.Duration                // Pace is used when a projection is running and it reaches the end of the event stream
+	Backoff         time.Duration                // Backoff is the delay before a failed projection is restarted
+	MaxBackoff      time.Duration                // MaxBackoff is the upper limit of the delay for projections with the RestartBackoff policy
+	OnRestart       func(name string, err error) // OnRestart is called with the error before a projection is restarted
+	HealthThreshold time.Duration                // HealthThreshold is how long a projection can fail or go without a completed fetch before it's unhealthy, keep it above the pace
+	projections     []managedProjection
+	cancelF         context.CancelFunc
+	wg              sync.WaitGroup
+	lock            sync.Mutex
+	failed          []ProjectionResult
+	started         time.Time
+}
+
+type managedProjection struct {
+	projection *Projection
+	policy     RestartPolicy
+}
+
+// NewProjectionManager creates a manager without projections
+func NewProjectionManager() *ProjectionManager {
+	return &ProjectionManager{
+		Pace:            time.Second * 10, // Default pace 10 seconds
+		Backoff:         time.Second,      // Default backoff 1 second
+		MaxBackoff:      time.Minute,      // Default max backoff 1 minute
+		HealthThreshold: 5 * time.Minute,  // Default health threshold 5 minutes
+		cancelF:         func() {},
+	}
+}
+
+// Add adds the projection to the manager under its name. It has to be called before Start.
+func (m *ProjectionManager) Add(projection *Projection, policy RestartPolicy) error {
+	for _, mp := range m.projections {
+		if mp.projection.Name == projection.Name {
+			return ErrProjectionExists
+		}
+	}
+	m.projections = append(m.projections, managedProjection{projection: projection, policy: policy})
+	return nil
+}
+
+// Projections returns the projections in the manager in the order they were added
+func (m *ProjectionManager) Projections() []*Projection {
+	projections := make([]*Projection, 0, len(m.projections))
+	for _, mp := range m.projections {
+		projections = append(projections, mp.projection)
+	}
+	return projections
+}
+
+// Start runs all projections in the manager
+func (m *ProjectionManager) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelF = cancel
+	m.lock.Lock()
+	m.failed = nil
+	m.started = time.Now()
+	m.lock.Unlock()
+
+	m.wg.Add(len(m.projections))
+	for _, mp := range m.projections {
+		Log().Debug("projection started", "projection", mp.projection.Name)
+		go m.supervise(ctx, mp)
+	}
+}
+
+// Stop halts all projections and waits for them to return
+func (m *ProjectionManager) Stop() {
+	m.cancelF()
+	m.wg.Wait()
+}
+
+// Wait blocks until all projections have stopped, either by Stop or by failing with the RestartNever policy.
+// The projections that stopped on an error are returned in a *ProjectionsError.
+func (m *ProjectionManager) Wait() error {
+	m.wg.Wait()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if len(m.failed) > 0 {
+		return &ProjectionsError{Results: m.failed}
+	}
+	return nil
+}
+
+// supervise runs the projection and restarts it on error until the context is cancelled
+func (m *ProjectionManager) supervise(ctx context.Context, mp managedProjection) {
+	defer m.wg.Done()
+
+	delay := m.Backoff
+	for {
+		started := time.Now()
+		err := mp.projection.Run(ctx, mp.projection.pace(m.Pace))
+		if err == nil || ctx.Err() != nil {
+			Log().Debug("projection stopped", "projection", mp.projection.Name)
+			return
+		}
+		if mp.policy == RestartNever {
+			Log().Error("projection failed", "projection", mp.projection.Name, "error", err)
+			m.lock.Lock()
+			m.failed = append(m.failed, ProjectionResult{Error: err, Name: mp.projection.Name})
+			m.lock.Unlock()
+			return
+		}
+		if m.OnRestart != nil {
+			m.OnRestart(mp.projection.Name, err)
+		}
+		if mp.policy == RestartBackoff && time.Since(started) > m.MaxBackoff {
+			// the projection was healthy for a while, start over from the initial backoff
+			delay = m.Backoff
+		}
+		Log().Warn("projection failed, restarting", "projection", mp.projection.Name, "error", err, "delay", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if mp.policy == RestartBackoff {
+			delay *= 2
+			if delay > m.MaxBackoff {
+				delay = m.MaxBackoff
+			}
+		}
+	}
+}
+
+// Health checks that no projection has failed or been stuck for longer than the HealthThreshold. The health of each
+// projection is in the checks with the position of the last handled event in the details.
+func (m *ProjectionManager) Health(ctx context.Context) core.Health {
+	m.lock.Lock()
+	started := m.started
+	m.lock.Unlock()
+
+	health := core.Health{Name: "projections", Healthy: true}
+	if started.IsZero() {
+		health.Healthy = false
+		health.Error = "projection manager not started"
+		return health
+	}
+	now := time.Now()
+	for _, mp := range m.projections {
+		p := mp.projection
+		check := core.Health{
+			Name:    p.Name,
+			Healthy: true,
+			Details: map[string]string{"position": strconv.FormatUint(p.handled.Load(), 10)},
+		}
+		lastRun := started
+		if t := p.LastRun(); !t.IsZero() {
+			lastRun = t
+			check.Details["last_run"] = lastRun.UTC().Format(time.RFC3339Nano)
+		}
+		if failingSince := p.failingSince.Load(); failingSince != 0 {
+			lastError, _ := p.lastError.Load().(string)
+			if now.Sub(time.Unix(0, failingSince)) > m.HealthThreshold {
+				check.Healthy = false
+				check.Error = fmt.Sprintf("failing for more than %s: %s", m.HealthThreshold, lastError)
+			}
+		} else if now.Sub(lastRun) > m.HealthThreshold {
+			check.Healthy = false
+			check.Error = fmt.Sprintf("no completed fetch for more than %s", m.HealthThreshold)
+		}
+		if !check.Healthy {
+			health.Healthy = false
+		}
+		health.Checks = append(health.Checks, check)
+	}
+	return health
+}
@@ -0,0 +1,184 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestManagerRestartAlways(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls atomic.Int32
+	handled := make(chan struct{})
+	// fetch from the start every time as the memory fetch function continues after the last fetched event
+	fetchF := func() (core.Iterator, error) {
+		return es.All(0, 1)()
+	}
+	p := eventsourcing.NewProjection(fetchF, func(e eventsourcing.Event) error {
+		// fail the two first times
+		call := calls.Add(1)
+		if call <= 2 {
+			return errors.New("temporary error")
+		}
+		if call == 3 {
+			close(handled)
+		}
+		return nil
+	})
+	p.Name = "p"
+
+	var restarts atomic.Int32
+	m := eventsourcing.NewProjectionManager()
+	m.Backoff = time.Millisecond
+	m.OnRestart = func(name string, err error) {
+		restarts.Add(1)
+	}
+	err = m.Add(p, eventsourcing.RestartAlways)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Start()
+
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the projection to be restarted and handle the event")
+	}
+	m.Stop()
+
+	err = m.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restarts.Load() != 2 {
+		t.Fatalf("expected 2 restarts was %d", restarts.Load())
+	}
+}
+
+func TestManagerRestartNever(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callbackErr := errors.New("callback error")
+	failing := eventsourcing.NewProjection(es.All(0, 1), func(e eventsourcing.Event) error {
+		return callbackErr
+	})
+	failing.Name = "failing"
+	healthy := eventsourcing.NewProjection(es.All(0, 1), func(e eventsourcing.Event) error {
+		return nil
+	})
+	healthy.Name = "healthy"
+
+	m := eventsourcing.NewProjectionManager()
+	m.Add(failing, eventsourcing.RestartNever)
+	m.Add(healthy, eventsourcing.RestartNever)
+	m.Start()
+
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		m.Stop()
+	}()
+
+	err = m.Wait()
+	var projectionsErr *eventsourcing.ProjectionsError
+	if !errors.As(err, &projectionsErr) {
+		t.Fatalf("expected ProjectionsError was %v", err)
+	}
+	if len(projectionsErr.Results) != 1 || projectionsErr.Results[0].Name != "failing" {
+		t.Fatalf("expected the failing projection in the error was %v", projectionsErr.Results)
+	}
+	if !errors.Is(err, callbackErr) {
+		t.Fatal("expected the callback error to be wrapped")
+	}
+}
+
+func TestManagerDuplicateName(t *testing.T) {
+	es := memory.Create()
+	p1 := eventsourcing.NewProjection(es.All(0, 1), func(e eventsourcing.Event) error { return nil })
+	p1.Name = "p"
+	p2 := eventsourcing.NewProjection(es.All(0, 1), func(e eventsourcing.Event) error { return nil })
+	p2.Name = "p"
+
+	m := eventsourcing.NewProjectionManager()
+	err := m.Add(p1, eventsourcing.RestartAlways)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = m.Add(p2, eventsourcing.RestartAlways)
+	if !errors.Is(err, eventsourcing.ErrProjectionExists) {
+		t.Fatalf("expected ErrProjectionExists was %v", err)
+	}
+}
+
+func TestManagerHealth(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	healthy := eventsourcing.NewProjection(es.All(0, 10), func(e eventsourcing.Event) error {
+		return nil
+	})
+	healthy.Name = "healthy"
+	// fetch from the start every time as the memory fetch function continues after the last fetched event
+	failing := eventsourcing.NewProjection(func() (core.Iterator, error) {
+		return es.All(0, 10)()
+	}, func(e eventsourcing.Event) error {
+		return errors.New("failed")
+	})
+	failing.Name = "failing"
+
+	m := eventsourcing.NewProjectionManager()
+	m.Pace = time.Millisecond
+	m.Backoff = time.Millisecond
+	m.HealthThreshold = 50 * time.Millisecond
+	m.Add(healthy, eventsourcing.RestartAlways)
+	m.Add(failing, eventsourcing.RestartAlways)
+
+	if m.Health(context.Background()).Healthy {
+		t.Fatal("expected the manager to be unhealthy before it's started")
+	}
+	m.Start()
+	defer m.Stop()
+
+	// the failing projection is healthy until it has failed longer than the threshold
+	health := m.Health(context.Background())
+	if !health.Healthy {
+		t.Fatalf("expected healthy manager got %+v", health)
+	}
+	time.Sleep(100 * time.Millisecond)
+	health = m.Health(context.Background())
+	if health.Healthy || len(health.Checks) != 2 {
+		t.Fatalf("expected unhealthy manager got %+v", health)
+	}
+	if !health.Checks[0].Healthy || health.Checks[0].Details["position"] != "1" {
+		t.Fatalf("expected the healthy projection at position 1 got %+v", health.Checks[0])
+	}
+	if health.Checks[1].Healthy || !strings.Contains(health.Checks[1].Error, "failed") {
+		t.Fatalf("expected the failing projection to be unhealthy got %+v", health.Checks[1])
+	}
+}
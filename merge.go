@@ -0,0 +1,118 @@
+package eventsourcing
+
+import (
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// MergeFetch returns a fetch function that fetches from all the fetch functions and merges the events into one
+// iterator ordered by timestamp. It makes it possible to feed one projection from multiple event stores.
+// The order is kept within each fetch round, events fetched in a later round can have an earlier timestamp if the
+// event stores are out of sync.
+//
+// The global versions of the independent event stores overlap, the position of a projection fed by the merged
+// iterator is meaningless. Don't use it with projections relying on the position, like checkpoints, Reached or WaitFor.
+// Federation is the restartable form keeping the position per event store, MergeFetch is a federation that ignores
+// the position.
+func MergeFetch(fetchFs ...fetchFunc) fetchFunc {
+	fetchFromFs := make([]fetchFromFunc, len(fetchFs))
+	for i, fetchF := range fetchFs {
+		fetchF := fetchF
+		fetchFromFs[i] = func(core.Version) (core.Iterator, error) {
+			return fetchF()
+		}
+	}
+	return NewFederation(nil, fetchFromFs...).Fetch
+}
+
+// MergeIterators merges the iterators into one iterator ordered by the event timestamp. Events with the same
+// timestamp are ordered by global version and after that in the order of the iterators.
+func MergeIterators(iterators ...core.Iterator) core.Iterator {
+	return &mergeIterator{
+		iterators: iterators,
+		heads:     make([]*core.Event, len(iterators)),
+	}
+}
+
+type mergeIterator struct {
+	iterators []core.Iterator
+	heads     []*core.Event // the next event from each iterator, nil when the iterator is exhausted
+	started   bool
+	event     core.Event
+	source    int   // index of the iterator the current event came from
+	pending   error // error from an underlying iterator returned on the next call to Next
+	err       error
+}
+
+// Next moves to the earliest event of the underlying iterators
+func (m *mergeIterator) Next() bool {
+	if m.err != nil {
+		return false
+	}
+	if !m.started {
+		m.started = true
+		for i := range m.iterators {
+			m.advance(i)
+		}
+	}
+	if m.pending != nil {
+		// let Value return the error
+		m.err = m.pending
+		return true
+	}
+
+	next := -1
+	for i, head := range m.heads {
+		if head == nil {
+			continue
+		}
+		if next == -1 || before(*head, *m.heads[next]) {
+			next = i
+		}
+	}
+	if next == -1 {
+		return false
+	}
+	m.event = *m.heads[next]
+	m.source = next
+	m.advance(next)
+	return true
+}
+
+// Value returns the current event or the error from an underlying iterator
+func (m *mergeIterator) Value() (core.Event, error) {
+	if m.err != nil {
+		return core.Event{}, m.err
+	}
+	return m.event, nil
+}
+
+// Close closes all underlying iterators
+func (m *mergeIterator) Close() {
+	for _, iterator := range m.iterators {
+		iterator.Close()
+	}
+}
+
+// advance fetch the next event from the iterator at index i
+func (m *mergeIterator) advance(i int) {
+	m.heads[i] = nil
+	if !m.iterators[i].Next() {
+		return
+	}
+	event, err := m.iterators[i].Value()
+	if err != nil {
+		if m.pending == nil {
+			m.pending = err
+		}
+		return
+	}
+	m.heads[i] = &event
+}
+
+// before returns true if event a should be ordered before event b
+func before(a, b core.Event) bool {
+	if a.Timestamp.Equal(b.Timestamp) {
+		return a.GlobalVersion < b.GlobalVersion
+	}
+	return a.Timestamp.Before(b.Timestamp)
+}
@@ -0,0 +1,69 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestMergeFetch(t *testing.T) {
+	aggregate.Register(&Person{})
+	es1 := memory.Create()
+	es2 := memory.Create()
+
+	start := time.Now()
+	born := func(id string, offset time.Duration) []core.Event {
+		return []core.Event{{AggregateID: id, AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: start.Add(offset), Data: []byte(`{"Name":"` + id + `"}`)}}
+	}
+	for _, err := range []error{
+		es1.Save(born("a", 0)),
+		es2.Save(born("b", time.Second)),
+		es1.Save(born("c", time.Second*2)),
+		es2.Save(born("d", time.Second*3)),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := ""
+	proj := eventsourcing.NewProjection(eventsourcing.MergeFetch(es1.All(0, 10), es2.All(0, 10)), func(event eventsourcing.Event) error {
+		names += event.Data().(*Born).Name
+		return nil
+	})
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if names != "abcd" {
+		t.Fatalf("expected events ordered by timestamp abcd was %s", names)
+	}
+}
+
+type errIterator struct{}
+
+func (e errIterator) Next() bool                 { return true }
+func (e errIterator) Value() (core.Event, error) { return core.Event{}, errors.New("iterator error") }
+func (e errIterator) Close()                     {}
+
+func TestMergeIteratorsError(t *testing.T) {
+	iterator := eventsourcing.MergeIterators(core.ZeroIterator{}, errIterator{})
+	defer iterator.Close()
+
+	if !iterator.Next() {
+		t.Fatal("expected Next to return true to expose the error")
+	}
+	_, err := iterator.Value()
+	if err == nil {
+		t.Fatal("expected error from the underlying iterator")
+	}
+	if iterator.Next() {
+		t.Fatal("expected no more events after the error")
+	}
+}
@@ -0,0 +1,46 @@
+package eventsourcing
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+
+	"github.com/hallgren/eventsourcing/internal"
+)
+
+// MetadataCodec controls the wire format of the event metadata
+type MetadataCodec interface {
+	Encode(metadata map[string]interface{}) ([]byte, error)
+	Decode(data []byte) (map[string]interface{}, error)
+}
+
+// SetMetadataCodec replaces the event encoder for the event metadata, set it to nil to use the event encoder again
+func SetMetadataCodec(c MetadataCodec) {
+	internal.MetadataCodec = c
+}
+
+// GobMetadataCodec stores the metadata with encoding/gob keeping the Go types of the values. Time values and integers
+// are not turned into strings and floats as with JSON and struct values are returned as the struct. Types other than
+// the basic types and time.Time has to be registered with gob.Register before they are used as metadata values.
+type GobMetadataCodec struct{}
+
+func init() {
+	gob.Register(time.Time{})
+}
+
+// Encode serializes the metadata with gob
+func (GobMetadataCodec) Encode(metadata map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(metadata)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode deserializes the gob data into metadata
+func (GobMetadataCodec) Decode(data []byte) (map[string]interface{}, error) {
+	metadata := make(map[string]interface{})
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&metadata)
+	return metadata, err
+}
@@ -0,0 +1,74 @@
+package eventsourcing_test
+
+import (
+	"encoding/gob"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+type RequestContext struct {
+	UserID string
+	IP     string
+}
+
+func TestGobMetadataCodec(t *testing.T) {
+	gob.Register(RequestContext{})
+	eventsourcing.SetMetadataCodec(eventsourcing.GobMetadataCodec{})
+	defer eventsourcing.SetMetadataCodec(nil)
+
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	aggregate.TrackChangeWithMetadata(person, &AgedOneYear{}, map[string]interface{}{
+		"at":      at,
+		"count":   3,
+		"request": RequestContext{UserID: "123", IP: "127.0.0.1"},
+	})
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coreIterator, err := es.All(0, 10)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator := eventsourcing.Iterator{CoreIterator: coreIterator}
+	defer iterator.Close()
+
+	// the Born event has no metadata
+	if !iterator.Next() {
+		t.Fatal("expected the Born event")
+	}
+	_, err = iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !iterator.Next() {
+		t.Fatal("expected the AgedOneYear event")
+	}
+	event, err := iterator.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata := event.Metadata()
+	if v, ok := metadata["at"].(time.Time); !ok || !v.Equal(at) {
+		t.Fatalf("expected time value %v was %#v", at, metadata["at"])
+	}
+	if v, ok := metadata["count"].(int); !ok || v != 3 {
+		t.Fatalf("expected int value 3 was %#v", metadata["count"])
+	}
+	if v, ok := metadata["request"].(RequestContext); !ok || v.UserID != "123" {
+		t.Fatalf("expected struct value was %#v", metadata["request"])
+	}
+}
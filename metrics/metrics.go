@@ -0,0 +1,181 @@
+// Package metrics measures the event store and projections. The measurements are reported to a Collector, the
+// prometheus module implements it with Prometheus counters, histograms and gauges.
+package metrics
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Collector receives the measurements
+type Collector interface {
+	// EventsAppended counts events saved on the aggregate type
+	EventsAppended(aggregateType string, count int)
+	// EventsRead counts events read on the aggregate type, from aggregate streams and global event feeds
+	EventsRead(aggregateType string, count int)
+	// SaveLatency observes the duration of a save of events on the aggregate type
+	SaveLatency(aggregateType string, d time.Duration)
+	// ProjectionEventProcessed counts an event handled by the projection
+	ProjectionEventProcessed(projection string)
+	// ProjectionError counts an event the projection failed to handle
+	ProjectionError(projection string)
+	// ProjectionLag sets the number of events in the global event feed after the event handled by the projection
+	ProjectionLag(projection string, lag uint64)
+	// ProjectionLastProcessed sets the time the projection last completed a fetch without error
+	ProjectionLastProcessed(projection string, t time.Time)
+}
+
+// Store is an event store reporting the events saved and read to a collector
+type Store struct {
+	es        core.EventStore
+	collector Collector
+	head      atomic.Uint64 // highest global version saved or read
+}
+
+// Wrap returns an event store measuring the calls to es
+func Wrap(es core.EventStore, c Collector) *Store {
+	return &Store{es: es, collector: c}
+}
+
+// Save saves the events in the underlying store
+func (s *Store) Save(events []core.Event) error {
+	return s.SaveContext(context.Background(), events)
+}
+
+// SaveContext saves the events in the underlying store and reports the events appended and the latency of the save
+func (s *Store) SaveContext(ctx context.Context, events []core.Event) error {
+	if len(events) == 0 {
+		return core.SaveContext(ctx, s.es, events)
+	}
+	start := time.Now()
+	err := core.SaveContext(ctx, s.es, events)
+	aggregateType := events[0].AggregateType
+	s.collector.SaveLatency(aggregateType, time.Since(start))
+	if err != nil {
+		return err
+	}
+	s.collector.EventsAppended(aggregateType, len(events))
+	s.seen(events[len(events)-1].GlobalVersion)
+	return nil
+}
+
+// SaveBatch saves the batches in the underlying store and reports the events appended and the latency of the save per
+// batch, core.ErrBatchNotSupported is returned if the underlying store is not a core.BatchSaver
+func (s *Store) SaveBatch(ctx context.Context, batches [][]core.Event) error {
+	saver, ok := s.es.(core.BatchSaver)
+	if !ok {
+		return core.ErrBatchNotSupported
+	}
+	start := time.Now()
+	err := saver.SaveBatch(ctx, batches)
+	d := time.Since(start)
+	for _, events := range batches {
+		if len(events) == 0 {
+			continue
+		}
+		s.collector.SaveLatency(events[0].AggregateType, d)
+	}
+	if err != nil {
+		return err
+	}
+	for _, events := range batches {
+		if len(events) == 0 {
+			continue
+		}
+		s.collector.EventsAppended(events[0].AggregateType, len(events))
+		s.seen(events[len(events)-1].GlobalVersion)
+	}
+	return nil
+}
+
+// Unwrap returns the underlying event store
+func (s *Store) Unwrap() core.EventStore {
+	return s.es
+}
+
+// Get returns the events of the aggregate from the underlying store and reports the events read
+func (s *Store) Get(ctx context.Context, id string, aggregateType string, afterVersion core.Version) (core.Iterator, error) {
+	it, err := s.es.Get(ctx, id, aggregateType, afterVersion)
+	if err != nil {
+		return nil, err
+	}
+	return &iterator{iterator: it, store: s}, nil
+}
+
+// Feed reports the events read from a global event feed like the All method on the event stores
+func (s *Store) Feed(fetchF func() (core.Iterator, error)) func() (core.Iterator, error) {
+	return func() (core.Iterator, error) {
+		it, err := fetchF()
+		if err != nil {
+			return nil, err
+		}
+		return &iterator{iterator: it, store: s}, nil
+	}
+}
+
+// Head returns the highest global version saved or read through the store. It's the head of the global event feed
+// in the process that saves the events, pass it to Handler to measure the projection lag.
+func (s *Store) Head() core.Version {
+	return core.Version(s.head.Load())
+}
+
+// seen moves the head to the global version if it's higher
+func (s *Store) seen(globalVersion core.Version) {
+	for {
+		head := s.head.Load()
+		if uint64(globalVersion) <= head || s.head.CompareAndSwap(head, uint64(globalVersion)) {
+			return
+		}
+	}
+}
+
+// Handler wraps the callback of a projection and reports the events it handles, fails on and its lag. The lag is the
+// head, e.g. Store.Head, minus the global version of the handled event. Set head to nil to not measure the lag.
+//
+//	p := eventsourcing.NewProjectionWithContext(es.Feed(fetchF), metrics.Handler(c, "person_view", es.Head, callback))
+func Handler(c Collector, name string, head func() core.Version, f func(ctx context.Context, event eventsourcing.Event) error) func(ctx context.Context, event eventsourcing.Event) error {
+	return func(ctx context.Context, event eventsourcing.Event) error {
+		err := f(ctx, event)
+		if err != nil {
+			c.ProjectionError(name)
+			return err
+		}
+		c.ProjectionEventProcessed(name)
+		if head != nil {
+			var lag uint64
+			if h := uint64(head()); h > uint64(event.GlobalVersion()) {
+				lag = h - uint64(event.GlobalVersion())
+			}
+			c.ProjectionLag(name, lag)
+		}
+		return nil
+	}
+}
+
+// iterator reports the events read to the collector
+type iterator struct {
+	iterator core.Iterator
+	store    *Store
+}
+
+func (i *iterator) Next() bool {
+	return i.iterator.Next()
+}
+
+func (i *iterator) Value() (core.Event, error) {
+	event, err := i.iterator.Value()
+	if err != nil {
+		return event, err
+	}
+	i.store.collector.EventsRead(event.AggregateType, 1)
+	i.store.seen(event.GlobalVersion)
+	return event, nil
+}
+
+func (i *iterator) Close() {
+	i.iterator.Close()
+}
@@ -0,0 +1,164 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	"github.com/hallgren/eventsourcing/metrics"
+)
+
+type Person struct {
+	aggregate.Root
+}
+
+type Born struct {
+	Name string
+}
+
+type AgedOneYear struct{}
+
+func (p *Person) Register(f aggregate.RegisterFunc) {
+	f(&Born{}, &AgedOneYear{})
+}
+
+func (p *Person) Transition(event eventsourcing.Event) {}
+
+type collector struct {
+	lock          sync.Mutex
+	appended      map[string]int
+	read          map[string]int
+	saves         int
+	processed     map[string]int
+	errors        map[string]int
+	lag           map[string]uint64
+	lastProcessed map[string]time.Time
+}
+
+func newCollector() *collector {
+	return &collector{
+		appended:      make(map[string]int),
+		read:          make(map[string]int),
+		processed:     make(map[string]int),
+		errors:        make(map[string]int),
+		lag:           make(map[string]uint64),
+		lastProcessed: make(map[string]time.Time),
+	}
+}
+
+func (c *collector) EventsAppended(aggregateType string, count int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.appended[aggregateType] += count
+}
+
+func (c *collector) EventsRead(aggregateType string, count int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.read[aggregateType] += count
+}
+
+func (c *collector) SaveLatency(aggregateType string, d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.saves++
+}
+
+func (c *collector) ProjectionEventProcessed(projection string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.processed[projection]++
+}
+
+func (c *collector) ProjectionError(projection string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.errors[projection]++
+}
+
+func (c *collector) ProjectionLag(projection string, lag uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lag[projection] = lag
+}
+
+func (c *collector) ProjectionLastProcessed(projection string, t time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.lastProcessed[projection] = t
+}
+
+func TestMetrics(t *testing.T) {
+	aggregate.Register(&Person{})
+	c := newCollector()
+	mem := memory.Create()
+	es := metrics.Wrap(mem, c)
+
+	p := Person{}
+	aggregate.TrackChange(&p, &Born{Name: "kalle"})
+	aggregate.TrackChange(&p, &AgedOneYear{})
+	aggregate.TrackChange(&p, &AgedOneYear{})
+	err := aggregate.Save(es, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.appended["Person"] != 3 || c.saves != 1 || es.Head() != 3 {
+		t.Fatalf("unexpected save metrics %+v head %d", c, es.Head())
+	}
+
+	err = aggregate.Load(context.Background(), es, p.ID(), &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.read["Person"] != 3 {
+		t.Fatalf("expected 3 events read was %d", c.read["Person"])
+	}
+
+	// the projection fails on the last event
+	projection := eventsourcing.NewProjectionWithContext(es.Feed(mem.All(0, 10)), metrics.Handler(c, "person_view", es.Head, func(ctx context.Context, event eventsourcing.Event) error {
+		if event.GlobalVersion() == 3 {
+			return errors.New("failed")
+		}
+		return nil
+	}))
+	_, result := projection.RunOnce()
+	if result.Error == nil {
+		t.Fatal("expected projection error")
+	}
+	if c.processed["person_view"] != 2 || c.errors["person_view"] != 1 {
+		t.Fatalf("expected 2 processed and 1 error was %d and %d", c.processed["person_view"], c.errors["person_view"])
+	}
+	// the second event is the last handled and one event behind the head
+	if c.lag["person_view"] != 1 {
+		t.Fatalf("expected lag 1 was %d", c.lag["person_view"])
+	}
+}
+
+func TestMetricsUnitOfWork(t *testing.T) {
+	aggregate.Register(&Person{})
+	c := newCollector()
+	es := metrics.Wrap(memory.Create(), c)
+
+	kalle := Person{}
+	aggregate.TrackChange(&kalle, &Born{Name: "kalle"})
+	anka := Person{}
+	aggregate.TrackChange(&anka, &Born{Name: "anka"})
+	aggregate.TrackChange(&anka, &AgedOneYear{})
+	uow := aggregate.NewUnitOfWork(es)
+	uow.Add(&kalle, &anka)
+	err := uow.Commit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.appended["Person"] != 3 || c.saves != 2 || es.Head() != 3 {
+		t.Fatalf("unexpected batch save metrics %+v head %d", c, es.Head())
+	}
+	if es.Unwrap() == nil {
+		t.Fatal("expected the wrapped event store")
+	}
+}
@@ -0,0 +1,106 @@
+// Package prometheus implements the metrics.Collector with Prometheus metrics in the "eventsourcing" namespace
+package prometheus
+
+import (
+	"time"
+
+	"github.com/hallgren/eventsourcing/metrics"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+var _ metrics.Collector = (*Collector)(nil)
+
+// Collector reports the measurements as Prometheus metrics
+type Collector struct {
+	eventsAppended    *prom.CounterVec
+	eventsRead        *prom.CounterVec
+	saveLatency       *prom.HistogramVec
+	projectionEvents  *prom.CounterVec
+	projectionErrors  *prom.CounterVec
+	projectionLag     *prom.GaugeVec
+	projectionLastRun *prom.GaugeVec
+}
+
+// New creates the collector and registers its metrics in the registerer
+func New(reg prom.Registerer) (*Collector, error) {
+	c := &Collector{
+		eventsAppended: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: "eventsourcing",
+			Name:      "events_appended_total",
+			Help:      "Number of events saved.",
+		}, []string{"aggregate_type"}),
+		eventsRead: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: "eventsourcing",
+			Name:      "events_read_total",
+			Help:      "Number of events read from aggregate streams and global event feeds.",
+		}, []string{"aggregate_type"}),
+		saveLatency: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: "eventsourcing",
+			Name:      "save_duration_seconds",
+			Help:      "Duration of saving the events of an aggregate.",
+			Buckets:   prom.DefBuckets,
+		}, []string{"aggregate_type"}),
+		projectionEvents: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: "eventsourcing",
+			Name:      "projection_events_total",
+			Help:      "Number of events handled by the projection.",
+		}, []string{"projection"}),
+		projectionErrors: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: "eventsourcing",
+			Name:      "projection_errors_total",
+			Help:      "Number of events the projection failed to handle.",
+		}, []string{"projection"}),
+		projectionLag: prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: "eventsourcing",
+			Name:      "projection_lag_events",
+			Help:      "Number of events in the global event feed after the last event handled by the projection.",
+		}, []string{"projection"}),
+		projectionLastRun: prom.NewGaugeVec(prom.GaugeOpts{
+			Namespace: "eventsourcing",
+			Name:      "projection_last_processed_timestamp_seconds",
+			Help:      "Unix time the projection last completed a fetch without error.",
+		}, []string{"projection"}),
+	}
+	for _, collector := range []prom.Collector{c.eventsAppended, c.eventsRead, c.saveLatency, c.projectionEvents, c.projectionErrors, c.projectionLag, c.projectionLastRun} {
+		err := reg.Register(collector)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// EventsAppended adds to eventsourcing_events_appended_total
+func (c *Collector) EventsAppended(aggregateType string, count int) {
+	c.eventsAppended.WithLabelValues(aggregateType).Add(float64(count))
+}
+
+// EventsRead adds to eventsourcing_events_read_total
+func (c *Collector) EventsRead(aggregateType string, count int) {
+	c.eventsRead.WithLabelValues(aggregateType).Add(float64(count))
+}
+
+// SaveLatency observes eventsourcing_save_duration_seconds
+func (c *Collector) SaveLatency(aggregateType string, d time.Duration) {
+	c.saveLatency.WithLabelValues(aggregateType).Observe(d.Seconds())
+}
+
+// ProjectionEventProcessed increments eventsourcing_projection_events_total
+func (c *Collector) ProjectionEventProcessed(projection string) {
+	c.projectionEvents.WithLabelValues(projection).Inc()
+}
+
+// ProjectionError increments eventsourcing_projection_errors_total
+func (c *Collector) ProjectionError(projection string) {
+	c.projectionErrors.WithLabelValues(projection).Inc()
+}
+
+// ProjectionLag sets eventsourcing_projection_lag_events
+func (c *Collector) ProjectionLag(projection string, lag uint64) {
+	c.projectionLag.WithLabelValues(projection).Set(float64(lag))
+}
+
+// ProjectionLastProcessed sets eventsourcing_projection_last_processed_timestamp_seconds
+func (c *Collector) ProjectionLastProcessed(projection string, t time.Time) {
+	c.projectionLastRun.WithLabelValues(projection).Set(float64(t.UnixNano()) / 1e9)
+}
@@ -0,0 +1,74 @@
+package prometheus_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/metrics/prometheus"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	reg := prom.NewRegistry()
+	c, err := prometheus.New(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.EventsAppended("Person", 3)
+	c.EventsRead("Person", 1)
+	c.EventsRead("Person", 1)
+	c.SaveLatency("Person", time.Millisecond)
+	c.ProjectionEventProcessed("person_view")
+	c.ProjectionError("person_view")
+	c.ProjectionLag("person_view", 7)
+	c.ProjectionLastProcessed("person_view", time.Unix(1700000000, 0))
+
+	expected := `
+# HELP eventsourcing_events_appended_total Number of events saved.
+# TYPE eventsourcing_events_appended_total counter
+eventsourcing_events_appended_total{aggregate_type="Person"} 3
+# HELP eventsourcing_events_read_total Number of events read from aggregate streams and global event feeds.
+# TYPE eventsourcing_events_read_total counter
+eventsourcing_events_read_total{aggregate_type="Person"} 2
+# HELP eventsourcing_projection_errors_total Number of events the projection failed to handle.
+# TYPE eventsourcing_projection_errors_total counter
+eventsourcing_projection_errors_total{projection="person_view"} 1
+# HELP eventsourcing_projection_events_total Number of events handled by the projection.
+# TYPE eventsourcing_projection_events_total counter
+eventsourcing_projection_events_total{projection="person_view"} 1
+# HELP eventsourcing_projection_lag_events Number of events in the global event feed after the last event handled by the projection.
+# TYPE eventsourcing_projection_lag_events gauge
+eventsourcing_projection_lag_events{projection="person_view"} 7
+# HELP eventsourcing_projection_last_processed_timestamp_seconds Unix time the projection last completed a fetch without error.
+# TYPE eventsourcing_projection_last_processed_timestamp_seconds gauge
+eventsourcing_projection_last_processed_timestamp_seconds{projection="person_view"} 1.7e+09
+`
+	err = testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"eventsourcing_events_appended_total",
+		"eventsourcing_events_read_total",
+		"eventsourcing_projection_errors_total",
+		"eventsourcing_projection_events_total",
+		"eventsourcing_projection_lag_events",
+		"eventsourcing_projection_last_processed_timestamp_seconds",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if testutil.CollectAndCount(reg, "eventsourcing_save_duration_seconds") != 1 {
+		t.Fatal("expected the save latency histogram")
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	reg := prom.NewRegistry()
+	_, err := prometheus.New(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = prometheus.New(reg)
+	if err == nil {
+		t.Fatal("expected already registered error")
+	}
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// HeadFunc returns the global version of the last event in the event store
+type HeadFunc func(ctx context.Context) (core.Version, error)
+
+// StatsHead returns the head from the stats of the event store
+func StatsHead(r core.StatsReader) HeadFunc {
+	return func(ctx context.Context) (core.Version, error) {
+		stats, err := r.Stats(ctx)
+		return stats.Head, err
+	}
+}
+
+// LagSampler samples the position of every projection in a projection manager against the head of the event store
+// and reports the lag and the time the projection last processed events. Unlike Handler it measures projections
+// running in any process and projections that are stuck.
+type LagSampler struct {
+	Interval  time.Duration // Interval is the time between the samples
+	collector Collector
+	manager   *eventsourcing.ProjectionManager
+	head      HeadFunc
+}
+
+// NewLagSampler creates a sampler of the projections in the manager
+func NewLagSampler(c Collector, m *eventsourcing.ProjectionManager, head HeadFunc) *LagSampler {
+	return &LagSampler{
+		Interval:  15 * time.Second,
+		collector: c,
+		manager:   m,
+		head:      head,
+	}
+}
+
+// Run samples the projections every interval until the context is done
+func (s *LagSampler) Run(ctx context.Context) error {
+	for {
+		err := s.Sample(ctx)
+		if err != nil {
+			eventsourcing.Log().Warn("projection lag sample failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.Interval):
+		}
+	}
+}
+
+// Sample reports the lag and the last processed time of the projections once
+func (s *LagSampler) Sample(ctx context.Context) error {
+	head, err := s.head(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range s.manager.Projections() {
+		var lag uint64
+		if position := p.Position(); head > position {
+			lag = uint64(head - position)
+		}
+		s.collector.ProjectionLag(p.Name, lag)
+		if t := p.LastRun(); !t.IsZero() {
+			s.collector.ProjectionLastProcessed(p.Name, t)
+		}
+	}
+	return nil
+}
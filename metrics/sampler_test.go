@@ -0,0 +1,56 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	"github.com/hallgren/eventsourcing/metrics"
+)
+
+func TestLagSampler(t *testing.T) {
+	aggregate.Register(&Person{})
+	es := memory.Create()
+	p := Person{}
+	aggregate.TrackChange(&p, &Born{Name: "kalle"})
+	aggregate.TrackChange(&p, &AgedOneYear{})
+	aggregate.TrackChange(&p, &AgedOneYear{})
+	err := aggregate.Save(es, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the projection handles two of the three events
+	projection := eventsourcing.NewProjection(es.All(0, 2), func(e eventsourcing.Event) error {
+		return nil
+	})
+	projection.Name = "person_view"
+	idle := eventsourcing.NewProjection(es.All(0, 2), func(e eventsourcing.Event) error {
+		return nil
+	})
+	idle.Name = "idle"
+	m := eventsourcing.NewProjectionManager()
+	m.Add(projection, eventsourcing.RestartNever)
+	m.Add(idle, eventsourcing.RestartNever)
+	_, result := projection.RunOnce()
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	c := newCollector()
+	err = metrics.NewLagSampler(c, m, metrics.StatsHead(es)).Sample(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.lag["person_view"] != 1 || c.lag["idle"] != 3 {
+		t.Fatalf("expected the lag 1 and 3 was %v", c.lag)
+	}
+	if !c.lastProcessed["person_view"].Equal(projection.LastRun()) || projection.LastRun().IsZero() {
+		t.Fatalf("unexpected last processed time %v", c.lastProcessed["person_view"])
+	}
+	if _, ok := c.lastProcessed["idle"]; ok {
+		t.Fatal("expected no last processed time of the projection that has not run")
+	}
+}
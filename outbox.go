@@ -0,0 +1,63 @@
+package eventsourcing
+
+import (
+	"context"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// OutboxSink publishes an event to an external system, e.g. a message broker
+type OutboxSink func(ctx context.Context, event Event) error
+
+// OutboxRelay publishes the events in an outbox to a sink. An event is removed from the outbox after it's published,
+// if the relay stops in between the event is published again. The sink has to handle duplicates, the delivery is
+// at least once.
+type OutboxRelay struct {
+	Retries   int           // Retries is the number of times a failed publish is retried before the relay fails
+	Backoff   time.Duration // Backoff is the delay before the first retry, it's doubled on every retry
+	BatchSize uint64        // BatchSize is the max number of events fetched from the outbox at a time
+	outbox    core.Outbox
+	sink      OutboxSink
+}
+
+// NewOutboxRelay creates a relay publishing the events in the outbox to the sink
+func NewOutboxRelay(outbox core.Outbox, sink OutboxSink) *OutboxRelay {
+	return &OutboxRelay{
+		Retries:   3,
+		Backoff:   100 * time.Millisecond,
+		BatchSize: 100,
+		outbox:    outbox,
+		sink:      sink,
+	}
+}
+
+// Projection returns the projection relaying the events, run it as any other projection. A relay failing after its
+// retries stops the projection, run it in a projection manager to restart it.
+func (r *OutboxRelay) Projection() *Projection {
+	p := NewProjectionWithContext(func() (core.Iterator, error) {
+		return r.outbox.Pending(context.Background(), r.BatchSize)
+	}, r.relay)
+	p.Name = "outbox"
+	return p
+}
+
+// relay publishes the event, retrying on failure, and removes it from the outbox
+func (r *OutboxRelay) relay(ctx context.Context, event Event) error {
+	backoff := r.Backoff
+	err := r.sink(ctx, event)
+	for retry := 0; err != nil && retry < r.Retries; retry++ {
+		Log().Warn("outbox publish failed, retrying", "global_version", event.GlobalVersion(), "retry", retry+1, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		err = r.sink(ctx, event)
+	}
+	if err != nil {
+		return err
+	}
+	return r.outbox.Published(ctx, core.Version(event.GlobalVersion()))
+}
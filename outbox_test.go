@@ -0,0 +1,103 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// sliceOutbox keeps the pending events in a slice
+type sliceOutbox struct {
+	events []core.Event
+}
+
+func (o *sliceOutbox) Pending(ctx context.Context, count uint64) (core.Iterator, error) {
+	events := o.events
+	if uint64(len(events)) > count {
+		events = events[:count]
+	}
+	return &sliceIterator{events: append([]core.Event{}, events...)}, nil
+}
+
+func (o *sliceOutbox) Published(ctx context.Context, globalVersion core.Version) error {
+	for i, e := range o.events {
+		if e.GlobalVersion == globalVersion {
+			o.events = append(o.events[:i], o.events[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+type sliceIterator struct {
+	events []core.Event
+	event  core.Event
+}
+
+func (i *sliceIterator) Next() bool {
+	if len(i.events) == 0 {
+		return false
+	}
+	i.event, i.events = i.events[0], i.events[1:]
+	return true
+}
+
+func (i *sliceIterator) Value() (core.Event, error) {
+	return i.event, nil
+}
+
+func (i *sliceIterator) Close() {}
+
+func TestOutboxRelay(t *testing.T) {
+	aggregate.Register(&Person{})
+	outbox := &sliceOutbox{}
+	for v := 1; v <= 3; v++ {
+		outbox.events = append(outbox.events, core.Event{AggregateID: "123", AggregateType: "Person", Version: core.Version(v), GlobalVersion: core.Version(v), Reason: "AgedOneYear", Data: []byte("{}")})
+	}
+
+	failures := 2
+	var published []eventsourcing.Version
+	relay := eventsourcing.NewOutboxRelay(outbox, func(ctx context.Context, event eventsourcing.Event) error {
+		if failures > 0 {
+			failures--
+			return errors.New("sink unavailable")
+		}
+		published = append(published, event.GlobalVersion())
+		return nil
+	})
+	relay.Backoff = time.Millisecond
+
+	result := relay.Projection().RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(published) != 3 || published[0] != 1 || published[2] != 3 {
+		t.Fatalf("expected the 3 events to be published in order was %v", published)
+	}
+	if len(outbox.events) != 0 {
+		t.Fatalf("expected an empty outbox was %d events", len(outbox.events))
+	}
+}
+
+func TestOutboxRelayFails(t *testing.T) {
+	aggregate.Register(&Person{})
+	outbox := &sliceOutbox{events: []core.Event{{AggregateID: "123", AggregateType: "Person", Version: 1, GlobalVersion: 1, Reason: "AgedOneYear", Data: []byte("{}")}}}
+
+	relay := eventsourcing.NewOutboxRelay(outbox, func(ctx context.Context, event eventsourcing.Event) error {
+		return errors.New("sink unavailable")
+	})
+	relay.Backoff = time.Millisecond
+
+	result := relay.Projection().RunToEnd(context.Background())
+	if result.Error == nil {
+		t.Fatal("expected error when the sink keeps failing")
+	}
+	if len(outbox.events) != 1 {
+		t.Fatal("expected the event to stay in the outbox")
+	}
+}
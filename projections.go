@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,6 +14,7 @@ import (
 
 type fetchFunc func() (core.Iterator, error)
 type callbackFunc func(e Event) error
+type callbackContextFunc func(ctx context.Context, e Event) error
 
 // ErrProjectionAlreadyRunning is returned if Run is called on an already running projection
 var ErrProjectionAlreadyRunning = errors.New("projection is already running")
@@ -20,19 +22,53 @@ var ErrProjectionAlreadyRunning = errors.New("projection is already running")
 type Projection struct {
 	running   atomic.Bool
 	fetchF    fetchFunc
-	callbackF callbackFunc
+	callbackF callbackContextFunc
 	trigger   chan func()
 	Strict    bool // Strict indicate if the projection should return error if the event it fetches is not found in the register
 	Name      string
+	Filter    core.Filter   // Filter is evaluated on the fetched events, events not matching are not passed to the callback
+	RateLimit int           // RateLimit is the max number of events per second handled by the callback, zero means no limit
+	BatchPace time.Duration // BatchPace is the pause between fetches when the projection runs to the end of the event stream
+	nextEvent time.Time     // nextEvent is the earliest time the next event is allowed to be handled
+	Pace      time.Duration // Pace overrides the pace of the group or manager running the projection, zero means the group or manager pace is used
+	BatchSize int           // BatchSize is the max number of events handled per fetch, only used by projections created with a fetch from function
+	Workers   int           // Workers is the number of goroutines calling the callback concurrently, the events of an aggregate are always handled in order by the same worker. Only used by projections created with a fetch from function, as a failed batch is replayed from the position
+
+	Buffer Buffer // Buffer is the callback holding events before they are handled, the checkpoint is kept before the held events and the buffer is flushed when the projection is stopped
+
+	SlowCallback   time.Duration                                   // SlowCallback is the duration a callback can take before it's reported as slow, zero turns the reporting off
+	OnSlowCallback func(name string, event Event, d time.Duration) // OnSlowCallback is called with the event of a slow callback, by default it's logged as a warning
+
+	Head         func(ctx context.Context) (core.Version, error)  // Head returns the head of the event store, e.g. the GlobalVersion method of the event store. It's read before the first fetch as the watermark to reach to be caught up
+	OnCaughtUp   func(ctx context.Context, position core.Version) // OnCaughtUp is called once when the projection has handled the events up to the watermark or reached the end of the event stream
+	watermark    core.Version                                     // watermark is the head as of the start of the projection
+	watermarkSet bool                                             // watermarkSet indicate if the watermark is read from Head
+	caughtUp     atomic.Bool
+
+	Version     int                             // Version of the projection logic, a changed version resets the checkpoint and replays the event stream
+	OnReset     func(ctx context.Context) error // OnReset is called before the checkpoint is reset, e.g. to clear the read-model
+	Start       StartPosition                   // Start decides where the projection starts when it has no checkpoint, only used by projections created with a fetch from function
+	Store       core.EventStore                 // Store is the event store fetched from, its core.GlobalVersionReader and core.TimeRangeReader find the Start position without reading the event stream
+	checkpoints core.CheckpointStore
+	fetchFrom   fetchFromFunc
+	position    core.Version  // global version of the last handled event
+	handled     atomic.Uint64 // position published after each fetch, safe to read while the projection is running
+	loaded      bool          // loaded indicate if the position is loaded from the checkpoint store
+
+	lastRun      atomic.Int64 // unix nano time of the last fetch handled without error
+	failingSince atomic.Int64 // unix nano time of the first error after the last run without error, zero when not failing
+	lastError    atomic.Value // string of the last error
 }
 
 // ProjectionGroup runs projections concurrently
 type ProjectionGroup struct {
-	Pace        time.Duration // Pace is used when a projection is running and it reaches the end of the event stream
-	projections []*Projection
-	cancelF     context.CancelFunc
-	wg          sync.WaitGroup
-	ErrChan     chan error
+	Pace          time.Duration // Pace is used when a projection is running and it reaches the end of the event stream
+	StartInterval time.Duration // StartInterval is the delay between starting each projection, spreading the catch-up load after a deploy
+	projections   []*Projection
+	cancelF       context.CancelFunc
+	stop          chan struct{}
+	wg            sync.WaitGroup
+	ErrChan       chan error
 }
 
 // ProjectionResult is the return type for a Group and Race
@@ -44,11 +80,19 @@ type ProjectionResult struct {
 
 // Projection creates a projection that will run down an event stream
 func NewProjection(fetchF fetchFunc, callbackF callbackFunc) *Projection {
+	return NewProjectionWithContext(fetchF, func(_ context.Context, e Event) error {
+		return callbackF(e)
+	})
+}
+
+// NewProjectionWithContext creates a projection where the callback receives the context the projection is running
+// with. It makes it possible for the callback to respect cancellation and to use values bound to the context.
+func NewProjectionWithContext(fetchF fetchFunc, callbackF callbackContextFunc) *Projection {
 	projection := Projection{
 		fetchF:    fetchF,
 		callbackF: callbackF,
-		trigger:   make(chan func()),
-		Strict:    true, // Default strict is active
+		trigger:   make(chan func(), 1), // buffered to not lose an async trigger while the projection is running
+		Strict:    true,                 // Default strict is active
 	}
 	return &projection
 }
@@ -84,6 +128,12 @@ func (p *Projection) TriggerSync() {
 // Run runs the projection forever until the context is cancelled. When there are no more events to consume it
 // waits for a trigger or context cancel.
 func (p *Projection) Run(ctx context.Context, pace time.Duration) error {
+	return p.run(ctx, pace, nil)
+}
+
+// run runs the projection until the context is cancelled or the stop channel is closed. When the stop channel is
+// closed the projection returns without error after it has finished the batch in flight.
+func (p *Projection) run(ctx context.Context, pace time.Duration, stop <-chan struct{}) error {
 	if p.running.Load() {
 		return ErrProjectionAlreadyRunning
 	}
@@ -100,7 +150,7 @@ func (p *Projection) Run(ctx context.Context, pace time.Duration) error {
 		f = noopFunc
 	}
 	for {
-		result := p.RunToEnd(ctx)
+		result := p.runToEnd(ctx, stop)
 		// if triggered by a sync trigger the triggerFunc callback that it's finished
 		// if not triggered by a sync trigger the triggerFunc will call an no ops function
 		triggerFunc()
@@ -108,6 +158,8 @@ func (p *Projection) Run(ctx context.Context, pace time.Duration) error {
 			return result.Error
 		}
 		select {
+		case <-stop:
+			return p.flush(ctx)
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-time.After(pace):
@@ -116,17 +168,39 @@ func (p *Projection) Run(ctx context.Context, pace time.Duration) error {
 	}
 }
 
+// flush handles the events held in the buffer when the projection is stopped and stores the position after them
+func (p *Projection) flush(ctx context.Context) error {
+	if p.Buffer == nil {
+		return nil
+	}
+	err := p.Buffer.Flush(ctx)
+	if err != nil {
+		return err
+	}
+	if p.checkpoints == nil || !p.loaded {
+		return nil
+	}
+	return p.saveCheckpoint()
+}
+
 // RunToEnd runs until the projection reaches the end of the event stream
 func (p *Projection) RunToEnd(ctx context.Context) ProjectionResult {
+	return p.runToEnd(ctx, nil)
+}
+
+// runToEnd runs until the projection reaches the end of the event stream or the stop channel is closed
+func (p *Projection) runToEnd(ctx context.Context, stop <-chan struct{}) ProjectionResult {
 	var result ProjectionResult
 	var lastHandledEvent Event
 
 	for {
 		select {
+		case <-stop:
+			return ProjectionResult{Name: p.Name, LastHandledEvent: lastHandledEvent}
 		case <-ctx.Done():
 			return ProjectionResult{Error: ctx.Err(), Name: result.Name, LastHandledEvent: result.LastHandledEvent}
 		default:
-			ran, result := p.RunOnce()
+			ran, result := p.runOnce(ctx)
 			// if the first event returned error or if it did not run at all
 			if result.LastHandledEvent.GlobalVersion() == 0 {
 				result.LastHandledEvent = lastHandledEvent
@@ -139,12 +213,143 @@ func (p *Projection) RunToEnd(ctx context.Context) ProjectionResult {
 				return result
 			}
 			lastHandledEvent = result.LastHandledEvent
+			if p.BatchPace > 0 {
+				select {
+				case <-stop:
+				case <-ctx.Done():
+				case <-time.After(p.BatchPace):
+				}
+			}
 		}
 	}
 }
 
 // RunOnce runs the fetch method one time
 func (p *Projection) RunOnce() (bool, ProjectionResult) {
+	return p.runOnce(context.Background())
+}
+
+// runOnce runs the fetch method one time passing the context to the callback
+func (p *Projection) runOnce(ctx context.Context) (ran bool, result ProjectionResult) {
+	defer func() {
+		// publish the position to readers waiting for a consistency token
+		p.handled.Store(uint64(p.position))
+		p.track(result.Error)
+		if result.Error == nil {
+			p.checkCaughtUp(ctx, ran)
+		}
+	}()
+	err := p.readWatermark(ctx)
+	if err != nil {
+		return false, ProjectionResult{Error: err, Name: p.Name}
+	}
+	if p.fetchFrom == nil {
+		return p.iterate(ctx)
+	}
+	err = p.loadCheckpoint(ctx)
+	if err != nil {
+		return false, ProjectionResult{Error: err, Name: p.Name}
+	}
+	position := p.position
+	ran, result = p.iterate(ctx)
+	// store the position of the handled events also when the callback returned an error
+	if p.checkpoints != nil && p.position != position {
+		err = p.saveCheckpoint()
+		if err != nil && result.Error == nil {
+			return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: result.LastHandledEvent}
+		}
+	}
+	return ran, result
+}
+
+// readWatermark reads the head of the event store the first time it's called, the projection is caught up when it has
+// handled the events up to it
+func (p *Projection) readWatermark(ctx context.Context) error {
+	if p.watermarkSet || p.Head == nil {
+		return nil
+	}
+	head, err := p.Head(ctx)
+	if err != nil {
+		return err
+	}
+	p.watermark = head
+	p.watermarkSet = true
+	return nil
+}
+
+// checkCaughtUp marks the projection as caught up and calls OnCaughtUp when it has handled the events up to the
+// watermark, or when the fetch returned no events as a filtered event stream may not hold the event at the watermark
+func (p *Projection) checkCaughtUp(ctx context.Context, ran bool) {
+	if p.caughtUp.Load() {
+		return
+	}
+	if ran && (!p.watermarkSet || p.position < p.watermark) {
+		return
+	}
+	p.caughtUp.Store(true)
+	if p.OnCaughtUp != nil {
+		p.OnCaughtUp(ctx, p.position)
+	}
+}
+
+// track records the time of the run for the health check of the projection manager
+func (p *Projection) track(err error) {
+	now := time.Now().UnixNano()
+	if err == nil {
+		p.lastRun.Store(now)
+		p.failingSince.Store(0)
+		return
+	}
+	p.failingSince.CompareAndSwap(0, now)
+	p.lastError.Store(err.Error())
+}
+
+// iterate fetch events and pass them to the callback, or to the workers if the projection has more than one worker.
+// The workers are only used when the events are fetched from the position, a fetch function without position has
+// already moved past the batch and can't replay it on error.
+func (p *Projection) iterate(ctx context.Context) (bool, ProjectionResult) {
+	callbackF := p.timedCallback()
+	if p.Workers <= 1 || p.fetchFrom == nil {
+		return p.iterateWith(ctx, callbackF)
+	}
+	position := p.position
+	s := startShards(ctx, p.Workers, callbackF)
+	ran, result := p.iterateWith(s.ctx, s.dispatch)
+	err := s.wait()
+	if result.Error == nil && err != nil {
+		result.Error = err
+	}
+	if result.Error != nil {
+		// the workers may have handled events in a different order than the global order, replay the whole batch
+		p.position = position
+		return false, ProjectionResult{Error: result.Error, Name: p.Name}
+	}
+	return ran, result
+}
+
+// timedCallback returns the callback reporting calls slower than SlowCallback
+func (p *Projection) timedCallback() callbackContextFunc {
+	if p.SlowCallback <= 0 {
+		return p.callbackF
+	}
+	report := p.OnSlowCallback
+	if report == nil {
+		report = func(name string, event Event, d time.Duration) {
+			Log().Warn("slow projection callback", "projection", name, "reason", event.Reason(), "aggregate_type", event.AggregateType(), "aggregate_id", event.AggregateID(), "global_version", event.GlobalVersion(), "duration", d)
+		}
+	}
+	return func(ctx context.Context, event Event) error {
+		start := time.Now()
+		err := p.callbackF(ctx, event)
+		if d := time.Since(start); d > p.SlowCallback {
+			report(p.Name, event, d)
+		}
+		return err
+	}
+}
+
+// iterateWith fetch events and pass them to the handle function
+func (p *Projection) iterateWith(ctx context.Context, handleF callbackContextFunc) (bool, ProjectionResult) {
 	// ran indicate if there were events to fetch
 	var ran bool
 	var lastHandledEvent Event
@@ -158,7 +363,13 @@ func (p *Projection) RunOnce() (bool, ProjectionResult) {
 	}
 	defer iterator.Close()
 
-	for iterator.Next() {
+	// the batch size is only used when the position is not part of the fetch function, as the rest of the batch would
+	// otherwise be skipped
+	limit := 0
+	if p.fetchFrom != nil {
+		limit = p.BatchSize
+	}
+	for fetched := 0; (limit <= 0 || fetched < limit) && iterator.Next(); fetched++ {
 		ran = true
 		event, err := iterator.Value()
 		if err != nil {
@@ -167,21 +378,60 @@ func (p *Projection) RunOnce() (bool, ProjectionResult) {
 					err = fmt.Errorf("event not registered aggregate type: %s, reason: %s, global version: %d, %w", event.AggregateType(), event.Reason(), event.GlobalVersion(), ErrEventNotRegistered)
 					return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
 				}
+				// step over the event not registered
+				p.position = core.Version(event.GlobalVersion())
 				continue
 			}
 			return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
 		}
 
-		err = p.callbackF(event)
+		if !p.Filter.Match(event.event) || !p.Filter.MatchMetadata(event.metadata) {
+			// step over the event not matching the filter
+			p.position = core.Version(event.GlobalVersion())
+			continue
+		}
+
+		err = p.throttle(ctx)
+		if err != nil {
+			return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
+		}
+		err = handleF(ctx, event)
 		if err != nil {
 			return false, ProjectionResult{Error: err, Name: p.Name, LastHandledEvent: lastHandledEvent}
 		}
 		// keep a reference to the last successfully handled event
 		lastHandledEvent = event
+		p.position = core.Version(event.GlobalVersion())
 	}
 	return ran, ProjectionResult{Error: nil, Name: p.Name, LastHandledEvent: lastHandledEvent}
 }
 
+// pace returns the projection pace or the default pace if the projection has no pace set
+func (p *Projection) pace(defaultPace time.Duration) time.Duration {
+	if p.Pace > 0 {
+		return p.Pace
+	}
+	return defaultPace
+}
+
+// throttle blocks until the next event is allowed to be handled based on the projection RateLimit
+func (p *Projection) throttle(ctx context.Context) error {
+	if p.RateLimit <= 0 {
+		return nil
+	}
+	now := time.Now()
+	if p.nextEvent.After(now) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(p.nextEvent.Sub(now)):
+		}
+		now = p.nextEvent
+	}
+	p.nextEvent = now.Add(time.Second / time.Duration(p.RateLimit))
+	return nil
+}
+
 // Group runs a group of projections concurrently
 func NewProjectionGroup(projections ...*Projection) *ProjectionGroup {
 	return &ProjectionGroup{
@@ -195,18 +445,32 @@ func NewProjectionGroup(projections ...*Projection) *ProjectionGroup {
 // if a result containing an error is returned from a projection
 func (g *ProjectionGroup) Start() {
 	g.ErrChan = make(chan error)
+	g.stop = make(chan struct{})
 	ctx, cancel := context.WithCancel(context.Background())
 	g.cancelF = cancel
 
 	g.wg.Add(len(g.projections))
-	for _, projection := range g.projections {
-		go func(p *Projection) {
+	for i, projection := range g.projections {
+		go func(p *Projection, delay time.Duration) {
 			defer g.wg.Done()
-			err := p.Run(ctx, g.Pace)
-			if !errors.Is(err, context.Canceled) {
+			if delay > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-g.stop:
+					return
+				case <-time.After(delay):
+				}
+			}
+			Log().Debug("projection started", "projection", p.Name)
+			err := p.run(ctx, p.pace(g.Pace), g.stop)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				Log().Error("projection failed", "projection", p.Name, "error", err)
 				g.ErrChan <- err
+				return
 			}
-		}(projection)
+			Log().Debug("projection stopped", "projection", p.Name)
+		}(projection, time.Duration(i)*g.StartInterval)
 	}
 }
 
@@ -246,6 +510,34 @@ func (g *ProjectionGroup) Stop() {
 	g.ErrChan = nil
 }
 
+// GracefulStop halts all projections in the group after they have finished the batch in flight and stored their
+// checkpoints. Projections that have not stopped within the timeout are cancelled.
+func (g *ProjectionGroup) GracefulStop(timeout time.Duration) {
+	if g.ErrChan == nil {
+		return
+	}
+	close(g.stop)
+
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		g.cancelF()
+		<-done
+	}
+	g.cancelF()
+
+	// close the error channel
+	close(g.ErrChan)
+
+	g.ErrChan = nil
+}
+
 // ProjectionsRace runs the projections to the end of the events streams.
 // Can be used on a stale event stream with no more events coming in or when you want to know when all projections are done.
 func ProjectionsRace(cancelOnError bool, projections ...*Projection) ([]ProjectionResult, error) {
@@ -280,3 +572,43 @@ func ProjectionsRace(cancelOnError bool, projections ...*Projection) ([]Projecti
 	wg.Wait()
 	return results, causingErr
 }
+
+// ProjectionsError holds the results of the projections that failed in a race or in a manager
+type ProjectionsError struct {
+	Results []ProjectionResult
+}
+
+func (e *ProjectionsError) Error() string {
+	msgs := make([]string, len(e.Results))
+	for i, result := range e.Results {
+		msgs[i] = fmt.Sprintf("%s: %v", result.Name, result.Error)
+	}
+	return fmt.Sprintf("%d projections failed, %s", len(e.Results), strings.Join(msgs, ", "))
+}
+
+// Unwrap returns the errors of the failed projections
+func (e *ProjectionsError) Unwrap() []error {
+	errs := make([]error, len(e.Results))
+	for i, result := range e.Results {
+		errs[i] = result.Error
+	}
+	return errs
+}
+
+// ProjectionsRaceAll runs the projections to the end of the event streams without cancelling the other projections
+// when one of them returns an error. When all projections are done a *ProjectionsError is returned holding the
+// results of all failed projections.
+func ProjectionsRaceAll(projections ...*Projection) ([]ProjectionResult, error) {
+	results, _ := ProjectionsRace(false, projections...)
+
+	var failed []ProjectionResult
+	for _, result := range results {
+		if result.Error != nil {
+			failed = append(failed, result)
+		}
+	}
+	if len(failed) > 0 {
+		return results, &ProjectionsError{Results: failed}
+	}
+	return results, nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -503,3 +504,525 @@ func TestKeepStartPosition(t *testing.T) {
 		t.Fatalf("expected counter to be 10 was %d", counter)
 	}
 }
+
+func TestCallbackWithContext(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type key string
+	ctx := context.WithValue(context.Background(), key("tenant"), "acme")
+	tenant := ""
+
+	proj := eventsourcing.NewProjectionWithContext(es.All(0, 1), func(ctx context.Context, event eventsourcing.Event) error {
+		tenant, _ = ctx.Value(key("tenant")).(string)
+		return nil
+	})
+
+	result := proj.RunToEnd(ctx)
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if tenant != "acme" {
+		t.Fatalf("expected tenant %q from context was %q", "acme", tenant)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	// Born + 9 AgedOneYear
+	err := createPersonEvent(es, "kalle", 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counter := 0
+	proj := eventsourcing.NewProjection(es.All(0, 5), func(event eventsourcing.Event) error {
+		counter++
+		return nil
+	})
+	proj.RateLimit = 100
+
+	start := time.Now()
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if counter != 10 {
+		t.Fatalf("expected 10 handled events was %d", counter)
+	}
+	// 10 events at 100 events per second has to take at least 90 milliseconds
+	if time.Since(start) < time.Millisecond*90 {
+		t.Fatalf("expected the projection to be throttled, took %s", time.Since(start))
+	}
+}
+
+func TestRateLimitCancel(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proj := eventsourcing.NewProjection(es.All(0, 5), func(event eventsourcing.Event) error {
+		return nil
+	})
+	proj.RateLimit = 1
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	result := proj.RunToEnd(ctx)
+	if !errors.Is(result.Error, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded got %v", result.Error)
+	}
+	if result.LastHandledEvent.Version() != 1 {
+		t.Fatalf("expected only the first event to be handled was version %d", result.LastHandledEvent.Version())
+	}
+}
+
+func TestBatchPace(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proj := eventsourcing.NewProjection(es.All(0, 1), func(event eventsourcing.Event) error {
+		return nil
+	})
+	proj.BatchPace = time.Millisecond * 20
+
+	start := time.Now()
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	// three batches with one event each
+	if time.Since(start) < time.Millisecond*60 {
+		t.Fatalf("expected a pause between each batch, took %s", time.Since(start))
+	}
+}
+
+func TestProjectionFilter(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	person, err := CreatePerson("anka")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+	err = aggregate.Save(es, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the AgedOneYear event from the saved aggregate holds metadata
+	counter := 0
+	proj := eventsourcing.NewProjection(es.All(0, 10), func(event eventsourcing.Event) error {
+		counter++
+		return nil
+	})
+	proj.Filter = core.ByReason("AgedOneYear").WithMetadata("foo", "bar")
+
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if counter != 1 {
+		t.Fatalf("expected 1 event to match the filter was %d", counter)
+	}
+}
+
+func TestGracefulStop(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	// Born + 9 AgedOneYear
+	err := createPersonEvent(es, "kalle", 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{}, 10)
+	var counter atomic.Int32
+	proj := eventsourcing.NewProjectionWithContext(es.All(0, 5), func(ctx context.Context, event eventsourcing.Event) error {
+		started <- struct{}{}
+		time.Sleep(time.Millisecond * 5)
+		// the callback context should not be cancelled during the drain
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		counter.Add(1)
+		return nil
+	})
+
+	group := eventsourcing.NewProjectionGroup(proj)
+	group.Start()
+
+	// stop when the first batch is in flight
+	<-started
+	group.GracefulStop(time.Second)
+
+	// the batch in flight is finished
+	if counter.Load() != 5 {
+		t.Fatalf("expected the first batch of 5 events to be handled was %d", counter.Load())
+	}
+}
+
+func TestGracefulStopTimeout(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{}, 10)
+	proj := eventsourcing.NewProjectionWithContext(es.All(0, 10), func(ctx context.Context, event eventsourcing.Event) error {
+		started <- struct{}{}
+		// block until the projection is cancelled
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	group := eventsourcing.NewProjectionGroup(proj)
+	group.Start()
+
+	<-started
+	start := time.Now()
+	group.GracefulStop(time.Millisecond * 10)
+	if time.Since(start) > time.Second {
+		t.Fatal("expected the projection to be cancelled after the timeout")
+	}
+}
+
+func TestRaceAll(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err1 := errors.New("error 1")
+	err2 := errors.New("error 2")
+	counter := 0
+
+	r1 := eventsourcing.NewProjection(es.All(0, 1), func(e eventsourcing.Event) error {
+		if e.GlobalVersion() == 5 {
+			return err1
+		}
+		return nil
+	})
+	r1.Name = "r1"
+	r2 := eventsourcing.NewProjection(es.All(0, 1), func(e eventsourcing.Event) error {
+		counter++
+		return nil
+	})
+	r2.Name = "r2"
+	r3 := eventsourcing.NewProjection(es.All(0, 1), func(e eventsourcing.Event) error {
+		if e.GlobalVersion() == 10 {
+			return err2
+		}
+		return nil
+	})
+	r3.Name = "r3"
+
+	results, err := eventsourcing.ProjectionsRaceAll(r1, r2, r3)
+
+	var projectionsErr *eventsourcing.ProjectionsError
+	if !errors.As(err, &projectionsErr) {
+		t.Fatalf("expected ProjectionsError got %v", err)
+	}
+	if len(projectionsErr.Results) != 2 {
+		t.Fatalf("expected two failed projections was %d", len(projectionsErr.Results))
+	}
+	if !errors.Is(err, err1) || !errors.Is(err, err2) {
+		t.Fatalf("expected both projection errors in %v", err)
+	}
+	// the healthy projection is not cancelled
+	if results[1].Error != nil {
+		t.Fatalf("expected no error from r2 got %v", results[1].Error)
+	}
+	if counter != 21 {
+		t.Fatalf("expected r2 to handle all 21 events was %d", counter)
+	}
+}
+
+func TestProjectionPace(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	var fast, slow atomic.Int32
+	fastProj := eventsourcing.NewProjection(es.All(0, 1), func(event eventsourcing.Event) error {
+		fast.Add(1)
+		return nil
+	})
+	fastProj.Pace = time.Millisecond
+	slowProj := eventsourcing.NewProjection(es.All(0, 1), func(event eventsourcing.Event) error {
+		slow.Add(1)
+		return nil
+	})
+
+	group := eventsourcing.NewProjectionGroup(fastProj, slowProj)
+	group.Pace = time.Hour
+	group.Start()
+	defer group.Stop()
+
+	// make sure the projections have finished their first round
+	time.Sleep(time.Millisecond * 10)
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 50)
+
+	if fast.Load() != 1 {
+		t.Fatalf("expected the projection with its own pace to handle the event was %d", fast.Load())
+	}
+	if slow.Load() != 0 {
+		t.Fatalf("expected the projection using the group pace not to handle the event was %d", slow.Load())
+	}
+}
+
+func TestProjectionBatchSize(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 100)()
+	}
+	counter := 0
+	proj := eventsourcing.NewPositionProjection(fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+		counter++
+		return nil
+	})
+	proj.BatchSize = 2
+
+	_, result := proj.RunOnce()
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if counter != 2 {
+		t.Fatalf("expected 2 handled events was %d", counter)
+	}
+
+	result = proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if counter != 5 {
+		t.Fatalf("expected 5 handled events was %d", counter)
+	}
+}
+
+func TestRunToEndLastHandledEvent(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	// Born + 9 AgedOneYear
+	err := createPersonEvent(es, "kalle", 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callbackErr := errors.New("callback error")
+	proj := eventsourcing.NewProjection(es.All(0, 3), func(event eventsourcing.Event) error {
+		if event.GlobalVersion() == 8 {
+			return callbackErr
+		}
+		return nil
+	})
+
+	// runs over several fetches until the callback fails
+	result := proj.RunToEnd(context.Background())
+	if !errors.Is(result.Error, callbackErr) {
+		t.Fatalf("expected callback error was %v", result.Error)
+	}
+	if result.LastHandledEvent.GlobalVersion() != 7 {
+		t.Fatalf("expected last handled event to have global version 7 was %d", result.LastHandledEvent.GlobalVersion())
+	}
+
+	// continues after the failing batch and returns when the event stream is exhausted
+	result = proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if result.LastHandledEvent.GlobalVersion() != 10 {
+		t.Fatalf("expected last handled event to have global version 10 was %d", result.LastHandledEvent.GlobalVersion())
+	}
+}
+
+func TestGroupStartInterval(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var first, second atomic.Int32
+	p1 := eventsourcing.NewProjection(es.All(0, 1), func(event eventsourcing.Event) error {
+		first.Add(1)
+		return nil
+	})
+	p2 := eventsourcing.NewProjection(es.All(0, 1), func(event eventsourcing.Event) error {
+		second.Add(1)
+		return nil
+	})
+
+	group := eventsourcing.NewProjectionGroup(p1, p2)
+	group.StartInterval = time.Millisecond * 100
+	group.Start()
+	defer group.Stop()
+
+	time.Sleep(time.Millisecond * 20)
+	if first.Load() != 1 {
+		t.Fatalf("expected the first projection to be started was %d", first.Load())
+	}
+	if second.Load() != 0 {
+		t.Fatalf("expected the second projection not to be started was %d", second.Load())
+	}
+	time.Sleep(time.Millisecond * 120)
+	if second.Load() != 1 {
+		t.Fatalf("expected the second projection to be started was %d", second.Load())
+	}
+}
+
+func TestProjectionSlowCallback(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 100)()
+	}
+	proj := eventsourcing.NewPositionProjection(fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+		if event.Reason() == "Born" {
+			time.Sleep(20 * time.Millisecond)
+		}
+		return nil
+	})
+	proj.Name = "slow"
+	proj.SlowCallback = 10 * time.Millisecond
+	var slow []eventsourcing.Event
+	proj.OnSlowCallback = func(name string, event eventsourcing.Event, d time.Duration) {
+		if name != "slow" {
+			t.Errorf("expected projection name slow was %q", name)
+		}
+		if d <= 10*time.Millisecond {
+			t.Errorf("expected duration above 10ms was %s", d)
+		}
+		slow = append(slow, event)
+	}
+
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(slow) != 1 {
+		t.Fatalf("expected 1 slow callback was %d", len(slow))
+	}
+	if slow[0].Reason() != "Born" || slow[0].AggregateID() == "" {
+		t.Fatalf("unexpected slow event %s %s", slow[0].Reason(), slow[0].AggregateID())
+	}
+}
+
+func TestProjectionCaughtUp(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	err := createPersonEvent(es, "kalle", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 1)()
+	}
+	proj := eventsourcing.NewPositionProjection(fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+		return nil
+	})
+	proj.Head = es.GlobalVersion
+	var caughtUp []core.Version
+	proj.OnCaughtUp = func(ctx context.Context, position core.Version) {
+		caughtUp = append(caughtUp, position)
+	}
+
+	proj.RunOnce()
+	if proj.CaughtUp() {
+		t.Fatal("expected the projection to be behind the head")
+	}
+	// events saved after the start don't move the watermark
+	err = createPersonEvent(es, "anka", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proj.RunOnce()
+	proj.RunOnce()
+	if !proj.CaughtUp() {
+		t.Fatal("expected the projection to have caught up with the head as of its start")
+	}
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(caughtUp) != 1 || caughtUp[0] != 3 {
+		t.Fatalf("expected OnCaughtUp once at position 3 was %v", caughtUp)
+	}
+
+	// without head the projection is caught up at the end of the event stream
+	proj = eventsourcing.NewPositionProjection(fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+		return nil
+	})
+	result = proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if !proj.CaughtUp() {
+		t.Fatal("expected the projection to be caught up at the end of the event stream")
+	}
+}
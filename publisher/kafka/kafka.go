@@ -0,0 +1,84 @@
+// Package kafka publishes stored events to Kafka topics. It's independent of the Kafka client, the application
+// implements Producer with the client of its choice.
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Message is the record produced to Kafka
+type Message struct {
+	Topic   string
+	Key     []byte // the events with the same key end up in the same partition
+	Value   []byte
+	Headers map[string]string
+}
+
+// Producer writes messages to Kafka
+type Producer interface {
+	Produce(ctx context.Context, messages ...Message) error
+}
+
+// MapFunc maps a stored event to the message produced to Kafka
+type MapFunc func(event core.Event) (Message, error)
+
+// DefaultMap produces the event to the topic named as the aggregate type with the aggregate id as key, keeping the
+// events of an aggregate in order in one partition. The value is the serialized event data and the event properties
+// are set as headers.
+func DefaultMap(event core.Event) (Message, error) {
+	return Message{
+		Topic: event.AggregateType,
+		Key:   []byte(event.AggregateID),
+		Value: event.Data,
+		Headers: map[string]string{
+			"aggregate_type": event.AggregateType,
+			"aggregate_id":   event.AggregateID,
+			"reason":         event.Reason,
+			"version":        strconv.FormatUint(uint64(event.Version), 10),
+			"global_version": strconv.FormatUint(uint64(event.GlobalVersion), 10),
+			"schema_version": strconv.FormatUint(uint64(event.SchemaVersion), 10),
+			"timestamp":      event.Timestamp.UTC().Format(time.RFC3339Nano),
+		},
+	}, nil
+}
+
+// Publisher produces events to Kafka
+type Publisher struct {
+	producer Producer
+	mapF     MapFunc
+}
+
+// New creates a publisher producing the events mapped by mapF, DefaultMap is used if mapF is nil
+func New(producer Producer, mapF MapFunc) *Publisher {
+	if mapF == nil {
+		mapF = DefaultMap
+	}
+	return &Publisher{producer: producer, mapF: mapF}
+}
+
+// Publish maps the events and produces the messages in one call to the producer
+func (p *Publisher) Publish(ctx context.Context, events ...core.Event) error {
+	messages := make([]Message, 0, len(events))
+	for _, event := range events {
+		m, err := p.mapF(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, m)
+	}
+	return p.producer.Produce(ctx, messages...)
+}
+
+// Handle produces the event. It's the callback of a projection tailing the global event feed and the sink of an
+// outbox relay.
+//
+//	p := eventsourcing.NewCheckpointProjection("kafka", 1, cs, fetchF, publisher.Handle)
+//	relay := eventsourcing.NewOutboxRelay(es, publisher.Handle)
+func (p *Publisher) Handle(ctx context.Context, event eventsourcing.Event) error {
+	return p.Publish(ctx, event.CoreEvent())
+}
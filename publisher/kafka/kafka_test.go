@@ -0,0 +1,60 @@
+package kafka_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/publisher/kafka"
+)
+
+type producer struct {
+	messages []kafka.Message
+}
+
+func (p *producer) Produce(ctx context.Context, messages ...kafka.Message) error {
+	p.messages = append(p.messages, messages...)
+	return nil
+}
+
+func TestPublish(t *testing.T) {
+	p := &producer{}
+	publisher := kafka.New(p, nil)
+	err := publisher.Publish(context.Background(),
+		core.Event{AggregateID: "123", AggregateType: "Person", Version: 1, GlobalVersion: 7, Reason: "Born", Timestamp: time.Now(), Data: []byte(`{"Name":"kalle"}`)},
+		core.Event{AggregateID: "123", AggregateType: "Person", Version: 2, GlobalVersion: 8, Reason: "AgedOneYear", Timestamp: time.Now(), Data: []byte(`{}`)},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.messages) != 2 {
+		t.Fatalf("expected 2 messages was %d", len(p.messages))
+	}
+	m := p.messages[0]
+	if m.Topic != "Person" || string(m.Key) != "123" || string(m.Value) != `{"Name":"kalle"}` {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	if m.Headers["reason"] != "Born" || m.Headers["global_version"] != "7" {
+		t.Fatalf("unexpected headers %v", m.Headers)
+	}
+}
+
+func TestPublishMapError(t *testing.T) {
+	p := &producer{}
+	errMap := errors.New("unmapped event")
+	publisher := kafka.New(p, func(event core.Event) (kafka.Message, error) {
+		if event.Reason == "Secret" {
+			return kafka.Message{}, errMap
+		}
+		return kafka.DefaultMap(event)
+	})
+	err := publisher.Publish(context.Background(), core.Event{AggregateID: "123", AggregateType: "Person", Reason: "Secret"})
+	if !errors.Is(err, errMap) {
+		t.Fatalf("expected the map error was %v", err)
+	}
+	if len(p.messages) != 0 {
+		t.Fatal("expected no produced messages")
+	}
+}
@@ -0,0 +1,76 @@
+// Package nats publishes stored events to NATS JetStream subjects. It's independent of the NATS client, the
+// application implements JetStream with the client of its choice.
+package nats
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Msg is the message published to JetStream
+type Msg struct {
+	Subject string
+	Data    []byte
+	Headers map[string]string
+	ID      string // the message id JetStream deduplicates on, publish it as the Nats-Msg-Id header
+}
+
+// JetStream publishes messages to a stream and returns when the stream has acknowledged the message
+type JetStream interface {
+	PublishMsg(ctx context.Context, msg Msg) error
+}
+
+// Publisher forwards stored events to JetStream subjects named <prefix>.<aggregateType>.<reason>
+type Publisher struct {
+	js     JetStream
+	prefix string
+}
+
+// New creates a publisher with the subject prefix "es"
+func New(js JetStream) *Publisher {
+	return NewWithPrefix(js, "es")
+}
+
+// NewWithPrefix creates a publisher with a custom subject prefix
+func NewWithPrefix(js JetStream, prefix string) *Publisher {
+	return &Publisher{js: js, prefix: prefix}
+}
+
+// Publish publishes the events in order. The global version of the event is the message id, JetStream drops
+// messages with an id already published within its duplicate window, making a republish after a crash harmless.
+func (p *Publisher) Publish(ctx context.Context, events ...core.Event) error {
+	for _, event := range events {
+		err := p.js.PublishMsg(ctx, p.msg(event))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handle publishes the event. It's the callback of a projection tailing the global event feed and the sink of an
+// outbox relay.
+func (p *Publisher) Handle(ctx context.Context, event eventsourcing.Event) error {
+	return p.Publish(ctx, event.CoreEvent())
+}
+
+// msg maps the stored event to the JetStream message
+func (p *Publisher) msg(event core.Event) Msg {
+	return Msg{
+		Subject: p.prefix + "." + event.AggregateType + "." + event.Reason,
+		Data:    event.Data,
+		Headers: map[string]string{
+			"aggregate_type": event.AggregateType,
+			"aggregate_id":   event.AggregateID,
+			"reason":         event.Reason,
+			"version":        strconv.FormatUint(uint64(event.Version), 10),
+			"schema_version": strconv.FormatUint(uint64(event.SchemaVersion), 10),
+			"timestamp":      event.Timestamp.UTC().Format(time.RFC3339Nano),
+		},
+		ID: strconv.FormatUint(uint64(event.GlobalVersion), 10),
+	}
+}
@@ -0,0 +1,72 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/publisher/nats"
+)
+
+// jetStream deduplicates the messages on the message id like JetStream does
+type jetStream struct {
+	ids  map[string]struct{}
+	msgs []nats.Msg
+	fail bool
+}
+
+func (js *jetStream) PublishMsg(ctx context.Context, msg nats.Msg) error {
+	if js.fail {
+		return errors.New("no responders")
+	}
+	if _, ok := js.ids[msg.ID]; ok {
+		return nil
+	}
+	js.ids[msg.ID] = struct{}{}
+	js.msgs = append(js.msgs, msg)
+	return nil
+}
+
+func TestPublish(t *testing.T) {
+	js := &jetStream{ids: make(map[string]struct{})}
+	publisher := nats.New(js)
+	events := []core.Event{
+		{AggregateID: "123", AggregateType: "Person", Version: 1, GlobalVersion: 7, Reason: "Born", Timestamp: time.Now(), Data: []byte(`{"Name":"kalle"}`)},
+		{AggregateID: "123", AggregateType: "Person", Version: 2, GlobalVersion: 8, Reason: "AgedOneYear", Timestamp: time.Now(), Data: []byte(`{}`)},
+	}
+	err := publisher.Publish(context.Background(), events...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// republishing the events after a crash does not duplicate them
+	err = publisher.Publish(context.Background(), events...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(js.msgs) != 2 {
+		t.Fatalf("expected 2 messages was %d", len(js.msgs))
+	}
+	m := js.msgs[0]
+	if m.Subject != "es.Person.Born" || m.ID != "7" || string(m.Data) != `{"Name":"kalle"}` || m.Headers["aggregate_id"] != "123" {
+		t.Fatalf("unexpected message %+v", m)
+	}
+}
+
+func TestPublishWithPrefix(t *testing.T) {
+	js := &jetStream{ids: make(map[string]struct{})}
+	err := nats.NewWithPrefix(js, "events").Publish(context.Background(), core.Event{AggregateType: "Person", Reason: "Born", GlobalVersion: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if js.msgs[0].Subject != "events.Person.Born" {
+		t.Fatalf("unexpected subject %s", js.msgs[0].Subject)
+	}
+
+	js.fail = true
+	err = nats.New(js).Publish(context.Background(), core.Event{AggregateType: "Person", Reason: "Born", GlobalVersion: 2})
+	if err == nil {
+		t.Fatal("expected publish error")
+	}
+}
@@ -0,0 +1,95 @@
+// Package sns publishes stored events to an AWS SNS topic, fanning them out to the SQS queues subscribed to it. It's
+// independent of the AWS SDK, the application implements Client with the SDK of its choice.
+package sns
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Message is the message published to the topic
+type Message struct {
+	TopicArn   string
+	Message    string
+	Attributes map[string]string // String message attributes, publish them with the data type "String"
+	GroupID    string            // the message group id of FIFO topics, empty for standard topics
+	DedupID    string            // the message deduplication id of FIFO topics, empty for standard topics
+}
+
+// Client publishes a message to SNS and returns when SNS has accepted the message
+type Client interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// Publisher forwards stored events to an SNS topic. The aggregate type and reason are message attributes, letting the
+// SQS subscriptions of the topic select events with a filter policy like
+//
+//	{"aggregate_type": ["Person"], "reason": ["Born"]}
+//
+// Topics with a name ending in ".fifo" are FIFO topics. Their messages are grouped by aggregate, keeping the events of
+// an aggregate in order, and deduplicated on the global version of the event.
+type Publisher struct {
+	client   Client
+	topicArn string
+	fifo     bool
+}
+
+// New creates a publisher to the topic
+func New(client Client, topicArn string) *Publisher {
+	return &Publisher{
+		client:   client,
+		topicArn: topicArn,
+		fifo:     strings.HasSuffix(topicArn, ".fifo"),
+	}
+}
+
+// Publish publishes the events in order
+func (p *Publisher) Publish(ctx context.Context, events ...core.Event) error {
+	for _, event := range events {
+		err := p.client.Publish(ctx, p.message(event))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handle publishes the event. It's the callback of a projection tailing the global event feed and the sink of an
+// outbox relay.
+func (p *Publisher) Handle(ctx context.Context, event eventsourcing.Event) error {
+	return p.Publish(ctx, event.CoreEvent())
+}
+
+// message maps the stored event to the SNS message
+func (p *Publisher) message(event core.Event) Message {
+	m := Message{
+		TopicArn: p.topicArn,
+		Message:  string(event.Data),
+		Attributes: map[string]string{
+			"aggregate_type": event.AggregateType,
+			"aggregate_id":   event.AggregateID,
+			"reason":         event.Reason,
+			"version":        strconv.FormatUint(uint64(event.Version), 10),
+			"global_version": strconv.FormatUint(uint64(event.GlobalVersion), 10),
+			"schema_version": strconv.FormatUint(uint64(event.SchemaVersion), 10),
+			"timestamp":      event.Timestamp.UTC().Format(time.RFC3339Nano),
+		},
+	}
+	// the SNS message is a string, binary event data from e.g. the msgpack encoder is sent base64 encoded
+	if !utf8.Valid(event.Data) {
+		m.Message = base64.StdEncoding.EncodeToString(event.Data)
+		m.Attributes["content_encoding"] = "base64"
+	}
+	if p.fifo {
+		m.GroupID = event.AggregateType + "_" + event.AggregateID
+		m.DedupID = strconv.FormatUint(uint64(event.GlobalVersion), 10)
+	}
+	return m
+}
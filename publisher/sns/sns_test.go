@@ -0,0 +1,80 @@
+package sns_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/publisher/sns"
+)
+
+type client struct {
+	msgs []sns.Message
+	fail bool
+}
+
+func (c *client) Publish(ctx context.Context, msg sns.Message) error {
+	if c.fail {
+		return errors.New("throttled")
+	}
+	c.msgs = append(c.msgs, msg)
+	return nil
+}
+
+var events = []core.Event{
+	{AggregateID: "123", AggregateType: "Person", Version: 1, GlobalVersion: 7, Reason: "Born", Timestamp: time.Now(), Data: []byte(`{"Name":"kalle"}`)},
+	{AggregateID: "123", AggregateType: "Person", Version: 2, GlobalVersion: 8, Reason: "AgedOneYear", Timestamp: time.Now(), Data: []byte{0x80}},
+}
+
+func TestPublish(t *testing.T) {
+	c := &client{}
+	err := sns.New(c, "arn:aws:sns:eu-north-1:123456789012:events").Publish(context.Background(), events...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.msgs) != 2 {
+		t.Fatalf("expected 2 messages was %d", len(c.msgs))
+	}
+	m := c.msgs[0]
+	if m.TopicArn != "arn:aws:sns:eu-north-1:123456789012:events" || m.Message != `{"Name":"kalle"}` {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	if m.Attributes["aggregate_type"] != "Person" || m.Attributes["reason"] != "Born" || m.Attributes["global_version"] != "7" {
+		t.Fatalf("unexpected attributes %v", m.Attributes)
+	}
+	if m.GroupID != "" || m.DedupID != "" {
+		t.Fatalf("expected no FIFO properties on a standard topic %+v", m)
+	}
+	// binary data is base64 encoded
+	m = c.msgs[1]
+	if m.Message != base64.StdEncoding.EncodeToString([]byte{0x80}) || m.Attributes["content_encoding"] != "base64" {
+		t.Fatalf("unexpected binary message %+v", m)
+	}
+}
+
+func TestPublishFIFO(t *testing.T) {
+	c := &client{}
+	err := sns.New(c, "arn:aws:sns:eu-north-1:123456789012:events.fifo").Publish(context.Background(), events...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range c.msgs {
+		if m.GroupID != "Person_123" {
+			t.Fatalf("expected the events grouped by aggregate was %s", m.GroupID)
+		}
+		if m.DedupID != m.Attributes["global_version"] || m.DedupID != []string{"7", "8"}[i] {
+			t.Fatalf("expected deduplication on the global version was %s", m.DedupID)
+		}
+	}
+}
+
+func TestPublishError(t *testing.T) {
+	c := &client{fail: true}
+	err := sns.New(c, "events").Publish(context.Background(), events...)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// key is a struct to not mix up a scope and value pair with another pair joined to the same string
+type key struct {
+	scope string
+	value string
+}
+
+type Memory struct {
+	reservations map[key]core.Reservation
+	lock         sync.Mutex
+}
+
+// Create in memory reservation store
+func Create() *Memory {
+	return &Memory{
+		reservations: make(map[key]core.Reservation),
+	}
+}
+
+func (m *Memory) Close() {
+
+}
+
+func (m *Memory) Reserve(ctx context.Context, scope, value, aggregateID string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	r, ok := m.reservations[key{scope, value}]
+	if ok && r.AggregateID != aggregateID {
+		return core.ErrReserved
+	}
+	m.reservations[key{scope, value}] = core.Reservation{Scope: scope, Value: value, AggregateID: aggregateID}
+	return nil
+}
+
+func (m *Memory) Release(ctx context.Context, scope, value, aggregateID string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	r, ok := m.reservations[key{scope, value}]
+	if ok && r.AggregateID == aggregateID {
+		delete(m.reservations, key{scope, value})
+	}
+	return nil
+}
+
+func (m *Memory) ReleaseAggregate(ctx context.Context, aggregateID string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for k, r := range m.reservations {
+		if r.AggregateID == aggregateID {
+			delete(m.reservations, k)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) Get(ctx context.Context, scope, value string) (core.Reservation, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	r, ok := m.reservations[key{scope, value}]
+	if !ok {
+		return core.Reservation{}, core.ErrReservationNotFound
+	}
+	return r, nil
+}
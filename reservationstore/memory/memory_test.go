@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/reservationstore/memory"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.ReservationStore, func(), error) {
+		rs := memory.Create()
+		return rs, func() { rs.Close() }, nil
+	}
+	testsuite.TestReservationStore(t, f)
+}
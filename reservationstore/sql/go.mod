@@ -0,0 +1,10 @@
+module github.com/hallgren/eventsourcing/reservationstore/sql
+
+go 1.13
+
+require (
+	github.com/hallgren/eventsourcing/core v0.5.0
+	github.com/mattn/go-sqlite3 v1.14.27
+)
+
+// replace github.com/hallgren/eventsourcing/core => ../../core
@@ -0,0 +1,36 @@
+package sql
+
+import "context"
+
+// Migrate the database
+func (s *SQL) Migrate() error {
+	sqlStmt := []string{
+		`create table reservations (scope VARCHAR NOT NULL, value VARCHAR NOT NULL, aggregate_id VARCHAR NOT NULL);`,
+		`create unique index scope_value on reservations (scope, value);`,
+		`create index reservation_aggregate_id on reservations (aggregate_id);`,
+	}
+	return s.migrate(sqlStmt)
+}
+
+func (s *SQL) migrate(stm []string) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// check if the migration is already done
+	rows, err := tx.Query(`Select count(*) from reservations`)
+	if err == nil {
+		rows.Close()
+		return nil
+	}
+
+	for _, b := range stm {
+		_, err := tx.Exec(b)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
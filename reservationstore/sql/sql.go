@@ -0,0 +1,71 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// SQL is a reservation store where the unique index on scope and value makes only one aggregate hold a value
+type SQL struct {
+	db *sql.DB
+}
+
+// Open connection to database
+func Open(db *sql.DB) *SQL {
+	return &SQL{
+		db: db,
+	}
+}
+
+// Close the connection
+func (s *SQL) Close() {
+	s.db.Close()
+}
+
+// Reserve inserts the reservation, the unique index rejects the insert if the value is already reserved
+func (s *SQL) Reserve(ctx context.Context, scope, value, aggregateID string) error {
+	statement := `INSERT INTO reservations (scope, value, aggregate_id) VALUES ($1, $2, $3)`
+	_, err := s.db.ExecContext(ctx, statement, scope, value, aggregateID)
+	if err == nil {
+		return nil
+	}
+	// the insert error is driver specific, read the reservation to find out if the value is taken
+	r, getErr := s.Get(ctx, scope, value)
+	if getErr != nil {
+		return err
+	}
+	if r.AggregateID != aggregateID {
+		return core.ErrReserved
+	}
+	return nil
+}
+
+// Release deletes the reservation if it's held by the aggregate
+func (s *SQL) Release(ctx context.Context, scope, value, aggregateID string) error {
+	statement := `DELETE FROM reservations WHERE scope=$1 AND value=$2 AND aggregate_id=$3`
+	_, err := s.db.ExecContext(ctx, statement, scope, value, aggregateID)
+	return err
+}
+
+// ReleaseAggregate deletes all reservations held by the aggregate
+func (s *SQL) ReleaseAggregate(ctx context.Context, aggregateID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM reservations WHERE aggregate_id=$1`, aggregateID)
+	return err
+}
+
+// Get returns the reservation of the value in the scope
+func (s *SQL) Get(ctx context.Context, scope, value string) (core.Reservation, error) {
+	r := core.Reservation{Scope: scope, Value: value}
+	statement := `SELECT aggregate_id FROM reservations WHERE scope=$1 AND value=$2`
+	err := s.db.QueryRowContext(ctx, statement, scope, value).Scan(&r.AggregateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Reservation{}, core.ErrReservationNotFound
+	}
+	if err != nil {
+		return core.Reservation{}, err
+	}
+	return r, nil
+}
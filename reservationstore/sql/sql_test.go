@@ -0,0 +1,53 @@
+package sql_test
+
+import (
+	sqldriver "database/sql"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/reservationstore/sql"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.ReservationStore, func(), error) {
+		return reservationstore()
+	}
+	testsuite.TestReservationStore(t, f)
+}
+
+func TestMultipleMigrate(t *testing.T) {
+	rs, close, err := reservationstore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer close()
+	err = rs.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func reservationstore() (*sql.SQL, func(), error) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+	err = db.Ping()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store := sql.Open(db)
+	err = store.Migrate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return store, func() {
+		store.Close()
+	}, nil
+}
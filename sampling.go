@@ -0,0 +1,157 @@
+package eventsourcing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Buffer is implemented by callbacks holding events before they are handled, e.g. the WindowSampler. A projection
+// with a buffer keeps its checkpoint before the oldest held event, making the held events replayed after a restart,
+// and flushes the buffer when it's stopped.
+type Buffer interface {
+	// Held returns the global version of the oldest held event, false if no events are held
+	Held() (core.Version, bool)
+	// Flush handles the held events
+	Flush(ctx context.Context) error
+}
+
+// SampleOneIn returns a callback that passes one in n events of the reasons to callbackF, starting with the first one.
+// Events of other reasons are always passed to callbackF. If no reasons are given all events are sampled.
+func SampleOneIn(n int, callbackF callbackFunc, reasons ...string) callbackFunc {
+	callbackContextF := SampleOneInContext(n, func(_ context.Context, e Event) error {
+		return callbackF(e)
+	}, reasons...)
+	return func(e Event) error {
+		return callbackContextF(context.Background(), e)
+	}
+}
+
+// SampleOneInContext is SampleOneIn for callbacks receiving the context of the projection. It's safe for concurrent
+// use by projection workers.
+func SampleOneInContext(n int, callbackF callbackContextFunc, reasons ...string) callbackContextFunc {
+	var lock sync.Mutex
+	counters := make(map[string]int)
+	return func(ctx context.Context, e Event) error {
+		if !sampled(e, reasons) {
+			return callbackF(ctx, e)
+		}
+		lock.Lock()
+		counter := counters[e.Reason()]
+		counters[e.Reason()] = counter + 1
+		lock.Unlock()
+		if n > 1 && counter%n != 0 {
+			return nil
+		}
+		return callbackF(ctx, e)
+	}
+}
+
+// SampleWindow returns a callback that groups events of the reasons into windows of the size based on the event
+// timestamp. The events in a window are passed to windowF when an event of the same reason falls outside the window,
+// meaning that the last window is kept open until a later event arrives. Events of other reasons are passed to
+// callbackF. If no reasons are given all events are sampled. The open windows are lost when the projection stops, use
+// a WindowSampler set as the projection Buffer to keep them.
+func SampleWindow(size time.Duration, windowF func(events []Event) error, callbackF callbackFunc, reasons ...string) callbackFunc {
+	s := NewWindowSampler(size, func(_ context.Context, events []Event) error {
+		return windowF(events)
+	}, func(_ context.Context, e Event) error {
+		return callbackF(e)
+	}, reasons...)
+	return func(e Event) error {
+		return s.Callback(context.Background(), e)
+	}
+}
+
+// SampleWindowContext is SampleWindow for callbacks receiving the context of the projection
+func SampleWindowContext(size time.Duration, windowF func(ctx context.Context, events []Event) error, callbackF callbackContextFunc, reasons ...string) callbackContextFunc {
+	return NewWindowSampler(size, windowF, callbackF, reasons...).Callback
+}
+
+// WindowSampler groups events of the reasons into windows of the size based on the event timestamp, see SampleWindow.
+// It's a Buffer, set as the projection Buffer the events in the open windows are replayed after a restart and the
+// open windows are passed to windowF when the projection is stopped. It's safe for concurrent use by projection
+// workers.
+type WindowSampler struct {
+	size      time.Duration
+	windowF   func(ctx context.Context, events []Event) error
+	callbackF callbackContextFunc
+	reasons   []string
+	lock      sync.Mutex
+	windows   map[string][]Event // open windows by reason
+}
+
+// NewWindowSampler creates a window sampler, pass its Callback method as the projection callback
+func NewWindowSampler(size time.Duration, windowF func(ctx context.Context, events []Event) error, callbackF callbackContextFunc, reasons ...string) *WindowSampler {
+	return &WindowSampler{
+		size:      size,
+		windowF:   windowF,
+		callbackF: callbackF,
+		reasons:   reasons,
+		windows:   make(map[string][]Event),
+	}
+}
+
+// Callback adds the event to the window of its reason, or passes it to callbackF if the reason is not sampled
+func (s *WindowSampler) Callback(ctx context.Context, e Event) error {
+	if !sampled(e, s.reasons) {
+		return s.callbackF(ctx, e)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	window := s.windows[e.Reason()]
+	if len(window) > 0 && !window[0].Timestamp().Truncate(s.size).Equal(e.Timestamp().Truncate(s.size)) {
+		err := s.windowF(ctx, window)
+		if err != nil {
+			return err
+		}
+		window = nil
+	}
+	s.windows[e.Reason()] = append(window, e)
+	return nil
+}
+
+// Held returns the global version of the oldest event in the open windows
+func (s *WindowSampler) Held() (core.Version, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var oldest core.Version
+	for _, window := range s.windows {
+		if len(window) > 0 && (oldest == 0 || core.Version(window[0].GlobalVersion()) < oldest) {
+			oldest = core.Version(window[0].GlobalVersion())
+		}
+	}
+	return oldest, oldest != 0
+}
+
+// Flush passes the open windows to windowF
+func (s *WindowSampler) Flush(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for reason, window := range s.windows {
+		if len(window) == 0 {
+			continue
+		}
+		err := s.windowF(ctx, window)
+		if err != nil {
+			return err
+		}
+		delete(s.windows, reason)
+	}
+	return nil
+}
+
+// sampled returns true if the event reason is part of the reasons or if there are no reasons
+func sampled(e Event, reasons []string) bool {
+	if len(reasons) == 0 {
+		return true
+	}
+	for _, reason := range reasons {
+		if e.Reason() == reason {
+			return true
+		}
+	}
+	return false
+}
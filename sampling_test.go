@@ -0,0 +1,163 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	cs "github.com/hallgren/eventsourcing/checkpointstore/memory"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestSampleOneIn(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	// Born + 10 AgedOneYear
+	err := createPersonEvent(es, "kalle", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	born := 0
+	aged := 0
+	proj := eventsourcing.NewProjection(es.All(0, 5), eventsourcing.SampleOneIn(3, func(event eventsourcing.Event) error {
+		switch event.Data().(type) {
+		case *Born:
+			born++
+		case *AgedOneYear:
+			aged++
+		}
+		return nil
+	}, "AgedOneYear"))
+
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if born != 1 {
+		t.Fatalf("expected the none sampled Born event to be passed was %d", born)
+	}
+	// AgedOneYear event 1, 4, 7 and 10
+	if aged != 4 {
+		t.Fatalf("expected 4 sampled AgedOneYear events was %d", aged)
+	}
+}
+
+func TestSampleWindow(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	events := []core.Event{
+		{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: start, Data: []byte(`{"Name":"kalle"}`)},
+	}
+	// two events in the first minute and three in the second
+	for i, offset := range []time.Duration{10, 20, 60, 70, 80} {
+		events = append(events, core.Event{AggregateID: "123", AggregateType: "Person", Version: core.Version(i + 2), Reason: "AgedOneYear", Timestamp: start.Add(offset * time.Second), Data: []byte(`{}`)})
+	}
+	err := es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	windows := [][]eventsourcing.Event{}
+	passed := 0
+	windowF := func(events []eventsourcing.Event) error {
+		windows = append(windows, events)
+		return nil
+	}
+	proj := eventsourcing.NewProjection(es.All(0, 10), eventsourcing.SampleWindow(time.Minute, windowF, func(event eventsourcing.Event) error {
+		passed++
+		return nil
+	}, "AgedOneYear"))
+
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if passed != 1 {
+		t.Fatalf("expected the Born event to be passed was %d", passed)
+	}
+	// the second window is kept open until a later event arrives
+	if len(windows) != 1 {
+		t.Fatalf("expected one closed window was %d", len(windows))
+	}
+	if len(windows[0]) != 2 {
+		t.Fatalf("expected two events in the first window was %d", len(windows[0]))
+	}
+}
+
+func TestWindowSamplerBuffer(t *testing.T) {
+	es := memory.Create()
+	checkpoints := cs.Create()
+	aggregate.Register(&Person{})
+
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	events := []core.Event{
+		{AggregateID: "123", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: start, Data: []byte(`{"Name":"kalle"}`)},
+	}
+	// two events in the first minute and three in the second
+	for i, offset := range []time.Duration{10, 20, 60, 70, 80} {
+		events = append(events, core.Event{AggregateID: "123", AggregateType: "Person", Version: core.Version(i + 2), Reason: "AgedOneYear", Timestamp: start.Add(offset * time.Second), Data: []byte(`{}`)})
+	}
+	err := es.Save(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	windows := [][]eventsourcing.Event{}
+	windowF := func(ctx context.Context, events []eventsourcing.Event) error {
+		windows = append(windows, events)
+		return nil
+	}
+	fetchF := func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 10)()
+	}
+	callbackF := func(ctx context.Context, event eventsourcing.Event) error { return nil }
+
+	sampler := eventsourcing.NewWindowSampler(time.Minute, windowF, callbackF, "AgedOneYear")
+	proj := eventsourcing.NewCheckpointProjection("trend", 1, checkpoints, fetchF, sampler.Callback)
+	proj.Buffer = sampler
+	result := proj.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	// the checkpoint is kept before the open window
+	checkpoint, err := checkpoints.Get(context.Background(), "trend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.GlobalVersion != 3 {
+		t.Fatalf("expected the checkpoint before the open window at 3 was %d", checkpoint.GlobalVersion)
+	}
+
+	// a restarted projection replays the open window and flushes it when stopped
+	sampler = eventsourcing.NewWindowSampler(time.Minute, windowF, callbackF, "AgedOneYear")
+	proj = eventsourcing.NewCheckpointProjection("trend", 1, checkpoints, fetchF, sampler.Callback)
+	proj.Buffer = sampler
+	group := eventsourcing.NewProjectionGroup(proj)
+	group.Start()
+	for !proj.CaughtUp() {
+		time.Sleep(time.Millisecond)
+	}
+	group.GracefulStop(time.Second)
+
+	if len(windows) != 2 {
+		t.Fatalf("expected two windows was %d", len(windows))
+	}
+	if len(windows[1]) != 3 {
+		t.Fatalf("expected three events in the flushed window was %d", len(windows[1]))
+	}
+	checkpoint, err = checkpoints.Get(context.Background(), "trend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.GlobalVersion != 6 {
+		t.Fatalf("expected the checkpoint after the flushed window at 6 was %d", checkpoint.GlobalVersion)
+	}
+}
@@ -0,0 +1,123 @@
+// Package schema infers the schema of stored event payloads. It's meant as a help when writing upcasters for events
+// whose structs have changed over time, by showing every field ever seen in the payloads of a reason.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Field is a field seen in the payloads. Nested fields are separated with a dot and array elements are marked with [].
+type Field struct {
+	Path     string
+	Types    []string // the JSON types seen on the field: string, number, bool, object, array or null
+	Count    int      // the number of payloads containing the field
+	Nullable bool     // true if the field is null or missing in any payload
+}
+
+// Schema is the union of the fields seen in the payloads of a reason
+type Schema struct {
+	Reason   string
+	Payloads int // the number of payloads scanned
+	Fields   []Field
+}
+
+// String returns a line per field with its types and the share of payloads containing it
+func (s Schema) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%d payloads)\n", s.Reason, s.Payloads)
+	for _, f := range s.Fields {
+		nullable := ""
+		if f.Nullable {
+			nullable = " nullable"
+		}
+		fmt.Fprintf(&b, "  %s %s%s %d/%d\n", f.Path, strings.Join(f.Types, "|"), nullable, f.Count, s.Payloads)
+	}
+	return b.String()
+}
+
+// Infer scans the JSON payloads of the events with the reason and returns the union schema. The iterator is typically
+// fetched from an event store All method. Events of other reasons are skipped.
+func Infer(iterator core.Iterator, reason string) (Schema, error) {
+	defer iterator.Close()
+
+	s := Schema{Reason: reason}
+	types := make(map[string]map[string]bool)
+	counts := make(map[string]int)
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if err != nil {
+			return Schema{}, err
+		}
+		if event.Reason != reason {
+			continue
+		}
+		var payload interface{}
+		err = json.Unmarshal(event.Data, &payload)
+		if err != nil {
+			return Schema{}, fmt.Errorf("could not decode payload of event with global version %d: %w", event.GlobalVersion, err)
+		}
+		s.Payloads++
+
+		seen := make(map[string]bool)
+		walk("", payload, func(path, typ string) {
+			if types[path] == nil {
+				types[path] = make(map[string]bool)
+			}
+			types[path][typ] = true
+			if !seen[path] {
+				seen[path] = true
+				counts[path]++
+			}
+		})
+	}
+
+	for path, t := range types {
+		if path == "" {
+			// the root of the payload
+			continue
+		}
+		f := Field{Path: path, Count: counts[path], Nullable: counts[path] < s.Payloads || t["null"]}
+		for typ := range t {
+			f.Types = append(f.Types, typ)
+		}
+		sort.Strings(f.Types)
+		s.Fields = append(s.Fields, f)
+	}
+	sort.Slice(s.Fields, func(i, j int) bool {
+		return s.Fields[i].Path < s.Fields[j].Path
+	})
+	return s, nil
+}
+
+// walk calls f with the path and JSON type of the value and all its nested values
+func walk(path string, value interface{}, f func(path, typ string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		f(path, "object")
+		for key, nested := range v {
+			if path == "" {
+				walk(key, nested, f)
+			} else {
+				walk(path+"."+key, nested, f)
+			}
+		}
+	case []interface{}:
+		f(path, "array")
+		for _, nested := range v {
+			walk(path+"[]", nested, f)
+		}
+	case string:
+		f(path, "string")
+	case float64:
+		f(path, "number")
+	case bool:
+		f(path, "bool")
+	case nil:
+		f(path, "null")
+	}
+}
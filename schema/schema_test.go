@@ -0,0 +1,80 @@
+package schema_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	"github.com/hallgren/eventsourcing/schema"
+)
+
+func TestInfer(t *testing.T) {
+	es := memory.Create()
+	payloads := []string{
+		`{"name":"kalle","age":1}`,
+		`{"name":"anka","age":"2","tags":["a"]}`,
+		`{"name":null,"address":{"city":"Stockholm"}}`,
+	}
+	for i, payload := range payloads {
+		err := es.Save([]core.Event{{AggregateID: "id", AggregateType: "Person", Version: core.Version(i + 1), Reason: "Born", Timestamp: time.Now(), Data: []byte(payload)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := es.Save([]core.Event{{AggregateID: "id", AggregateType: "Person", Version: 4, Reason: "AgedOneYear", Timestamp: time.Now(), Data: []byte(`{"other":true}`)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	iterator, err := es.All(0, 10)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := schema.Infer(iterator, "Born")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Payloads != 3 {
+		t.Fatalf("expected 3 payloads was %d", s.Payloads)
+	}
+
+	expected := []schema.Field{
+		{Path: "address", Types: []string{"object"}, Count: 1, Nullable: true},
+		{Path: "address.city", Types: []string{"string"}, Count: 1, Nullable: true},
+		{Path: "age", Types: []string{"number", "string"}, Count: 2, Nullable: true},
+		{Path: "name", Types: []string{"null", "string"}, Count: 3, Nullable: true},
+		{Path: "tags", Types: []string{"array"}, Count: 1, Nullable: true},
+		{Path: "tags[]", Types: []string{"string"}, Count: 1, Nullable: true},
+	}
+	if len(s.Fields) != len(expected) {
+		t.Fatalf("expected %d fields was %d: %s", len(expected), len(s.Fields), s)
+	}
+	for i, f := range s.Fields {
+		e := expected[i]
+		if f.Path != e.Path || f.Count != e.Count || f.Nullable != e.Nullable || len(f.Types) != len(e.Types) {
+			t.Fatalf("expected field %v was %v", e, f)
+		}
+		for j := range f.Types {
+			if f.Types[j] != e.Types[j] {
+				t.Fatalf("expected field %v was %v", e, f)
+			}
+		}
+	}
+}
+
+func TestInferNotJSON(t *testing.T) {
+	es := memory.Create()
+	err := es.Save([]core.Event{{AggregateID: "id", AggregateType: "Person", Version: 1, Reason: "Born", Timestamp: time.Now(), Data: []byte{0x01}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	iterator, err := es.All(0, 10)()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = schema.Infer(iterator, "Born")
+	if err == nil {
+		t.Fatal("expected error on payload not in JSON")
+	}
+}
@@ -0,0 +1,93 @@
+package eventsourcing
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+)
+
+// shards distributes events to a pool of workers where the events of an aggregate are always handled by the same
+// worker. It gives parallelism across aggregates while keeping the order of the events within an aggregate.
+type shards struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	queues []chan Event
+	wg     sync.WaitGroup
+	lock   sync.Mutex
+	err    error // the first error returned from the callback
+}
+
+// startShards starts the workers, they run until wait is called or a callback returns an error
+func startShards(ctx context.Context, workers int, callbackF callbackContextFunc) *shards {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &shards{
+		ctx:    ctx,
+		cancel: cancel,
+		queues: make([]chan Event, workers),
+	}
+	s.wg.Add(workers)
+	for i := range s.queues {
+		s.queues[i] = make(chan Event)
+		go func(queue chan Event) {
+			defer s.wg.Done()
+			for event := range queue {
+				if s.ctx.Err() != nil {
+					// drain the queue after a failure
+					continue
+				}
+				err := callbackF(s.ctx, event)
+				if err != nil {
+					s.fail(err)
+				}
+			}
+		}(s.queues[i])
+	}
+	return s
+}
+
+// dispatch passes the event to the worker owning its aggregate. It blocks until the worker is ready to receive the
+// event and returns an error if any worker has failed.
+func (s *shards) dispatch(_ context.Context, event Event) error {
+	h := fnv.New32a()
+	h.Write([]byte(event.AggregateType()))
+	h.Write([]byte(event.AggregateID()))
+	queue := s.queues[h.Sum32()%uint32(len(s.queues))]
+
+	select {
+	case queue <- event:
+		return nil
+	case <-s.ctx.Done():
+		return s.error()
+	}
+}
+
+// wait blocks until the workers have handled the dispatched events and returns the first error from the callback
+func (s *shards) wait() error {
+	for _, queue := range s.queues {
+		close(queue)
+	}
+	s.wg.Wait()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cancel()
+	return s.err
+}
+
+func (s *shards) fail(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.err == nil {
+		s.err = err
+		s.cancel()
+	}
+}
+
+// error returns the first error from the callback or the context error if the context was cancelled
+func (s *shards) error() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	return s.ctx.Err()
+}
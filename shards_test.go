@@ -0,0 +1,131 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestWorkersKeepAggregateOrder(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	for i := 0; i < 10; i++ {
+		err := createPersonEvent(es, fmt.Sprintf("person%d", i), 9)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var lock sync.Mutex
+	versions := make(map[string][]eventsourcing.Version)
+	p := eventsourcing.NewPositionProjection(func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 7)()
+	}, func(ctx context.Context, e eventsourcing.Event) error {
+		lock.Lock()
+		defer lock.Unlock()
+		versions[e.AggregateID()] = append(versions[e.AggregateID()], e.Version())
+		return nil
+	})
+	p.Workers = 4
+
+	result := p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if result.LastHandledEvent.GlobalVersion() != 100 {
+		t.Fatalf("expected last handled event to have global version 100 was %d", result.LastHandledEvent.GlobalVersion())
+	}
+	if len(versions) != 10 {
+		t.Fatalf("expected events from 10 aggregates was %d", len(versions))
+	}
+	for id, v := range versions {
+		if len(v) != 10 {
+			t.Fatalf("expected 10 events for %s was %d", id, len(v))
+		}
+		for i := range v {
+			if v[i] != eventsourcing.Version(i+1) {
+				t.Fatalf("expected events of %s in order was %v", id, v)
+			}
+		}
+	}
+}
+
+func TestWorkersError(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	for i := 0; i < 5; i++ {
+		err := createPersonEvent(es, fmt.Sprintf("person%d", i), 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	callbackErr := errors.New("callback error")
+	p := eventsourcing.NewPositionProjection(func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 100)()
+	}, func(ctx context.Context, e eventsourcing.Event) error {
+		if e.GlobalVersion() == 13 {
+			return callbackErr
+		}
+		return nil
+	})
+	p.Workers = 3
+
+	result := p.RunToEnd(context.Background())
+	if !errors.Is(result.Error, callbackErr) {
+		t.Fatalf("expected callback error was %v", result.Error)
+	}
+}
+
+func TestWorkersReplayBatchAfterError(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	for i := 0; i < 5; i++ {
+		err := createPersonEvent(es, fmt.Sprintf("person%d", i), 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var lock sync.Mutex
+	handled := make(map[eventsourcing.Version]bool)
+	failed := false
+	p := eventsourcing.NewPositionProjection(func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 100)()
+	}, func(ctx context.Context, e eventsourcing.Event) error {
+		lock.Lock()
+		defer lock.Unlock()
+		if e.GlobalVersion() == 13 && !failed {
+			failed = true
+			return errors.New("callback error")
+		}
+		handled[e.GlobalVersion()] = true
+		return nil
+	})
+	p.Workers = 3
+
+	result := p.RunToEnd(context.Background())
+	if result.Error == nil {
+		t.Fatal("expected callback error")
+	}
+	result = p.RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(handled) != 25 {
+		t.Fatalf("expected all 25 events to be handled after the replay was %d", len(handled))
+	}
+}
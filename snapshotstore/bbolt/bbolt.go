@@ -0,0 +1,113 @@
+package bbolt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+	"go.etcd.io/bbolt"
+)
+
+const (
+	snapshotsBucketName = "snapshots"
+)
+
+// BBolt is the snapshot store handler
+type BBolt struct {
+	db *bbolt.DB // The bbolt db where we store everything
+}
+
+// MustOpenBBolt opens the snapshot store found in the given file. If the file is not found it will be created and
+// initialized. Will panic if it has problems persisting the changes to the filesystem.
+func MustOpenBBolt(dbFile string) *BBolt {
+	db, err := bbolt.Open(dbFile, 0600, &bbolt.Options{
+		Timeout: 1 * time.Second,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Ensure that we have a bucket to store the snapshots
+	err = db.Update(func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists([]byte(snapshotsBucketName)); err != nil {
+			return errors.New("could not create snapshots bucket")
+		}
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return &BBolt{
+		db: db,
+	}
+}
+
+// Save persists the snapshot, an existing snapshot of the aggregate is overwritten
+func (s *BBolt) Save(snapshot core.Snapshot) error {
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(snapshotsBucketName)).Put(key(snapshot.Type, snapshot.ID), value)
+	})
+}
+
+// Get returns the snapshot of the aggregate
+func (s *BBolt) Get(ctx context.Context, aggregateID, aggregateType string) (core.Snapshot, error) {
+	var snapshot core.Snapshot
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		value := tx.Bucket([]byte(snapshotsBucketName)).Get(key(aggregateType, aggregateID))
+		if value == nil {
+			return core.ErrSnapshotNotFound
+		}
+		return json.Unmarshal(value, &snapshot)
+	})
+	if err != nil {
+		return core.Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// Prune deletes the snapshots taken before t
+func (s *BBolt) Prune(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(snapshotsBucketName))
+		c := bucket.Cursor()
+		for k, v := c.First(); k != nil; {
+			var snapshot core.Snapshot
+			err := json.Unmarshal(v, &snapshot)
+			if err != nil {
+				return err
+			}
+			if !snapshot.Timestamp.Before(before) {
+				k, v = c.Next()
+				continue
+			}
+			err = c.Delete()
+			if err != nil {
+				return err
+			}
+			deleted++
+			// the cursor is moved to the next item by the delete
+			k, v = c.Seek(k)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// Close closes the underlying database
+func (s *BBolt) Close() error {
+	return s.db.Close()
+}
+
+func key(aggregateType, aggregateID string) []byte {
+	return []byte(aggregateType + "_" + aggregateID)
+}
@@ -0,0 +1,34 @@
+package bbolt_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/snapshotstore/bbolt"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.SnapshotStore, func(), error) {
+		dbFile := "bolt.db"
+		ss := bbolt.MustOpenBBolt(dbFile)
+		return ss, func() {
+			ss.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestSnapshotStore(t, f)
+}
+
+func TestPruner(t *testing.T) {
+	f := func() (core.SnapshotStore, core.SnapshotPruner, func(), error) {
+		dbFile := "prune.db"
+		ss := bbolt.MustOpenBBolt(dbFile)
+		return ss, ss, func() {
+			ss.Close()
+			os.Remove(dbFile)
+		}, nil
+	}
+	testsuite.TestSnapshotPruner(t, f)
+}
@@ -0,0 +1,12 @@
+module github.com/hallgren/eventsourcing/snapshotstore/bbolt
+
+go 1.23
+
+require (
+	github.com/hallgren/eventsourcing/core v0.5.0
+	go.etcd.io/bbolt v1.4.0
+)
+
+require golang.org/x/sys v0.29.0 // indirect
+
+// replace github.com/hallgren/eventsourcing/core => ../../core
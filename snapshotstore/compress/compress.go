@@ -0,0 +1,110 @@
+// Package compress wraps a snapshot store to compress the snapshot state before it's saved and decompress it when read.
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Compressor compresses and decompresses the snapshot state
+type Compressor interface {
+	Compress(data []byte) ([]byte, error)
+	// Decompress must return data untouched if it's not compressed by the compressor, making it possible to read
+	// snapshots saved before compression was enabled.
+	Decompress(data []byte) ([]byte, error)
+}
+
+// Store is a snapshot store that compresses the snapshot state
+type Store struct {
+	ss         core.SnapshotStore
+	compressor Compressor
+	// MinSize is the smallest state in bytes that is compressed, smaller states are saved as is
+	MinSize int
+}
+
+// Wrap returns a snapshot store compressing the snapshot state with compressor before it's saved in ss
+func Wrap(ss core.SnapshotStore, compressor Compressor) *Store {
+	return &Store{
+		ss:         ss,
+		compressor: compressor,
+	}
+}
+
+// Save compresses the snapshot state and saves the snapshot in the underlying store
+func (s *Store) Save(snapshot core.Snapshot) error {
+	if len(snapshot.State) >= s.MinSize {
+		state, err := s.compressor.Compress(snapshot.State)
+		if err != nil {
+			return err
+		}
+		snapshot.State = state
+	}
+	return s.ss.Save(snapshot)
+}
+
+// Get returns the snapshot from the underlying store with its state decompressed
+func (s *Store) Get(ctx context.Context, id, aggregateType string) (core.Snapshot, error) {
+	snapshot, err := s.ss.Get(ctx, id, aggregateType)
+	if err != nil {
+		return core.Snapshot{}, err
+	}
+	snapshot.State, err = s.compressor.Decompress(snapshot.State)
+	if err != nil {
+		return core.Snapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// Prune deletes the snapshots taken before t in the underlying store
+func (s *Store) Prune(ctx context.Context, before time.Time) (int, error) {
+	pruner, ok := s.ss.(core.SnapshotPruner)
+	if !ok {
+		return 0, core.ErrPruneNotSupported
+	}
+	return pruner.Prune(ctx, before)
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+type gzipCompressor struct {
+	level int
+}
+
+// Gzip compresses with gzip on the given level, use gzip.DefaultCompression if unsure
+func Gzip(level int) Compressor {
+	return gzipCompressor{level: level}
+}
+
+func (g gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, g.level)
+	if err != nil {
+		return nil, err
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (g gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		return data, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
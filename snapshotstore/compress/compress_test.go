@@ -0,0 +1,85 @@
+package compress_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/snapshotstore/compress"
+	"github.com/hallgren/eventsourcing/snapshotstore/memory"
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.SnapshotStore, func(), error) {
+		return compress.Wrap(memory.Create(), compress.Gzip(gzip.DefaultCompression)), func() {}, nil
+	}
+	testsuite.TestSnapshotStore(t, f)
+}
+
+func TestCompressedState(t *testing.T) {
+	ss := memory.Create()
+	store := compress.Wrap(ss, compress.Gzip(gzip.BestCompression))
+	state := bytes.Repeat([]byte(`{"name":"kalle"}`), 100)
+
+	err := store.Save(core.Snapshot{ID: "id", Type: "person", State: state})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ss.Get(context.Background(), "id", "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw.State) >= len(state) {
+		t.Fatalf("expected compressed state smaller than %d was %d", len(state), len(raw.State))
+	}
+	s, err := store.Get(context.Background(), "id", "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.State, state) {
+		t.Fatal("expected decompressed state to equal the saved state")
+	}
+}
+
+func TestUncompressedState(t *testing.T) {
+	ss := memory.Create()
+	store := compress.Wrap(ss, compress.Gzip(gzip.DefaultCompression))
+	store.MinSize = 100
+
+	// snapshot saved before compression was enabled
+	err := ss.Save(core.Snapshot{ID: "old", Type: "person", State: []byte(`{"name":"anka"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Save(core.Snapshot{ID: "small", Type: "person", State: []byte(`{"name":"kalle"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := ss.Get(context.Background(), "small", "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw.State) != `{"name":"kalle"}` {
+		t.Fatalf("expected state below MinSize to be saved as is was %q", raw.State)
+	}
+	s, err := store.Get(context.Background(), "old", "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(s.State) != `{"name":"anka"}` {
+		t.Fatalf("expected uncompressed state to be read as is was %q", s.State)
+	}
+}
+
+func TestPruner(t *testing.T) {
+	f := func() (core.SnapshotStore, core.SnapshotPruner, func(), error) {
+		ss := memory.Create()
+		store := compress.Wrap(ss, compress.Gzip(gzip.DefaultCompression))
+		return store, store, func() {}, nil
+	}
+	testsuite.TestSnapshotPruner(t, f)
+}
@@ -0,0 +1,5 @@
+module github.com/hallgren/eventsourcing/snapshotstore/compress/zstd
+
+go 1.21
+
+require github.com/klauspost/compress v1.17.11
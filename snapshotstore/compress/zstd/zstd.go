@@ -0,0 +1,50 @@
+// Package zstd is a zstd compressor to use with the compress snapshot store wrapper
+package zstd
+
+import (
+	"bytes"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var magic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// Zstd compresses and decompresses snapshot state with zstd
+type Zstd struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// New creates a zstd compressor on the given level
+func New(level zstd.EncoderLevel) (*Zstd, error) {
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level))
+	if err != nil {
+		return nil, err
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Zstd{
+		encoder: encoder,
+		decoder: decoder,
+	}, nil
+}
+
+// Compress compresses data
+func (z *Zstd) Compress(data []byte) ([]byte, error) {
+	return z.encoder.EncodeAll(data, nil), nil
+}
+
+// Decompress decompresses data, data not compressed by zstd is returned as is
+func (z *Zstd) Decompress(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, magic) {
+		return data, nil
+	}
+	return z.decoder.DecodeAll(data, nil)
+}
+
+// Close releases the resources held by the decoder
+func (z *Zstd) Close() {
+	z.decoder.Close()
+}
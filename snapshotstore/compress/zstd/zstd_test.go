@@ -0,0 +1,49 @@
+package zstd_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/snapshotstore/compress/zstd"
+	kzstd "github.com/klauspost/compress/zstd"
+)
+
+func TestCompress(t *testing.T) {
+	z, err := zstd.New(kzstd.SpeedDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer z.Close()
+
+	state := bytes.Repeat([]byte(`{"name":"kalle"}`), 100)
+	compressed, err := z.Compress(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compressed) >= len(state) {
+		t.Fatalf("expected compressed state smaller than %d was %d", len(state), len(compressed))
+	}
+	decompressed, err := z.Decompress(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decompressed, state) {
+		t.Fatal("expected decompressed state to equal the original state")
+	}
+}
+
+func TestDecompressUncompressed(t *testing.T) {
+	z, err := zstd.New(kzstd.SpeedDefault)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer z.Close()
+
+	state, err := z.Decompress([]byte(`{"name":"kalle"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(state) != `{"name":"kalle"}` {
+		t.Fatalf("expected uncompressed state to be returned as is was %q", state)
+	}
+}
@@ -0,0 +1,163 @@
+// Package encrypt wraps a snapshot store to encrypt the snapshot state at rest with AES-GCM.
+package encrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// ErrKeyNotFound returned from a key provider when the key is not found
+var ErrKeyNotFound = errors.New("encryption key not found")
+
+// magic prefix the encrypted state to separate it from snapshots saved before encryption was enabled
+var magic = []byte("esenc1")
+
+// KeyProvider supplies the keys. The key id is stored with the encrypted state making it possible to rotate the key
+// used for new snapshots while old snapshots still can be decrypted.
+type KeyProvider interface {
+	// CurrentKey returns the id and the key to encrypt new snapshots with
+	CurrentKey(ctx context.Context) (string, []byte, error)
+	// Key returns the key with the id
+	Key(ctx context.Context, id string) ([]byte, error)
+}
+
+// Store is a snapshot store that encrypts the snapshot state
+type Store struct {
+	ss   core.SnapshotStore
+	keys KeyProvider
+}
+
+// Wrap returns a snapshot store encrypting the snapshot state with keys from the key provider before it's saved in ss
+func Wrap(ss core.SnapshotStore, keys KeyProvider) *Store {
+	return &Store{
+		ss:   ss,
+		keys: keys,
+	}
+}
+
+// Save encrypts the snapshot state and saves the snapshot in the underlying store
+func (s *Store) Save(snapshot core.Snapshot) error {
+	id, key, err := s.keys.CurrentKey(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(id) > 255 {
+		return fmt.Errorf("key id %q is longer than 255 bytes", id)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return err
+	}
+
+	// magic | key id length | key id | nonce | cipher text
+	state := make([]byte, 0, len(magic)+1+len(id)+len(nonce)+len(snapshot.State)+gcm.Overhead())
+	state = append(state, magic...)
+	state = append(state, byte(len(id)))
+	state = append(state, id...)
+	state = append(state, nonce...)
+	snapshot.State = gcm.Seal(state, nonce, snapshot.State, additionalData(snapshot))
+	return s.ss.Save(snapshot)
+}
+
+// Get returns the snapshot from the underlying store with its state decrypted. Snapshots saved before encryption was
+// enabled are returned as is.
+func (s *Store) Get(ctx context.Context, id, aggregateType string) (core.Snapshot, error) {
+	snapshot, err := s.ss.Get(ctx, id, aggregateType)
+	if err != nil {
+		return core.Snapshot{}, err
+	}
+	if !bytes.HasPrefix(snapshot.State, magic) {
+		return snapshot, nil
+	}
+	data := snapshot.State[len(magic):]
+	if len(data) < 1 || len(data) < 1+int(data[0]) {
+		return core.Snapshot{}, errors.New("could not decrypt snapshot, malformed state")
+	}
+	idLen := int(data[0])
+	keyID := string(data[1 : 1+idLen])
+	data = data[1+idLen:]
+
+	key, err := s.keys.Key(ctx, keyID)
+	if err != nil {
+		return core.Snapshot{}, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return core.Snapshot{}, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return core.Snapshot{}, errors.New("could not decrypt snapshot, malformed state")
+	}
+	state, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], additionalData(snapshot))
+	if err != nil {
+		return core.Snapshot{}, fmt.Errorf("could not decrypt snapshot, %w", err)
+	}
+	snapshot.State = state
+	return snapshot, nil
+}
+
+// Prune deletes the snapshots taken before t in the underlying store
+func (s *Store) Prune(ctx context.Context, before time.Time) (int, error) {
+	pruner, ok := s.ss.(core.SnapshotPruner)
+	if !ok {
+		return 0, core.ErrPruneNotSupported
+	}
+	return pruner.Prune(ctx, before)
+}
+
+// additionalData binds the encrypted state to the aggregate it belongs to
+func additionalData(snapshot core.Snapshot) []byte {
+	return []byte(snapshot.Type + "_" + snapshot.ID)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// StaticKeys is a key provider holding the keys in memory
+type StaticKeys struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeys creates a key provider encrypting with the key named current. The keys must be 16, 24 or 32 bytes to
+// select AES-128, AES-192 or AES-256.
+func NewStaticKeys(current string, keys map[string][]byte) *StaticKeys {
+	return &StaticKeys{
+		current: current,
+		keys:    keys,
+	}
+}
+
+// CurrentKey returns the key used to encrypt new snapshots
+func (k *StaticKeys) CurrentKey(ctx context.Context) (string, []byte, error) {
+	key, err := k.Key(ctx, k.current)
+	return k.current, key, err
+}
+
+// Key returns the key with the id
+func (k *StaticKeys) Key(ctx context.Context, id string) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%s %w", id, ErrKeyNotFound)
+	}
+	return key, nil
+}
@@ -0,0 +1,124 @@
+package encrypt_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/core/testsuite"
+	"github.com/hallgren/eventsourcing/snapshotstore/encrypt"
+	"github.com/hallgren/eventsourcing/snapshotstore/memory"
+)
+
+var (
+	key1 = bytes.Repeat([]byte{1}, 32)
+	key2 = bytes.Repeat([]byte{2}, 32)
+)
+
+func TestSuite(t *testing.T) {
+	f := func() (core.SnapshotStore, func(), error) {
+		keys := encrypt.NewStaticKeys("1", map[string][]byte{"1": key1})
+		return encrypt.Wrap(memory.Create(), keys), func() {}, nil
+	}
+	testsuite.TestSnapshotStore(t, f)
+}
+
+func TestEncryptedState(t *testing.T) {
+	ss := memory.Create()
+	store := encrypt.Wrap(ss, encrypt.NewStaticKeys("1", map[string][]byte{"1": key1}))
+	state := []byte(`{"name":"kalle"}`)
+
+	err := store.Save(core.Snapshot{ID: "id", Type: "person", State: state})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ss.Get(context.Background(), "id", "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw.State, []byte("kalle")) {
+		t.Fatalf("expected encrypted state was %q", raw.State)
+	}
+	s, err := store.Get(context.Background(), "id", "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.State, state) {
+		t.Fatalf("expected decrypted state %q was %q", state, s.State)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	ss := memory.Create()
+	old := encrypt.Wrap(ss, encrypt.NewStaticKeys("1", map[string][]byte{"1": key1}))
+	err := old.Save(core.Snapshot{ID: "id", Type: "person", State: []byte("old")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := encrypt.Wrap(ss, encrypt.NewStaticKeys("2", map[string][]byte{"1": key1, "2": key2}))
+	s, err := store.Get(context.Background(), "id", "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(s.State) != "old" {
+		t.Fatalf("expected state encrypted with the old key to be decrypted was %q", s.State)
+	}
+
+	// the shredded key makes the snapshot unreadable
+	shredded := encrypt.Wrap(ss, encrypt.NewStaticKeys("2", map[string][]byte{"2": key2}))
+	_, err = shredded.Get(context.Background(), "id", "person")
+	if !errors.Is(err, encrypt.ErrKeyNotFound) {
+		t.Fatalf("expected key not found error was %v", err)
+	}
+}
+
+func TestTamperedState(t *testing.T) {
+	ss := memory.Create()
+	store := encrypt.Wrap(ss, encrypt.NewStaticKeys("1", map[string][]byte{"1": key1}))
+	err := store.Save(core.Snapshot{ID: "id", Type: "person", State: []byte("state")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ss.Get(context.Background(), "id", "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// move the encrypted state to another aggregate
+	raw.ID = "other"
+	err = ss.Save(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.Get(context.Background(), "other", "person")
+	if err == nil {
+		t.Fatal("expected error when decrypting state moved from another aggregate")
+	}
+}
+
+func TestUnencryptedState(t *testing.T) {
+	ss := memory.Create()
+	store := encrypt.Wrap(ss, encrypt.NewStaticKeys("1", map[string][]byte{"1": key1}))
+	err := ss.Save(core.Snapshot{ID: "id", Type: "person", State: []byte("plain")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := store.Get(context.Background(), "id", "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(s.State) != "plain" {
+		t.Fatalf("expected snapshot saved before encryption to be read as is was %q", s.State)
+	}
+}
+
+func TestPruner(t *testing.T) {
+	f := func() (core.SnapshotStore, core.SnapshotPruner, func(), error) {
+		ss := memory.Create()
+		store := encrypt.Wrap(ss, encrypt.NewStaticKeys("1", map[string][]byte{"1": key1}))
+		return store, store, func() {}, nil
+	}
+	testsuite.TestSnapshotPruner(t, f)
+}
@@ -2,6 +2,7 @@ package memory
 
 import (
 	"context"
+	"time"
 
 	"github.com/hallgren/eventsourcing/core"
 )
@@ -33,3 +34,15 @@ func (m *Memory) Save(snapshot core.Snapshot) error {
 	m.snapshots[snapshot.Type+"_"+snapshot.ID] = snapshot
 	return nil
 }
+
+// Prune deletes the snapshots taken before t
+func (m *Memory) Prune(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	for key, snapshot := range m.snapshots {
+		if snapshot.Timestamp.Before(before) {
+			delete(m.snapshots, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
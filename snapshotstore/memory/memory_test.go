@@ -15,3 +15,11 @@ func TestSuite(t *testing.T) {
 	}
 	testsuite.TestSnapshotStore(t, f)
 }
+
+func TestPruner(t *testing.T) {
+	f := func() (core.SnapshotStore, core.SnapshotPruner, func(), error) {
+		ss := memory.Create()
+		return ss, ss, func() { ss.Close() }, nil
+	}
+	testsuite.TestSnapshotPruner(t, f)
+}
@@ -3,7 +3,7 @@ module github.com/hallgren/eventsourcing/snapshotstore/sql
 go 1.13
 
 require (
-	github.com/hallgren/eventsourcing/core v0.4.0
+	github.com/hallgren/eventsourcing/core v0.5.0
 	github.com/mattn/go-sqlite3 v1.14.27
 )
 
@@ -2,7 +2,16 @@ package sql
 
 import "context"
 
-const createTable = `create table snapshots (id VARCHAR NOT NULL, type VARCHAR, version INTEGER, global_version INTEGER, state BLOB);`
+const createTable = `create table snapshots (id VARCHAR NOT NULL, type VARCHAR, version INTEGER, global_version INTEGER, state BLOB, schema_version INTEGER NOT NULL DEFAULT 0, timestamp VARCHAR NOT NULL DEFAULT '');`
+
+// columns added after the snapshots table was first released
+var addedColumns = []struct {
+	name string
+	stmt string
+}{
+	{"schema_version", `alter table snapshots add column schema_version INTEGER NOT NULL DEFAULT 0;`},
+	{"timestamp", `alter table snapshots add column timestamp VARCHAR NOT NULL DEFAULT '';`},
+}
 
 // Migrate the database
 func (s *SQL) Migrate() error {
@@ -10,7 +19,27 @@ func (s *SQL) Migrate() error {
 		createTable,
 		`create unique index id_type on snapshots (id, type);`,
 	}
-	return s.migrate(sqlStmt)
+	err := s.migrate(sqlStmt)
+	if err != nil {
+		return err
+	}
+	return s.migrateColumns()
+}
+
+// migrateColumns adds the columns missing in snapshot tables created by an earlier version
+func (s *SQL) migrateColumns() error {
+	for _, column := range addedColumns {
+		rows, err := s.db.Query(`Select ` + column.name + ` from snapshots limit 1`)
+		if err == nil {
+			rows.Close()
+			continue
+		}
+		_, err = s.db.Exec(column.stmt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *SQL) migrate(stm []string) error {
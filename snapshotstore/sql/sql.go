@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hallgren/eventsourcing/core"
 )
@@ -41,15 +42,15 @@ func (s *SQL) Save(snapshot core.Snapshot) error {
 	}
 	if err == sql.ErrNoRows {
 		// insert
-		statement = `INSERT INTO snapshots (state, id, type, version, global_version) VALUES ($1, $2, $3, $4, $5)`
-		_, err = tx.Exec(statement, string(snapshot.State), snapshot.ID, snapshot.Type, snapshot.Version, snapshot.GlobalVersion)
+		statement = `INSERT INTO snapshots (state, id, type, version, global_version, schema_version, timestamp) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+		_, err = tx.Exec(statement, string(snapshot.State), snapshot.ID, snapshot.Type, snapshot.Version, snapshot.GlobalVersion, snapshot.SchemaVersion, timestamp(snapshot.Timestamp))
 		if err != nil {
 			return err
 		}
 	} else {
 		// update
-		statement = `UPDATE snapshots set state=$1, version=$2, global_version=$3 where id=$4 AND type=$5`
-		_, err = tx.Exec(statement, string(snapshot.State), snapshot.Version, snapshot.GlobalVersion, snapshot.ID, snapshot.Type)
+		statement = `UPDATE snapshots set state=$1, version=$2, global_version=$3, schema_version=$4, timestamp=$5 where id=$6 AND type=$7`
+		_, err = tx.Exec(statement, string(snapshot.State), snapshot.Version, snapshot.GlobalVersion, snapshot.SchemaVersion, timestamp(snapshot.Timestamp), snapshot.ID, snapshot.Type)
 		if err != nil {
 			return err
 		}
@@ -62,18 +63,28 @@ func (s *SQL) Get(ctx context.Context, aggregateID, aggregateType string) (core.
 	var globalVersion core.Version
 	var version core.Version
 	var state []byte
+	var schemaVersion uint
+	var ts string
 
-	selectStm := `Select version, global_version, state from snapshots where id=? and type=?`
+	selectStm := `Select version, global_version, state, schema_version, timestamp from snapshots where id=? and type=?`
 	row := s.db.QueryRow(selectStm, aggregateID, aggregateType)
 	if row.Err() != nil {
 		return core.Snapshot{}, row.Err()
 	}
-	err := row.Scan(&version, &globalVersion, &state)
+	err := row.Scan(&version, &globalVersion, &state, &schemaVersion, &ts)
 	if err != nil && errors.Is(err, sql.ErrNoRows) {
 		return core.Snapshot{}, core.ErrSnapshotNotFound
 	} else if err != nil {
 		return core.Snapshot{}, err
 	}
+	// snapshots saved before the timestamp column was added have no timestamp
+	var t time.Time
+	if ts != "" {
+		t, err = time.Parse(time.RFC3339, ts)
+		if err != nil {
+			return core.Snapshot{}, err
+		}
+	}
 
 	return core.Snapshot{
 		ID:            aggregateID,
@@ -81,5 +92,25 @@ func (s *SQL) Get(ctx context.Context, aggregateID, aggregateType string) (core.
 		Version:       version,
 		GlobalVersion: globalVersion,
 		State:         state,
+		SchemaVersion: schemaVersion,
+		Timestamp:     t,
 	}, nil
 }
+
+// Prune deletes the snapshots taken before t, snapshots without timestamp are deleted as their age is unknown
+func (s *SQL) Prune(ctx context.Context, before time.Time) (int, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM snapshots where timestamp < $1`, timestamp(before))
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(deleted), nil
+}
+
+// timestamp formats t in UTC to make the stored timestamps comparable as strings
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -1,6 +1,7 @@
 package sql_test
 
 import (
+	"context"
 	sqldriver "database/sql"
 	"testing"
 
@@ -17,6 +18,14 @@ func TestSuite(t *testing.T) {
 	testsuite.TestSnapshotStore(t, f)
 }
 
+func TestPruner(t *testing.T) {
+	f := func() (core.SnapshotStore, core.SnapshotPruner, func(), error) {
+		ss, close, err := snapshotstore()
+		return ss, ss, close, err
+	}
+	testsuite.TestSnapshotPruner(t, f)
+}
+
 func TestMultipleMigrate(t *testing.T) {
 	ss, close, err := snapshotstore()
 	if err != nil {
@@ -29,6 +38,38 @@ func TestMultipleMigrate(t *testing.T) {
 	}
 }
 
+func TestMigrateSchemaVersion(t *testing.T) {
+	db, err := sqldriver.Open("sqlite3", "file::memory:?cache=shared&_schema_version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	// table layout from before the schema_version column was introduced
+	_, err = db.Exec(`create table snapshots (id VARCHAR NOT NULL, type VARCHAR, version INTEGER, global_version INTEGER, state BLOB);`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`INSERT INTO snapshots (state, id, type, version, global_version) VALUES ('{}', 'id', 'person', 1, 1)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ss := sql.Open(db)
+	err = ss.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := ss.Get(context.Background(), "id", "person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.SchemaVersion != 0 {
+		t.Fatalf("expected schema version 0 on migrated snapshot was %d", s.SchemaVersion)
+	}
+}
+
 func snapshotstore() (*sql.SQL, func(), error) {
 	db, err := sqldriver.Open("sqlite3", "file::memory:?locked.sqlite?cache=shared")
 	if err != nil {
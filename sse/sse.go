@@ -0,0 +1,115 @@
+// Package sse streams the global event feed as server-sent events. Browser dashboards and lightweight consumers can
+// follow the event store over HTTP without a message broker.
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Handler is an http.Handler streaming the events as server-sent events. The id of each message is the global version
+// of the event, a reconnecting client sends it in the Last-Event-ID header and the stream resumes after that event.
+// Clients without a Last-Event-ID can set the global version to start after in the "after" query parameter.
+type Handler struct {
+	Filter core.Filter   // Filter selects the events in the stream
+	Pace   time.Duration // Pace is the delay before the feed is read again when the client has all events
+	fetchF func(start core.Version) (core.Iterator, error)
+}
+
+// NewHandler creates a handler reading the global event feed via fetchF
+func NewHandler(fetchF func(start core.Version) (core.Iterator, error)) *Handler {
+	return &Handler{
+		Pace:   time.Second,
+		fetchF: fetchF,
+	}
+}
+
+// ServeHTTP streams the events until the client disconnects
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	position, err := lastEventID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	for {
+		position, err = h.stream(w, position)
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(h.Pace):
+		}
+	}
+}
+
+// stream writes the events after the position and returns the position of the last written event
+func (h *Handler) stream(w http.ResponseWriter, position core.Version) (core.Version, error) {
+	coreIterator, err := h.fetchF(position + 1)
+	if err != nil {
+		return position, err
+	}
+	iterator := eventsourcing.Iterator{CoreIterator: coreIterator}
+	defer iterator.Close()
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if errors.Is(err, eventsourcing.ErrEventNotRegistered) {
+			// step over events the process can't deserialize
+			position = core.Version(event.GlobalVersion())
+			continue
+		} else if err != nil {
+			return position, err
+		}
+		position = core.Version(event.GlobalVersion())
+		if !h.Filter.Match(event.CoreEvent()) || !h.Filter.MatchMetadata(event.Metadata()) {
+			continue
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return position, err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.GlobalVersion(), event.Reason(), data)
+		if err != nil {
+			return position, err
+		}
+	}
+	return position, nil
+}
+
+// lastEventID returns the global version the client has seen from the Last-Event-ID header or the after parameter
+func lastEventID(r *http.Request) (core.Version, error) {
+	id := r.Header.Get("Last-Event-ID")
+	if id == "" {
+		id = r.URL.Query().Get("after")
+	}
+	if id == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid last event id %q", id)
+	}
+	return core.Version(v), nil
+}
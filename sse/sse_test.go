@@ -0,0 +1,104 @@
+package sse_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	"github.com/hallgren/eventsourcing/sse"
+)
+
+type Person struct {
+	aggregate.Root
+}
+
+type Born struct {
+	Name string
+}
+
+type AgedOneYear struct{}
+
+func (p *Person) Register(f aggregate.RegisterFunc) {
+	f(&Born{}, &AgedOneYear{})
+}
+
+func (p *Person) Transition(event eventsourcing.Event) {}
+
+func TestHandler(t *testing.T) {
+	aggregate.Register(&Person{})
+	es := memory.Create()
+	p := Person{}
+	aggregate.TrackChange(&p, &Born{Name: "kalle"})
+	aggregate.TrackChange(&p, &AgedOneYear{})
+	aggregate.TrackChange(&p, &AgedOneYear{})
+	err := aggregate.Save(es, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := sse.NewHandler(func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 10)()
+	})
+	handler.Pace = 10 * time.Millisecond
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// resume after the first event
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected content type %s", resp.Header.Get("Content-Type"))
+	}
+
+	// the event saved while streaming is pushed to the client
+	aggregate.TrackChange(&p, &AgedOneYear{})
+	err = aggregate.Save(es, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	scanner := bufio.NewScanner(resp.Body)
+	for len(ids) < 3 && scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "id: ") {
+			ids = append(ids, strings.TrimPrefix(line, "id: "))
+		}
+		if strings.HasPrefix(line, "event: ") && line != "event: AgedOneYear" {
+			t.Fatalf("unexpected event %s", line)
+		}
+	}
+	if strings.Join(ids, ",") != "2,3,4" {
+		t.Fatalf("expected the events after 1 was %v", ids)
+	}
+}
+
+func TestHandlerInvalidLastEventID(t *testing.T) {
+	handler := sse.NewHandler(func(start core.Version) (core.Iterator, error) {
+		return core.ZeroIterator{}, nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/?after=abc", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected bad request was %d", w.Code)
+	}
+}
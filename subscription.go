@@ -0,0 +1,116 @@
+package eventsourcing
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Subscription receives the events published on the event bus matching its filter on a buffered channel. It's meant
+// for live features like websocket pushes and cache invalidation. Close the subscription when it's not used anymore.
+type Subscription struct {
+	bus    *EventBus
+	filter func(event Event) bool
+	events chan Event
+
+	lock   sync.RWMutex // held while sending to stop Close from closing the channel during a send
+	closed bool
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Listen returns a subscription to all events published on the bus. The channel of the subscription buffers size
+// events, Publish blocks when the buffer is full until there is room, the subscription is closed or the context of
+// the publish is done.
+func (b *EventBus) Listen(size int) *Subscription {
+	return b.listen(size, func(event Event) bool {
+		return true
+	})
+}
+
+// ListenAggregateType returns a subscription to the events of the aggregate types published on the bus
+func (b *EventBus) ListenAggregateType(size int, aggregateTypes ...string) *Subscription {
+	types := make(map[string]struct{}, len(aggregateTypes))
+	for _, t := range aggregateTypes {
+		types[t] = struct{}{}
+	}
+	return b.listen(size, func(event Event) bool {
+		_, ok := types[event.AggregateType()]
+		return ok
+	})
+}
+
+// ListenEvent returns a subscription to the events with data of the same type as the passed events
+//
+//	sub := bus.ListenEvent(100, &Born{}, &AgedOneYear{})
+func (b *EventBus) ListenEvent(size int, events ...interface{}) *Subscription {
+	types := make(map[reflect.Type]struct{}, len(events))
+	for _, e := range events {
+		types[reflect.TypeOf(e)] = struct{}{}
+	}
+	return b.listen(size, func(event Event) bool {
+		_, ok := types[reflect.TypeOf(event.Data())]
+		return ok
+	})
+}
+
+func (b *EventBus) listen(size int, filter func(event Event) bool) *Subscription {
+	s := &Subscription{
+		bus:    b,
+		filter: filter,
+		events: make(chan Event, size),
+		done:   make(chan struct{}),
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.subscriptions == nil {
+		b.subscriptions = make(map[*Subscription]struct{})
+	}
+	b.subscriptions[s] = struct{}{}
+	return s
+}
+
+// Subscriptions returns the number of open subscriptions on the bus
+func (b *EventBus) Subscriptions() int {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return len(b.subscriptions)
+}
+
+// Events returns the channel of the subscription, it's closed when the subscription is closed
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Close removes the subscription from the bus and closes its channel
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.lock.Lock()
+		delete(s.bus.subscriptions, s)
+		s.bus.lock.Unlock()
+
+		// release publishers blocked on a full channel before the channel is closed
+		close(s.done)
+		s.lock.Lock()
+		s.closed = true
+		close(s.events)
+		s.lock.Unlock()
+	})
+}
+
+// send passes the event to the subscription if it matches the filter
+func (s *Subscription) send(ctx context.Context, event Event) {
+	if !s.filter(event) {
+		return
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.events <- event:
+	case <-s.done:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,72 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+)
+
+func TestSubscriptions(t *testing.T) {
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+
+	bus := eventsourcing.NewEventBus()
+	all := bus.Listen(10)
+	persons := bus.ListenAggregateType(10, "Person")
+	others := bus.ListenAggregateType(10, "Other")
+	born := bus.ListenEvent(10, &Born{})
+	defer all.Close()
+	defer persons.Close()
+	defer others.Close()
+	defer born.Close()
+
+	bus.Publish(context.Background(), person.Events()...)
+
+	if len(all.Events()) != 2 || len(persons.Events()) != 2 || len(others.Events()) != 0 || len(born.Events()) != 1 {
+		t.Fatalf("unexpected number of events all: %d, persons: %d, others: %d, born: %d", len(all.Events()), len(persons.Events()), len(others.Events()), len(born.Events()))
+	}
+	event := <-born.Events()
+	if event.Data().(*Born).Name != "kalle" {
+		t.Fatalf("expected kalle was %v", event.Data())
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	person.GrowOlder()
+
+	bus := eventsourcing.NewEventBus()
+	sub := bus.Listen(1)
+
+	// the second event blocks the publish on the full buffer until the subscription is closed
+	published := make(chan struct{})
+	go func() {
+		bus.Publish(context.Background(), person.Events()...)
+		close(published)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	sub.Close()
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked after the subscription was closed")
+	}
+
+	count := 0
+	for range sub.Events() {
+		count++
+	}
+	if count != 1 {
+		t.Fatalf("expected the buffered event was %d", count)
+	}
+	// closed subscriptions don't get events
+	bus.Publish(context.Background(), person.Events()...)
+}
@@ -0,0 +1,195 @@
+// Package tracing instruments the event store, projections and publishers with OpenTelemetry spans. The trace context
+// of the save is stored in the event metadata, letting the handling of an event in a projection or publisher link back
+// to the request that saved it.
+package tracing
+
+import (
+	"context"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/hallgren/eventsourcing/tracing"
+
+// Span attributes
+const (
+	AttributeAggregateType = attribute.Key("eventsourcing.aggregate_type")
+	AttributeAggregateID   = attribute.Key("eventsourcing.aggregate_id")
+	AttributeReason        = attribute.Key("eventsourcing.reason")
+	AttributeGlobalVersion = attribute.Key("eventsourcing.global_version")
+	AttributeEvents        = attribute.Key("eventsourcing.events")
+)
+
+// Store is an event store creating a span for each save and each read of aggregate events. Loading an aggregate reads
+// its events, the span of the read lasts until the iterator is closed and covers the replay of the events.
+type Store struct {
+	es     core.EventStore
+	tracer trace.Tracer
+}
+
+// Wrap returns an event store tracing the calls to es with tracers from the provider
+func Wrap(es core.EventStore, tp trace.TracerProvider) *Store {
+	return &Store{
+		es:     es,
+		tracer: tp.Tracer(instrumentationName),
+	}
+}
+
+// Save saves the events in the underlying store in a span
+func (s *Store) Save(events []core.Event) error {
+	return s.SaveContext(context.Background(), events)
+}
+
+// SaveContext saves the events in the underlying store in a span that is a child of the span in the context
+func (s *Store) SaveContext(ctx context.Context, events []core.Event) error {
+	attributes := []attribute.KeyValue{AttributeEvents.Int(len(events))}
+	if len(events) > 0 {
+		attributes = append(attributes,
+			AttributeAggregateType.String(events[0].AggregateType),
+			AttributeAggregateID.String(events[0].AggregateID),
+		)
+	}
+	ctx, span := s.tracer.Start(ctx, "eventsourcing.save", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+	defer span.End()
+	err := core.SaveContext(ctx, s.es, events)
+	recordError(span, err)
+	return err
+}
+
+// SaveBatch saves the batches in the underlying store in a span, core.ErrBatchNotSupported is returned if the
+// underlying store is not a core.BatchSaver
+func (s *Store) SaveBatch(ctx context.Context, batches [][]core.Event) error {
+	saver, ok := s.es.(core.BatchSaver)
+	if !ok {
+		return core.ErrBatchNotSupported
+	}
+	events := 0
+	for _, batch := range batches {
+		events += len(batch)
+	}
+	ctx, span := s.tracer.Start(ctx, "eventsourcing.save_batch", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(AttributeEvents.Int(events)))
+	defer span.End()
+	err := saver.SaveBatch(ctx, batches)
+	recordError(span, err)
+	return err
+}
+
+// Unwrap returns the underlying event store
+func (s *Store) Unwrap() core.EventStore {
+	return s.es
+}
+
+// Get returns the events of the aggregate from the underlying store. The span ends when the iterator is closed.
+func (s *Store) Get(ctx context.Context, id string, aggregateType string, afterVersion core.Version) (core.Iterator, error) {
+	ctx, span := s.tracer.Start(ctx, "eventsourcing.get", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		AttributeAggregateType.String(aggregateType),
+		AttributeAggregateID.String(id),
+	))
+	it, err := s.es.Get(ctx, id, aggregateType, afterVersion)
+	if err != nil {
+		recordError(span, err)
+		span.End()
+		return nil, err
+	}
+	return &iterator{iterator: it, span: span}, nil
+}
+
+// Feed traces the fetch of events from a global event feed like the All method on the event stores. The span ends
+// when the iterator is closed.
+func (s *Store) Feed(fetchF func() (core.Iterator, error)) func() (core.Iterator, error) {
+	return func() (core.Iterator, error) {
+		_, span := s.tracer.Start(context.Background(), "eventsourcing.fetch", trace.WithSpanKind(trace.SpanKindClient))
+		it, err := fetchF()
+		if err != nil {
+			recordError(span, err)
+			span.End()
+			return nil, err
+		}
+		return &iterator{iterator: it, span: span}, nil
+	}
+}
+
+// Enricher injects the trace context of the save into the metadata of every event saved via a repository
+func Enricher() aggregate.Enricher {
+	return func(ctx context.Context, metadata map[string]interface{}) {
+		carrier := propagation.MapCarrier{}
+		otel.GetTextMapPropagator().Inject(ctx, carrier)
+		for k, v := range carrier {
+			metadata[k] = v
+		}
+	}
+}
+
+// Extract returns the context with the trace context stored in the event metadata by the Enricher
+func Extract(ctx context.Context, event eventsourcing.Event) context.Context {
+	carrier := propagation.MapCarrier{}
+	for k, v := range event.Metadata() {
+		if s, ok := v.(string); ok {
+			carrier[k] = s
+		}
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// Handler wraps the callback of a projection or the Handle method of a publisher with a span per event. The span is
+// linked to the trace the event was saved in.
+//
+//	p := eventsourcing.NewProjectionWithContext(fetchF, tracing.Handler(tp, "person_view", callback))
+func Handler(tp trace.TracerProvider, name string, f func(ctx context.Context, event eventsourcing.Event) error) func(ctx context.Context, event eventsourcing.Event) error {
+	tracer := tp.Tracer(instrumentationName)
+	return func(ctx context.Context, event eventsourcing.Event) error {
+		link := trace.LinkFromContext(Extract(context.Background(), event))
+		ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindConsumer), trace.WithLinks(link), trace.WithAttributes(
+			AttributeAggregateType.String(event.AggregateType()),
+			AttributeAggregateID.String(event.AggregateID()),
+			AttributeReason.String(event.Reason()),
+			AttributeGlobalVersion.Int64(int64(event.GlobalVersion())),
+		))
+		defer span.End()
+		err := f(ctx, event)
+		recordError(span, err)
+		return err
+	}
+}
+
+// iterator counts the events read and ends the span when it's closed
+type iterator struct {
+	iterator core.Iterator
+	span     trace.Span
+	events   int
+}
+
+func (i *iterator) Next() bool {
+	return i.iterator.Next()
+}
+
+func (i *iterator) Value() (core.Event, error) {
+	event, err := i.iterator.Value()
+	if err != nil {
+		recordError(i.span, err)
+		return event, err
+	}
+	i.events++
+	return event, nil
+}
+
+func (i *iterator) Close() {
+	i.iterator.Close()
+	i.span.SetAttributes(AttributeEvents.Int(i.events))
+	i.span.End()
+}
+
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
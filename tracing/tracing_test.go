@@ -0,0 +1,127 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	"github.com/hallgren/eventsourcing/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type Person struct {
+	aggregate.Root
+	Name string
+}
+
+type Born struct {
+	Name string
+}
+
+func (p *Person) Register(f aggregate.RegisterFunc) {
+	f(&Born{})
+}
+
+func (p *Person) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Born:
+		p.Name = e.Name
+	}
+}
+
+func TestTracing(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	mem := memory.Create()
+	es := tracing.Wrap(mem, tp)
+	persons := aggregate.NewRepository[Person](es)
+	persons.Enrich(tracing.Enricher())
+
+	// the request saving the person
+	ctx, request := tp.Tracer("test").Start(context.Background(), "request")
+	p := Person{}
+	aggregate.TrackChange(&p, &Born{Name: "kalle"})
+	err := persons.Save(ctx, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.End()
+
+	_, err = persons.Load(context.Background(), p.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	projection := eventsourcing.NewProjectionWithContext(es.Feed(mem.All(0, 10)), tracing.Handler(tp, "person_view", func(ctx context.Context, event eventsourcing.Event) error {
+		return nil
+	}))
+	_, result := projection.RunOnce()
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	for _, name := range []string{"request", "eventsourcing.save", "eventsourcing.get", "eventsourcing.fetch", "person_view"} {
+		if _, ok := spans[name]; !ok {
+			t.Fatalf("missing span %s", name)
+		}
+	}
+	if spans["eventsourcing.save"].Parent().SpanID() != request.SpanContext().SpanID() {
+		t.Fatal("expected the save span to be a child of the request span")
+	}
+	if !hasAttribute(spans["eventsourcing.get"], tracing.AttributeEvents.Int(1)) {
+		t.Fatalf("expected one event read was %v", spans["eventsourcing.get"].Attributes())
+	}
+	links := spans["person_view"].Links()
+	if len(links) != 1 || links[0].SpanContext.TraceID() != request.SpanContext().TraceID() {
+		t.Fatalf("expected the projection span to link to the request trace was %v", links)
+	}
+}
+
+func TestTracingUnitOfWork(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	es := tracing.Wrap(memory.Create(), tp)
+	aggregate.Register(&Person{})
+
+	kalle := Person{}
+	aggregate.TrackChange(&kalle, &Born{Name: "kalle"})
+	anka := Person{}
+	aggregate.TrackChange(&anka, &Born{Name: "anka"})
+	uow := aggregate.NewUnitOfWork(es)
+	uow.Add(&kalle, &anka)
+	err := uow.Commit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "eventsourcing.save_batch" || !hasAttribute(spans[0], tracing.AttributeEvents.Int(2)) {
+		t.Fatalf("expected one batch save span with two events was %v", spans)
+	}
+
+	// the event deleter of the wrapped event store is reached via Unwrap
+	err = aggregate.HardDelete(context.Background(), es, kalle.ID(), &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasAttribute(span sdktrace.ReadOnlySpan, kv attribute.KeyValue) bool {
+	for _, a := range span.Attributes() {
+		if a == kv {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package eventsourcing
+
+import (
+	"context"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// asyncTrigger is implemented by Projection and ProjectionGroup
+type asyncTrigger interface {
+	TriggerAsync()
+}
+
+// triggerStore wraps an event store and triggers projections after the events are saved. The optional read interfaces
+// of the wrapped event store are reached via Unwrap.
+type triggerStore struct {
+	core.EventStore
+	triggers []asyncTrigger
+}
+
+// TriggerOnSave returns an event store that triggers the projections or projection groups after each successful
+// save, making running projections wake up immediately instead of waiting for the pace. The trigger is asynchronous,
+// Save does not wait for the projections to handle the events.
+func TriggerOnSave(es core.EventStore, triggers ...asyncTrigger) core.EventStore {
+	return &triggerStore{
+		EventStore: es,
+		triggers:   triggers,
+	}
+}
+
+// Save saves the events and triggers the projections
+func (s *triggerStore) Save(events []core.Event) error {
+	return s.SaveContext(context.Background(), events)
+}
+
+// SaveContext saves the events bound by the context and triggers the projections
+func (s *triggerStore) SaveContext(ctx context.Context, events []core.Event) error {
+	err := core.SaveContext(ctx, s.EventStore, events)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	for _, t := range s.triggers {
+		t.TriggerAsync()
+	}
+	return nil
+}
+
+// SaveBatch saves the batches in the wrapped event store and triggers the projections, core.ErrBatchNotSupported is
+// returned if the wrapped event store is not a core.BatchSaver
+func (s *triggerStore) SaveBatch(ctx context.Context, batches [][]core.Event) error {
+	saver, ok := s.EventStore.(core.BatchSaver)
+	if !ok {
+		return core.ErrBatchNotSupported
+	}
+	err := saver.SaveBatch(ctx, batches)
+	if err != nil {
+		return err
+	}
+	for _, t := range s.triggers {
+		t.TriggerAsync()
+	}
+	return nil
+}
+
+// Unwrap returns the wrapped event store
+func (s *triggerStore) Unwrap() core.EventStore {
+	return s.EventStore
+}
@@ -0,0 +1,105 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+func TestTriggerOnSave(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+
+	handled := make(chan string, 1)
+	proj := eventsourcing.NewProjection(es.All(0, 1), func(event eventsourcing.Event) error {
+		switch e := event.Data().(type) {
+		case *Born:
+			handled <- e.Name
+		}
+		return nil
+	})
+
+	group := eventsourcing.NewProjectionGroup(proj)
+	group.Pace = time.Hour
+	group.Start()
+	defer group.Stop()
+
+	// make sure the projection has finished it's first round
+	time.Sleep(time.Millisecond * 10)
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Save(eventsourcing.TriggerOnSave(es, group), person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case name := <-handled:
+		if name != "kalle" {
+			t.Fatalf("expected kalle was %q", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the projection to be triggered by the save")
+	}
+}
+
+type countTrigger struct {
+	count atomic.Int32
+}
+
+func (c *countTrigger) TriggerAsync() {
+	c.count.Add(1)
+}
+
+func TestTriggerOnSaveWrappedEventStore(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+	trigger := &countTrigger{}
+	wrapped := eventsourcing.TriggerOnSave(es, trigger)
+
+	kalle, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	anka, err := CreatePerson("anka")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the batch save of the wrapped event store is used
+	uow := aggregate.NewUnitOfWork(wrapped)
+	uow.Add(kalle, anka)
+	err = uow.Commit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trigger.count.Load() != 1 {
+		t.Fatalf("expected the batch save to trigger once was %d", trigger.count.Load())
+	}
+
+	// the optional interfaces of the wrapped event store are reached via Unwrap
+	if u, ok := wrapped.(core.Unwrapper); !ok || u.Unwrap() != es {
+		t.Fatal("expected the wrapped event store from Unwrap")
+	}
+	err = aggregate.HardDelete(context.Background(), wrapped, kalle.ID(), &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := core.LastEvent(context.Background(), wrapped, kalle.ID(), "Person")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Reason != "Tombstone" {
+		t.Fatalf("expected the tombstone to be the last event was %s", last.Reason)
+	}
+}
@@ -0,0 +1,99 @@
+package eventsourcing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Validator inspects an event before it's saved, returning an error rejects the save
+type Validator func(event core.Event) error
+
+// ValidationError is returned when a validator rejects an event. No events from the save are stored.
+type ValidationError struct {
+	AggregateType string
+	AggregateID   string
+	Version       Version
+	Reason        string
+	Err           error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid event aggregate type: %s, id: %s, version: %d, reason: %s, %v", e.AggregateType, e.AggregateID, e.Version, e.Reason, e.Err)
+}
+
+// Unwrap returns the validator error
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// validateStore wraps an event store and validates the events before they are saved. The optional read interfaces of
+// the wrapped event store are reached via Unwrap.
+type validateStore struct {
+	core.EventStore
+	validators []Validator
+}
+
+// ValidateOnSave returns an event store that runs the validators on each event before it's saved. Malformed events
+// are rejected before they are appended to the event store where they would stay forever.
+func ValidateOnSave(es core.EventStore, validators ...Validator) core.EventStore {
+	return &validateStore{
+		EventStore: es,
+		validators: validators,
+	}
+}
+
+// Save validates the events and saves them if all are valid
+func (s *validateStore) Save(events []core.Event) error {
+	return s.SaveContext(context.Background(), events)
+}
+
+// SaveContext validates the events and saves them bound by the context if all are valid
+func (s *validateStore) SaveContext(ctx context.Context, events []core.Event) error {
+	err := s.validate(events)
+	if err != nil {
+		return err
+	}
+	return core.SaveContext(ctx, s.EventStore, events)
+}
+
+// SaveBatch validates the events of all batches and saves them in the wrapped event store if all are valid,
+// core.ErrBatchNotSupported is returned if the wrapped event store is not a core.BatchSaver
+func (s *validateStore) SaveBatch(ctx context.Context, batches [][]core.Event) error {
+	saver, ok := s.EventStore.(core.BatchSaver)
+	if !ok {
+		return core.ErrBatchNotSupported
+	}
+	for _, events := range batches {
+		err := s.validate(events)
+		if err != nil {
+			return err
+		}
+	}
+	return saver.SaveBatch(ctx, batches)
+}
+
+// Unwrap returns the wrapped event store
+func (s *validateStore) Unwrap() core.EventStore {
+	return s.EventStore
+}
+
+// validate runs the validators on the events
+func (s *validateStore) validate(events []core.Event) error {
+	for _, event := range events {
+		for _, validate := range s.validators {
+			err := validate(event)
+			if err != nil {
+				return &ValidationError{
+					AggregateType: event.AggregateType,
+					AggregateID:   event.AggregateID,
+					Version:       Version(event.Version),
+					Reason:        event.Reason,
+					Err:           err,
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package eventsourcing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+)
+
+var errBlankName = errors.New("name can't be blank")
+
+func validateBorn(event core.Event) error {
+	if event.Reason != "Born" {
+		return nil
+	}
+	born := Born{}
+	err := json.Unmarshal(event.Data, &born)
+	if err != nil {
+		return err
+	}
+	if born.Name == "" {
+		return errBlankName
+	}
+	return nil
+}
+
+func TestValidateOnSave(t *testing.T) {
+	es := memory.Create()
+	aggregate.Register(&Person{})
+	store := eventsourcing.ValidateOnSave(es, validateBorn)
+
+	person, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = aggregate.Save(store, person)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := Person{}
+	aggregate.TrackChange(&invalid, &Born{})
+	aggregate.TrackChange(&invalid, &AgedOneYear{})
+	err = aggregate.Save(store, &invalid)
+	var validationErr *eventsourcing.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError got %v", err)
+	}
+	if !errors.Is(err, errBlankName) {
+		t.Fatalf("expected the validator error got %v", err)
+	}
+	if validationErr.Reason != "Born" || validationErr.Version != 1 {
+		t.Fatalf("missing event context on error %v", validationErr)
+	}
+
+	// none of the events in the rejected save are stored
+	iterator, err := es.Get(context.Background(), invalid.ID(), "Person", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iterator.Close()
+	if iterator.Next() {
+		t.Fatal("expected no events stored from the rejected save")
+	}
+}
+
+func TestValidateOnSaveBatch(t *testing.T) {
+	// setup
+	es := memory.Create()
+	aggregate.Register(&Person{})
+	wrapped := eventsourcing.ValidateOnSave(es, validateBorn)
+
+	kalle, err := CreatePerson("kalle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blank := &Person{}
+	aggregate.TrackChange(blank, &Born{})
+
+	// the invalid event in one batch rejects all batches
+	uow := aggregate.NewUnitOfWork(wrapped)
+	uow.Add(kalle, blank)
+	err = uow.Commit(context.Background())
+	if !errors.Is(err, errBlankName) {
+		t.Fatalf("expected blank name error was %v", err)
+	}
+	exists, err := aggregate.Exists(context.Background(), wrapped, kalle.ID(), &Person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Fatal("expected no events to be saved")
+	}
+
+	uow = aggregate.NewUnitOfWork(wrapped)
+	uow.Add(kalle)
+	err = uow.Commit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, ok := wrapped.(core.Unwrapper); !ok || u.Unwrap() != es {
+		t.Fatal("expected the wrapped event store from Unwrap")
+	}
+}
@@ -0,0 +1,128 @@
+// Package webhook delivers events to HTTP endpoints. Each endpoint is a checkpoint projection over the global event
+// feed, keeping its own cursor, that POSTs the events as JSON signed with the endpoint secret.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+const (
+	// SignatureHeader holds the hex encoded HMAC-SHA256 of the request body signed with the endpoint secret
+	SignatureHeader = "X-Event-Signature"
+	// DeliveryHeader holds the global version of the event, receivers can use it to drop duplicates
+	DeliveryHeader = "X-Event-Global-Version"
+)
+
+// Endpoint is an HTTP endpoint receiving events
+type Endpoint struct {
+	Name   string      // Name is unique per endpoint and used as the checkpoint name of its cursor
+	URL    string      // URL the events are posted to
+	Secret string      // Secret signs the request body, the signature is not set when the secret is empty
+	Filter core.Filter // Filter selects the events posted to the endpoint
+}
+
+// Webhooks delivers the events from the global event feed to the registered endpoints
+type Webhooks struct {
+	Client  *http.Client  // Client posts the events
+	Retries int           // Retries is the number of times a failed delivery is retried before the endpoint projection fails
+	Backoff time.Duration // Backoff is the delay before the first retry, it's doubled on every retry
+	cs      core.CheckpointStore
+	fetchF  func(start core.Version) (core.Iterator, error)
+}
+
+// New creates webhooks reading the global event feed via fetchF and keeping the endpoint cursors in the checkpoint
+// store
+func New(cs core.CheckpointStore, fetchF func(start core.Version) (core.Iterator, error)) *Webhooks {
+	return &Webhooks{
+		Client:  &http.Client{Timeout: 10 * time.Second},
+		Retries: 3,
+		Backoff: time.Second,
+		cs:      cs,
+		fetchF:  fetchF,
+	}
+}
+
+// Projection returns the projection delivering the events to the endpoint. The projection stores the global version
+// of the last delivered event as its cursor, a restarted projection continues after it. The delivery is at least
+// once, an event can be delivered again if the projection stops before the cursor is stored.
+//
+//	manager.Add(webhooks.Projection(endpoint), eventsourcing.RestartBackoff)
+func (w *Webhooks) Projection(endpoint Endpoint) *eventsourcing.Projection {
+	p := eventsourcing.NewCheckpointProjection("webhook_"+endpoint.Name, 1, w.cs, w.fetchF, func(ctx context.Context, event eventsourcing.Event) error {
+		return w.deliver(ctx, endpoint, event)
+	})
+	p.Filter = endpoint.Filter
+	p.Strict = false
+	return p
+}
+
+// deliver posts the event to the endpoint, retrying on failure
+func (w *Webhooks) deliver(ctx context.Context, endpoint Endpoint, event eventsourcing.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	backoff := w.Backoff
+	err = w.post(ctx, endpoint, event, body)
+	for retry := 0; err != nil && retry < w.Retries; retry++ {
+		eventsourcing.Log().Warn("webhook delivery failed, retrying", "endpoint", endpoint.Name, "global_version", event.GlobalVersion(), "retry", retry+1, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		err = w.post(ctx, endpoint, event, body)
+	}
+	return err
+}
+
+// post sends the body to the endpoint, responses outside the 2xx range are errors
+func (w *Webhooks) post(ctx context.Context, endpoint Endpoint, event eventsourcing.Event, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(event.GlobalVersion()), 10))
+	if endpoint.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(endpoint.Secret, body))
+	}
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// read the body to reuse the connection
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s responded with status %d", endpoint.Name, resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body, receivers check the signature header with Verify
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature, the value of the signature header, is the signature of the body signed with
+// the secret. The comparison takes constant time to not leak the signature.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
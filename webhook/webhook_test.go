@@ -0,0 +1,143 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	cs "github.com/hallgren/eventsourcing/checkpointstore/memory"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	"github.com/hallgren/eventsourcing/webhook"
+)
+
+type Person struct {
+	aggregate.Root
+	Name string
+}
+
+type Born struct {
+	Name string
+}
+
+type AgedOneYear struct{}
+
+func (p *Person) Register(f aggregate.RegisterFunc) {
+	f(&Born{}, &AgedOneYear{})
+}
+
+func (p *Person) Transition(event eventsourcing.Event) {
+	switch e := event.Data().(type) {
+	case *Born:
+		p.Name = e.Name
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	aggregate.Register(&Person{})
+	es := memory.Create()
+	for _, name := range []string{"kalle", "anka"} {
+		p := Person{}
+		aggregate.TrackChange(&p, &Born{Name: name})
+		aggregate.TrackChange(&p, &AgedOneYear{})
+		err := aggregate.Save(es, &p)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var lock sync.Mutex
+	var names []string
+	failed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if !failed {
+			failed = true
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !webhook.Verify("secret", body, r.Header.Get(webhook.SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var event struct {
+			Reason string
+			Data   Born
+		}
+		json.Unmarshal(body, &event)
+		names = append(names, event.Data.Name)
+	}))
+	defer server.Close()
+
+	checkpoints := cs.Create()
+	webhooks := webhook.New(checkpoints, func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 10)()
+	})
+	webhooks.Backoff = time.Millisecond
+	endpoint := webhook.Endpoint{Name: "births", URL: server.URL, Secret: "secret", Filter: core.Filter{Reasons: []string{"Born"}}}
+
+	result := webhooks.Projection(endpoint).RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(names) != 2 || names[0] != "kalle" || names[1] != "anka" {
+		t.Fatalf("expected the births of kalle and anka was %v", names)
+	}
+
+	// the cursor of the endpoint makes a new projection continue after the delivered events
+	result = webhooks.Projection(endpoint).RunToEnd(context.Background())
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+	if len(names) != 2 {
+		t.Fatalf("expected no events to be delivered again was %v", names)
+	}
+}
+
+func TestWebhookFails(t *testing.T) {
+	aggregate.Register(&Person{})
+	es := memory.Create()
+	p := Person{}
+	aggregate.TrackChange(&p, &Born{Name: "kalle"})
+	err := aggregate.Save(es, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhooks := webhook.New(cs.Create(), func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 10)()
+	})
+	webhooks.Backoff = time.Millisecond
+	result := webhooks.Projection(webhook.Endpoint{Name: "failing", URL: server.URL}).RunToEnd(context.Background())
+	if result.Error == nil {
+		t.Fatal("expected error when the endpoint keeps failing")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"reason":"Born"}`)
+	signature := webhook.Sign("secret", body)
+	if !webhook.Verify("secret", body, signature) {
+		t.Fatal("expected the signature to verify")
+	}
+	if webhook.Verify("other", body, signature) {
+		t.Fatal("expected the signature of another secret to fail")
+	}
+	if webhook.Verify("secret", []byte(`{"reason":"Died"}`), signature) {
+		t.Fatal("expected the signature of another body to fail")
+	}
+}
@@ -0,0 +1,14 @@
+module github.com/hallgren/eventsourcing/wsfeed
+
+go 1.21
+
+require (
+	github.com/gorilla/websocket v1.5.3
+	github.com/hallgren/eventsourcing v0.9.0
+	github.com/hallgren/eventsourcing/core v0.5.0
+)
+
+// replace (
+// 	github.com/hallgren/eventsourcing => ../
+// 	github.com/hallgren/eventsourcing/core => ../core
+// )
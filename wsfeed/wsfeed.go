@@ -0,0 +1,148 @@
+// Package wsfeed pushes the global event feed to websocket clients. Each connection has its own filter on aggregate
+// type, event reason and start position that the client can change while connected, making it a fit for interactive
+// UIs that switch between views.
+package wsfeed
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/core"
+)
+
+// Subscription is the filter of a connection. The client sets it with the query parameters "type", "reason" (both
+// repeatable) and "after" when connecting and replaces it by sending a Subscription as a JSON message.
+type Subscription struct {
+	AggregateTypes []string `json:"aggregate_types"` // AggregateTypes selects events on any of the aggregate types
+	Reasons        []string `json:"reasons"`         // Reasons selects events with any of the reasons
+	After          *uint64  `json:"after,omitempty"` // After moves the connection to the events after the global version
+}
+
+// Handler is an http.Handler upgrading requests to websocket connections and pushing the events as JSON text
+// messages. A reconnecting client resumes by setting "after" to the global version of the last received event.
+type Handler struct {
+	Filter   core.Filter        // Filter selects the events available to all connections
+	Pace     time.Duration      // Pace is the delay before the feed is read again when the client has all events
+	Upgrader websocket.Upgrader // Upgrader upgrades the HTTP request, set CheckOrigin to allow cross origin clients
+	fetchF   func(start core.Version) (core.Iterator, error)
+}
+
+// NewHandler creates a handler reading the global event feed via fetchF
+func NewHandler(fetchF func(start core.Version) (core.Iterator, error)) *Handler {
+	return &Handler{
+		Pace:   time.Second,
+		fetchF: fetchF,
+	}
+}
+
+// ServeHTTP pushes the events until the client disconnects
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	sub, err := subscription(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	conn, err := h.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has replied to the client
+		return
+	}
+	defer conn.Close()
+
+	// the read loop is the only reader of the connection, it passes the subscriptions from the client to the
+	// write loop below
+	subscriptions := make(chan Subscription)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			var s Subscription
+			err := conn.ReadJSON(&s)
+			if err != nil {
+				return
+			}
+			select {
+			case subscriptions <- s:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}()
+
+	var position core.Version
+	if sub.After != nil {
+		position = core.Version(*sub.After)
+	}
+	for {
+		position, err = h.push(conn, sub, position)
+		if err != nil {
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, ""), time.Now().Add(time.Second))
+			return
+		}
+		select {
+		case <-done:
+			return
+		case <-r.Context().Done():
+			return
+		case s := <-subscriptions:
+			sub = s
+			if s.After != nil {
+				position = core.Version(*s.After)
+			}
+		case <-time.After(h.Pace):
+		}
+	}
+}
+
+// push writes the events after the position matching the subscription and returns the position of the last read event
+func (h *Handler) push(conn *websocket.Conn, sub Subscription, position core.Version) (core.Version, error) {
+	filter := core.Filter{AggregateTypes: sub.AggregateTypes, Reasons: sub.Reasons}
+	coreIterator, err := h.fetchF(position + 1)
+	if err != nil {
+		return position, err
+	}
+	iterator := eventsourcing.Iterator{CoreIterator: coreIterator}
+	defer iterator.Close()
+	for iterator.Next() {
+		event, err := iterator.Value()
+		if errors.Is(err, eventsourcing.ErrEventNotRegistered) {
+			// step over events the process can't deserialize
+			position = core.Version(event.GlobalVersion())
+			continue
+		} else if err != nil {
+			return position, err
+		}
+		position = core.Version(event.GlobalVersion())
+		if !h.Filter.Match(event.CoreEvent()) || !filter.Match(event.CoreEvent()) || !h.Filter.MatchMetadata(event.Metadata()) {
+			continue
+		}
+		err = conn.WriteJSON(event)
+		if err != nil {
+			return position, err
+		}
+	}
+	return position, nil
+}
+
+// subscription returns the subscription from the query parameters of the request
+func subscription(r *http.Request) (Subscription, error) {
+	query := r.URL.Query()
+	sub := Subscription{
+		AggregateTypes: query["type"],
+		Reasons:        query["reason"],
+	}
+	after := query.Get("after")
+	if after != "" {
+		v, err := strconv.ParseUint(after, 10, 64)
+		if err != nil {
+			return sub, fmt.Errorf("invalid after %q", after)
+		}
+		sub.After = &v
+	}
+	return sub, nil
+}
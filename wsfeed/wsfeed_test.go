@@ -0,0 +1,116 @@
+package wsfeed_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hallgren/eventsourcing"
+	"github.com/hallgren/eventsourcing/aggregate"
+	"github.com/hallgren/eventsourcing/core"
+	"github.com/hallgren/eventsourcing/eventstore/memory"
+	"github.com/hallgren/eventsourcing/wsfeed"
+)
+
+type Person struct {
+	aggregate.Root
+}
+
+type Born struct {
+	Name string
+}
+
+type AgedOneYear struct{}
+
+func (p *Person) Register(f aggregate.RegisterFunc) {
+	f(&Born{}, &AgedOneYear{})
+}
+
+func (p *Person) Transition(event eventsourcing.Event) {}
+
+type message struct {
+	AggregateType string `json:"aggregate_type"`
+	GlobalVersion uint64 `json:"global_version"`
+	Reason        string `json:"reason"`
+}
+
+func TestHandler(t *testing.T) {
+	aggregate.Register(&Person{})
+	es := memory.Create()
+	p := Person{}
+	aggregate.TrackChange(&p, &Born{Name: "kalle"})
+	aggregate.TrackChange(&p, &AgedOneYear{})
+	aggregate.TrackChange(&p, &AgedOneYear{})
+	err := aggregate.Save(es, &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := wsfeed.NewHandler(func(start core.Version) (core.Iterator, error) {
+		return es.All(start, 10)()
+	})
+	handler.Pace = 10 * time.Millisecond
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?reason=AgedOneYear&after=1"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	read := func() message {
+		var m message
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		err := conn.ReadJSON(&m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	for _, v := range []uint64{2, 3} {
+		m := read()
+		if m.GlobalVersion != v || m.Reason != "AgedOneYear" || m.AggregateType != "Person" {
+			t.Fatalf("unexpected message %+v", m)
+		}
+	}
+
+	// the client moves back to the start and only wants the Born events
+	after := uint64(0)
+	err = conn.WriteJSON(wsfeed.Subscription{Reasons: []string{"Born"}, After: &after})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := read()
+	if m.GlobalVersion != 1 || m.Reason != "Born" {
+		t.Fatalf("expected the Born event was %+v", m)
+	}
+
+	// the event saved while connected is pushed to the client
+	p2 := Person{}
+	aggregate.TrackChange(&p2, &Born{Name: "anka"})
+	err = aggregate.Save(es, &p2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = read()
+	if m.GlobalVersion != 4 || m.Reason != "Born" {
+		t.Fatalf("expected the new Born event was %+v", m)
+	}
+}
+
+func TestHandlerInvalidAfter(t *testing.T) {
+	handler := wsfeed.NewHandler(func(start core.Version) (core.Iterator, error) {
+		return core.ZeroIterator{}, nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/?after=abc", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected bad request was %d", w.Code)
+	}
+}